@@ -19,19 +19,60 @@ package v1alpha4
 import (
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.Cluster)
 
-	return Convert_v1alpha4_Cluster_To_v1beta1_Cluster(src, dst, nil)
+	if err := Convert_v1alpha4_Cluster_To_v1beta1_Cluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.Cluster{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	if restored.Spec.Topology != nil && dst.Spec.Topology != nil {
+		dst.Spec.Topology.Variables = restored.Spec.Topology.Variables
+		dst.Spec.Topology.ControlPlane.MachineHealthCheck = restored.Spec.Topology.ControlPlane.MachineHealthCheck
+
+		if restored.Spec.Topology.Workers != nil && dst.Spec.Topology.Workers != nil {
+			dst.Spec.Topology.Workers.Upgrade = restored.Spec.Topology.Workers.Upgrade
+			for i := range dst.Spec.Topology.Workers.MachineDeployments {
+				md := &dst.Spec.Topology.Workers.MachineDeployments[i]
+				for _, restoredMD := range restored.Spec.Topology.Workers.MachineDeployments {
+					if restoredMD.Name == md.Name {
+						md.Variables = restoredMD.Variables
+						md.UpgradeOrder = restoredMD.UpgradeOrder
+						md.MachineHealthCheck = restoredMD.MachineHealthCheck
+						break
+					}
+				}
+			}
+		}
+	}
+	dst.Status.Topology = restored.Status.Topology
+
+	return nil
 }
 
 func (dst *Cluster) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.Cluster)
 
-	return Convert_v1beta1_Cluster_To_v1alpha4_Cluster(src, dst, nil)
+	if err := Convert_v1beta1_Cluster_To_v1alpha4_Cluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *ClusterList) ConvertTo(dstRaw conversion.Hub) error {
@@ -49,13 +90,45 @@ func (dst *ClusterList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *ClusterClass) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.ClusterClass)
 
-	return Convert_v1alpha4_ClusterClass_To_v1beta1_ClusterClass(src, dst, nil)
+	if err := Convert_v1alpha4_ClusterClass_To_v1beta1_ClusterClass(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.ClusterClass{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Variables = restored.Spec.Variables
+	dst.Spec.Patches = restored.Spec.Patches
+	dst.Spec.ControlPlane.MachineHealthCheck = restored.Spec.ControlPlane.MachineHealthCheck
+	for i := range dst.Spec.Workers.MachineDeployments {
+		md := &dst.Spec.Workers.MachineDeployments[i]
+		for _, restoredMD := range restored.Spec.Workers.MachineDeployments {
+			if restoredMD.Class == md.Class {
+				md.MachineHealthCheck = restoredMD.MachineHealthCheck
+				break
+			}
+		}
+	}
+
+	return nil
 }
 
 func (dst *ClusterClass) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.ClusterClass)
 
-	return Convert_v1beta1_ClusterClass_To_v1alpha4_ClusterClass(src, dst, nil)
+	if err := Convert_v1beta1_ClusterClass_To_v1alpha4_ClusterClass(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *ClusterClassList) ConvertTo(dstRaw conversion.Hub) error {
@@ -170,3 +243,43 @@ func Convert_v1alpha4_MachineStatus_To_v1beta1_MachineStatus(in *MachineStatus,
 	// Status.version has been removed in v1beta1, thus requiring custom conversion function. the information will be dropped.
 	return autoConvert_v1alpha4_MachineStatus_To_v1beta1_MachineStatus(in, out, s)
 }
+
+func Convert_v1beta1_ClusterClassSpec_To_v1alpha4_ClusterClassSpec(in *v1beta1.ClusterClassSpec, out *ClusterClassSpec, s apiconversion.Scope) error {
+	// spec.{variables,patches} have been added with v1beta1.
+	return autoConvert_v1beta1_ClusterClassSpec_To_v1alpha4_ClusterClassSpec(in, out, s)
+}
+
+func Convert_v1beta1_ControlPlaneClass_To_v1alpha4_ControlPlaneClass(in *v1beta1.ControlPlaneClass, out *ControlPlaneClass, s apiconversion.Scope) error {
+	// spec.controlPlane.machineHealthCheck has been added with v1beta1.
+	return autoConvert_v1beta1_ControlPlaneClass_To_v1alpha4_ControlPlaneClass(in, out, s)
+}
+
+func Convert_v1beta1_MachineDeploymentClass_To_v1alpha4_MachineDeploymentClass(in *v1beta1.MachineDeploymentClass, out *MachineDeploymentClass, s apiconversion.Scope) error {
+	// spec.workers.machineDeployments[].machineHealthCheck has been added with v1beta1.
+	return autoConvert_v1beta1_MachineDeploymentClass_To_v1alpha4_MachineDeploymentClass(in, out, s)
+}
+
+func Convert_v1beta1_Topology_To_v1alpha4_Topology(in *v1beta1.Topology, out *Topology, s apiconversion.Scope) error {
+	// spec.topology.variables has been added with v1beta1.
+	return autoConvert_v1beta1_Topology_To_v1alpha4_Topology(in, out, s)
+}
+
+func Convert_v1beta1_MachineDeploymentTopology_To_v1alpha4_MachineDeploymentTopology(in *v1beta1.MachineDeploymentTopology, out *MachineDeploymentTopology, s apiconversion.Scope) error {
+	// spec.topology.workers.machineDeployments[].{variables,upgradeOrder,machineHealthCheck} have been added with v1beta1.
+	return autoConvert_v1beta1_MachineDeploymentTopology_To_v1alpha4_MachineDeploymentTopology(in, out, s)
+}
+
+func Convert_v1beta1_ControlPlaneTopology_To_v1alpha4_ControlPlaneTopology(in *v1beta1.ControlPlaneTopology, out *ControlPlaneTopology, s apiconversion.Scope) error {
+	// spec.topology.controlPlane.machineHealthCheck has been added with v1beta1.
+	return autoConvert_v1beta1_ControlPlaneTopology_To_v1alpha4_ControlPlaneTopology(in, out, s)
+}
+
+func Convert_v1beta1_WorkersTopology_To_v1alpha4_WorkersTopology(in *v1beta1.WorkersTopology, out *WorkersTopology, s apiconversion.Scope) error {
+	// spec.topology.workers.upgrade has been added with v1beta1.
+	return autoConvert_v1beta1_WorkersTopology_To_v1alpha4_WorkersTopology(in, out, s)
+}
+
+func Convert_v1beta1_ClusterStatus_To_v1alpha4_ClusterStatus(in *v1beta1.ClusterStatus, out *ClusterStatus, s apiconversion.Scope) error {
+	// status.topology has been added with v1beta1.
+	return autoConvert_v1beta1_ClusterStatus_To_v1alpha4_ClusterStatus(in, out, s)
+}
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	fuzz "github.com/google/gofuzz"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
@@ -28,12 +29,14 @@ import (
 
 func TestFuzzyConversion(t *testing.T) {
 	t.Run("for Cluster", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
-		Hub:   &v1beta1.Cluster{},
-		Spoke: &Cluster{},
+		Hub:         &v1beta1.Cluster{},
+		Spoke:       &Cluster{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{ClusterJSONFuzzFuncs},
 	}))
 	t.Run("for ClusterClass", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
-		Hub:   &v1beta1.ClusterClass{},
-		Spoke: &ClusterClass{},
+		Hub:         &v1beta1.ClusterClass{},
+		Spoke:       &ClusterClass{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{ClusterClassJSONFuzzFuncs},
 	}))
 
 	t.Run("for Machine", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
@@ -71,3 +74,71 @@ func MachineStatusFuzzer(in *MachineStatus, c fuzz.Continue) {
 	// data is going to be lost, so we're forcing zero values to avoid round trip errors.
 	in.Version = nil
 }
+
+func ClusterJSONFuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		ClusterVariableFuzzer,
+	}
+}
+
+func ClusterVariableFuzzer(in *v1beta1.ClusterVariable, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	// Not every random byte array is valid JSON, e.g. a string without `""`,so we're setting a valid value.
+	in.Value = apiextensionsv1.JSON{Raw: []byte("\"test-string\"")}
+}
+
+func ClusterClassJSONFuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		JSONPatchFuzzer,
+		JSONSchemaPropsFuzzer,
+	}
+}
+
+func JSONPatchFuzzer(in *v1beta1.JSONPatch, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	// Not every random byte array is valid JSON, e.g. a string without `""`,so we're setting a valid value.
+	in.Value = &apiextensionsv1.JSON{Raw: []byte("\"test-string\"")}
+}
+
+func JSONSchemaPropsFuzzer(in *v1beta1.JSONSchemaProps, c fuzz.Continue) {
+	// NOTE: We have to fuzz the individual fields manually,
+	// because we cannot call `FuzzNoCustom` as it would lead
+	// to an infinite recursion.
+	in.Type = c.RandString()
+	for i := 0; i < c.Intn(10); i++ {
+		in.Required = append(in.Required, c.RandString())
+	}
+	in.MaxItems = pointerInt64(c.Int63())
+	in.MinItems = pointerInt64(c.Int63())
+	in.UniqueItems = c.RandBool()
+	in.Format = c.RandString()
+	in.MaxLength = pointerInt64(c.Int63())
+	in.MinLength = pointerInt64(c.Int63())
+	in.Pattern = c.RandString()
+	in.Maximum = pointerInt64(c.Int63())
+	in.ExclusiveMaximum = c.RandBool()
+	in.Minimum = pointerInt64(c.Int63())
+	in.ExclusiveMinimum = c.RandBool()
+
+	// Not every random byte array is valid JSON, e.g. a string without `""`,so we're setting valid values.
+	in.Enum = []apiextensionsv1.JSON{
+		{Raw: []byte("\"a\"")},
+		{Raw: []byte("\"b\"")},
+		{Raw: []byte("\"c\"")},
+	}
+	in.Default = &apiextensionsv1.JSON{Raw: []byte(`{"a":"b"}`)}
+
+	// We're using a copy of the current JSONSchemaProps,
+	// because we cannot recursively fuzz new schemas.
+	in.Properties = map[string]v1beta1.JSONSchemaProps{}
+	for i := 0; i < c.Intn(10); i++ {
+		in.Properties[c.RandString()] = *in.DeepCopy()
+	}
+	in.Items = in.DeepCopy()
+}
+
+func pointerInt64(i int64) *int64 {
+	return &i
+}
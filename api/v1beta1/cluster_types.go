@@ -23,7 +23,9 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
 	capierrors "sigs.k8s.io/cluster-api/errors"
@@ -95,6 +97,12 @@ type Topology struct {
 	// for the cluster.
 	// +optional
 	Workers *WorkersTopology `json:"workers,omitempty"`
+
+	// Variables can be used to customize the Cluster through
+	// patches. They must comply to the corresponding
+	// variables defined in the ClusterClass.
+	// +optional
+	Variables []ClusterVariable `json:"variables,omitempty"`
 }
 
 // ControlPlaneTopology specifies the parameters for the control plane nodes in the cluster.
@@ -113,6 +121,11 @@ type ControlPlaneTopology struct {
 	// When specified against a control plane provider that lacks support for this field, this value will be ignored.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// MachineHealthCheck allows to enable, disable and override
+	// the MachineHealthCheck configuration in the ClusterClass for this control plane.
+	// +optional
+	MachineHealthCheck *MachineHealthCheckTopology `json:"machineHealthCheck,omitempty"`
 }
 
 // WorkersTopology represents the different sets of worker nodes in the cluster.
@@ -120,6 +133,20 @@ type WorkersTopology struct {
 	// MachineDeployments is a list of machine deployments in the cluster.
 	// +optional
 	MachineDeployments []MachineDeploymentTopology `json:"machineDeployments,omitempty"`
+
+	// Upgrade defines how the MachineDeployments are upgraded when the topology version changes.
+	// +optional
+	Upgrade *WorkersUpgrade `json:"upgrade,omitempty"`
+}
+
+// WorkersUpgrade defines how the MachineDeployments in the topology are upgraded.
+type WorkersUpgrade struct {
+	// MaxConcurrency is the maximum number of MachineDeployments that can be upgraded at the same time.
+	// Value can be an absolute number (ex: 5) or a percentage of the MachineDeployments in the topology (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up, and it is always at least 1.
+	// Defaults to 1.
+	// +optional
+	MaxConcurrency *intstr.IntOrString `json:"maxConcurrency,omitempty"`
 }
 
 // MachineDeploymentTopology specifies the different parameters for a set of worker nodes in the topology.
@@ -147,6 +174,66 @@ type MachineDeploymentTopology struct {
 	// of this value.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Variables can be used to customize the MachineDeployment through patches.
+	// +optional
+	Variables *MachineDeploymentVariables `json:"variables,omitempty"`
+
+	// UpgradeOrder defines the order in which MachineDeployments are upgraded when the topology version changes.
+	// MachineDeployments with a lower value are upgraded first; MachineDeployments with a higher value
+	// are not upgraded until all the MachineDeployments with a lower value have completed the upgrade.
+	// MachineDeployments with the same value are upgraded concurrently, according to workers.upgrade.maxConcurrency.
+	// Defaults to 0.
+	// +optional
+	UpgradeOrder *int32 `json:"upgradeOrder,omitempty"`
+
+	// MachineHealthCheck allows to enable, disable and override
+	// the MachineHealthCheck configuration in the ClusterClass for this MachineDeployment.
+	// +optional
+	MachineHealthCheck *MachineHealthCheckTopology `json:"machineHealthCheck,omitempty"`
+}
+
+// MachineHealthCheckTopology defines a MachineHealthCheck for a group of machines.
+type MachineHealthCheckTopology struct {
+	// Enable controls if a MachineHealthCheck should be created for the target machines.
+	//
+	// If false: No MachineHealthCheck will be created.
+	//
+	// If not set(default): A MachineHealthCheck will be created if it is defined here or
+	//  in the associated ClusterClass. If no MachineHealthCheck is defined then none will be created.
+	//
+	// If true: A MachineHealthCheck is guaranteed to be created. Cluster validation will
+	// block if `enable` is true and no MachineHealthCheck definition is available.
+	// +optional
+	Enable *bool `json:"enable,omitempty"`
+
+	// MachineHealthCheckClass defines a MachineHealthCheck for a group of machines.
+	// If specified (any field is set), it entirely overrides the MachineHealthCheckClass defined in ClusterClass.
+	MachineHealthCheckClass `json:",inline"`
+}
+
+// MachineDeploymentVariables can be used to provide variables for a specific MachineDeployment.
+type MachineDeploymentVariables struct {
+	// Overrides can be used to override Cluster level variables.
+	// +optional
+	Overrides []ClusterVariable `json:"overrides,omitempty"`
+}
+
+// ClusterVariable can be used to customize the Cluster through
+// patches. It must comply to the corresponding
+// ClusterClassVariable defined in the ClusterClass.
+type ClusterVariable struct {
+	// Name of the variable.
+	Name string `json:"name"`
+
+	// Value of the variable.
+	// Note: the value will be validated against the schema of the corresponding ClusterClassVariable
+	// from the ClusterClass.
+	// Note: We have to use apiextensionsv1.JSON instead of a custom JSON type, because controller-tools has a
+	// hard-coded schema for apiextensionsv1.JSON which cannot be produced by another type via controller-tools,
+	// i.e. it's not possible to have no type field.
+	// Ref: https://github.com/kubernetes-sigs/controller-tools/blob/d0e03a142d0ecdd5491593e941ee1d6b5d91dba6/pkg/crd/known_types.go#L106-L111
+	Value apiextensionsv1.JSON `json:"value"`
 }
 
 // ANCHOR_END: ClusterSpec
@@ -240,6 +327,39 @@ type ClusterStatus struct {
 	// ManagedExternalEtcdReady indicates external etcd cluster is fully provisioned
 	// +optional
 	ManagedExternalEtcdReady bool `json:"managedExternalEtcdReady"`
+
+	// Topology reports the status of the managed topology, if the Cluster uses one.
+	// +optional
+	Topology *TopologyStatus `json:"topology,omitempty"`
+}
+
+// TopologyStatus reports the status of a managed topology.
+type TopologyStatus struct {
+	// Class is the name of the ClusterClass the managed topology is reconciled with. While the Cluster is being
+	// rebased to a different ClusterClass, it is the name of the previous ClusterClass, until the templates
+	// of all the MachineDeployments have been rotated.
+	// +optional
+	Class string `json:"class,omitempty"`
+
+	// MachineDeployments reports the upgrade status of the MachineDeployments in the managed topology.
+	// +optional
+	MachineDeployments *MachineDeploymentsUpgradeStatus `json:"machineDeployments,omitempty"`
+}
+
+// MachineDeploymentsUpgradeStatus reports which MachineDeployments are pending, upgrading
+// or upgraded to the version defined in the managed topology.
+type MachineDeploymentsUpgradeStatus struct {
+	// Pending is the list of the names of the MachineDeployments waiting to be upgraded.
+	// +optional
+	Pending []string `json:"pending,omitempty"`
+
+	// Upgrading is the list of the names of the MachineDeployments rolling out the upgrade.
+	// +optional
+	Upgrading []string `json:"upgrading,omitempty"`
+
+	// Upgraded is the list of the names of the MachineDeployments which completed the upgrade.
+	// +optional
+	Upgraded []string `json:"upgraded,omitempty"`
 }
 
 // ANCHOR_END: ClusterStatus
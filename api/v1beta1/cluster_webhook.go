@@ -17,23 +17,36 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/blang/semver"
+	"github.com/gobuffalo/flect"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util/version"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func (c *Cluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	w := &clusterWebhook{Client: mgr.GetClient()}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(c).
+		WithDefaulter(w).
+		WithValidator(w).
 		Complete()
 }
 
@@ -59,6 +72,17 @@ func (c *Cluster) Default() {
 		if !strings.HasPrefix(c.Spec.Topology.Version, "v") {
 			c.Spec.Topology.Version = "v" + c.Spec.Topology.Version
 		}
+
+		if c.Spec.Topology.ControlPlane.MachineHealthCheck != nil {
+			defaultNamespace(c.Spec.Topology.ControlPlane.MachineHealthCheck.RemediationTemplate, c.Namespace)
+		}
+		if c.Spec.Topology.Workers != nil {
+			for i := range c.Spec.Topology.Workers.MachineDeployments {
+				if mhc := c.Spec.Topology.Workers.MachineDeployments[i].MachineHealthCheck; mhc != nil {
+					defaultNamespace(mhc.RemediationTemplate, c.Namespace)
+				}
+			}
+		}
 	}
 }
 
@@ -156,10 +180,18 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 		)
 	}
 
+	// Variables must be unique and have a valid JSON value.
+	allErrs = append(allErrs, validateClusterVariablesSyntax(c.Spec.Topology.Variables, field.NewPath("spec", "topology", "variables"))...)
+
+	// MachineHealthCheck overrides for the control plane must be valid.
+	if mhc := c.Spec.Topology.ControlPlane.MachineHealthCheck; mhc != nil && mhc.isDefined() {
+		allErrs = append(allErrs, mhc.validate(c.Namespace, field.NewPath("spec", "topology", "controlPlane", "machineHealthCheck"))...)
+	}
+
 	// MachineDeployment names must be unique.
 	if c.Spec.Topology.Workers != nil {
 		names := sets.String{}
-		for _, md := range c.Spec.Topology.Workers.MachineDeployments {
+		for i, md := range c.Spec.Topology.Workers.MachineDeployments {
 			if names.Has(md.Name) {
 				allErrs = append(allErrs,
 					field.Invalid(
@@ -170,6 +202,32 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 				)
 			}
 			names.Insert(md.Name)
+
+			// Variable overrides must be unique and have a valid JSON value.
+			if md.Variables != nil {
+				allErrs = append(allErrs, validateClusterVariablesSyntax(md.Variables.Overrides,
+					field.NewPath("spec", "topology", "workers", "machineDeployments").Index(i).Child("variables", "overrides"))...)
+			}
+
+			// MachineHealthCheck overrides must be valid.
+			if md.MachineHealthCheck != nil && md.MachineHealthCheck.isDefined() {
+				allErrs = append(allErrs, md.MachineHealthCheck.validate(c.Namespace,
+					field.NewPath("spec", "topology", "workers", "machineDeployments").Index(i).Child("machineHealthCheck"))...)
+			}
+		}
+	}
+
+	// MaxConcurrency for MachineDeployment upgrades must be a positive number or percentage.
+	if c.Spec.Topology.Workers != nil && c.Spec.Topology.Workers.Upgrade != nil && c.Spec.Topology.Workers.Upgrade.MaxConcurrency != nil {
+		maxConcurrency := c.Spec.Topology.Workers.Upgrade.MaxConcurrency
+		if value, err := intstr.GetScaledValueFromIntOrPercent(maxConcurrency, 100, true); err != nil || value < 1 {
+			allErrs = append(allErrs,
+				field.Invalid(
+					field.NewPath("spec", "topology", "workers", "upgrade", "maxConcurrency"),
+					maxConcurrency.String(),
+					"must be a positive number or a percentage greater than 0%",
+				),
+			)
 		}
 	}
 
@@ -197,17 +255,8 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 			)
 		}
 	default: // On update
-		// Class could not be mutated.
-		if c.Spec.Topology.Class != old.Spec.Topology.Class {
-			allErrs = append(
-				allErrs,
-				field.Invalid(
-					field.NewPath("spec", "topology", "class"),
-					c.Spec.Topology.Class,
-					"class cannot be changed",
-				),
-			)
-		}
+		// NOTE: Class could be changed only to a compatible ClusterClass; this is validated by
+		// clusterWebhook.validateClusterClassRebase, given that it requires to read both the ClusterClasses.
 
 		// Version could only be increased.
 		inVersion, err := semver.ParseTolerant(c.Spec.Topology.Version)
@@ -247,3 +296,383 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 
 	return allErrs
 }
+
+// validateClusterVariablesSyntax validates the Cluster variables without taking into account the
+// corresponding ClusterClass variables, e.g. that names are unique and values are valid JSON.
+func validateClusterVariablesSyntax(variables []ClusterVariable, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := sets.String{}
+	for i, variable := range variables {
+		if variable.Name == "" {
+			allErrs = append(allErrs, field.Required(pathPrefix.Index(i).Child("name"), "variable name must be defined"))
+		}
+		if names.Has(variable.Name) {
+			allErrs = append(allErrs,
+				field.Invalid(
+					pathPrefix.Index(i).Child("name"),
+					variable.Name,
+					fmt.Sprintf("variable names should be unique. Variable with name %q is defined more than once.", variable.Name),
+				),
+			)
+		}
+		names.Insert(variable.Name)
+
+		if !json.Valid(variable.Value.Raw) {
+			allErrs = append(allErrs, field.Invalid(pathPrefix.Index(i).Child("value"), string(variable.Value.Raw), "must be valid JSON"))
+		}
+	}
+
+	return allErrs
+}
+
+var _ admission.CustomDefaulter = &clusterWebhook{}
+var _ admission.CustomValidator = &clusterWebhook{}
+
+// clusterWebhook extends the Cluster defaulting and validation webhooks with the operations
+// requiring the ClusterClass referenced in Cluster.Spec.Topology, e.g. variables defaulting and validation.
+type clusterWebhook struct {
+	Client client.Reader
+}
+
+// Default implements admission.CustomDefaulter.
+func (w *clusterWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	cluster, ok := obj.(*Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", obj))
+	}
+
+	cluster.Default()
+
+	if cluster.Spec.Topology == nil || !feature.Gates.Enabled(feature.ClusterTopology) {
+		return nil
+	}
+
+	clusterClass, err := w.getClusterClass(ctx, cluster)
+	if err != nil {
+		// NOTE: If the ClusterClass does not exist yet, variables are going to be defaulted
+		// by the topology controller when computing the desired state.
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return apierrors.NewInternalError(err)
+	}
+
+	variables, err := defaultClusterVariables(cluster.Spec.Topology.Variables, clusterClass.Spec.Variables)
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	cluster.Spec.Topology.Variables = variables
+
+	// NOTE: Variable overrides of MachineDeployments are not added when not set, because in this case
+	// the Cluster level values apply.
+	if cluster.Spec.Topology.Workers != nil {
+		for i := range cluster.Spec.Topology.Workers.MachineDeployments {
+			md := &cluster.Spec.Topology.Workers.MachineDeployments[i]
+			if md.Variables == nil {
+				continue
+			}
+			overrides, err := defaultClusterVariableValues(md.Variables.Overrides, clusterClass.Spec.Variables)
+			if err != nil {
+				return apierrors.NewBadRequest(err.Error())
+			}
+			md.Variables.Overrides = overrides
+		}
+	}
+	return nil
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (w *clusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	cluster, ok := obj.(*Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", obj))
+	}
+	if err := cluster.ValidateCreate(); err != nil {
+		return err
+	}
+	if err := w.validateManagedExternalEtcdRef(ctx, nil, cluster); err != nil {
+		return err
+	}
+	clusterClass, err := w.getTopologyClusterClass(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	return validateWithClusterClass(cluster, clusterClass)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (w *clusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	cluster, ok := newObj.(*Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", newObj))
+	}
+	if err := cluster.ValidateUpdate(oldObj); err != nil {
+		return err
+	}
+	clusterClass, err := w.getTopologyClusterClass(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if err := w.validateClusterClassRebase(ctx, oldObj.(*Cluster), cluster, clusterClass); err != nil {
+		return err
+	}
+	if err := w.validateManagedExternalEtcdRef(ctx, oldObj.(*Cluster), cluster); err != nil {
+		return err
+	}
+	return validateWithClusterClass(cluster, clusterClass)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (w *clusterWebhook) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	cluster, ok := obj.(*Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", obj))
+	}
+	return cluster.ValidateDelete()
+}
+
+// getTopologyClusterClass gets the ClusterClass referenced in Cluster.Spec.Topology, so it is read only once
+// for all the validations requiring it; nil is returned if the Cluster does not have a managed topology or
+// if the ClusterClass does not exist yet.
+func (w *clusterWebhook) getTopologyClusterClass(ctx context.Context, cluster *Cluster) (*ClusterClass, error) {
+	if cluster.Spec.Topology == nil || !feature.Gates.Enabled(feature.ClusterTopology) {
+		return nil, nil
+	}
+
+	clusterClass, err := w.getClusterClass(ctx, cluster)
+	if err != nil {
+		// NOTE: If the ClusterClass does not exist yet, it is not possible to validate variables and MachineHealthChecks;
+		// the topology controller is going to report missing required variables when computing the desired state, and
+		// to create MachineHealthChecks only if a definition is available.
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, apierrors.NewInternalError(err)
+	}
+	return clusterClass, nil
+}
+
+// validateWithClusterClass runs the validations of the Cluster requiring the ClusterClass referenced in Cluster.Spec.Topology.
+func validateWithClusterClass(cluster *Cluster, clusterClass *ClusterClass) error {
+	if clusterClass == nil {
+		return nil
+	}
+
+	allErrs := validateTopologyMachineHealthChecks(cluster, clusterClass)
+	allErrs = append(allErrs, validateTopologyVariables(cluster, clusterClass)...)
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Cluster").GroupKind(), cluster.Name, allErrs)
+	}
+	return nil
+}
+
+// validateTopologyVariables validates the Cluster variables against the schema of the variables defined in the ClusterClass.
+func validateTopologyVariables(cluster *Cluster, clusterClass *ClusterClass) field.ErrorList {
+	allErrs := validateClusterVariables(cluster.Spec.Topology.Variables, clusterClass.Spec.Variables, field.NewPath("spec", "topology", "variables"))
+	if cluster.Spec.Topology.Workers != nil {
+		for i, md := range cluster.Spec.Topology.Workers.MachineDeployments {
+			if md.Variables == nil {
+				continue
+			}
+			allErrs = append(allErrs, validateClusterVariableValues(md.Variables.Overrides, clusterClass.Spec.Variables,
+				field.NewPath("spec", "topology", "workers", "machineDeployments").Index(i).Child("variables", "overrides"))...)
+		}
+	}
+	return allErrs
+}
+
+// validateTopologyMachineHealthChecks validates that a MachineHealthCheck definition is available, either in the Cluster topology
+// or in the ClusterClass, for the control plane and the MachineDeployments explicitly enabling MachineHealthChecks.
+func validateTopologyMachineHealthChecks(cluster *Cluster, clusterClass *ClusterClass) field.ErrorList {
+	var allErrs field.ErrorList
+	if mhc := cluster.Spec.Topology.ControlPlane.MachineHealthCheck; mhc != nil && mhc.Enable != nil && *mhc.Enable {
+		fldPath := field.NewPath("spec", "topology", "controlPlane", "machineHealthCheck", "enable")
+		switch {
+		case clusterClass.Spec.ControlPlane.MachineInfrastructure == nil:
+			allErrs = append(allErrs, field.Forbidden(fldPath,
+				fmt.Sprintf("cannot be true because ClusterClass %q does not define a Machine based control plane", clusterClass.Name)))
+		case clusterClass.Spec.ControlPlane.MachineHealthCheck == nil && !mhc.isDefined():
+			allErrs = append(allErrs, field.Forbidden(fldPath,
+				fmt.Sprintf("cannot be true because a MachineHealthCheck is not defined in the topology or in ClusterClass %q", clusterClass.Name)))
+		}
+	}
+	if cluster.Spec.Topology.Workers != nil {
+		mhcClasses := map[string]*MachineHealthCheckClass{}
+		for _, mdClass := range clusterClass.Spec.Workers.MachineDeployments {
+			mhcClasses[mdClass.Class] = mdClass.MachineHealthCheck
+		}
+		for i, md := range cluster.Spec.Topology.Workers.MachineDeployments {
+			mhc := md.MachineHealthCheck
+			if mhc == nil || mhc.Enable == nil || !*mhc.Enable || mhc.isDefined() || mhcClasses[md.Class] != nil {
+				continue
+			}
+			allErrs = append(allErrs, field.Forbidden(
+				field.NewPath("spec", "topology", "workers", "machineDeployments").Index(i).Child("machineHealthCheck", "enable"),
+				fmt.Sprintf("cannot be true because a MachineHealthCheck is not defined in the topology or in MachineDeployment class %q", md.Class)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateClusterClassRebase validates that a Cluster can be rebased from the ClusterClass referenced by the old Cluster
+// to the ClusterClass referenced by the new Cluster, if the class has been changed.
+// NOTE: newClusterClass is the ClusterClass referenced by the new Cluster, nil if it does not exist.
+func (w *clusterWebhook) validateClusterClassRebase(ctx context.Context, oldCluster, newCluster *Cluster, newClusterClass *ClusterClass) error {
+	if oldCluster.Spec.Topology == nil || newCluster.Spec.Topology == nil ||
+		oldCluster.Spec.Topology.Class == newCluster.Spec.Topology.Class {
+		return nil
+	}
+
+	classPath := field.NewPath("spec", "topology", "class")
+	oldClusterClass, err := w.getClusterClass(ctx, oldCluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierrors.NewInvalid(GroupVersion.WithKind("Cluster").GroupKind(), newCluster.Name, field.ErrorList{
+				field.Invalid(classPath, newCluster.Spec.Topology.Class,
+					fmt.Sprintf("cannot be changed because ClusterClass %q does not exist", oldCluster.Spec.Topology.Class)),
+			})
+		}
+		return apierrors.NewInternalError(err)
+	}
+	if newClusterClass == nil {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Cluster").GroupKind(), newCluster.Name, field.ErrorList{
+			field.Invalid(classPath, newCluster.Spec.Topology.Class,
+				fmt.Sprintf("cannot be changed because ClusterClass %q does not exist", newCluster.Spec.Topology.Class)),
+		})
+	}
+
+	if allErrs := oldClusterClass.ValidateRebase(newClusterClass, classPath); len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Cluster").GroupKind(), newCluster.Name, allErrs)
+	}
+	return nil
+}
+
+// ValidateRebase validates that a Cluster can be rebased from this ClusterClass to the desired ClusterClass,
+// i.e. both ClusterClasses are in the same namespace, the templates for the InfrastructureCluster, the ControlPlane
+// and the ControlPlane InfrastructureMachines are of the same GroupKind, and all the MachineDeploymentClasses of
+// this ClusterClass are preserved with templates of the same GroupKind.
+// NOTE: This is used both by the Cluster webhook and by the topology controller.
+func (c *ClusterClass) ValidateRebase(desired *ClusterClass, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	invalid := func(detail string, args ...interface{}) {
+		allErrs = append(allErrs, field.Invalid(pathPrefix, desired.Name,
+			fmt.Sprintf("ClusterClass %q is not compatible with ClusterClass %q: ", desired.Name, c.Name)+fmt.Sprintf(detail, args...)))
+	}
+	checkTemplateRef := func(name string, currentRef, desiredRef *corev1.ObjectReference) {
+		if currentRef == nil || desiredRef == nil {
+			if currentRef != desiredRef {
+				invalid("%s cannot be added or removed", name)
+			}
+			return
+		}
+		currentGK := currentRef.GroupVersionKind().GroupKind()
+		desiredGK := desiredRef.GroupVersionKind().GroupKind()
+		if currentGK != desiredGK {
+			invalid("the GroupKind of the template in %s cannot be changed from %s to %s", name, currentGK, desiredGK)
+		}
+	}
+
+	if c.Namespace != desired.Namespace {
+		invalid("the namespace cannot be changed from %s to %s", c.Namespace, desired.Namespace)
+	}
+
+	checkTemplateRef("spec.infrastructure", c.Spec.Infrastructure.Ref, desired.Spec.Infrastructure.Ref)
+	checkTemplateRef("spec.controlPlane", c.Spec.ControlPlane.Ref, desired.Spec.ControlPlane.Ref)
+	switch {
+	case c.Spec.ControlPlane.MachineInfrastructure == nil && desired.Spec.ControlPlane.MachineInfrastructure == nil:
+	case c.Spec.ControlPlane.MachineInfrastructure == nil || desired.Spec.ControlPlane.MachineInfrastructure == nil:
+		invalid("spec.controlPlane.machineInfrastructure cannot be added or removed")
+	default:
+		checkTemplateRef("spec.controlPlane.machineInfrastructure",
+			c.Spec.ControlPlane.MachineInfrastructure.Ref, desired.Spec.ControlPlane.MachineInfrastructure.Ref)
+	}
+
+	desiredMachineDeploymentClasses := map[string]MachineDeploymentClass{}
+	for _, mdClass := range desired.Spec.Workers.MachineDeployments {
+		desiredMachineDeploymentClasses[mdClass.Class] = mdClass
+	}
+	for _, currentMDClass := range c.Spec.Workers.MachineDeployments {
+		desiredMDClass, ok := desiredMachineDeploymentClasses[currentMDClass.Class]
+		if !ok {
+			invalid("MachineDeployment class %q cannot be removed", currentMDClass.Class)
+			continue
+		}
+		checkTemplateRef(fmt.Sprintf("MachineDeployment class %q template.bootstrap", currentMDClass.Class),
+			currentMDClass.Template.Bootstrap.Ref, desiredMDClass.Template.Bootstrap.Ref)
+		checkTemplateRef(fmt.Sprintf("MachineDeployment class %q template.infrastructure", currentMDClass.Class),
+			currentMDClass.Template.Infrastructure.Ref, desiredMDClass.Template.Infrastructure.Ref)
+	}
+
+	return allErrs
+}
+
+// validateManagedExternalEtcdRef validates that the CustomResourceDefinition of the object referenced in
+// Cluster.Spec.ManagedExternalEtcdRef has the Cluster API contract label, and that the referenced apiVersion
+// is one of the versions declared as compliant with the etcd provider contract.
+// NOTE: The check is skipped on update if the reference has not been changed, so existing Clusters are not
+// blocked if an etcd provider is upgraded to a version not compliant with the contract.
+func (w *clusterWebhook) validateManagedExternalEtcdRef(ctx context.Context, oldCluster, newCluster *Cluster) error {
+	ref := newCluster.Spec.ManagedExternalEtcdRef
+	if ref == nil {
+		return nil
+	}
+	if oldCluster != nil && oldCluster.Spec.ManagedExternalEtcdRef != nil &&
+		oldCluster.Spec.ManagedExternalEtcdRef.APIVersion == ref.APIVersion &&
+		oldCluster.Spec.ManagedExternalEtcdRef.Kind == ref.Kind {
+		return nil
+	}
+
+	refPath := field.NewPath("spec", "managedExternalEtcdRef")
+	gvk := ref.GroupVersionKind()
+	crdMetadata := &metav1.PartialObjectMetadata{}
+	crdMetadata.SetName(fmt.Sprintf("%s.%s", flect.Pluralize(strings.ToLower(gvk.Kind)), gvk.Group))
+	crdMetadata.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	if err := w.Client.Get(ctx, client.ObjectKeyFromObject(crdMetadata), crdMetadata); err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierrors.NewInvalid(GroupVersion.WithKind("Cluster").GroupKind(), newCluster.Name, field.ErrorList{
+				field.Invalid(refPath, gvk.GroupKind().String(),
+					fmt.Sprintf("CustomResourceDefinition %q does not exist", crdMetadata.Name)),
+			})
+		}
+		return apierrors.NewInternalError(errors.Wrapf(err, "failed to get CustomResourceDefinition %s", crdMetadata.Name))
+	}
+
+	if allErrs := validateEtcdProviderContract(crdMetadata, gvk.Version, refPath); len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Cluster").GroupKind(), newCluster.Name, allErrs)
+	}
+	return nil
+}
+
+// validateEtcdProviderContract validates that the CustomResourceDefinition metadata has the Cluster API contract label,
+// e.g. cluster.x-k8s.io/v1beta1: v1alpha1_v1beta1, and that the given version is one of the versions listed in the label.
+func validateEtcdProviderContract(crdMetadata *metav1.PartialObjectMetadata, version string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	contractVersions, ok := crdMetadata.GetLabels()[GroupVersion.String()]
+	if !ok {
+		allErrs = append(allErrs, field.Invalid(fldPath, crdMetadata.Name,
+			fmt.Sprintf("CustomResourceDefinition %q must have the %q label to be used as a managed external etcd provider", crdMetadata.Name, GroupVersion.String())))
+		return allErrs
+	}
+	for _, v := range strings.Split(contractVersions, "_") {
+		if v == version {
+			return allErrs
+		}
+	}
+	allErrs = append(allErrs, field.Invalid(fldPath, version,
+		fmt.Sprintf("version %q of CustomResourceDefinition %q is not compliant with the %s etcd provider contract, compliant versions are %q",
+			version, crdMetadata.Name, GroupVersion.String(), contractVersions)))
+	return allErrs
+}
+
+func (w *clusterWebhook) getClusterClass(ctx context.Context, cluster *Cluster) (*ClusterClass, error) {
+	clusterClass := &ClusterClass{}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.Topology.Class}
+	if err := w.Client.Get(ctx, key, clusterClass); err != nil {
+		return nil, errors.Wrapf(err, "failed to get ClusterClass %s", key)
+	}
+	return clusterClass, nil
+}
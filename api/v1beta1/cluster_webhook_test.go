@@ -17,14 +17,23 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilfeature "k8s.io/component-base/featuregate/testing"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/cluster-api/feature"
 	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestClusterDefaultNamespaces(t *testing.T) {
@@ -198,6 +207,51 @@ func TestClusterTopologyValidation(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "should return error when max concurrency for MachineDeployment upgrades is zero",
+			expectErr: true,
+			in: &Cluster{
+				Spec: ClusterSpec{
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+						Workers: &WorkersTopology{
+							Upgrade: &WorkersUpgrade{MaxConcurrency: intOrStrPtr(intstr.FromInt(0))},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should return error when max concurrency for MachineDeployment upgrades is an invalid percentage",
+			expectErr: true,
+			in: &Cluster{
+				Spec: ClusterSpec{
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+						Workers: &WorkersTopology{
+							Upgrade: &WorkersUpgrade{MaxConcurrency: intOrStrPtr(intstr.FromString("ten"))},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should pass when max concurrency for MachineDeployment upgrades is a percentage",
+			expectErr: false,
+			in: &Cluster{
+				Spec: ClusterSpec{
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+						Workers: &WorkersTopology{
+							Upgrade: &WorkersUpgrade{MaxConcurrency: intOrStrPtr(intstr.FromString("25%"))},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:      "should return error when downgrading topology version - major",
 			expectErr: true,
@@ -369,8 +423,9 @@ func TestClusterTopologyValidation(t *testing.T) {
 			},
 		},
 		{
-			name:      "should return error on update when Topology class is changed",
-			expectErr: true,
+			// NOTE: Compatibility between the ClusterClasses is validated by clusterWebhook.validateClusterClassRebase.
+			name:      "should not return error on update when Topology class is changed",
+			expectErr: false,
 			old: &Cluster{
 				Spec: ClusterSpec{
 					InfrastructureRef: &corev1.ObjectReference{},
@@ -469,3 +524,485 @@ func TestClusterTopologyValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterWebhookVariables(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+
+	clusterClass := &ClusterClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "class1",
+			Namespace: "default",
+		},
+		Spec: ClusterClassSpec{
+			Variables: []ClusterClassVariable{
+				{
+					Name:     "location",
+					Required: true,
+					Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+						Type:    "string",
+						Default: &apiextensionsv1.JSON{Raw: []byte(`"us"`)},
+					}},
+				},
+				{
+					Name:   "replicas",
+					Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "integer"}},
+				},
+			},
+		},
+	}
+	w := &clusterWebhook{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterClass).Build()}
+
+	newCluster := func(class string, variables ...ClusterVariable) *Cluster {
+		return &Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster1",
+				Namespace: "default",
+			},
+			Spec: ClusterSpec{
+				Topology: &Topology{
+					Class:     class,
+					Version:   "v1.21.2",
+					Variables: variables,
+				},
+			},
+		}
+	}
+
+	t.Run("default variables from the ClusterClass", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := newCluster("class1")
+		g.Expect(w.Default(context.Background(), cluster)).To(Succeed())
+		g.Expect(cluster.Spec.Topology.Variables).To(Equal([]ClusterVariable{
+			{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us"`)}},
+		}))
+		g.Expect(w.ValidateCreate(context.Background(), cluster)).To(Succeed())
+	})
+
+	t.Run("fail with invalid variables", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := newCluster("class1",
+			ClusterVariable{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us"`)}},
+			ClusterVariable{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`"three"`)}},
+		)
+		g.Expect(w.ValidateCreate(context.Background(), cluster)).ToNot(Succeed())
+		g.Expect(w.ValidateUpdate(context.Background(), cluster.DeepCopy(), cluster)).ToNot(Succeed())
+	})
+
+	t.Run("validate variable overrides of MachineDeployments", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := newCluster("class1",
+			ClusterVariable{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us"`)}},
+		)
+		cluster.Spec.Topology.Workers = &WorkersTopology{
+			MachineDeployments: []MachineDeploymentTopology{
+				{
+					Class: "aa",
+					Name:  "md1",
+					Variables: &MachineDeploymentVariables{
+						Overrides: []ClusterVariable{
+							{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+						},
+					},
+				},
+			},
+		}
+		g.Expect(w.Default(context.Background(), cluster)).To(Succeed())
+		g.Expect(cluster.Spec.Topology.Workers.MachineDeployments[0].Variables.Overrides).To(HaveLen(1))
+		g.Expect(w.ValidateCreate(context.Background(), cluster)).To(Succeed())
+
+		cluster.Spec.Topology.Workers.MachineDeployments[0].Variables.Overrides = []ClusterVariable{
+			{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`"three"`)}},
+		}
+		g.Expect(w.ValidateCreate(context.Background(), cluster)).ToNot(Succeed())
+
+		cluster.Spec.Topology.Workers.MachineDeployments[0].Variables.Overrides = []ClusterVariable{
+			{Name: "unknown", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+		}
+		g.Expect(w.ValidateCreate(context.Background(), cluster)).ToNot(Succeed())
+	})
+
+	t.Run("skip variables when the ClusterClass does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := newCluster("does-not-exist")
+		g.Expect(w.Default(context.Background(), cluster)).To(Succeed())
+		g.Expect(cluster.Spec.Topology.Variables).To(BeEmpty())
+		g.Expect(w.ValidateCreate(context.Background(), cluster)).To(Succeed())
+	})
+
+	t.Run("read the ClusterClass once for all the validations", func(t *testing.T) {
+		g := NewWithT(t)
+
+		reader := &countingReader{Reader: w.Client}
+		cw := &clusterWebhook{Client: reader}
+		cluster := newCluster("class1",
+			ClusterVariable{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us"`)}},
+		)
+		g.Expect(cw.ValidateCreate(context.Background(), cluster)).To(Succeed())
+		g.Expect(reader.gets).To(Equal(1))
+	})
+
+	t.Run("skip variables validation when the ClusterTopology feature flag is disabled", func(t *testing.T) {
+		defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, false)()
+		g := NewWithT(t)
+
+		cluster := newCluster("class1",
+			ClusterVariable{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`"three"`)}},
+		)
+		clusterClass, err := w.getTopologyClusterClass(context.Background(), cluster)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(clusterClass).To(BeNil())
+		g.Expect(validateWithClusterClass(cluster, clusterClass)).To(Succeed())
+	})
+}
+
+func TestClusterWebhookClusterClassRebase(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+
+	ref := func(kind, name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: kind, Namespace: "default", Name: name}
+	}
+	newClusterClass := func(name, infraKind string, mdClasses ...string) *ClusterClass {
+		clusterClass := &ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: ClusterClassSpec{
+				Infrastructure: LocalObjectTemplate{Ref: ref(infraKind, name+"-infra")},
+				ControlPlane: ControlPlaneClass{
+					LocalObjectTemplate: LocalObjectTemplate{Ref: ref("GenericControlPlaneTemplate", name+"-cp")},
+				},
+			},
+		}
+		for _, mdClass := range mdClasses {
+			clusterClass.Spec.Workers.MachineDeployments = append(clusterClass.Spec.Workers.MachineDeployments, MachineDeploymentClass{
+				Class: mdClass,
+				Template: MachineDeploymentClassTemplate{
+					Bootstrap:      LocalObjectTemplate{Ref: ref("GenericBootstrapConfigTemplate", name+"-"+mdClass+"-bootstrap")},
+					Infrastructure: LocalObjectTemplate{Ref: ref("GenericInfrastructureMachineTemplate", name+"-"+mdClass+"-infra")},
+				},
+			})
+		}
+		return clusterClass
+	}
+
+	w := &clusterWebhook{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newClusterClass("class-v1", "GenericInfrastructureClusterTemplate", "linux-worker"),
+		newClusterClass("class-v2", "GenericInfrastructureClusterTemplate", "linux-worker", "windows-worker"),
+		newClusterClass("class-other-infra", "OtherInfrastructureClusterTemplate", "linux-worker"),
+		newClusterClass("class-no-workers", "GenericInfrastructureClusterTemplate"),
+	).Build()}
+
+	newCluster := func(class string) *Cluster {
+		return &Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"},
+			Spec: ClusterSpec{
+				Topology: &Topology{
+					Class:   class,
+					Version: "v1.21.2",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		oldClass  string
+		newClass  string
+		expectErr bool
+	}{
+		{
+			name:     "allow rebasing to a compatible ClusterClass",
+			oldClass: "class-v1",
+			newClass: "class-v2",
+		},
+		{
+			name:      "reject rebasing to a ClusterClass with a different infrastructure kind",
+			oldClass:  "class-v1",
+			newClass:  "class-other-infra",
+			expectErr: true,
+		},
+		{
+			name:      "reject rebasing to a ClusterClass removing a MachineDeployment class",
+			oldClass:  "class-v1",
+			newClass:  "class-no-workers",
+			expectErr: true,
+		},
+		{
+			name:      "reject rebasing to a ClusterClass which does not exist",
+			oldClass:  "class-v1",
+			newClass:  "does-not-exist",
+			expectErr: true,
+		},
+		{
+			name:      "reject rebasing from a ClusterClass which does not exist",
+			oldClass:  "does-not-exist",
+			newClass:  "class-v2",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := w.ValidateUpdate(context.Background(), newCluster(tt.oldClass), newCluster(tt.newClass))
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func intOrStrPtr(i intstr.IntOrString) *intstr.IntOrString {
+	return &i
+}
+
+func TestClusterWebhookMachineHealthChecks(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+
+	ref := &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "GenericTemplate", Namespace: "default", Name: "template"}
+	check := MachineHealthCheckClass{
+		UnhealthyConditions: []UnhealthyCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+		},
+	}
+	newClusterClass := func(name string, machineInfrastructure *LocalObjectTemplate, cpCheck, mdCheck *MachineHealthCheckClass) *ClusterClass {
+		return &ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: ClusterClassSpec{
+				Infrastructure: LocalObjectTemplate{Ref: ref},
+				ControlPlane: ControlPlaneClass{
+					LocalObjectTemplate:   LocalObjectTemplate{Ref: ref},
+					MachineInfrastructure: machineInfrastructure,
+					MachineHealthCheck:    cpCheck,
+				},
+				Workers: WorkersClass{
+					MachineDeployments: []MachineDeploymentClass{
+						{
+							Class: "linux-worker",
+							Template: MachineDeploymentClassTemplate{
+								Bootstrap:      LocalObjectTemplate{Ref: ref},
+								Infrastructure: LocalObjectTemplate{Ref: ref},
+							},
+							MachineHealthCheck: mdCheck,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	w := &clusterWebhook{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newClusterClass("class-with-checks", &LocalObjectTemplate{Ref: ref}, &check, &check),
+		newClusterClass("class-without-checks", &LocalObjectTemplate{Ref: ref}, nil, nil),
+		newClusterClass("class-without-machines", nil, nil, nil),
+	).Build()}
+
+	newCluster := func(class string, cpCheck, mdCheck *MachineHealthCheckTopology) *Cluster {
+		return &Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"},
+			Spec: ClusterSpec{
+				Topology: &Topology{
+					Class:   class,
+					Version: "v1.21.2",
+					ControlPlane: ControlPlaneTopology{
+						MachineHealthCheck: cpCheck,
+					},
+					Workers: &WorkersTopology{
+						MachineDeployments: []MachineDeploymentTopology{
+							{
+								Class:              "linux-worker",
+								Name:               "md1",
+								MachineHealthCheck: mdCheck,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	enable := &MachineHealthCheckTopology{Enable: pointer.Bool(true)}
+	disable := &MachineHealthCheckTopology{Enable: pointer.Bool(false)}
+	override := &MachineHealthCheckTopology{Enable: pointer.Bool(true), MachineHealthCheckClass: check}
+	invalidOverride := &MachineHealthCheckTopology{MachineHealthCheckClass: MachineHealthCheckClass{NodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute}}}
+
+	tests := []struct {
+		name      string
+		cluster   *Cluster
+		expectErr bool
+	}{
+		{
+			name:    "pass when enabling MachineHealthChecks defined in the ClusterClass",
+			cluster: newCluster("class-with-checks", enable, enable),
+		},
+		{
+			name:    "pass when disabling MachineHealthChecks defined in the ClusterClass",
+			cluster: newCluster("class-with-checks", disable, disable),
+		},
+		{
+			name:    "pass when enabling MachineHealthChecks defined in the topology",
+			cluster: newCluster("class-without-checks", override, override),
+		},
+		{
+			name:      "fail when enabling a control plane MachineHealthCheck which is not defined",
+			cluster:   newCluster("class-without-checks", enable, nil),
+			expectErr: true,
+		},
+		{
+			name:      "fail when enabling a MachineDeployment MachineHealthCheck which is not defined",
+			cluster:   newCluster("class-without-checks", nil, enable),
+			expectErr: true,
+		},
+		{
+			name:      "fail when enabling a control plane MachineHealthCheck for a control plane which is not Machine based",
+			cluster:   newCluster("class-without-machines", override, nil),
+			expectErr: true,
+		},
+		{
+			name:      "fail when a MachineHealthCheck override is not valid",
+			cluster:   newCluster("class-with-checks", nil, invalidOverride),
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := w.ValidateCreate(context.Background(), tt.cluster)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestClusterWebhookManagedExternalEtcdRef(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+
+	newCRD := func(kind string, labels map[string]string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+				Kind:       "CustomResourceDefinition",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   strings.ToLower(kind) + "s.etcdcluster.cluster.x-k8s.io",
+				Labels: labels,
+			},
+		}
+	}
+	w := &clusterWebhook{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newCRD("CompliantEtcdCluster", map[string]string{GroupVersion.String(): "v1alpha3_v1beta1"}),
+		newCRD("NonCompliantEtcdCluster", nil),
+	).Build()}
+
+	newCluster := func(apiVersion, kind string) *Cluster {
+		cluster := &Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "default"},
+		}
+		if kind != "" {
+			cluster.Spec.ManagedExternalEtcdRef = &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Namespace: "default", Name: "etcd"}
+		}
+		return cluster
+	}
+
+	tests := []struct {
+		name       string
+		oldCluster *Cluster
+		cluster    *Cluster
+		expectErr  bool
+	}{
+		{
+			name:    "pass when the Cluster does not use a managed external etcd",
+			cluster: newCluster("", ""),
+		},
+		{
+			name:    "pass when the CRD has the contract label for the referenced version",
+			cluster: newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "CompliantEtcdCluster"),
+		},
+		{
+			name:      "fail when the CRD does not have the contract label for the referenced version",
+			cluster:   newCluster("etcdcluster.cluster.x-k8s.io/v1alpha4", "CompliantEtcdCluster"),
+			expectErr: true,
+		},
+		{
+			name:      "fail when the CRD does not have the contract label",
+			cluster:   newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "NonCompliantEtcdCluster"),
+			expectErr: true,
+		},
+		{
+			name:      "fail when the CRD does not exist",
+			cluster:   newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "MissingEtcdCluster"),
+			expectErr: true,
+		},
+		{
+			name:       "pass on update when the reference is not changed",
+			oldCluster: newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "NonCompliantEtcdCluster"),
+			cluster:    newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "NonCompliantEtcdCluster"),
+		},
+		{
+			name:       "fail on update when the reference is changed to a CRD without the contract label",
+			oldCluster: newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "CompliantEtcdCluster"),
+			cluster:    newCluster("etcdcluster.cluster.x-k8s.io/v1beta1", "NonCompliantEtcdCluster"),
+			expectErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var err error
+			if tt.oldCluster != nil {
+				err = w.ValidateUpdate(context.Background(), tt.oldCluster, tt.cluster)
+			} else {
+				err = w.ValidateCreate(context.Background(), tt.cluster)
+			}
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+// countingReader is a client.Reader counting the Get calls.
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	r.gets++
+	return r.Reader.Get(ctx, key, obj)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// JSONPatchOpAdd is the add operation of a JSON patch.
+	JSONPatchOpAdd = "add"
+
+	// JSONPatchOpReplace is the replace operation of a JSON patch.
+	JSONPatchOpReplace = "replace"
+
+	// JSONPatchOpRemove is the remove operation of a JSON patch.
+	JSONPatchOpRemove = "remove"
+)
+
+var validJSONPatchOps = sets.NewString(JSONPatchOpAdd, JSONPatchOpReplace, JSONPatchOpRemove)
+
+// validatePatches validates the patches defined in a ClusterClass.
+func (in *ClusterClass) validatePatches() field.ErrorList {
+	var allErrs field.ErrorList
+
+	variables := sets.NewString()
+	for _, variable := range in.Spec.Variables {
+		variables.Insert(variable.Name)
+	}
+	classes := in.Spec.Workers.classNames()
+
+	names := sets.NewString()
+	for i, patch := range in.Spec.Patches {
+		path := field.NewPath("spec", "patches").Index(i)
+
+		if patch.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "patch name must be defined"))
+		}
+		if names.Has(patch.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), patch.Name,
+				fmt.Sprintf("patch names should be unique. Patch with name %q is defined more than once", patch.Name)))
+		}
+		names.Insert(patch.Name)
+
+		for j, definition := range patch.Definitions {
+			definitionPath := path.Child("definitions").Index(j)
+			allErrs = append(allErrs, definition.Selector.validate(classes, definitionPath.Child("selector"))...)
+			for k, jsonPatch := range definition.JSONPatches {
+				allErrs = append(allErrs, jsonPatch.validate(variables, definitionPath.Child("jsonPatches").Index(k))...)
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func (s *PatchSelector) validate(classes sets.String, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if s.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(path.Child("apiVersion"), "apiVersion must be defined"))
+	}
+	if s.Kind == "" {
+		allErrs = append(allErrs, field.Required(path.Child("kind"), "kind must be defined"))
+	}
+
+	match := s.MatchResources
+	if !match.ControlPlane && !match.InfrastructureCluster && match.MachineDeploymentClass == nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("matchResources"), match,
+			"at least one of controlPlane, infrastructureCluster or machineDeploymentClass must be set"))
+	}
+	if match.MachineDeploymentClass != nil {
+		for i, name := range match.MachineDeploymentClass.Names {
+			if !classes.Has(name) {
+				allErrs = append(allErrs, field.Invalid(path.Child("matchResources", "machineDeploymentClass", "names").Index(i), name,
+					"MachineDeployment class is not defined in the ClusterClass"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func (p *JSONPatch) validate(variables sets.String, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !validJSONPatchOps.Has(p.Op) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("op"), p.Op, validJSONPatchOps.List()))
+	}
+
+	allErrs = append(allErrs, p.validatePath(path.Child("path"))...)
+
+	switch p.Op {
+	case JSONPatchOpAdd, JSONPatchOpReplace:
+		if p.Value == nil && p.ValueFrom == nil {
+			allErrs = append(allErrs, field.Invalid(path, p, "either value or valueFrom must be set"))
+		}
+		if p.Value != nil && p.ValueFrom != nil {
+			allErrs = append(allErrs, field.Invalid(path, p, "value and valueFrom are mutually exclusive"))
+		}
+	case JSONPatchOpRemove:
+		if p.Value != nil || p.ValueFrom != nil {
+			allErrs = append(allErrs, field.Invalid(path, p, "value and valueFrom must not be set for remove operations"))
+		}
+	}
+
+	if p.Value != nil && !json.Valid(p.Value.Raw) {
+		allErrs = append(allErrs, field.Invalid(path.Child("value"), string(p.Value.Raw), "must be valid JSON"))
+	}
+
+	if p.ValueFrom != nil {
+		name := strings.SplitN(p.ValueFrom.Variable, ".", 2)[0]
+		if name != BuiltinVariablePrefix && !variables.Has(name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("valueFrom", "variable"), p.ValueFrom.Variable,
+				"must be a variable defined in .spec.variables or a builtin variable"))
+		}
+	}
+
+	return allErrs
+}
+
+func (p *JSONPatch) validatePath(path *field.Path) field.ErrorList {
+	if !strings.HasPrefix(p.Path, "/spec/") {
+		return field.ErrorList{field.Invalid(path, p.Path, "must start with \"/spec/\"")}
+	}
+
+	// Only append and prepend are allowed for arrays, because indexes can shift when templates change.
+	segments := strings.Split(p.Path, "/")
+	for i, segment := range segments {
+		_, err := strconv.Atoi(segment)
+		if err != nil && segment != "-" {
+			continue
+		}
+		if p.Op != JSONPatchOpAdd {
+			return field.ErrorList{field.Invalid(path, p.Path, fmt.Sprintf("indexes are not allowed for %s operations", p.Op))}
+		}
+		if i != len(segments)-1 || (segment != "0" && segment != "-") {
+			return field.ErrorList{field.Invalid(path, p.Path, "only index 0 (prepend) and - (append) are allowed as last segment for add operations")}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestClusterClassValidatePatches(t *testing.T) {
+	selector := PatchSelector{
+		APIVersion:     "infrastructure.cluster.x-k8s.io/v1beta1",
+		Kind:           "GenericInfrastructureClusterTemplate",
+		MatchResources: PatchSelectorMatch{InfrastructureCluster: true},
+	}
+	value := &apiextensionsv1.JSON{Raw: []byte(`"value"`)}
+
+	tests := []struct {
+		name      string
+		patches   []ClusterClassPatch
+		expectErr bool
+	}{
+		{
+			name: "pass with valid patches",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: selector,
+							JSONPatches: []JSONPatch{
+								{Op: "add", Path: "/spec/template/spec/value", Value: value},
+								{Op: "add", Path: "/spec/template/spec/location", ValueFrom: &JSONPatchValue{Variable: "location"}},
+								{Op: "add", Path: "/spec/template/spec/name", ValueFrom: &JSONPatchValue{Variable: "builtin.cluster.name"}},
+								{Op: "add", Path: "/spec/template/spec/files/-", Value: value},
+								{Op: "replace", Path: "/spec/template/spec/value", Value: value},
+								{Op: "remove", Path: "/spec/template/spec/value"},
+							},
+						},
+					},
+				},
+				{
+					Name: "patch2",
+					Definitions: []PatchDefinition{
+						{
+							Selector: PatchSelector{
+								APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+								Kind:       "GenericBootstrapConfigTemplate",
+								MatchResources: PatchSelectorMatch{
+									MachineDeploymentClass: &PatchSelectorMatchMachineDeploymentClass{Names: []string{"aa"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "fail with duplicate patch names",
+			patches: []ClusterClassPatch{
+				{Name: "patch1"},
+				{Name: "patch1"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a selector not matching any resource",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: PatchSelector{
+								APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+								Kind:       "GenericInfrastructureClusterTemplate",
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a selector matching an undefined MachineDeployment class",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: PatchSelector{
+								APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+								Kind:       "GenericBootstrapConfigTemplate",
+								MatchResources: PatchSelectorMatch{
+									MachineDeploymentClass: &PatchSelectorMatchMachineDeploymentClass{Names: []string{"bb"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with both value and valueFrom",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: selector,
+							JSONPatches: []JSONPatch{
+								{Op: "add", Path: "/spec/template/spec/value", Value: value, ValueFrom: &JSONPatchValue{Variable: "location"}},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with an undefined variable",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: selector,
+							JSONPatches: []JSONPatch{
+								{Op: "add", Path: "/spec/template/spec/value", ValueFrom: &JSONPatchValue{Variable: "unknown"}},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a path outside spec",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: selector,
+							JSONPatches: []JSONPatch{
+								{Op: "add", Path: "/metadata/labels/foo", Value: value},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with an index in a replace operation",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: selector,
+							JSONPatches: []JSONPatch{
+								{Op: "replace", Path: "/spec/template/spec/files/1", Value: value},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with an unsupported operation",
+			patches: []ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []PatchDefinition{
+						{
+							Selector: selector,
+							JSONPatches: []JSONPatch{
+								{Op: "copy", Path: "/spec/template/spec/value", Value: value},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			clusterClass := &ClusterClass{
+				Spec: ClusterClassSpec{
+					Workers: WorkersClass{
+						MachineDeployments: []MachineDeploymentClass{{Class: "aa"}},
+					},
+					Variables: []ClusterClassVariable{
+						{Name: "location", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "string"}}},
+					},
+					Patches: tt.patches,
+				},
+			}
+
+			errs := clusterClass.validatePatches()
+			if tt.expectErr {
+				g.Expect(errs).ToNot(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
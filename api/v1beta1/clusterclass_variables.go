@@ -0,0 +1,358 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// BuiltinVariablePrefix is the prefix of the builtin variables which are provided by the
+	// topology controller, e.g. builtin.cluster.name; it is reserved and can't be used in ClusterClass variables.
+	BuiltinVariablePrefix = "builtin"
+)
+
+// validVariableTypes are the variable types supported by ClusterClass variables.
+var validVariableTypes = sets.NewString("object", "array", "string", "integer", "number", "boolean")
+
+// validateVariables validates the variables defined in a ClusterClass.
+func validateVariables(variables []ClusterClassVariable, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := sets.NewString()
+	for i, variable := range variables {
+		path := pathPrefix.Index(i)
+
+		if variable.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "variable name must be defined"))
+			continue
+		}
+		if strings.HasPrefix(variable.Name, BuiltinVariablePrefix) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), variable.Name,
+				fmt.Sprintf("%q is a reserved prefix for builtin variables", BuiltinVariablePrefix)))
+		}
+		if strings.Contains(variable.Name, ".") {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), variable.Name, "variable name cannot contain \".\""))
+		}
+		if names.Has(variable.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), variable.Name,
+				fmt.Sprintf("variable names should be unique. Variable with name %q is defined more than once", variable.Name)))
+		}
+		names.Insert(variable.Name)
+
+		allErrs = append(allErrs, validateSchema(&variable.Schema.OpenAPIV3Schema, path.Child("schema", "openAPIV3Schema"))...)
+	}
+
+	return allErrs
+}
+
+// validateSchema validates the schema of a ClusterClass variable.
+func validateSchema(schema *JSONSchemaProps, pathPrefix *field.Path) field.ErrorList {
+	allErrs := validateSchemaTypes(schema, pathPrefix)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	apiExtensionsSchema, err := schema.toAPIExtensions()
+	if err != nil {
+		return field.ErrorList{field.Invalid(pathPrefix, "", err.Error())}
+	}
+
+	// Ensure the schema can be converted to a structural schema, so it can be used for defaulting.
+	// NOTE: The subset of the OpenAPI schema supported by variables is structural as long as all types are
+	// defined (see validateSchemaTypes); structuralschema.ValidateStructural is not used because it
+	// requires an object at the root, like in CRDs.
+	if _, err := structuralschema.NewStructural(apiExtensionsSchema); err != nil {
+		return field.ErrorList{field.Invalid(pathPrefix, "", err.Error())}
+	}
+
+	validator, _, err := validation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: apiExtensionsSchema})
+	if err != nil {
+		return field.ErrorList{field.Invalid(pathPrefix, "", fmt.Sprintf("failed to build validator: %v", err))}
+	}
+
+	// Ensure the default value and the enum values are valid according to the schema.
+	if schema.Default != nil {
+		value, err := unmarshalJSON(schema.Default)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(pathPrefix.Child("default"), string(schema.Default.Raw), err.Error()))
+		} else {
+			allErrs = append(allErrs, validation.ValidateCustomResource(pathPrefix.Child("default"), value, validator)...)
+		}
+	}
+	for i := range schema.Enum {
+		value, err := unmarshalJSON(&schema.Enum[i])
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(pathPrefix.Child("enum").Index(i), string(schema.Enum[i].Raw), err.Error()))
+			continue
+		}
+		allErrs = append(allErrs, validation.ValidateCustomResource(pathPrefix.Child("enum").Index(i), value, validator)...)
+	}
+
+	return allErrs
+}
+
+// validateSchemaTypes ensures types are defined and properties and items are only used with the corresponding types.
+func validateSchemaTypes(schema *JSONSchemaProps, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !validVariableTypes.Has(schema.Type) {
+		allErrs = append(allErrs, field.NotSupported(pathPrefix.Child("type"), schema.Type, validVariableTypes.List()))
+	}
+	if len(schema.Properties) > 0 && schema.Type != "object" {
+		allErrs = append(allErrs, field.Forbidden(pathPrefix.Child("properties"), "can only be set if type is object"))
+	}
+	if schema.Items != nil && schema.Type != "array" {
+		allErrs = append(allErrs, field.Forbidden(pathPrefix.Child("items"), "can only be set if type is array"))
+	}
+	if schema.Items == nil && schema.Type == "array" {
+		allErrs = append(allErrs, field.Required(pathPrefix.Child("items"), "must be set if type is array"))
+	}
+
+	for name, property := range schema.Properties {
+		property := property
+		allErrs = append(allErrs, validateSchemaTypes(&property, pathPrefix.Child("properties").Key(name))...)
+	}
+	if schema.Items != nil {
+		allErrs = append(allErrs, validateSchemaTypes(schema.Items, pathPrefix.Child("items"))...)
+	}
+
+	return allErrs
+}
+
+// validateClusterVariables validates the variables of a Cluster topology against the variables defined in the ClusterClass.
+func validateClusterVariables(values []ClusterVariable, definitions []ClusterClassVariable, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	valuesByName := map[string]ClusterVariable{}
+	for _, value := range values {
+		valuesByName[value.Name] = value
+	}
+
+	for _, definition := range definitions {
+		if _, ok := valuesByName[definition.Name]; !ok && definition.Required {
+			allErrs = append(allErrs, field.Required(pathPrefix,
+				fmt.Sprintf("required variable %q must be set", definition.Name)))
+		}
+	}
+
+	return append(allErrs, validateClusterVariableValues(values, definitions, pathPrefix)...)
+}
+
+// validateClusterVariableValues validates that the given variables are defined in the ClusterClass and that their
+// values comply to the corresponding schema; it does not check required variables, so it can be used also for
+// variable overrides of MachineDeployments.
+func validateClusterVariableValues(values []ClusterVariable, definitions []ClusterClassVariable, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	definitionsByName := map[string]ClusterClassVariable{}
+	for _, definition := range definitions {
+		definitionsByName[definition.Name] = definition
+	}
+
+	for i, value := range values {
+		definition, ok := definitionsByName[value.Name]
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(pathPrefix.Index(i).Child("name"), value.Name,
+				"variable is not defined in the ClusterClass"))
+			continue
+		}
+		allErrs = append(allErrs, validateClusterVariable(&value, &definition, pathPrefix.Index(i).Child("value"))...)
+	}
+
+	return allErrs
+}
+
+// validateClusterVariable validates the value of a Cluster variable against the schema of the corresponding ClusterClass variable.
+func validateClusterVariable(value *ClusterVariable, definition *ClusterClassVariable, path *field.Path) field.ErrorList {
+	variableValue, err := unmarshalJSON(&value.Value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, string(value.Value.Raw), err.Error())}
+	}
+
+	apiExtensionsSchema, err := definition.Schema.OpenAPIV3Schema.toAPIExtensions()
+	if err != nil {
+		return field.ErrorList{field.InternalError(path,
+			errors.Wrapf(err, "failed to convert schema definition for variable %q", definition.Name))}
+	}
+
+	validator, _, err := validation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: apiExtensionsSchema})
+	if err != nil {
+		return field.ErrorList{field.InternalError(path,
+			errors.Wrapf(err, "failed to create schema validator for variable %q", definition.Name))}
+	}
+
+	return validation.ValidateCustomResource(path, variableValue, validator)
+}
+
+// defaultClusterVariables adds the variables with a default value in the ClusterClass which are not set
+// in the Cluster topology, and it applies defaults defined in nested schemas to the existing values.
+func defaultClusterVariables(values []ClusterVariable, definitions []ClusterClassVariable) ([]ClusterVariable, error) {
+	valuesByName := sets.NewString()
+	for _, value := range values {
+		valuesByName.Insert(value.Name)
+	}
+
+	// Add the variables which are not set only if there is a default value for them.
+	for _, definition := range definitions {
+		if valuesByName.Has(definition.Name) || definition.Schema.OpenAPIV3Schema.Default == nil {
+			continue
+		}
+		values = append(values, ClusterVariable{
+			Name:  definition.Name,
+			Value: *definition.Schema.OpenAPIV3Schema.Default.DeepCopy(),
+		})
+	}
+
+	return defaultClusterVariableValues(values, definitions)
+}
+
+// defaultClusterVariableValues applies defaults defined in nested schemas to the given variables, without adding
+// the variables which are not set; it is used also for variable overrides of MachineDeployments.
+func defaultClusterVariableValues(values []ClusterVariable, definitions []ClusterClassVariable) ([]ClusterVariable, error) {
+	definitionsByName := map[string]ClusterClassVariable{}
+	for _, definition := range definitions {
+		definitionsByName[definition.Name] = definition
+	}
+
+	for i := range values {
+		definition, ok := definitionsByName[values[i].Name]
+		if !ok {
+			continue
+		}
+		defaulted, err := defaultValue(&values[i].Value, &definition.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to default variable %q", definition.Name)
+		}
+		values[i].Value = *defaulted
+	}
+
+	return values, nil
+}
+
+// defaultValue applies the defaults defined in the schema to the value.
+func defaultValue(value *apiextensionsv1.JSON, schema *JSONSchemaProps) (*apiextensionsv1.JSON, error) {
+	apiExtensionsSchema, err := schema.toAPIExtensions()
+	if err != nil {
+		return nil, err
+	}
+	structural, err := structuralschema.NewStructural(apiExtensionsSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := unmarshalJSON(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: Default mutates maps and slices in place, thus applying defaults to nested fields;
+	// the top-level default is used only when the variable is not set at all.
+	structuraldefaulting.Default(v, structural)
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &apiextensionsv1.JSON{Raw: raw}, nil
+}
+
+// unmarshalJSON unmarshals a JSON value into a generic Go value.
+func unmarshalJSON(value *apiextensionsv1.JSON) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(value.Raw, &v); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal value")
+	}
+	return v, nil
+}
+
+// toAPIExtensions converts a JSONSchemaProps to the corresponding apiextensions type,
+// so it is possible to use the CRD validation and defaulting machinery.
+func (s *JSONSchemaProps) toAPIExtensions() (*apiextensions.JSONSchemaProps, error) {
+	props := &apiextensions.JSONSchemaProps{
+		Type:             s.Type,
+		Required:         s.Required,
+		MaxItems:         s.MaxItems,
+		MinItems:         s.MinItems,
+		UniqueItems:      s.UniqueItems,
+		Format:           s.Format,
+		MaxLength:        s.MaxLength,
+		MinLength:        s.MinLength,
+		Pattern:          s.Pattern,
+		ExclusiveMaximum: s.ExclusiveMaximum,
+		ExclusiveMinimum: s.ExclusiveMinimum,
+	}
+
+	if s.Maximum != nil {
+		f := float64(*s.Maximum)
+		props.Maximum = &f
+	}
+	if s.Minimum != nil {
+		f := float64(*s.Minimum)
+		props.Minimum = &f
+	}
+
+	if s.Default != nil {
+		v, err := unmarshalJSON(s.Default)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid default")
+		}
+		var d apiextensions.JSON = v
+		props.Default = &d
+	}
+
+	for i := range s.Enum {
+		v, err := unmarshalJSON(&s.Enum[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid enum value at index %d", i)
+		}
+		props.Enum = append(props.Enum, v)
+	}
+
+	if len(s.Properties) > 0 {
+		props.Properties = map[string]apiextensions.JSONSchemaProps{}
+		for name, property := range s.Properties {
+			property := property
+			p, err := property.toAPIExtensions()
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid property %q", name)
+			}
+			props.Properties[name] = *p
+		}
+	}
+
+	if s.Items != nil {
+		items, err := s.Items.toAPIExtensions()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid items")
+		}
+		props.Items = &apiextensions.JSONSchemaPropsOrArray{Schema: items}
+	}
+
+	return props, nil
+}
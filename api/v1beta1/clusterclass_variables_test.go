@@ -0,0 +1,241 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateVariables(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables []ClusterClassVariable
+		expectErr bool
+	}{
+		{
+			name: "pass with valid variables",
+			variables: []ClusterClassVariable{
+				{
+					Name:     "location",
+					Required: true,
+					Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+						Type:    "string",
+						Enum:    []apiextensionsv1.JSON{{Raw: []byte(`"us"`)}, {Raw: []byte(`"eu"`)}},
+						Default: &apiextensionsv1.JSON{Raw: []byte(`"us"`)},
+					}},
+				},
+				{
+					Name: "proxy",
+					Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]JSONSchemaProps{
+							"httpProxy": {Type: "string", Format: "uri"},
+							"noProxy": {
+								Type:  "array",
+								Items: &JSONSchemaProps{Type: "string"},
+							},
+						},
+						Required: []string{"httpProxy"},
+					}},
+				},
+			},
+		},
+		{
+			name: "fail with duplicate names",
+			variables: []ClusterClassVariable{
+				{Name: "location", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "string"}}},
+				{Name: "location", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "string"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with builtin prefix",
+			variables: []ClusterClassVariable{
+				{Name: "builtinLocation", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "string"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with dots in the name",
+			variables: []ClusterClassVariable{
+				{Name: "my.location", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "string"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with unsupported type",
+			variables: []ClusterClassVariable{
+				{Name: "location", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "null"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with array without items",
+			variables: []ClusterClassVariable{
+				{Name: "locations", Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "array"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with default value not matching the schema",
+			variables: []ClusterClassVariable{
+				{
+					Name: "replicas",
+					Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+						Type:    "integer",
+						Default: &apiextensionsv1.JSON{Raw: []byte(`"three"`)},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errs := validateVariables(tt.variables, field.NewPath("spec", "variables"))
+			if tt.expectErr {
+				g.Expect(errs).ToNot(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateClusterVariables(t *testing.T) {
+	definitions := []ClusterClassVariable{
+		{
+			Name:     "location",
+			Required: true,
+			Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+				Type: "string",
+				Enum: []apiextensionsv1.JSON{{Raw: []byte(`"us"`)}, {Raw: []byte(`"eu"`)}},
+			}},
+		},
+		{
+			Name: "replicas",
+			Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+				Type:    "integer",
+				Minimum: pointerInt64(1),
+			}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		values    []ClusterVariable
+		expectErr bool
+	}{
+		{
+			name: "pass with valid values",
+			values: []ClusterVariable{
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"eu"`)}},
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+			},
+		},
+		{
+			name: "fail with a required variable missing",
+			values: []ClusterVariable{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a variable not defined in the ClusterClass",
+			values: []ClusterVariable{
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"eu"`)}},
+				{Name: "unknown", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a value not in the enum",
+			values: []ClusterVariable{
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"asia"`)}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a value lower than the minimum",
+			values: []ClusterVariable{
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"eu"`)}},
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`0`)}},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errs := validateClusterVariables(tt.values, definitions, field.NewPath("spec", "topology", "variables"))
+			if tt.expectErr {
+				g.Expect(errs).ToNot(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestDefaultClusterVariables(t *testing.T) {
+	g := NewWithT(t)
+
+	definitions := []ClusterClassVariable{
+		{
+			Name: "location",
+			Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+				Type:    "string",
+				Default: &apiextensionsv1.JSON{Raw: []byte(`"us"`)},
+			}},
+		},
+		{
+			Name: "proxy",
+			Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]JSONSchemaProps{
+					"httpProxy": {Type: "string"},
+					"port":      {Type: "integer", Default: &apiextensionsv1.JSON{Raw: []byte(`3128`)}},
+				},
+			}},
+		},
+		{
+			Name:   "optional",
+			Schema: VariableSchema{OpenAPIV3Schema: JSONSchemaProps{Type: "string"}},
+		},
+	}
+	values := []ClusterVariable{
+		{Name: "proxy", Value: apiextensionsv1.JSON{Raw: []byte(`{"httpProxy":"http://proxy"}`)}},
+	}
+
+	got, err := defaultClusterVariables(values, definitions)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(ConsistOf(
+		ClusterVariable{Name: "proxy", Value: apiextensionsv1.JSON{Raw: []byte(`{"httpProxy":"http://proxy","port":3128}`)}},
+		ClusterVariable{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us"`)}},
+	))
+}
+
+func pointerInt64(i int64) *int64 {
+	return &i
+}
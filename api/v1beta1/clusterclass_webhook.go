@@ -53,9 +53,16 @@ func (in *ClusterClass) Default() {
 		defaultNamespace(in.Spec.ControlPlane.MachineInfrastructure.Ref, in.Namespace)
 	}
 
+	if in.Spec.ControlPlane.MachineHealthCheck != nil {
+		defaultNamespace(in.Spec.ControlPlane.MachineHealthCheck.RemediationTemplate, in.Namespace)
+	}
+
 	for i := range in.Spec.Workers.MachineDeployments {
 		defaultNamespace(in.Spec.Workers.MachineDeployments[i].Template.Bootstrap.Ref, in.Namespace)
 		defaultNamespace(in.Spec.Workers.MachineDeployments[i].Template.Infrastructure.Ref, in.Namespace)
+		if in.Spec.Workers.MachineDeployments[i].MachineHealthCheck != nil {
+			defaultNamespace(in.Spec.Workers.MachineDeployments[i].MachineHealthCheck.RemediationTemplate, in.Namespace)
+		}
 	}
 }
 
@@ -105,6 +112,13 @@ func (in *ClusterClass) validate(old *ClusterClass) error {
 	// Ensure spec changes are compatible.
 	allErrs = append(allErrs, in.validateCompatibleSpecChanges(old)...)
 
+	// Ensure variables and patches are valid.
+	allErrs = append(allErrs, validateVariables(in.Spec.Variables, field.NewPath("spec", "variables"))...)
+	allErrs = append(allErrs, in.validatePatches()...)
+
+	// Ensure MachineHealthChecks are valid.
+	allErrs = append(allErrs, in.validateMachineHealthChecks()...)
+
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("ClusterClass").GroupKind(), in.Name, allErrs)
 	}
@@ -128,6 +142,31 @@ func (in *ClusterClass) validateAllRefs() field.ErrorList {
 	return allErrs
 }
 
+func (in *ClusterClass) validateMachineHealthChecks() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if in.Spec.ControlPlane.MachineHealthCheck != nil {
+		fldPath := field.NewPath("spec", "controlPlane", "machineHealthCheck")
+		allErrs = append(allErrs, in.Spec.ControlPlane.MachineHealthCheck.validate(in.Namespace, fldPath)...)
+
+		// MachineHealthChecks are supported only for Machine based control planes.
+		if in.Spec.ControlPlane.MachineInfrastructure == nil {
+			allErrs = append(allErrs,
+				field.Forbidden(fldPath, "can be set only if spec.controlPlane.machineInfrastructure is set"),
+			)
+		}
+	}
+
+	for i, class := range in.Spec.Workers.MachineDeployments {
+		if class.MachineHealthCheck != nil {
+			allErrs = append(allErrs, class.MachineHealthCheck.validate(in.Namespace,
+				field.NewPath("spec", "workers", "machineDeployments").Index(i).Child("machineHealthCheck"))...)
+		}
+	}
+
+	return allErrs
+}
+
 func (in *ClusterClass) validateCompatibleSpecChanges(old *ClusterClass) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -18,10 +18,12 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilfeature "k8s.io/component-base/featuregate/testing"
 	"sigs.k8s.io/cluster-api/feature"
 
@@ -1442,3 +1444,104 @@ func TestClusterClassValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterClassMachineHealthCheckValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create or update ClusterClasses.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "group.test.io/foo",
+		Kind:       "barTemplate",
+		Name:       "baz",
+		Namespace:  "default",
+	}
+	unhealthyConditions := []UnhealthyCondition{
+		{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+	}
+	maxUnhealthyInvalid := intstr.FromString("foo")
+
+	newClusterClass := func(machineInfrastructure *LocalObjectTemplate, cpCheck, mdCheck *MachineHealthCheckClass) *ClusterClass {
+		return &ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			Spec: ClusterClassSpec{
+				Infrastructure: LocalObjectTemplate{Ref: ref},
+				ControlPlane: ControlPlaneClass{
+					LocalObjectTemplate:   LocalObjectTemplate{Ref: ref},
+					MachineInfrastructure: machineInfrastructure,
+					MachineHealthCheck:    cpCheck,
+				},
+				Workers: WorkersClass{
+					MachineDeployments: []MachineDeploymentClass{
+						{
+							Class: "aa",
+							Template: MachineDeploymentClassTemplate{
+								Bootstrap:      LocalObjectTemplate{Ref: ref},
+								Infrastructure: LocalObjectTemplate{Ref: ref},
+							},
+							MachineHealthCheck: mdCheck,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		in        *ClusterClass
+		expectErr bool
+	}{
+		{
+			name: "pass with valid MachineHealthChecks",
+			in: newClusterClass(&LocalObjectTemplate{Ref: ref},
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions, NodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute}},
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions, RemediationTemplate: ref}),
+			expectErr: false,
+		},
+		{
+			name:      "fail if the control plane MachineHealthCheck is defined for a control plane which is not Machine based",
+			in:        newClusterClass(nil, &MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions}, nil),
+			expectErr: true,
+		},
+		{
+			name:      "fail if unhealthyConditions are not defined",
+			in:        newClusterClass(nil, nil, &MachineHealthCheckClass{}),
+			expectErr: true,
+		},
+		{
+			name: "fail if nodeStartupTimeout is too short",
+			in: newClusterClass(nil, nil,
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions, NodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Second}}),
+			expectErr: true,
+		},
+		{
+			name: "fail if maxUnhealthy is not valid",
+			in: newClusterClass(nil, nil,
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions, MaxUnhealthy: &maxUnhealthyInvalid}),
+			expectErr: true,
+		},
+		{
+			name: "fail if the remediationTemplate is in another namespace",
+			in: newClusterClass(nil, nil,
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions, RemediationTemplate: &corev1.ObjectReference{
+					APIVersion: "group.test.io/foo",
+					Kind:       "barTemplate",
+					Name:       "baz",
+					Namespace:  "another-namespace",
+				}}),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			if tt.expectErr {
+				g.Expect(tt.in.validate(nil)).NotTo(Succeed())
+			} else {
+				g.Expect(tt.in.validate(nil)).To(Succeed())
+			}
+		})
+	}
+}
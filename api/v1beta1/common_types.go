@@ -102,6 +102,9 @@ const (
 
 	// ControlPlaneUpgradeCompletedAnnotation is set by the controlplane on the external etcd object after controlplane upgrade is completed
 	ControlPlaneUpgradeCompletedAnnotation = "controlplane.cluster.x-k8s.io/upgrade-complete"
+
+	// EtcdClusterUpgradingAnnotation is set by the etcd provider on the external etcd object while an etcd cluster upgrade is in progress.
+	EtcdClusterUpgradingAnnotation = "etcdcluster.cluster.x-k8s.io/upgrading"
 )
 
 const (
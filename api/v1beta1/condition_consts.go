@@ -266,4 +266,25 @@ const (
 
 	// EtcdHealthCheckFailedReason (Severity=Error) documents that healthcheck on an etcd member failed
 	EtcdHealthCheckFailedReason = "EtcdMemberHealthCheckFailed"
+
+	// EtcdProviderContractViolationReason (Severity=Error) documents an etcd cluster object which does not satisfy
+	// the etcd provider contract, e.g. an etcd cluster which is ready but does not report its endpoints.
+	EtcdProviderContractViolationReason = "EtcdProviderContractViolation"
+
+	// ManagedExternalEtcdUpgradeCompletedCondition documents the progress of an upgrade of the managed external etcd cluster,
+	// which is coordinated with the control plane rollout: the etcd cluster is upgraded first, then the control plane
+	// rolls out using the endpoints of the upgraded etcd cluster.
+	ManagedExternalEtcdUpgradeCompletedCondition ConditionType = "ManagedEtcdUpgradeCompleted"
+
+	// ManagedEtcdUpgradeInProgressReason (Severity=Info) documents a cluster waiting for the etcd cluster upgrade
+	// to complete; the control plane is paused in the meantime.
+	ManagedEtcdUpgradeInProgressReason = "ManagedEtcdUpgradeInProgress"
+
+	// WaitingForManagedEtcdEndpointsReason (Severity=Info) documents a cluster waiting for the upgraded etcd cluster
+	// to be ready and to report its endpoints.
+	WaitingForManagedEtcdEndpointsReason = "WaitingForManagedEtcdEndpoints"
+
+	// WaitingForControlPlaneRolloutReason (Severity=Info) documents a cluster waiting for the control plane
+	// to roll out using the endpoints of the upgraded etcd cluster.
+	WaitingForControlPlaneRolloutReason = "WaitingForControlPlaneRollout"
 )
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -169,3 +170,52 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("MachineHealthCheck").GroupKind(), m.Name, allErrs)
 }
+
+// validate validates a MachineHealthCheckClass defined in a ClusterClass or in a Cluster topology,
+// using the same rules applied to the MachineHealthCheck generated from it.
+func (m *MachineHealthCheckClass) validate(namespace string, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(m.UnhealthyConditions) == 0 {
+		allErrs = append(
+			allErrs,
+			field.Required(pathPrefix.Child("unhealthyConditions"), "must define at least one unhealthy condition"),
+		)
+	}
+
+	if m.NodeStartupTimeout != nil &&
+		m.NodeStartupTimeout.Seconds() != disabledNodeStartupTimeout.Seconds() &&
+		m.NodeStartupTimeout.Seconds() < minNodeStartupTimeout.Seconds() {
+		allErrs = append(
+			allErrs,
+			field.Invalid(pathPrefix.Child("nodeStartupTimeout"), m.NodeStartupTimeout.Seconds(), "must be at least 30s"),
+		)
+	}
+
+	if m.MaxUnhealthy != nil {
+		if _, err := intstr.GetScaledValueFromIntOrPercent(m.MaxUnhealthy, 0, false); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(pathPrefix.Child("maxUnhealthy"), m.MaxUnhealthy, fmt.Sprintf("must be either an int or a percentage: %v", err.Error())),
+			)
+		}
+	}
+
+	if m.RemediationTemplate != nil && m.RemediationTemplate.Namespace != namespace {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				pathPrefix.Child("remediationTemplate", "namespace"),
+				m.RemediationTemplate.Namespace,
+				"must match metadata.namespace",
+			),
+		)
+	}
+
+	return allErrs
+}
+
+// isDefined returns true if any field of the MachineHealthCheckClass in the MachineHealthCheckTopology is set.
+func (m *MachineHealthCheckTopology) isDefined() bool {
+	return !reflect.DeepEqual(m.MachineHealthCheckClass, MachineHealthCheckClass{})
+}
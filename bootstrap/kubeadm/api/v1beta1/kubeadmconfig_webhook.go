@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -25,10 +26,11 @@ import (
 )
 
 var (
-	conflictingFileSourceMsg = "only one of content or contentFrom may be specified for a single file"
-	missingSecretNameMsg     = "secret file source must specify non-empty secret name"
-	missingSecretKeyMsg      = "secret file source must specify non-empty secret key"
-	pathConflictMsg          = "path property must be unique among all files"
+	conflictingFileSourceMsg   = "only one of content or contentFrom may be specified for a single file"
+	missingSecretNameMsg       = "secret file source must specify non-empty secret name"
+	missingSecretKeyMsg        = "secret file source must specify non-empty secret key"
+	pathConflictMsg            = "path property must be unique among all files"
+	bottlerocketUnsupportedMsg = "not supported when format is bottlerocket"
 )
 
 func (c *KubeadmConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -43,12 +45,16 @@ var _ webhook.Validator = &KubeadmConfig{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
 func (c *KubeadmConfig) ValidateCreate() error {
-	return c.Spec.validate(c.Name)
+	return c.Spec.validate(c.Name, nil)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (c *KubeadmConfig) ValidateUpdate(old runtime.Object) error {
-	return c.Spec.validate(c.Name)
+	var oldSpec *KubeadmConfigSpec
+	if oldConfig, ok := old.(*KubeadmConfig); ok && oldConfig != nil {
+		oldSpec = &oldConfig.Spec
+	}
+	return c.Spec.validate(c.Name, oldSpec)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -56,7 +62,7 @@ func (c *KubeadmConfig) ValidateDelete() error {
 	return nil
 }
 
-func (c *KubeadmConfigSpec) validate(name string) error {
+func (c *KubeadmConfigSpec) validate(name string, old *KubeadmConfigSpec) error {
 	var allErrs field.ErrorList
 
 	knownPaths := map[string]struct{}{}
@@ -112,8 +118,43 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 		knownPaths[file.Path] = struct{}{}
 	}
 
+	allErrs = append(allErrs, c.ValidateBottlerocket(old, field.NewPath("spec"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmConfig").GroupKind(), name, allErrs)
 }
+
+// ValidateBottlerocket rejects the fields that can't be translated into Bottlerocket settings when format is bottlerocket
+// and that would silently change the behaviour of the nodes if ignored when generating the bootstrap data.
+// When old is not nil, i.e. on update, only the fields changed from old are validated, so objects created before
+// this validation existed can still be updated, e.g. to add finalizers or owner references.
+func (c *KubeadmConfigSpec) ValidateBottlerocket(old *KubeadmConfigSpec, pathPrefix *field.Path) field.ErrorList {
+	if c.Format != Bottlerocket {
+		return nil
+	}
+	if old == nil || old.Format != Bottlerocket {
+		old = &KubeadmConfigSpec{}
+	}
+
+	var allErrs field.ErrorList
+
+	if c.DiskSetup != nil && !equality.Semantic.DeepEqual(c.DiskSetup, old.DiskSetup) {
+		allErrs = append(allErrs, field.Forbidden(pathPrefix.Child("diskSetup"), bottlerocketUnsupportedMsg))
+	}
+	if len(c.Mounts) > 0 && !equality.Semantic.DeepEqual(c.Mounts, old.Mounts) {
+		allErrs = append(allErrs, field.Forbidden(pathPrefix.Child("mounts"), bottlerocketUnsupportedMsg))
+	}
+	if c.NTP != nil && c.NTP.Enabled != nil && !*c.NTP.Enabled && !equality.Semantic.DeepEqual(c.NTP, old.NTP) {
+		allErrs = append(allErrs, field.Invalid(pathPrefix.Child("ntp", "enabled"), *c.NTP.Enabled, "NTP can't be disabled when format is bottlerocket"))
+	}
+
+	// The admin container supports a single user; the other user fields, e.g. sudo, groups or lockPassword,
+	// are ignored given that the user of the admin container already has administrative access.
+	if len(c.Users) > 1 && !equality.Semantic.DeepEqual(c.Users, old.Users) {
+		allErrs = append(allErrs, field.TooMany(pathPrefix.Child("users"), len(c.Users), 1))
+	}
+
+	return allErrs
+}
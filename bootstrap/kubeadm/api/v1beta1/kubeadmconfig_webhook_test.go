@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestKubeadmConfigValidateBottlerocket(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      KubeadmConfigSpec
+		expectErr bool
+	}{
+		{
+			name: "accepts the fields supported by bottlerocket",
+			spec: KubeadmConfigSpec{
+				Format:              Bottlerocket,
+				PreKubeadmCommands:  []string{"echo pre"},
+				PostKubeadmCommands: []string{"echo post"},
+				NTP:                 &NTP{Enabled: pointer.Bool(true), Servers: []string{"0.pool.ntp.org"}},
+				Users: []User{
+					{Name: "ec2-user", Passwd: pointer.String("hash"), SSHAuthorizedKeys: []string{"ssh-rsa key"}},
+				},
+			},
+		},
+		{
+			name: "rejects disk setup",
+			spec: KubeadmConfigSpec{
+				Format:    Bottlerocket,
+				DiskSetup: &DiskSetup{Partitions: []Partition{{Device: "/dev/sdb", Layout: true}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "rejects mounts",
+			spec: KubeadmConfigSpec{
+				Format: Bottlerocket,
+				Mounts: []MountPoints{{"/dev/sdb", "/data"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "rejects disabling ntp",
+			spec: KubeadmConfigSpec{
+				Format: Bottlerocket,
+				NTP:    &NTP{Enabled: pointer.Bool(false)},
+			},
+			expectErr: true,
+		},
+		{
+			name: "rejects more than one user",
+			spec: KubeadmConfigSpec{
+				Format: Bottlerocket,
+				Users:  []User{{Name: "user1"}, {Name: "user2"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "accepts an admin user with fields ignored by the admin container",
+			spec: KubeadmConfigSpec{
+				Format: Bottlerocket,
+				Users: []User{
+					{
+						Name:              "admin",
+						Groups:            pointer.String("wheel"),
+						Sudo:              pointer.String("ALL=(ALL) NOPASSWD:ALL"),
+						Shell:             pointer.String("/bin/bash"),
+						LockPassword:      pointer.Bool(false),
+						SSHAuthorizedKeys: []string{"ssh-rsa key"},
+					},
+				},
+			},
+		},
+		{
+			name: "accepts the same fields with cloud-config",
+			spec: KubeadmConfigSpec{
+				Format:    CloudConfig,
+				DiskSetup: &DiskSetup{Partitions: []Partition{{Device: "/dev/sdb", Layout: true}}},
+				Mounts:    []MountPoints{{"/dev/sdb", "/data"}},
+				NTP:       &NTP{Enabled: pointer.Bool(false)},
+				Users:     []User{{Name: "user1", Sudo: pointer.String("ALL=(ALL) NOPASSWD:ALL")}, {Name: "user2"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: metav1.NamespaceDefault},
+				Spec:       tt.spec,
+			}
+			oldConfig := &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: metav1.NamespaceDefault},
+				Spec:       KubeadmConfigSpec{Format: tt.spec.Format},
+			}
+			if tt.expectErr {
+				g.Expect(config.ValidateCreate()).NotTo(Succeed())
+				g.Expect(config.ValidateUpdate(oldConfig)).NotTo(Succeed())
+			} else {
+				g.Expect(config.ValidateCreate()).To(Succeed())
+				g.Expect(config.ValidateUpdate(oldConfig)).To(Succeed())
+			}
+
+			// Updates not changing the fields, e.g. adding a finalizer, are always allowed.
+			updatedConfig := config.DeepCopy()
+			updatedConfig.Finalizers = []string{"test"}
+			g.Expect(updatedConfig.ValidateUpdate(config)).To(Succeed())
+
+			template := &KubeadmConfigTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: metav1.NamespaceDefault},
+				Spec:       KubeadmConfigTemplateSpec{Template: KubeadmConfigTemplateResource{Spec: tt.spec}},
+			}
+			oldTemplate := &KubeadmConfigTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: metav1.NamespaceDefault},
+				Spec:       KubeadmConfigTemplateSpec{Template: KubeadmConfigTemplateResource{Spec: oldConfig.Spec}},
+			}
+			if tt.expectErr {
+				g.Expect(template.ValidateCreate()).NotTo(Succeed())
+				g.Expect(template.ValidateUpdate(oldTemplate)).NotTo(Succeed())
+			} else {
+				g.Expect(template.ValidateCreate()).To(Succeed())
+				g.Expect(template.ValidateUpdate(oldTemplate)).To(Succeed())
+			}
+			g.Expect(template.ValidateUpdate(template.DeepCopy())).To(Succeed())
+		})
+	}
+}
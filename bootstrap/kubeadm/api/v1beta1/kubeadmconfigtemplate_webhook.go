@@ -17,7 +17,11 @@ limitations under the License.
 package v1beta1
 
 import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 func (r *KubeadmConfigTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -25,3 +29,34 @@ func (r *KubeadmConfigTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error
 		For(r).
 		Complete()
 }
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-bootstrap-cluster-x-k8s-io-v1beta1-kubeadmconfigtemplate,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=bootstrap.cluster.x-k8s.io,resources=kubeadmconfigtemplates,versions=v1beta1,name=validation.kubeadmconfigtemplate.bootstrap.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &KubeadmConfigTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *KubeadmConfigTemplate) ValidateCreate() error {
+	return r.validate(nil)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *KubeadmConfigTemplate) ValidateUpdate(old runtime.Object) error {
+	var oldSpec *KubeadmConfigSpec
+	if oldTemplate, ok := old.(*KubeadmConfigTemplate); ok && oldTemplate != nil {
+		oldSpec = &oldTemplate.Spec.Template.Spec
+	}
+	return r.validate(oldSpec)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *KubeadmConfigTemplate) ValidateDelete() error {
+	return nil
+}
+
+func (r *KubeadmConfigTemplate) validate(old *KubeadmConfigSpec) error {
+	allErrs := r.Spec.Template.Spec.ValidateBottlerocket(old, field.NewPath("spec", "template", "spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmConfigTemplate").GroupKind(), r.Name, allErrs)
+}
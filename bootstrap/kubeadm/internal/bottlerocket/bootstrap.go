@@ -43,6 +43,11 @@ no-proxy = [{{stringsJoin .NoProxyEndpoints "," }}]
 data = "{{.RegistryMirrorCACert}}"
 trusted=true
 {{- end -}}
+`
+	ntpTemplate = `{{ define "ntpSettings" -}}
+[settings.ntp]
+time-servers = [{{stringsJoin .NTPServers ", " }}]
+{{- end -}}
 `
 	nodeLabelsTemplate = `{{ define "nodeLabelSettings" -}}
 [settings.kubernetes.node-labels]
@@ -81,6 +86,10 @@ source = "{{.ControlContainerSource}}"
 {{template "registryMirrorCACertSettings" .}}
 {{- end -}}
 
+{{- if .NTPServers}}
+{{template "ntpSettings" .}}
+{{- end -}}
+
 {{- if (ne .NodeLabels "")}}
 {{template "nodeLabelSettings" .}}
 {{- end -}}
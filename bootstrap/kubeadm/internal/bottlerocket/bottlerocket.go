@@ -42,10 +42,18 @@ type BottlerocketSettingsInput struct {
 	NoProxyEndpoints           []string
 	RegistryMirrorEndpoint     string
 	RegistryMirrorCACert       string
+	NTPServers                 []string
 	NodeLabels                 string
 	Taints                     string
 }
 
+// AdminContainerUserDataInput holds the settings for the user of the admin container.
+type AdminContainerUserDataInput struct {
+	User              string
+	PasswordHash      string
+	SSHAuthorizedKeys string
+}
+
 type HostPath struct {
 	Path string
 	Type string
@@ -56,6 +64,9 @@ func generateBootstrapContainerUserData(kind string, tpl string, data interface{
 	if _, err := tm.Parse(filesTemplate); err != nil {
 		return nil, errors.Wrap(err, "failed to parse files template")
 	}
+	if _, err := tm.Parse(commandsTemplate); err != nil {
+		return nil, errors.Wrap(err, "failed to parse commands template")
+	}
 
 	t, err := tm.Parse(tpl)
 	if err != nil {
@@ -109,6 +120,9 @@ func generateNodeUserData(kind string, tpl string, data interface{}) ([]byte, er
 	if _, err := tm.Parse(registryMirrorCACertTemplate); err != nil {
 		return nil, errors.Wrapf(err, "failed to parse registry mirror ca cert %s template", kind)
 	}
+	if _, err := tm.Parse(ntpTemplate); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse ntp %s template", kind)
+	}
 	if _, err := tm.Parse(nodeLabelsTemplate); err != nil {
 		return nil, errors.Wrapf(err, "failed to parse node labels %s template", kind)
 	}
@@ -128,15 +142,12 @@ func generateNodeUserData(kind string, tpl string, data interface{}) ([]byte, er
 }
 
 // getBottlerocketNodeUserData returns the userdata for the host bottlerocket in toml format
-func getBottlerocketNodeUserData(bootstrapContainerUserData []byte, users []bootstrapv1.User, config *BottlerocketConfig) ([]byte, error) {
+func getBottlerocketNodeUserData(bootstrapContainerUserData []byte, users []bootstrapv1.User, ntp *bootstrapv1.NTP, config *BottlerocketConfig) ([]byte, error) {
 	// base64 encode the bootstrapContainer's user data
 	b64BootstrapContainerUserData := base64.StdEncoding.EncodeToString(bootstrapContainerUserData)
 
-	// Parse out all the ssh authorized keys
-	sshAuthorizedKeys := getAllAuthorizedKeys(users)
-
 	// generate the userdata for the admin container
-	adminContainerUserData, err := generateAdminContainerUserData("InitAdminContainer", usersTemplate, sshAuthorizedKeys)
+	adminContainerUserData, err := generateAdminContainerUserData("InitAdminContainer", usersTemplate, getAdminContainerUserDataInput(users))
 	if err != nil {
 		return nil, err
 	}
@@ -160,6 +171,11 @@ func getBottlerocketNodeUserData(bootstrapContainerUserData []byte, users []boot
 			bottlerocketInput.NoProxyEndpoints = append(bottlerocketInput.NoProxyEndpoints, strconv.Quote(noProxy))
 		}
 	}
+	if ntp != nil {
+		for _, server := range ntp.Servers {
+			bottlerocketInput.NTPServers = append(bottlerocketInput.NTPServers, strconv.Quote(server))
+		}
+	}
 	if config.RegistryMirrorConfiguration.CACert != "" {
 		bottlerocketInput.RegistryMirrorCACert = base64.StdEncoding.EncodeToString([]byte(config.RegistryMirrorConfiguration.CACert))
 	}
@@ -230,6 +246,25 @@ func getAllAuthorizedKeys(users []bootstrapv1.User) string {
 	return strings.Join(sshAuthorizedKeys, ",")
 }
 
+// getAdminContainerUserDataInput returns the settings for the user of the admin container.
+// NOTE: The admin container supports a single user, so the name and the password of the first user are used,
+// while the ssh authorized keys of all the users are granted to it.
+func getAdminContainerUserDataInput(users []bootstrapv1.User) *AdminContainerUserDataInput {
+	input := &AdminContainerUserDataInput{
+		SSHAuthorizedKeys: getAllAuthorizedKeys(users),
+	}
+	if len(users) == 0 {
+		return input
+	}
+	if users[0].Name != "" {
+		input.User = strconv.Quote(users[0].Name)
+	}
+	if users[0].Passwd != nil && *users[0].Passwd != "" {
+		input.PasswordHash = strconv.Quote(*users[0].Passwd)
+	}
+	return input
+}
+
 func patchKubeVipFile(writeFiles []bootstrapv1.File) ([]bootstrapv1.File, error) {
 	var patchedFiles []bootstrapv1.File
 	for _, file := range writeFiles {
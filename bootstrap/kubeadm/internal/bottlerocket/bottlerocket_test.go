@@ -0,0 +1,94 @@
+package bottlerocket
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/pointer"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/cloudinit"
+)
+
+var userDataRegexp = regexp.MustCompile(`user-data = "(.*)"`)
+
+func TestNewNode(t *testing.T) {
+	g := NewWithT(t)
+
+	input := &cloudinit.NodeInput{
+		BaseUserData: cloudinit.BaseUserData{
+			PreKubeadmCommands:  []string{"echo pre", `echo "quoted"`},
+			PostKubeadmCommands: []string{"echo post"},
+			Users: []bootstrapv1.User{
+				{
+					Name:              "ec2-user",
+					Passwd:            pointer.String("$6$rounds=4096$hash"),
+					SSHAuthorizedKeys: []string{"ssh-rsa key1", "ssh-rsa key2"},
+				},
+			},
+			NTP: &bootstrapv1.NTP{
+				Servers: []string{"0.pool.ntp.org", "1.pool.ntp.org"},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+	config := &BottlerocketConfig{
+		Pause:                 bootstrapv1.Pause{ImageMeta: bootstrapv1.ImageMeta{ImageRepository: "pause", ImageTag: "v1"}},
+		BottlerocketBootstrap: bootstrapv1.BottlerocketBootstrap{ImageMeta: bootstrapv1.ImageMeta{ImageRepository: "bootstrap", ImageTag: "v1"}},
+	}
+
+	out, err := NewNode(input, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`[settings.ntp]
+time-servers = ["0.pool.ntp.org", "1.pool.ntp.org"]`))
+
+	userData := userDataRegexp.FindAllStringSubmatch(string(out), -1)
+	g.Expect(userData).To(HaveLen(2))
+
+	bootstrapContainerUserData, err := base64.StdEncoding.DecodeString(userData[0][1])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(bootstrapContainerUserData)).To(HaveSuffix(`      my-join-config
+preKubeadmCommands:
+- "echo pre"
+- "echo \"quoted\""
+postKubeadmCommands:
+- "echo post"
+runcmd: "WorkerJoin"
+`))
+
+	adminContainerUserData, err := base64.StdEncoding.DecodeString(userData[1][1])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(adminContainerUserData)).To(Equal(`
+{
+	"user": "ec2-user",
+	"password-hash": "$6$rounds=4096$hash",
+	"ssh": {
+		"authorized-keys": ["ssh-rsa key1","ssh-rsa key2"]
+	}
+}`))
+}
+
+func TestNewNodeWithoutOptionalSettings(t *testing.T) {
+	g := NewWithT(t)
+
+	input := &cloudinit.NodeInput{
+		JoinConfiguration: "my-join-config",
+	}
+	config := &BottlerocketConfig{}
+
+	out, err := NewNode(input, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("[settings.ntp]"))
+
+	userData := userDataRegexp.FindAllStringSubmatch(string(out), -1)
+	g.Expect(userData).To(HaveLen(2))
+
+	bootstrapContainerUserData, err := base64.StdEncoding.DecodeString(userData[0][1])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(bootstrapContainerUserData)).To(HaveSuffix(`      my-join-config
+runcmd: "WorkerJoin"
+`))
+	g.Expect(userData[1][1]).To(BeEmpty())
+}
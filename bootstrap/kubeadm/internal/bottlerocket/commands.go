@@ -0,0 +1,20 @@
+package bottlerocket
+
+const (
+	// commandsTemplate renders the commands the bootstrap container runs on the host before and after kubeadm.
+	commandsTemplate = `{{- define "preKubeadmCommands" -}}
+{{- if . }}
+preKubeadmCommands:{{ range . }}
+- {{printf "%q" .}}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+{{- define "postKubeadmCommands" -}}
+{{- if . }}
+postKubeadmCommands:{{ range . }}
+- {{printf "%q" .}}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+`
+)
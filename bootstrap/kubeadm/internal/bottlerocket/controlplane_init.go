@@ -21,6 +21,8 @@ const (
 {{.ClusterConfiguration | Indent 6}}
       ---
 {{.InitConfiguration | Indent 6}}
+{{- template "preKubeadmCommands" .PreKubeadmCommands}}
+{{- template "postKubeadmCommands" .PostKubeadmCommands}}
 runcmd: "ControlPlaneInit"
 `
 )
@@ -43,5 +45,5 @@ func NewInitControlPlane(input *cloudinit.ControlPlaneInput, config *Bottlerocke
 		return nil, err
 	}
 
-	return getBottlerocketNodeUserData(bootstrapContainerUserData, input.Users, config)
+	return getBottlerocketNodeUserData(bootstrapContainerUserData, input.Users, input.NTP, config)
 }
@@ -12,6 +12,8 @@ const (
     permissions: '0640'
     content: |
 {{.JoinConfiguration | Indent 6}}
+{{- template "preKubeadmCommands" .PreKubeadmCommands}}
+{{- template "postKubeadmCommands" .PostKubeadmCommands}}
 runcmd: "ControlPlaneJoin"
 `
 )
@@ -32,5 +34,5 @@ func NewJoinControlPlane(input *cloudinit.ControlPlaneJoinInput, config *Bottler
 		return nil, errors.Wrapf(err, "failed to generate user data for machine joining control plane")
 	}
 
-	return getBottlerocketNodeUserData(bootstrapContainerUserData, input.Users, config)
+	return getBottlerocketNodeUserData(bootstrapContainerUserData, input.Users, input.NTP, config)
 }
@@ -14,6 +14,8 @@ const (
     content: |
       ---
 {{.JoinConfiguration | Indent 6}}
+{{- template "preKubeadmCommands" .PreKubeadmCommands}}
+{{- template "postKubeadmCommands" .PostKubeadmCommands}}
 runcmd: "WorkerJoin"
 `
 )
@@ -28,5 +30,5 @@ func NewNode(input *cloudinit.NodeInput, config *BottlerocketConfig) ([]byte, er
 		return nil, err
 	}
 
-	return getBottlerocketNodeUserData(bootstrapContainerUserData, input.Users, config)
+	return getBottlerocketNodeUserData(bootstrapContainerUserData, input.Users, input.NTP, config)
 }
@@ -1,10 +1,16 @@
 package bottlerocket
 
 const (
-	usersTemplate = `{{- if . }}
+	usersTemplate = `{{- if or .SSHAuthorizedKeys .PasswordHash }}
 {
+	{{- if .User }}
+	"user": {{.User}},
+	{{- end }}
+	{{- if .PasswordHash }}
+	"password-hash": {{.PasswordHash}},
+	{{- end }}
 	"ssh": {
-		"authorized-keys": [{{.}}]
+		"authorized-keys": [{{.SSHAuthorizedKeys}}]
 	}
 }
 {{- end -}}
@@ -0,0 +1,552 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yamlprocessor "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// bundleManifestFile is the name of the file describing the content of a bundle.
+	bundleManifestFile = "bundle.yaml"
+
+	// bundleImagesFile is the name of the file listing the images required by the content of a bundle.
+	bundleImagesFile = "images.txt"
+
+	// bundleRepositoryDir is the name of the folder hosting the local repository in a bundle.
+	bundleRepositoryDir = "repository"
+
+	// bundleComponentsFile is the name of the components YAML for each provider in a bundle.
+	bundleComponentsFile = "components.yaml"
+
+	// bundleConfigFile is the name of the clusterctl configuration file generated when importing a bundle.
+	bundleConfigFile = "clusterctl.yaml"
+
+	bundleMetadataFile     = "metadata.yaml"
+	bundleCertManagerName  = "cert-manager"
+	bundleCertManagerFile  = "cert-manager.yaml"
+	bundleMaxFileSizeBytes = 256 << 20
+)
+
+// BundleCreateOptions carries the options supported by BundleCreate.
+type BundleCreateOptions struct {
+	// CoreProvider version (e.g. cluster-api:v1.0.0) to add to the bundle. If unspecified, the
+	// cluster-api core provider's latest release is used.
+	CoreProvider string
+
+	// BootstrapProviders and versions (e.g. kubeadm:v1.0.0) to add to the bundle.
+	// If unspecified, the kubeadm bootstrap provider's latest release is used.
+	BootstrapProviders []string
+
+	// ControlPlaneProviders and versions (e.g. kubeadm:v1.0.0) to add to the bundle.
+	// If unspecified, the kubeadm control plane provider's latest release is used.
+	ControlPlaneProviders []string
+
+	// InfrastructureProviders and versions (e.g. aws:v1.0.0) to add to the bundle.
+	InfrastructureProviders []string
+
+	// Flavors defines the workload cluster templates to add to the bundle for each infrastructure provider,
+	// in addition to the default one. Flavors not available for a provider are ignored.
+	Flavors []string
+
+	// OutputFile defines the path of the bundle to be created.
+	OutputFile string
+}
+
+// BundleImportOptions carries the options supported by BundleImport.
+type BundleImportOptions struct {
+	// BundleFile defines the path of the bundle to be imported.
+	BundleFile string
+
+	// Directory defines the directory where the bundle should be extracted; a clusterctl configuration
+	// file serving providers and cert-manager from the extracted local repository is generated in this directory.
+	Directory string
+
+	// ImageRepository defines the repository hosting a mirror of the images listed in the bundle, e.g. registry.local/cluster-api;
+	// if set, all the image references are rewritten to use this repository.
+	ImageRepository string
+}
+
+// BundleImportResult is the result of BundleImport.
+type BundleImportResult struct {
+	// ConfigFile is the path of the generated clusterctl configuration file, to be used with clusterctl init --config.
+	ConfigFile string
+
+	// Images lists the images required by the content of the bundle, and where they are expected to be pulled from.
+	Images []BundleImage
+}
+
+// BundleImage is an image required by the content of a bundle.
+type BundleImage struct {
+	// Source is the image reference as defined in the bundle.
+	Source string
+
+	// Target is the image reference used after applying the image overrides of the generated clusterctl configuration.
+	Target string
+}
+
+// bundleManifest describes the content of a bundle.
+type bundleManifest struct {
+	Providers   []bundleProvider    `json:"providers"`
+	CertManager bundleCertManager   `json:"certManager"`
+	Images      map[string][]string `json:"images"`
+}
+
+// bundleProvider is a provider stored in a bundle.
+type bundleProvider struct {
+	Name      string                    `json:"name"`
+	Type      clusterctlv1.ProviderType `json:"type"`
+	Version   string                    `json:"version"`
+	Templates []string                  `json:"templates,omitempty"`
+}
+
+// bundleCertManager is the cert-manager release stored in a bundle.
+type bundleCertManager struct {
+	Version string `json:"version"`
+}
+
+// bundleConfig mirrors the subset of the clusterctl configuration file generated when importing a bundle.
+type bundleConfig struct {
+	Providers   []bundleConfigProvider           `json:"providers"`
+	CertManager bundleConfigCertManager          `json:"cert-manager"`
+	Images      map[string]bundleConfigImageMeta `json:"images,omitempty"`
+}
+
+type bundleConfigProvider struct {
+	Name string                    `json:"name"`
+	URL  string                    `json:"url"`
+	Type clusterctlv1.ProviderType `json:"type"`
+}
+
+type bundleConfigCertManager struct {
+	URL     string `json:"url"`
+	Version string `json:"version"`
+}
+
+type bundleConfigImageMeta struct {
+	Repository string `json:"repository,omitempty"`
+}
+
+// BundleCreate creates a bundle with everything required for running clusterctl init in an air-gapped environment.
+func (c *clusterctlClient) BundleCreate(options BundleCreateOptions) error {
+	log := logf.Log
+
+	if options.OutputFile == "" {
+		return errors.New("invalid arguments: please provide an output file")
+	}
+
+	// Enforce the presence of a core provider, a bootstrap provider and a control-plane provider like in a first run of init.
+	if options.CoreProvider == "" {
+		options.CoreProvider = config.ClusterAPIProviderName
+	}
+	if len(options.BootstrapProviders) == 0 {
+		options.BootstrapProviders = append(options.BootstrapProviders, config.KubeadmBootstrapProviderName)
+	}
+	if len(options.ControlPlaneProviders) == 0 {
+		options.ControlPlaneProviders = append(options.ControlPlaneProviders, config.KubeadmControlPlaneProviderName)
+	}
+
+	files := map[string][]byte{}
+	manifest := &bundleManifest{
+		Images: map[string][]string{},
+	}
+
+	providers := []struct {
+		providerType clusterctlv1.ProviderType
+		names        []string
+	}{
+		{providerType: clusterctlv1.CoreProviderType, names: []string{options.CoreProvider}},
+		{providerType: clusterctlv1.BootstrapProviderType, names: options.BootstrapProviders},
+		{providerType: clusterctlv1.ControlPlaneProviderType, names: options.ControlPlaneProviders},
+		{providerType: clusterctlv1.InfrastructureProviderType, names: options.InfrastructureProviders},
+	}
+	for _, p := range providers {
+		for _, name := range p.names {
+			// It is possible to opt-out from bootstrap/control-plane providers using '-' as a provider name (NoopProvider).
+			if name == NoopProvider {
+				if p.providerType == clusterctlv1.CoreProviderType {
+					return errors.New("the '-' value can not be used for the core provider")
+				}
+				continue
+			}
+
+			provider, images, err := c.addProviderToBundle(files, name, p.providerType, options.Flavors)
+			if err != nil {
+				return err
+			}
+			log.Info("Adding to the bundle", "Provider", clusterctlv1.ManifestLabel(provider.Name, provider.Type), "Version", provider.Version)
+			manifest.Providers = append(manifest.Providers, *provider)
+			manifest.Images[clusterctlv1.ManifestLabel(provider.Name, provider.Type)] = images
+		}
+	}
+
+	certManagerVersion, images, err := c.addCertManagerToBundle(files)
+	if err != nil {
+		return err
+	}
+	log.Info("Adding to the bundle", "Provider", bundleCertManagerName, "Version", certManagerVersion)
+	manifest.CertManager = bundleCertManager{Version: certManagerVersion}
+	manifest.Images[config.CertManagerImageComponent] = images
+
+	manifestYaml, err := yaml.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the bundle manifest")
+	}
+	files[bundleManifestFile] = manifestYaml
+	files[bundleImagesFile] = []byte(strings.Join(manifest.allImages(), "\n") + "\n")
+
+	return writeBundle(options.OutputFile, files)
+}
+
+// addProviderToBundle adds the components YAML, the metadata and the cluster templates of a provider to the bundle files.
+func (c *clusterctlClient) addProviderToBundle(files map[string][]byte, provider string, providerType clusterctlv1.ProviderType, flavors []string) (*bundleProvider, []string, error) {
+	name, version, err := parseProviderName(provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	providerConfig, err := c.configClient.Providers().Get(name, providerType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repositoryClient, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Gets the components without variable substitution so it is possible to resolve the version and to inspect the images.
+	components, err := repositoryClient.Components().Get(repository.ComponentsOptions{Version: version, SkipTemplateProcess: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	version = components.Version()
+
+	rawComponents, err := repositoryClient.Components().Raw(repository.ComponentsOptions{Version: version})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata, err := repositoryClient.Metadata(version).Get()
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata.SetGroupVersionKind(clusterctlv1.GroupVersion.WithKind("Metadata"))
+	rawMetadata, err := yaml.Marshal(metadata)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal the metadata for provider %q", providerConfig.ManifestLabel())
+	}
+
+	dir := path.Join(bundleRepositoryDir, providerConfig.ManifestLabel(), version)
+	files[path.Join(dir, bundleComponentsFile)] = rawComponents
+	files[path.Join(dir, bundleMetadataFile)] = rawMetadata
+
+	bundleProvider := &bundleProvider{
+		Name:    name,
+		Type:    providerType,
+		Version: version,
+	}
+
+	// Templates are expected to exist for the infrastructure providers only.
+	if providerType == clusterctlv1.InfrastructureProviderType {
+		templateNamer := yamlprocessor.NewSimpleProcessor()
+		for _, flavor := range append([]string{""}, flavors...) {
+			rawTemplate, err := repositoryClient.Templates(version).Raw(flavor)
+			if err != nil {
+				logf.Log.V(1).Info("Skipping cluster template", "Provider", providerConfig.ManifestLabel(), "Flavor", flavor, "Error", err.Error())
+				continue
+			}
+			templateName := templateNamer.GetTemplateName(version, flavor)
+			files[path.Join(dir, templateName)] = rawTemplate
+			bundleProvider.Templates = append(bundleProvider.Templates, templateName)
+		}
+	}
+
+	return bundleProvider, components.Images(), nil
+}
+
+// addCertManagerToBundle adds the cert-manager manifest to the bundle files.
+func (c *clusterctlClient) addCertManagerToBundle(files map[string][]byte) (string, []string, error) {
+	certManagerConfig, err := c.configClient.CertManager().Get()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Given that cert manager is not stored in the clusterctl config, and since the repository
+	// client's methods requires a Provider as a parameter, a fake provider is used, like in the cert manager client.
+	certManagerFakeProvider := config.NewProvider(bundleCertManagerName, certManagerConfig.URL(), "")
+	certManagerRepository, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: certManagerFakeProvider})
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawCertManager, err := certManagerRepository.Components().Raw(repository.ComponentsOptions{Version: certManagerConfig.Version()})
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to read cert-manager components")
+	}
+
+	objs, err := utilyaml.ToUnstructured(rawCertManager)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to parse cert-manager components")
+	}
+	objs, err = util.FixImages(objs, func(image string) (string, error) {
+		return c.configClient.ImageMeta().AlterImage(config.CertManagerImageComponent, image)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	images, err := util.InspectImages(objs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	files[path.Join(bundleRepositoryDir, bundleCertManagerName, certManagerConfig.Version(), bundleCertManagerFile)] = rawCertManager
+	return certManagerConfig.Version(), images, nil
+}
+
+// allImages returns the sorted list of unique images in the bundle manifest.
+func (m *bundleManifest) allImages() []string {
+	set := map[string]struct{}{}
+	for _, images := range m.Images {
+		for _, image := range images {
+			set[image] = struct{}{}
+		}
+	}
+	images := make([]string, 0, len(set))
+	for image := range set {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// writeBundle writes the bundle files into a gzipped tarball.
+func writeBundle(outputFile string, files map[string][]byte) error {
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create bundle %q", outputFile)
+	}
+	defer f.Close()
+
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	modTime := time.Now()
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: modTime,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "failed to write %q to bundle %q", name, outputFile)
+		}
+		if _, err := tarWriter.Write(files[name]); err != nil {
+			return errors.Wrapf(err, "failed to write %q to bundle %q", name, outputFile)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write bundle %q", outputFile)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write bundle %q", outputFile)
+	}
+	return f.Close()
+}
+
+// BundleImport extracts a bundle and generates a clusterctl configuration file serving providers, cert-manager
+// and images from the bundle content.
+func (c *clusterctlClient) BundleImport(options BundleImportOptions) (*BundleImportResult, error) {
+	if options.BundleFile == "" {
+		return nil, errors.New("invalid arguments: please provide a bundle file")
+	}
+	if options.Directory == "" {
+		return nil, errors.New("invalid arguments: please provide a directory")
+	}
+
+	directory, err := filepath.Abs(options.Directory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the absolute path of %q", options.Directory)
+	}
+
+	if err := extractBundle(options.BundleFile, directory); err != nil {
+		return nil, err
+	}
+
+	rawManifest, err := os.ReadFile(filepath.Join(directory, bundleManifestFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the manifest of bundle %q", options.BundleFile)
+	}
+	manifest := &bundleManifest{}
+	if err := yaml.Unmarshal(rawManifest, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the manifest of bundle %q", options.BundleFile)
+	}
+
+	// Generates a clusterctl configuration file pointing to the local repository extracted from the bundle.
+	repositoryDir := filepath.Join(directory, bundleRepositoryDir)
+	bundleConfig := &bundleConfig{
+		CertManager: bundleConfigCertManager{
+			URL:     localRepositoryURL(repositoryDir, bundleCertManagerName, manifest.CertManager.Version, bundleCertManagerFile),
+			Version: manifest.CertManager.Version,
+		},
+	}
+	for _, p := range manifest.Providers {
+		bundleConfig.Providers = append(bundleConfig.Providers, bundleConfigProvider{
+			Name: p.Name,
+			URL:  localRepositoryURL(repositoryDir, clusterctlv1.ManifestLabel(p.Name, p.Type), p.Version, bundleComponentsFile),
+			Type: p.Type,
+		})
+	}
+	if options.ImageRepository != "" {
+		bundleConfig.Images = map[string]bundleConfigImageMeta{
+			"all": {Repository: options.ImageRepository},
+		}
+	}
+
+	rawConfig, err := yaml.Marshal(bundleConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the clusterctl configuration")
+	}
+	configFile := filepath.Join(directory, bundleConfigFile)
+	if err := os.WriteFile(configFile, rawConfig, 0600); err != nil {
+		return nil, errors.Wrapf(err, "failed to write the clusterctl configuration %q", configFile)
+	}
+
+	// Rewrites the image references using the image overrides from the generated configuration, the same
+	// way clusterctl init does when installing the providers.
+	configClient, err := config.New(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]string, 0, len(manifest.Images))
+	for component := range manifest.Images {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	result := &BundleImportResult{
+		ConfigFile: configFile,
+	}
+	seen := map[string]bool{}
+	for _, component := range components {
+		for _, image := range manifest.Images[component] {
+			if seen[image] {
+				continue
+			}
+			seen[image] = true
+
+			target, err := configClient.ImageMeta().AlterImage(component, image)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to rewrite image %q", image)
+			}
+			result.Images = append(result.Images, BundleImage{Source: image, Target: target})
+		}
+	}
+	sort.Slice(result.Images, func(i, j int) bool {
+		return result.Images[i].Source < result.Images[j].Source
+	})
+
+	return result, nil
+}
+
+// localRepositoryURL returns the url of a file hosted on a local repository.
+func localRepositoryURL(basePath, providerLabel, version, file string) string {
+	// NB. windows paths requires an additional leading / to be used in a file URI.
+	p := filepath.ToSlash(filepath.Join(basePath, providerLabel, version, file))
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return fmt.Sprintf("file://%s", p)
+}
+
+// extractBundle extracts a gzipped tarball into the target directory.
+func extractBundle(bundleFile, directory string) error {
+	f, err := os.Open(bundleFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open bundle %q", bundleFile)
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read bundle %q", bundleFile)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read bundle %q", bundleFile)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Prevent the bundle to write files outside of the target directory.
+		target := filepath.Join(directory, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(directory)+string(os.PathSeparator)) {
+			return errors.Errorf("invalid bundle %q: file %q is outside of the bundle", bundleFile, header.Name)
+		}
+		if header.Size > bundleMaxFileSizeBytes {
+			return errors.Errorf("invalid bundle %q: file %q exceeds the maximum size", bundleFile, header.Name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return errors.Wrapf(err, "failed to create folder for %q", target)
+		}
+		content, err := io.ReadAll(io.LimitReader(tarReader, bundleMaxFileSizeBytes))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q from bundle %q", header.Name, bundleFile)
+		}
+		if err := os.WriteFile(target, content, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %q", target)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+var certManagerBundleYAML = []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cert-manager
+  namespace: cert-manager
+spec:
+  template:
+    spec:
+      containers:
+      - image: quay.io/jetstack/cert-manager-controller:v1.5.3
+        name: cert-manager
+`)
+
+func fakeBundleClient() *fakeClient {
+	config1 := fakeConfig(
+		[]config.Provider{capiProviderConfig, bootstrapProviderConfig, controlPlaneProviderConfig, infraProviderConfig},
+		map[string]string{"SOME_VARIABLE": "value"},
+	)
+
+	client := fakeClusterCtlClient(config1, fakeRepositories(config1, nil), nil)
+
+	// cert-manager is not a provider, so the repository is not added to the config.
+	certManagerProvider := config.NewProvider("cert-manager", config.CertManagerDefaultURL, "")
+	client.repositories[certManagerProvider.ManifestLabel()] = newFakeRepository(certManagerProvider, config1).
+		WithPaths("root", "cert-manager.yaml").
+		WithDefaultVersion(config.CertManagerDefaultVersion).
+		WithFile(config.CertManagerDefaultVersion, "cert-manager.yaml", certManagerBundleYAML)
+
+	return client
+}
+
+func Test_clusterctlClient_BundleCreate(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   BundleCreateOptions
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name: "bundle with default providers and an infrastructure provider",
+			options: BundleCreateOptions{
+				InfrastructureProviders: []string{"infra"},
+				Flavors:                 []string{"not-existing"},
+			},
+			wantFiles: []string{
+				"bundle.yaml",
+				"images.txt",
+				"repository/bootstrap-kubeadm/v2.0.0/components.yaml",
+				"repository/bootstrap-kubeadm/v2.0.0/metadata.yaml",
+				"repository/cert-manager/v1.5.3/cert-manager.yaml",
+				"repository/cluster-api/v1.0.0/components.yaml",
+				"repository/cluster-api/v1.0.0/metadata.yaml",
+				"repository/control-plane-kubeadm/v2.0.0/components.yaml",
+				"repository/control-plane-kubeadm/v2.0.0/metadata.yaml",
+				"repository/infrastructure-infra/v3.0.0/cluster-template.yaml",
+				"repository/infrastructure-infra/v3.0.0/components.yaml",
+				"repository/infrastructure-infra/v3.0.0/metadata.yaml",
+			},
+			wantErr: false,
+		},
+		{
+			name: "bundle with explicit versions and without control plane provider",
+			options: BundleCreateOptions{
+				CoreProvider:          "cluster-api:v1.1.0",
+				ControlPlaneProviders: []string{NoopProvider},
+			},
+			wantFiles: []string{
+				"bundle.yaml",
+				"images.txt",
+				"repository/bootstrap-kubeadm/v2.0.0/components.yaml",
+				"repository/bootstrap-kubeadm/v2.0.0/metadata.yaml",
+				"repository/cert-manager/v1.5.3/cert-manager.yaml",
+				"repository/cluster-api/v1.1.0/components.yaml",
+				"repository/cluster-api/v1.1.0/metadata.yaml",
+			},
+			wantErr: false,
+		},
+		{
+			name: "fails if the core provider is disabled",
+			options: BundleCreateOptions{
+				CoreProvider: NoopProvider,
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the provider does not exist",
+			options: BundleCreateOptions{
+				InfrastructureProviders: []string{"not-existing"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tt.options.OutputFile = filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+			err := fakeBundleClient().BundleCreate(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(bundleFileNames(g, tt.options.OutputFile)).To(Equal(tt.wantFiles))
+		})
+	}
+}
+
+func Test_clusterctlClient_BundleImport(t *testing.T) {
+	g := NewWithT(t)
+
+	bundleFile := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	g.Expect(fakeBundleClient().BundleCreate(BundleCreateOptions{
+		InfrastructureProviders: []string{"infra"},
+	})).To(Not(Succeed()))
+	g.Expect(fakeBundleClient().BundleCreate(BundleCreateOptions{
+		InfrastructureProviders: []string{"infra"},
+		OutputFile:              bundleFile,
+	})).To(Succeed())
+
+	directory := t.TempDir()
+	result, err := fakeBundleClient().BundleImport(BundleImportOptions{
+		BundleFile:      bundleFile,
+		Directory:       directory,
+		ImageRepository: "registry.local/capi",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.ConfigFile).To(Equal(filepath.Join(directory, "clusterctl.yaml")))
+	g.Expect(result.Images).To(Equal([]BundleImage{
+		{
+			Source: "k8s.gcr.io/cluster-api-aws/cluster-api-aws-controller:v0.5.3",
+			Target: "registry.local/capi/cluster-api-aws-controller:v0.5.3",
+		},
+		{
+			Source: "quay.io/jetstack/cert-manager-controller:v1.5.3",
+			Target: "registry.local/capi/cert-manager-controller:v1.5.3",
+		},
+	}))
+
+	// Check that a clusterctl client using the generated configuration reads providers from the bundle.
+	c, err := New(result.ConfigFile)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	components, err := c.GetProviderComponents("infra", clusterctlv1.InfrastructureProviderType, ComponentsOptions{SkipTemplateProcess: true})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(components.Version()).To(Equal("v3.0.0"))
+	g.Expect(components.Images()).To(ConsistOf("registry.local/capi/cluster-api-aws-controller:v0.5.3"))
+
+	certManagerConfig, err := config.New(result.ConfigFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	certManager, err := certManagerConfig.CertManager().Get()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(certManager.Version()).To(Equal(config.CertManagerDefaultVersion))
+	g.Expect(certManager.URL()).To(Equal("file://" + filepath.ToSlash(filepath.Join(directory, "repository", "cert-manager", "v1.5.3", "cert-manager.yaml"))))
+}
+
+func Test_extractBundle(t *testing.T) {
+	g := NewWithT(t)
+
+	bundleFile := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(bundleFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	content := []byte("content")
+	g.Expect(tarWriter.WriteHeader(&tar.Header{Name: "../outside.yaml", Mode: 0600, Size: int64(len(content))})).To(Succeed())
+	_, err = tarWriter.Write(content)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tarWriter.Close()).To(Succeed())
+	g.Expect(gzipWriter.Close()).To(Succeed())
+	g.Expect(f.Close()).To(Succeed())
+
+	directory := filepath.Join(t.TempDir(), "bundle")
+	err = extractBundle(bundleFile, directory)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("outside of the bundle"))
+	g.Expect(filepath.Join(filepath.Dir(directory), "outside.yaml")).NotTo(BeAnExistingFile())
+}
+
+func bundleFileNames(g *WithT, bundleFile string) []string {
+	f, err := os.Open(bundleFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	names := []string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	return names
+}
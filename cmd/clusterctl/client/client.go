@@ -17,6 +17,8 @@ limitations under the License.
 package client
 
 import (
+	"context"
+
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/alpha"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
@@ -39,6 +41,12 @@ type Client interface {
 	// InitImages returns the list of images required for executing the init command.
 	InitImages(options InitOptions) ([]string, error)
 
+	// BundleCreate creates a bundle with everything required for running init in an air-gapped environment.
+	BundleCreate(options BundleCreateOptions) error
+
+	// BundleImport extracts a bundle and generates a clusterctl configuration for running init from the bundle content.
+	BundleImport(options BundleImportOptions) (*BundleImportResult, error)
+
 	// GetClusterTemplate returns a workload cluster template.
 	GetClusterTemplate(options GetClusterTemplateOptions) (Template, error)
 
@@ -75,6 +83,10 @@ type Client interface {
 	// DescribeCluster returns the object tree representing the status of a Cluster API cluster.
 	DescribeCluster(options DescribeClusterOptions) (*tree.ObjectTree, error)
 
+	// WatchCluster calls onChange with the object tree representing the status of a Cluster API cluster, and then
+	// every time the tree changes, until the context is done or onChange returns an error.
+	WatchCluster(ctx context.Context, options DescribeClusterOptions, onChange func(*tree.ObjectTree) error) error
+
 	// Interface for alpha features in clusterctl
 	AlphaClient
 }
@@ -89,6 +101,8 @@ type AlphaClient interface {
 	RolloutResume(options RolloutOptions) error
 	// RolloutUndo provides rollout rollback of cluster-api resources
 	RolloutUndo(options RolloutOptions) error
+	// TopologyPlan computes the changes the topology controller would apply to managed topologies
+	TopologyPlan(options TopologyPlanOptions) (*TopologyPlanOutput, error)
 }
 
 // YamlPrinter exposes methods that prints the processed template and
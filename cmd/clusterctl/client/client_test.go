@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -96,6 +97,14 @@ func (f fakeClient) InitImages(options InitOptions) ([]string, error) {
 	return f.internalClient.InitImages(options)
 }
 
+func (f fakeClient) BundleCreate(options BundleCreateOptions) error {
+	return f.internalClient.BundleCreate(options)
+}
+
+func (f fakeClient) BundleImport(options BundleImportOptions) (*BundleImportResult, error) {
+	return f.internalClient.BundleImport(options)
+}
+
 func (f fakeClient) Delete(options DeleteOptions) error {
 	return f.internalClient.Delete(options)
 }
@@ -136,6 +145,10 @@ func (f fakeClient) DescribeCluster(options DescribeClusterOptions) (*tree.Objec
 	return f.internalClient.DescribeCluster(options)
 }
 
+func (f fakeClient) WatchCluster(ctx context.Context, options DescribeClusterOptions, onChange func(*tree.ObjectTree) error) error {
+	return f.internalClient.WatchCluster(ctx, options, onChange)
+}
+
 func (f fakeClient) RolloutPause(options RolloutOptions) error {
 	return f.internalClient.RolloutPause(options)
 }
@@ -148,6 +161,10 @@ func (f fakeClient) RolloutUndo(options RolloutOptions) error {
 	return f.internalClient.RolloutUndo(options)
 }
 
+func (f fakeClient) TopologyPlan(options TopologyPlanOptions) (*TopologyPlanOutput, error) {
+	return f.internalClient.TopologyPlan(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
@@ -318,6 +335,10 @@ func (f *fakeClusterClient) WorkloadCluster() cluster.WorkloadCluster {
 	return f.internalclient.WorkloadCluster()
 }
 
+func (f *fakeClusterClient) Topology() cluster.TopologyClient {
+	return f.internalclient.Topology()
+}
+
 func (f *fakeClusterClient) WithObjs(objs ...client.Object) *fakeClusterClient {
 	f.fakeProxy.WithObjs(objs...)
 	return f
@@ -380,6 +401,10 @@ func (f fakeConfigClient) ImageMeta() config.ImageMetaClient {
 	return f.internalclient.ImageMeta()
 }
 
+func (f fakeConfigClient) Processors() config.ProcessorsClient {
+	return f.internalclient.Processors()
+}
+
 func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 	f.fakeReader.WithVar(key, value)
 	return f
@@ -486,14 +511,18 @@ type fakeTemplateClient struct {
 	processor             yaml.Processor
 }
 
-func (f *fakeTemplateClient) Get(flavor, targetNamespace string, skipTemplateProcess bool) (repository.Template, error) {
+func (f *fakeTemplateClient) Raw(flavor string) ([]byte, error) {
 	name := "cluster-template"
 	if flavor != "" {
 		name = fmt.Sprintf("%s-%s", name, flavor)
 	}
 	name = fmt.Sprintf("%s.yaml", name)
 
-	content, err := f.fakeRepository.GetFile(f.version, name)
+	return f.fakeRepository.GetFile(f.version, name)
+}
+
+func (f *fakeTemplateClient) Get(flavor, targetNamespace string, skipTemplateProcess bool) (repository.Template, error) {
+	content, err := f.Raw(flavor)
 	if err != nil {
 		return nil, err
 	}
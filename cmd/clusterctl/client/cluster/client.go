@@ -89,6 +89,9 @@ type Client interface {
 
 	// WorkloadCluster has methods for fetching kubeconfig of workload cluster from management cluster.
 	WorkloadCluster() WorkloadCluster
+
+	// Topology has methods to work with managed topologies.
+	Topology() TopologyClient
 }
 
 // PollImmediateWaiter tries a condition func until it returns true, an error, or the timeout is reached.
@@ -135,7 +138,7 @@ func (c *clusterClient) ProviderInstaller() ProviderInstaller {
 }
 
 func (c *clusterClient) ObjectMover() ObjectMover {
-	return newObjectMover(c.proxy, c.ProviderInventory())
+	return newObjectMover(c.configClient, c.kubeconfig, c.proxy, c.ProviderInventory(), c.pollImmediateWaiter)
 }
 
 func (c *clusterClient) ProviderUpgrader() ProviderUpgrader {
@@ -150,6 +153,10 @@ func (c *clusterClient) WorkloadCluster() WorkloadCluster {
 	return newWorkloadCluster(c.proxy)
 }
 
+func (c *clusterClient) Topology() TopologyClient {
+	return newTopologyClient(c.proxy)
+}
+
 // Option is a configuration option supplied to New.
 type Option func(*clusterClient)
 
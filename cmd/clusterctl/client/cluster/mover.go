@@ -18,9 +18,13 @@ package cluster
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
-	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -32,7 +36,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/controllers/external/contract"
+	"sigs.k8s.io/cluster-api/internal/backup"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,24 +48,53 @@ import (
 // ObjectMover defines methods for moving Cluster API objects to another management cluster.
 type ObjectMover interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
-	Move(namespace string, toCluster Client, dryRun bool) error
+	// If selector is not empty, only the selected Clusters with their dependents are moved; the objects used also by other Clusters,
+	// e.g. ClusterClasses or ClusterResourceSets, are copied to the target management cluster but not deleted from the source one.
+	// If checkpointFile is not empty, the progress of the move is recorded in it, so the move can be resumed or rolled back in case of failures.
+	Move(namespace string, selector *ClusterSelector, toCluster Client, dryRun bool, checkpointFile string) error
+	// ResumeMove resumes a move operation from the checkpoint recorded in checkpointFile.
+	ResumeMove(toCluster Client, checkpointFile string) error
+	// RollbackMove rolls back a move operation recorded in checkpointFile by deleting the objects already created in the
+	// target management cluster and by resuming the Clusters in the source management cluster.
+	RollbackMove(toCluster Client, checkpointFile string) error
 	// Backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
 	Backup(namespace string, directory string) error
+	// BackupArchive saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a gzip'd tar archive.
+	BackupArchive(namespace string, w io.Writer) error
 	// Restore restores all the Cluster API objects existing in a configured directory to a target management cluster.
 	Restore(toCluster Client, directory string) error
+	// RestoreArchive restores all the Cluster API objects existing in a gzip'd tar archive to a target management cluster.
+	RestoreArchive(toCluster Client, r io.Reader) error
 }
 
 // objectMover implements the ObjectMover interface.
 type objectMover struct {
+	fromKubeconfig        Kubeconfig
 	fromProxy             Proxy
 	fromProviderInventory InventoryClient
 	dryRun                bool
+
+	// pollImmediateWaiter and etcdEndpointsTimeout are used to wait for the managed external etcd clusters in the target
+	// management cluster to report their endpoints at the end of the move.
+	pollImmediateWaiter  PollImmediateWaiter
+	etcdEndpointsTimeout time.Duration
+
+	// checkpoint records the progress of the move operation, if any.
+	checkpoint *moveCheckpoint
 }
 
 // ensure objectMover implements the ObjectMover interface.
 var _ ObjectMover = &objectMover{}
 
-func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) error {
+const (
+	waitEtcdEndpointsInterval = 5 * time.Second
+
+	// defaultEtcdEndpointsTimeout defines the default time to wait for the managed external etcd clusters to report
+	// their endpoints in the target management cluster.
+	defaultEtcdEndpointsTimeout = 10 * time.Minute
+)
+
+func (o *objectMover) Move(namespace string, selector *ClusterSelector, toCluster Client, dryRun bool, checkpointFile string) error {
 	log := logf.Log
 	log.Info("Performing move...")
 	o.dryRun = dryRun
@@ -75,11 +111,23 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 		}
 	}
 
-	objectGraph, err := o.getObjectGraph(namespace)
+	objectGraph, err := o.getObjectGraph(namespace, selector)
 	if err != nil {
 		return errors.Wrap(err, "failed to get object graph")
 	}
 
+	// Records the progress of the move, if required.
+	if checkpointFile != "" && !o.dryRun {
+		source, target, err := o.getCheckpointClusters(toCluster)
+		if err != nil {
+			return err
+		}
+		o.checkpoint, err = newMoveCheckpoint(checkpointFile, namespace, selector, source, target)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Move the objects to the target cluster.
 	var proxy Proxy
 	if !o.dryRun {
@@ -89,22 +137,142 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 	return o.move(objectGraph, proxy)
 }
 
+func (o *objectMover) ResumeMove(toCluster Client, checkpointFile string) error {
+	log := logf.Log
+
+	checkpoint, err := readMoveCheckpoint(checkpointFile)
+	if err != nil {
+		return err
+	}
+	if err := o.checkCheckpointClusters(checkpoint, toCluster); err != nil {
+		return err
+	}
+	o.checkpoint = checkpoint
+	log.Info("Resuming move...", "Phase", checkpoint.Phase)
+
+	// checks that all the required providers in place in the target cluster.
+	if err := o.checkTargetProviders(toCluster.ProviderInventory()); err != nil {
+		return errors.Wrap(err, "failed to check providers in target cluster")
+	}
+
+	// If objects are already being deleted from the source cluster, the object graph is rebuilt from the checkpoint;
+	// otherwise the object graph is discovered again and the objects already created in the target cluster are restored.
+	var objectGraph *objectGraph
+	if checkpoint.reached(moveCheckpointDeleting) {
+		objectGraph = checkpoint.getObjectGraph(o.fromProxy, o.fromProviderInventory)
+	} else {
+		objectGraph, err = o.getObjectGraph(checkpoint.Namespace, checkpoint.Selector)
+		if err != nil {
+			return errors.Wrap(err, "failed to get object graph")
+		}
+		checkpoint.restore(objectGraph)
+	}
+
+	return o.move(objectGraph, toCluster.Proxy())
+}
+
+func (o *objectMover) RollbackMove(toCluster Client, checkpointFile string) error {
+	log := logf.Log
+	log.Info("Performing rollback of move...")
+
+	checkpoint, err := readMoveCheckpoint(checkpointFile)
+	if err != nil {
+		return err
+	}
+	if checkpoint.reached(moveCheckpointDeleting) {
+		return errors.Errorf("cannot rollback the move because objects are already being deleted from the source cluster (phase %s); please resume the move instead", checkpoint.Phase)
+	}
+	if err := o.checkCheckpointClusters(checkpoint, toCluster); err != nil {
+		return err
+	}
+	o.checkpoint = checkpoint
+
+	return o.rollback(checkpoint.getObjectGraph(o.fromProxy, o.fromProviderInventory), toCluster.Proxy())
+}
+
+// getCheckpointClusters returns the identity of the source and of the target management clusters of a move.
+func (o *objectMover) getCheckpointClusters(toCluster Client) (moveCheckpointCluster, moveCheckpointCluster, error) {
+	source, err := newMoveCheckpointCluster(o.fromKubeconfig, o.fromProxy)
+	if err != nil {
+		return moveCheckpointCluster{}, moveCheckpointCluster{}, err
+	}
+	target, err := newMoveCheckpointCluster(toCluster.Kubeconfig(), toCluster.Proxy())
+	if err != nil {
+		return moveCheckpointCluster{}, moveCheckpointCluster{}, err
+	}
+	return source, target, nil
+}
+
+// checkCheckpointClusters checks a checkpoint was recorded for a move between the same source and target management clusters.
+func (o *objectMover) checkCheckpointClusters(checkpoint *moveCheckpoint, toCluster Client) error {
+	source, target, err := o.getCheckpointClusters(toCluster)
+	if err != nil {
+		return err
+	}
+	return checkpoint.checkClusters(source, target)
+}
+
 func (o *objectMover) Backup(namespace string, directory string) error {
 	log := logf.Log
 	log.Info("Performing backup...")
 
-	objectGraph, err := o.getObjectGraph(namespace)
+	objectGraph, err := o.getObjectGraph(namespace, nil)
 	if err != nil {
 		return errors.Wrap(err, "failed to get object graph")
 	}
 
-	return o.backup(objectGraph, directory)
+	log.Info(fmt.Sprintf("Saving files to %s", directory))
+	return o.backup(objectGraph, backup.NewLocalDestination(directory))
+}
+
+func (o *objectMover) BackupArchive(namespace string, w io.Writer) error {
+	log := logf.Log
+	log.Info("Performing backup...")
+
+	objectGraph, err := o.getObjectGraph(namespace, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get object graph")
+	}
+
+	log.Info("Saving objects to the backup archive")
+	archive := backup.NewArchiveWriter(w)
+	if err := o.backup(objectGraph, archive); err != nil {
+		return err
+	}
+	return archive.Close()
 }
 
 func (o *objectMover) Restore(toCluster Client, directory string) error {
 	log := logf.Log
 	log.Info("Performing restore...")
 
+	objs, err := o.filesToObjs(directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to process object files")
+	}
+
+	return o.restoreObjs(objs, toCluster)
+}
+
+func (o *objectMover) RestoreArchive(toCluster Client, r io.Reader) error {
+	log := logf.Log
+	log.Info("Performing restore...")
+
+	files, err := backup.ReadArchive(r)
+	if err != nil {
+		return err
+	}
+
+	objs, err := yaml.ToUnstructured(yaml.JoinYaml(files...))
+	if err != nil {
+		return errors.Wrap(err, "failed to process the objects in the backup archive")
+	}
+
+	return o.restoreObjs(objs, toCluster)
+}
+
+// restoreObjs rebuilds the object graph from the objects saved by a backup and restores them to the target cluster.
+func (o *objectMover) restoreObjs(objs []unstructured.Unstructured, toCluster Client) error {
 	// Build an empty object graph used for the restore sequence not tied to a specific namespace
 	objectGraph := newObjectGraph(o.fromProxy, o.fromProviderInventory)
 
@@ -114,11 +282,6 @@ func (o *objectMover) Restore(toCluster Client, directory string) error {
 		return errors.Wrap(err, "failed to retrieve discovery types")
 	}
 
-	objs, err := o.filesToObjs(directory)
-	if err != nil {
-		return errors.Wrap(err, "failed to process object files")
-	}
-
 	for i := range objs {
 		if err = objectGraph.addRestoredObj(&objs[i]); err != nil {
 			return err
@@ -129,6 +292,12 @@ func (o *objectMover) Restore(toCluster Client, directory string) error {
 	// by a naming convention (without any explicit OwnerReference).
 	objectGraph.setSoftOwnership()
 
+	// Completes rebuilding the graph from file by grouping the objects of the managed external etcd of each Cluster.
+	objectGraph.setManagedEtcdGroups()
+
+	// Completes rebuilding the graph from file by linking the ClusterClasses with their templates and with the Clusters using them.
+	objectGraph.setClusterClassSoftOwnership()
+
 	// Completes the graph by setting for each node the list of tenants the node belongs to.
 	objectGraph.setTenants()
 
@@ -172,7 +341,7 @@ func (o *objectMover) filesToObjs(dir string) ([]unstructured.Unstructured, erro
 	return objs, nil
 }
 
-func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
+func (o *objectMover) getObjectGraph(namespace string, selector *ClusterSelector) (*objectGraph, error) {
 	objectGraph := newObjectGraph(o.fromProxy, o.fromProviderInventory)
 
 	// Gets all the types defined by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
@@ -188,6 +357,11 @@ func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
 		return nil, errors.Wrap(err, "failed to discover the object graph")
 	}
 
+	// Restricts the object graph to the selected Clusters, if any.
+	if err := objectGraph.setClusterSelection(selector); err != nil {
+		return nil, errors.Wrap(err, "failed to select the Clusters to move")
+	}
+
 	// Checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move/backup operation.
 	// This is required because if the infrastructure is provisioned, then we can reasonably assume that the objects we are moving/backing up are
 	// not currently waiting for long-running reconciliation loops, and so we can safely rely on the pause field on the Cluster object
@@ -202,11 +376,31 @@ func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
 	return objectGraph, nil
 }
 
-func newObjectMover(fromProxy Proxy, fromProviderInventory InventoryClient) *objectMover {
+func newObjectMover(configClient config.Client, fromKubeconfig Kubeconfig, fromProxy Proxy, fromProviderInventory InventoryClient, pollImmediateWaiter PollImmediateWaiter) *objectMover {
 	return &objectMover{
+		fromKubeconfig:        fromKubeconfig,
 		fromProxy:             fromProxy,
 		fromProviderInventory: fromProviderInventory,
+		pollImmediateWaiter:   pollImmediateWaiter,
+		etcdEndpointsTimeout:  getEtcdEndpointsTimeout(configClient),
+	}
+}
+
+// getEtcdEndpointsTimeout returns the timeout for the managed external etcd clusters to report their endpoints
+// in the target management cluster, as defined by the etcd-endpoints-timeout variable.
+func getEtcdEndpointsTimeout(configClient config.Client) time.Duration {
+	log := logf.Log
+
+	timeout, err := configClient.Variables().Get(config.EtcdEndpointsTimeoutVariable)
+	if err != nil || timeout == "" {
+		return defaultEtcdEndpointsTimeout
+	}
+	timeoutDuration, err := time.ParseDuration(timeout)
+	if err != nil {
+		log.Info("Invalid value set for the etcd endpoints timeout", config.EtcdEndpointsTimeoutVariable, timeout)
+		return defaultEtcdEndpointsTimeout
 	}
+	return timeoutDuration
 }
 
 // checkProvisioningCompleted checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move operation.
@@ -310,69 +504,133 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 	clusters := graph.getClusters()
 	log.Info("Moving Cluster API objects", "Clusters", len(clusters))
 
-	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
-	log.V(1).Info("Pausing the source cluster")
-	if err := setClusterPause(o.fromProxy, clusters, true, o.dryRun); err != nil {
-		return err
-	}
-
-	// Ensure all the expected target namespaces are in place before creating objects.
-	log.V(1).Info("Creating target namespaces, if missing")
-	if err := o.ensureNamespaces(graph, toProxy); err != nil {
-		return err
-	}
-
 	// Define the move sequence by processing the ownerReference chain, so we ensure that a Kubernetes object is moved only after its owners.
 	// The sequence is bases on object graph nodes, each one representing a Kubernetes object; nodes are grouped, so bulk of nodes can be moved in parallel. e.g.
 	// - All the Clusters should be moved first (group 1, processed in parallel)
 	// - All the MachineDeployments should be moved second (group 1, processed in parallel)
 	// - then all the MachineSets, then all the Machines, etc.
-	moveSequence := getMoveSequence(graph)
+	// NB. The control plane of a Cluster is moved after the objects of the managed external etcd of the Cluster, if any.
+	// NB. When resuming a move after objects are already being deleted from the source cluster, the sequence is rebuilt from the checkpoint.
+	var moveSequence *moveSequence
+	if o.checkpoint.reached(moveCheckpointDeleting) {
+		moveSequence = o.checkpoint.getMoveSequence(graph)
+	} else {
+		var err error
+		if moveSequence, err = getMoveSequence(graph); err != nil {
+			return err
+		}
+	}
 
-	// Create all objects group by group, ensuring all the ownerReferences are re-created.
-	log.Info("Creating objects in the target cluster")
-	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
-		if err := o.createGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+	if !o.checkpoint.reached(moveCheckpointDeleting) {
+		// Records the Clusters being moved before pausing them, so it is possible to resume them in case of rollback.
+		if err := o.checkpoint.setClusters(clusters); err != nil {
+			return err
+		}
+
+		// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
+		if !o.checkpoint.isSourcePaused() {
+			log.V(1).Info("Pausing the source cluster")
+			if err := setClusterPause(o.fromProxy, clusters, true, o.dryRun); err != nil {
+				return err
+			}
+			if err := o.checkpoint.setSourcePaused(); err != nil {
+				return err
+			}
+		}
+
+		// Ensure all the expected target namespaces are in place before creating objects.
+		log.V(1).Info("Creating target namespaces, if missing")
+		if err := o.ensureNamespaces(graph, toProxy); err != nil {
+			return err
+		}
+
+		// Create all objects group by group, ensuring all the ownerReferences are re-created.
+		log.Info("Creating objects in the target cluster")
+		if err := o.checkpoint.setPhase(moveCheckpointCreating); err != nil {
 			return err
 		}
+		for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+			if err := o.createGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+				return err
+			}
+		}
 	}
 
-	// Delete all objects group by group in reverse order.
-	log.Info("Deleting objects from the source cluster")
-	for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
-		if err := o.deleteGroup(moveSequence.getGroup(groupIndex)); err != nil {
+	if !o.checkpoint.reached(moveCheckpointResuming) {
+		// Delete all objects group by group in reverse order.
+		log.Info("Deleting objects from the source cluster")
+		if err := o.checkpoint.setPhase(moveCheckpointDeleting); err != nil {
 			return err
 		}
+		for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
+			if err := o.deleteGroup(moveSequence.getGroup(groupIndex)); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Reset the pause field on the Cluster object in the target management cluster, so the controllers start reconciling it.
 	log.V(1).Info("Resuming the target cluster")
-	return setClusterPause(toProxy, clusters, false, o.dryRun)
+	if err := o.checkpoint.setPhase(moveCheckpointResuming); err != nil {
+		return err
+	}
+	if err := setClusterPause(toProxy, clusters, false, o.dryRun); err != nil {
+		return err
+	}
+
+	// The move is completed, so the checkpoint is not required anymore.
+	if err := o.checkpoint.complete(); err != nil {
+		return err
+	}
+
+	// Check the managed external etcd clusters are still using the same endpoints, so the control planes are not
+	// disconnected from the etcd members.
+	// NOTE: The move is already completed at this stage, so a failure is reported as a warning.
+	log.V(1).Info("Checking managed external etcd endpoints")
+	if err := o.checkManagedEtcdEndpoints(graph, toProxy); err != nil {
+		log.Info("Warning: failed to check the managed external etcd endpoints, please verify them manually", "error", err.Error())
+	}
+	return nil
 }
 
-func (o *objectMover) backup(graph *objectGraph, directory string) error {
+// objectWriter stores the objects saved by a backup, e.g. as files in a directory or in an archive.
+type objectWriter interface {
+	Write(name string, data []byte) error
+}
+
+func (o *objectMover) backup(graph *objectGraph, w objectWriter) error {
 	log := logf.Log
 
-	clusters := graph.getClusters()
-	log.Info("Starting backup of Cluster API objects", "Clusters", len(clusters))
-
-	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
-	log.V(1).Info("Pausing the source cluster")
-	if err := setClusterPause(o.fromProxy, clusters, true, o.dryRun); err != nil {
-		return err
-	}
+	log.Info("Starting backup of Cluster API objects", "Clusters", len(graph.getClusters()))
 
 	// Define the move sequence by processing the ownerReference chain, so we ensure that a Kubernetes object is moved only after its owners.
 	// The sequence is bases on object graph nodes, each one representing a Kubernetes object; nodes are grouped, so bulk of nodes can be moved in parallel. e.g.
 	// - All the Clusters should be moved first (group 1, processed in parallel)
 	// - All the MachineDeployments should be moved second (group 1, processed in parallel)
 	// - then all the MachineSets, then all the Machines, etc.
-	moveSequence := getMoveSequence(graph)
+	moveSequence, err := getMoveSequence(graph)
+	if err != nil {
+		return err
+	}
 
-	// Save all objects group by group
-	log.Info(fmt.Sprintf("Saving files to %s", directory))
+	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
+	// NOTE: Clusters already paused are skipped, so they are not resumed at the end of the backup.
+	log.V(1).Info("Pausing the source cluster")
+	clusters, err := getUnpausedClusters(o.fromProxy, graph.getClusters())
+	if err != nil {
+		return err
+	}
+	if err := setClusterPause(o.fromProxy, clusters, true, o.dryRun); err != nil {
+		return err
+	}
+
+	// Save all objects group by group; in case of errors, the source cluster is resumed anyway, so a failed
+	// backup doesn't leave the Clusters paused.
 	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
-		if err := o.backupGroup(moveSequence.getGroup(groupIndex), directory); err != nil {
+		if err := o.backupGroup(moveSequence.getGroup(groupIndex), w); err != nil {
+			if resumeErr := setClusterPause(o.fromProxy, clusters, false, o.dryRun); resumeErr != nil {
+				return kerrors.NewAggregate([]error{err, resumeErr})
+			}
 			return err
 		}
 	}
@@ -399,7 +657,10 @@ func (o *objectMover) restore(graph *objectGraph, toProxy Proxy) error {
 	// - All the Clusters should be moved first (group 1, processed in parallel)
 	// - All the MachineDeployments should be moved second (group 1, processed in parallel)
 	// - then all the MachineSets, then all the Machines, etc.
-	moveSequence := getMoveSequence(graph)
+	moveSequence, err := getMoveSequence(graph)
+	if err != nil {
+		return err
+	}
 
 	// Create all objects group by group, ensuring all the ownerReferences are re-created.
 	log.Info("Restoring objects into the target cluster")
@@ -412,7 +673,18 @@ func (o *objectMover) restore(graph *objectGraph, toProxy Proxy) error {
 	// Resume reconciling the Clusters after being restored from a backup.
 	// By default, during backup, Clusters are paused so they must be unpaused to be used again
 	log.V(1).Info("Resuming the target cluster")
-	return setClusterPause(toProxy, clusters, false, o.dryRun)
+	if err := setClusterPause(toProxy, clusters, false, o.dryRun); err != nil {
+		return err
+	}
+
+	// Check the managed external etcd clusters are still using the same endpoints, so the control planes are not
+	// disconnected from the etcd members.
+	// NOTE: The restore is already completed at this stage, so a failure is reported as a warning.
+	log.V(1).Info("Checking managed external etcd endpoints")
+	if err := o.checkManagedEtcdEndpoints(graph, toProxy); err != nil {
+		log.Info("Warning: failed to check the managed external etcd endpoints, please verify them manually", "error", err.Error())
+	}
+	return nil
 }
 
 // moveSequence defines a list of group of moveGroups.
@@ -443,7 +715,9 @@ func (s *moveSequence) getGroup(i int) moveGroup {
 }
 
 // Define the move sequence by processing the ownerReference chain.
-func getMoveSequence(graph *objectGraph) *moveSequence {
+// An error is returned if some nodes can't be added to the move sequence, e.g. because of a loop in the ownerReference
+// chain or in the move order constraints.
+func getMoveSequence(graph *objectGraph) (*moveSequence, error) {
 	moveSequence := &moveSequence{
 		groups:   []moveGroup{},
 		nodesMap: make(map[*node]empty),
@@ -476,18 +750,37 @@ func getMoveSequence(graph *objectGraph) *moveSequence {
 					break
 				}
 			}
+			for other := range n.moveAfter {
+				if !moveSequence.hasNode(other) {
+					ownersInPlace = false
+					break
+				}
+			}
 			if ownersInPlace {
 				moveGroup = append(moveGroup, n)
 			}
 		}
 
-		// If the resulting move group is empty it means that all the nodes are already in the sequence, so exit.
+		// If the resulting move group is empty it means that all the nodes that can be moved are already in the sequence, so exit.
 		if len(moveGroup) == 0 {
 			break
 		}
 		moveSequence.addGroup(moveGroup)
 	}
-	return moveSequence
+
+	// Ensure all the nodes have been added to the move sequence; otherwise, moving the nodes in the sequence
+	// would silently leave behind the remaining nodes, e.g. the control plane of a Cluster.
+	var notPlaced []string
+	for _, n := range graph.getMoveNodes() {
+		if !moveSequence.hasNode(n) {
+			notPlaced = append(notPlaced, fmt.Sprintf("%s %s/%s", n.identity.Kind, n.identity.Namespace, n.identity.Name))
+		}
+	}
+	if len(notPlaced) > 0 {
+		sort.Strings(notPlaced)
+		return nil, errors.Errorf("failed to define the move sequence: the following objects depend on objects that can't be moved before them: %s", strings.Join(notPlaced, ", "))
+	}
+	return moveSequence, nil
 }
 
 // setClusterPause sets the paused field on nodes referring to Cluster objects.
@@ -514,6 +807,30 @@ func setClusterPause(proxy Proxy, clusters []*node, value bool, dryRun bool) err
 	return nil
 }
 
+// getUnpausedClusters returns the nodes referring to Cluster objects which are not paused.
+func getUnpausedClusters(proxy Proxy, clusters []*node) ([]*node, error) {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	unpaused := []*node{}
+	for _, cluster := range clusters {
+		clusterObj := &clusterv1.Cluster{}
+		clusterObjKey := client.ObjectKey{
+			Namespace: cluster.identity.Namespace,
+			Name:      cluster.identity.Name,
+		}
+		if err := c.Get(ctx, clusterObjKey, clusterObj); err != nil {
+			return nil, errors.Wrapf(err, "error reading Cluster %s/%s", clusterObjKey.Namespace, clusterObjKey.Name)
+		}
+		if !clusterObj.Spec.Paused {
+			unpaused = append(unpaused, cluster)
+		}
+	}
+	return unpaused, nil
+}
+
 // patchCluster applies a patch to a node referring to a Cluster object.
 func patchCluster(proxy Proxy, cluster *node, patch client.Patch) error {
 	cFrom, err := proxy.NewClient()
@@ -641,6 +958,25 @@ func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
 	errList := []error{}
 
 	for _, nodeToCreate := range group {
+		// If the object was already created in a previous attempt of the move operation, skip it.
+		if o.checkpoint.hasObject(nodeToCreate) {
+			continue
+		}
+
+		// Checks if the object already exists in the target cluster before the first attempt to create it, so objects created
+		// by the move are never considered as existing, even when the creation is retried, e.g. after a timeout, or resumed.
+		if err := o.checkExistingInTarget(nodeToCreate, toProxy); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
+		// Records the object before creating it, so it is possible to rollback the move even if it fails before
+		// recording the object created in the target cluster.
+		if err := o.checkpoint.addPendingObject(nodeToCreate); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
 		// Creates the Kubernetes object corresponding to the nodeToCreate.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
 		err := retryWithExponentialBackoff(createTargetObjectBackoff, func() error {
@@ -648,6 +984,12 @@ func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
 		})
 		if err != nil {
 			errList = append(errList, err)
+			continue
+		}
+
+		// Records the object created in the target cluster, so it is possible to resume or rollback the move.
+		if err := o.checkpoint.addObject(nodeToCreate); err != nil {
+			errList = append(errList, err)
 		}
 	}
 
@@ -658,7 +1000,39 @@ func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
 	return nil
 }
 
-func (o *objectMover) backupGroup(group moveGroup, directory string) error {
+// checkExistingInTarget sets existingInTarget for a node according to the object existing in the target management cluster.
+// If the object is already recorded in the checkpoint, it was recorded before the first attempt to create it, so the
+// recorded value is used; this prevents objects created by a failed attempt of the move from being considered as existing.
+func (o *objectMover) checkExistingInTarget(n *node, toProxy Proxy) error {
+	if o.dryRun {
+		return nil
+	}
+
+	if obj := o.checkpoint.getObject(n); obj != nil {
+		n.existingInTarget = obj.Existing
+		return nil
+	}
+
+	cTo, err := toProxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(n.identity.APIVersion)
+	obj.SetKind(n.identity.Kind)
+	if err := cTo.Get(ctx, client.ObjectKey{Namespace: n.identity.Namespace, Name: n.identity.Name}, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "error reading %q %s/%s", obj.GroupVersionKind(), n.identity.Namespace, n.identity.Name)
+		}
+		n.existingInTarget = false
+		return nil
+	}
+	n.existingInTarget = true
+	return nil
+}
+
+func (o *objectMover) backupGroup(group moveGroup, w objectWriter) error {
 	backupTargetObjectBackoff := newWriteBackoff()
 	errList := []error{}
 
@@ -666,7 +1040,7 @@ func (o *objectMover) backupGroup(group moveGroup, directory string) error {
 		// Backs-up the Kubernetes object corresponding to the nodeToBackup.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
 		err := retryWithExponentialBackoff(backupTargetObjectBackoff, func() error {
-			return o.backupTargetObject(nodeToBackup, directory)
+			return o.backupTargetObject(nodeToBackup, w)
 		})
 		if err != nil {
 			errList = append(errList, err)
@@ -730,6 +1104,11 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
+	// Stores the endpoints of a managed external etcd cluster, so it is possible to check they are not changed after the move.
+	if nodeToCreate.isManagedEtcdCluster {
+		nodeToCreate.etcdEndpoints = getManagedEtcdEndpoints(obj)
+	}
+
 	// New objects cannot have a specified resource version. Clear it out.
 	obj.SetResourceVersion("")
 
@@ -788,7 +1167,7 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	return nil
 }
 
-func (o *objectMover) backupTargetObject(nodeToCreate *node, directory string) error {
+func (o *objectMover) backupTargetObject(nodeToCreate *node, w objectWriter) error {
 	log := logf.Log
 	log.V(1).Info("Saving", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
 
@@ -811,28 +1190,13 @@ func (o *objectMover) backupTargetObject(nodeToCreate *node, directory string) e
 			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
-	// Get JSON for object and write it into the configured directory
+	// Get JSON for object and write it into the configured destination, overriding existing files if any.
 	byObj, err := obj.MarshalJSON()
 	if err != nil {
 		return err
 	}
 
-	filenameObj := nodeToCreate.getFilename()
-	objectFile := filepath.Join(directory, filenameObj)
-
-	// If file exists, then remove it to be written again
-	if _, err = os.Stat(objectFile); err == nil {
-		if err = os.Remove(objectFile); err != nil {
-			return err
-		}
-	}
-
-	err = ioutil.WriteFile(objectFile, byObj, 0600)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return w.Write(nodeToCreate.getFilename(), byObj)
 }
 
 func (o *objectMover) restoreTargetObject(nodeToCreate *node, toProxy Proxy) error {
@@ -867,6 +1231,11 @@ func (o *objectMover) restoreTargetObject(nodeToCreate *node, toProxy Proxy) err
 	// Rebuild the source object
 	obj := nodeToCreate.restoreObject
 
+	// Stores the endpoints of a managed external etcd cluster, so it is possible to check they are not changed after the restore.
+	if nodeToCreate.isManagedEtcdCluster {
+		nodeToCreate.etcdEndpoints = getManagedEtcdEndpoints(obj)
+	}
+
 	obj.SetAPIVersion(nodeToCreate.identity.APIVersion)
 	obj.SetKind(nodeToCreate.identity.Kind)
 
@@ -892,6 +1261,83 @@ func (o *objectMover) restoreTargetObject(nodeToCreate *node, toProxy Proxy) err
 	return nil
 }
 
+// getManagedEtcdEndpoints returns the endpoints reported by a managed external etcd cluster object, if any.
+func getManagedEtcdEndpoints(obj *unstructured.Unstructured) []string {
+	endpoints, err := contract.EtcdCluster().Endpoints().Get(obj)
+	if err != nil {
+		return nil
+	}
+	return endpoints
+}
+
+// checkManagedEtcdEndpoints checks that the managed external etcd cluster objects in the target management cluster report
+// the same endpoints reported before the move operation.
+// NOTE: The status of the objects is not moved, so it is required to wait for the etcd provider in the target management cluster
+// to report the endpoints again; the check fails if this does not happen within the configured timeout.
+func (o *objectMover) checkManagedEtcdEndpoints(graph *objectGraph, toProxy Proxy) error {
+	log := logf.Log
+
+	if o.dryRun {
+		return nil
+	}
+
+	errList := []error{}
+	for _, etcdCluster := range graph.getManagedEtcdClusters() {
+		if len(etcdCluster.etcdEndpoints) == 0 {
+			continue
+		}
+
+		var endpoints []string
+		if err := o.pollImmediateWaiter(waitEtcdEndpointsInterval, o.etcdEndpointsTimeout, func() (bool, error) {
+			var err error
+			endpoints, err = getTargetManagedEtcdEndpoints(toProxy, etcdCluster)
+			if err != nil {
+				log.V(5).Info("Waiting for the managed external etcd endpoints", "error", err.Error())
+				return false, nil
+			}
+			return len(endpoints) > 0, nil
+		}); err != nil {
+			errList = append(errList, errors.Wrapf(err, "%q %s/%s did not report its endpoints within %s",
+				etcdCluster.identity.GroupVersionKind(), etcdCluster.identity.Namespace, etcdCluster.identity.Name, o.etcdEndpointsTimeout))
+			continue
+		}
+
+		if !reflect.DeepEqual(endpoints, etcdCluster.etcdEndpoints) {
+			errList = append(errList, errors.Errorf("the endpoints of %q %s/%s changed from %q to %q",
+				etcdCluster.identity.GroupVersionKind(), etcdCluster.identity.Namespace, etcdCluster.identity.Name,
+				strings.Join(etcdCluster.etcdEndpoints, ","), strings.Join(endpoints, ",")))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// getTargetManagedEtcdEndpoints returns the endpoints reported by a managed external etcd cluster object in the target management cluster.
+func getTargetManagedEtcdEndpoints(toProxy Proxy, etcdCluster *node) ([]string, error) {
+	cTo, err := toProxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(etcdCluster.identity.APIVersion)
+	obj.SetKind(etcdCluster.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: etcdCluster.identity.Namespace,
+		Name:      etcdCluster.identity.Name,
+	}
+	if err := cTo.Get(ctx, objKey, obj); err != nil {
+		return nil, errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	endpoints, err := contract.EtcdCluster().Endpoints().Get(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the endpoints of %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return endpoints, nil
+}
+
 // Recreate all the OwnerReferences using the newUID of the owner nodes.
 func (o *objectMover) buildOwnerChain(obj *unstructured.Unstructured, n *node) {
 	if len(n.owners) > 0 {
@@ -949,6 +1395,13 @@ func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
 		return nil
 	}
 
+	// Don't delete nodes shared with Clusters not being moved (e.g. a ClusterClass used also by other Clusters).
+	if nodeToDelete.isShared {
+		log := logf.Log
+		log.V(1).Info("Keeping shared object in the source cluster", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+		return nil
+	}
+
 	log := logf.Log
 	log.V(1).Info("Deleting", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
 
@@ -995,6 +1448,111 @@ func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
 	return nil
 }
 
+// rollback deletes the objects already created in the target management cluster by a move operation, in reverse creation order,
+// and then resumes the Clusters in the source management cluster.
+func (o *objectMover) rollback(graph *objectGraph, toProxy Proxy) error {
+	log := logf.Log
+
+	moveSequence := o.checkpoint.getMoveSequence(graph)
+
+	// Delete all objects group by group in reverse order.
+	log.Info("Deleting objects from the target cluster")
+	for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
+		if err := o.rollbackGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+			return err
+		}
+	}
+
+	// Reset the pause field on the Cluster object in the source management cluster, so the controllers start reconciling it again.
+	log.V(1).Info("Resuming the source cluster")
+	if err := setClusterPause(o.fromProxy, o.checkpoint.getClusters(), false, o.dryRun); err != nil {
+		return err
+	}
+
+	// The rollback is completed, so the checkpoint is not required anymore.
+	return o.checkpoint.complete()
+}
+
+// rollbackGroup deletes all the Kubernetes objects from the target management cluster corresponding to the object graph nodes in a moveGroup.
+func (o *objectMover) rollbackGroup(group moveGroup, toProxy Proxy) error {
+	deleteTargetObjectBackoff := newWriteBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToDelete := group[i]
+
+		// Delete the Kubernetes object corresponding to the current node.
+		// Nb. The operation is wrapped in a retry loop to make rollback more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(deleteTargetObjectBackoff, func() error {
+			return o.deleteTargetObject(nodeToDelete, toProxy)
+		})
+
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// deleteTargetObject deletes the Kubernetes object corresponding to the node from the target management cluster, taking care of removing all the finalizers so
+// the objects gets immediately deleted (force delete).
+func (o *objectMover) deleteTargetObject(nodeToDelete *node, toProxy Proxy) error {
+	log := logf.Log
+
+	// Don't delete cluster-wide nodes, nodes that are below a hierarchy that starts with a global object or nodes already existing in the target cluster before the move.
+	if nodeToDelete.isGlobal || nodeToDelete.isGlobalHierarchy || nodeToDelete.existingInTarget {
+		log.V(5).Info("Object not created by move, skipping delete for", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+		return nil
+	}
+
+	log.V(1).Info("Deleting", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+
+	cTo, err := toProxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	// Get the target object
+	targetObj := &unstructured.Unstructured{}
+	targetObj.SetAPIVersion(nodeToDelete.identity.APIVersion)
+	targetObj.SetKind(nodeToDelete.identity.Kind)
+	targetObjKey := client.ObjectKey{
+		Namespace: nodeToDelete.identity.Namespace,
+		Name:      nodeToDelete.identity.Name,
+	}
+
+	if err := cTo.Get(ctx, targetObjKey, targetObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			// If the object is already deleted, move on.
+			log.V(5).Info("Object already deleted, skipping delete for", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+			return nil
+		}
+		return errors.Wrapf(err, "error reading %q %s/%s",
+			targetObj.GroupVersionKind(), targetObj.GetNamespace(), targetObj.GetName())
+	}
+
+	// If the object was re-created after the move, e.g. by someone else, move on.
+	// NB. If the move failed before recording the object created in the target cluster, the object did not exist before the move, so it is deleted.
+	if nodeToDelete.newUID != "" && targetObj.GetUID() != nodeToDelete.newUID {
+		log.V(5).Info("Object not created by move, skipping delete for", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+		return nil
+	}
+
+	if len(targetObj.GetFinalizers()) > 0 {
+		if err := cTo.Patch(ctx, targetObj, removeFinalizersPatch); err != nil {
+			return errors.Wrapf(err, "error removing finalizers from %q %s/%s",
+				targetObj.GroupVersionKind(), targetObj.GetNamespace(), targetObj.GetName())
+		}
+	}
+
+	if err := cTo.Delete(ctx, targetObj); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "error deleting %q %s/%s",
+			targetObj.GroupVersionKind(), targetObj.GetNamespace(), targetObj.GetName())
+	}
+
+	return nil
+}
+
 // checkTargetProviders checks that all the providers installed in the source cluster exists in the target cluster as well (with a version >= of the current version).
 func (o *objectMover) checkTargetProviders(toInventory InventoryClient) error {
 	if o.dryRun {
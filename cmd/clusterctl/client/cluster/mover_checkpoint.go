@@ -0,0 +1,405 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// moveCheckpointPhase defines the phases of a move operation recorded in a checkpoint.
+type moveCheckpointPhase string
+
+const (
+	// moveCheckpointPausing is the phase where the Clusters in the source management cluster are paused.
+	moveCheckpointPausing moveCheckpointPhase = "Pausing"
+
+	// moveCheckpointCreating is the phase where the objects are created in the target management cluster.
+	moveCheckpointCreating moveCheckpointPhase = "Creating"
+
+	// moveCheckpointDeleting is the phase where the objects are deleted from the source management cluster.
+	moveCheckpointDeleting moveCheckpointPhase = "Deleting"
+
+	// moveCheckpointResuming is the phase where the Clusters in the target management cluster are resumed.
+	moveCheckpointResuming moveCheckpointPhase = "Resuming"
+)
+
+// moveCheckpointPhases defines the order of the move phases.
+var moveCheckpointPhases = map[moveCheckpointPhase]int{
+	moveCheckpointPausing:  0,
+	moveCheckpointCreating: 1,
+	moveCheckpointDeleting: 2,
+	moveCheckpointResuming: 3,
+}
+
+// moveCheckpoint records the progress of a move operation into a local file, so it is possible to resume
+// or to rollback the move if it fails halfway through.
+// NOTE: All the methods are no-op on a nil checkpoint, so the move can be executed without recording a checkpoint.
+type moveCheckpoint struct {
+	// Namespace where the moved objects exist.
+	Namespace string `json:"namespace"`
+
+	// Selector defines the Clusters being moved, if not all the Clusters in the namespace are moved.
+	Selector *ClusterSelector `json:"selector,omitempty"`
+
+	// Source is the management cluster the objects are moved from.
+	Source moveCheckpointCluster `json:"source"`
+
+	// Target is the management cluster the objects are moved to.
+	Target moveCheckpointCluster `json:"target"`
+
+	// Phase of the move operation.
+	Phase moveCheckpointPhase `json:"phase"`
+
+	// SourcePaused is set to true once all the Clusters in the source management cluster are paused.
+	SourcePaused bool `json:"sourcePaused"`
+
+	// Clusters being moved.
+	Clusters []corev1.ObjectReference `json:"clusters,omitempty"`
+
+	// Objects already created in the target management cluster, in creation order.
+	Objects []moveCheckpointObject `json:"objects,omitempty"`
+
+	// path of the checkpoint file.
+	path string
+}
+
+// moveCheckpointCluster identifies a management cluster involved in a move operation, so a checkpoint
+// is never used for resuming or rolling back a move between different management clusters.
+type moveCheckpointCluster struct {
+	// Kubeconfig is the path of the kubeconfig file used for accessing the management cluster, if not the default one.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// Context is the kubeconfig context used for accessing the management cluster, if not the current one.
+	Context string `json:"context,omitempty"`
+
+	// Server is the URL of the API server of the management cluster.
+	Server string `json:"server,omitempty"`
+}
+
+// newMoveCheckpointCluster returns the identity of the management cluster accessed using the given kubeconfig and proxy.
+func newMoveCheckpointCluster(kubeconfig Kubeconfig, proxy Proxy) (moveCheckpointCluster, error) {
+	c := moveCheckpointCluster{
+		Kubeconfig: kubeconfig.Path,
+		Context:    kubeconfig.Context,
+	}
+	config, err := proxy.GetConfig()
+	if err != nil {
+		return c, errors.Wrap(err, "failed to get the API server of the management cluster")
+	}
+	if config != nil {
+		c.Server = config.Host
+	}
+	return c, nil
+}
+
+func (c moveCheckpointCluster) String() string {
+	return fmt.Sprintf("%q (kubeconfig %q, context %q)", c.Server, c.Kubeconfig, c.Context)
+}
+
+// moveCheckpointObject records an object created in the target management cluster.
+type moveCheckpointObject struct {
+	// Identity of the object in the source management cluster, including the source UID.
+	Identity corev1.ObjectReference `json:"identity"`
+
+	// TargetUID is the UID of the object in the target management cluster.
+	TargetUID types.UID `json:"targetUID,omitempty"`
+
+	// Pending is set to true if the object is recorded before creating it, and the creation is not completed yet.
+	Pending bool `json:"pending,omitempty"`
+
+	// Global is set to true if the object is a global object or it belongs to a global object hierarchy;
+	// those objects are never deleted from the source management cluster.
+	Global bool `json:"global,omitempty"`
+
+	// Shared is set to true if the object is shared with Clusters not being moved;
+	// those objects are copied, but never deleted from the source management cluster.
+	Shared bool `json:"shared,omitempty"`
+
+	// Existing is set to true if the object already existed in the target management cluster before the first
+	// attempt to create it; those objects are not deleted from the target management cluster in case of rollback.
+	Existing bool `json:"existing,omitempty"`
+
+	// ManagedEtcdCluster is set to true if the object is referenced by Cluster.Spec.ManagedExternalEtcdRef.
+	ManagedEtcdCluster bool `json:"managedEtcdCluster,omitempty"`
+
+	// EtcdEndpoints reported by a managed external etcd cluster object before the move.
+	EtcdEndpoints []string `json:"etcdEndpoints,omitempty"`
+}
+
+// newMoveCheckpoint returns a new checkpoint for a move operation; it fails if a checkpoint from
+// a previous move operation already exists, so the user can decide to resume or to rollback it.
+func newMoveCheckpoint(path, namespace string, selector *ClusterSelector, source, target moveCheckpointCluster) (*moveCheckpoint, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, errors.Errorf("a checkpoint of a previous move exists in %q; please resume or rollback the previous move, or delete the checkpoint file", path)
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to check the move checkpoint file %q", path)
+	}
+
+	c := &moveCheckpoint{
+		Namespace: namespace,
+		Source:    source,
+		Target:    target,
+		Phase:     moveCheckpointPausing,
+		path:      path,
+	}
+	if !selector.IsEmpty() {
+		c.Selector = selector
+	}
+	return c, nil
+}
+
+// readMoveCheckpoint reads the checkpoint of a move operation from a file.
+func readMoveCheckpoint(path string) (*moveCheckpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("the move checkpoint file %q does not exist", path)
+		}
+		return nil, errors.Wrapf(err, "failed to read the move checkpoint file %q", path)
+	}
+
+	c := &moveCheckpoint{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the move checkpoint file %q", path)
+	}
+	if _, ok := moveCheckpointPhases[c.Phase]; !ok {
+		return nil, errors.Errorf("invalid phase %q in the move checkpoint file %q", c.Phase, path)
+	}
+	c.path = path
+	return c, nil
+}
+
+// checkClusters checks the checkpoint was recorded for a move between the given management clusters; this prevents
+// resuming or rolling back a move using a checkpoint recorded for different management clusters, e.g. when
+// using the default checkpoint file for moves between different management clusters.
+func (c *moveCheckpoint) checkClusters(source, target moveCheckpointCluster) error {
+	if c.Source != source {
+		return errors.Errorf("the move checkpoint in %q was recorded for the source management cluster %s, but the source management cluster is %s; please use the same kubeconfig and context of the recorded move", c.path, c.Source, source)
+	}
+	if c.Target != target {
+		return errors.Errorf("the move checkpoint in %q was recorded for the target management cluster %s, but the target management cluster is %s; please use the same kubeconfig and context of the recorded move", c.path, c.Target, target)
+	}
+	return nil
+}
+
+// save writes the checkpoint to file.
+func (c *moveCheckpoint) save() error {
+	if c == nil {
+		return nil
+	}
+
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the move checkpoint")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create the directory for the move checkpoint file %q", c.path)
+	}
+	if err := os.WriteFile(c.path, b, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the move checkpoint file %q", c.path)
+	}
+	return nil
+}
+
+// complete removes the checkpoint file once the move operation is completed or rolled back.
+func (c *moveCheckpoint) complete() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to delete the move checkpoint file %q", c.path)
+	}
+	return nil
+}
+
+// reached returns true if the move operation already reached the given phase.
+func (c *moveCheckpoint) reached(phase moveCheckpointPhase) bool {
+	if c == nil {
+		return false
+	}
+	return moveCheckpointPhases[c.Phase] >= moveCheckpointPhases[phase]
+}
+
+// setPhase records the current phase of the move operation.
+func (c *moveCheckpoint) setPhase(phase moveCheckpointPhase) error {
+	if c == nil {
+		return nil
+	}
+
+	c.Phase = phase
+	return c.save()
+}
+
+// setClusters records the Clusters being moved.
+func (c *moveCheckpoint) setClusters(clusters []*node) error {
+	if c == nil {
+		return nil
+	}
+
+	c.Clusters = make([]corev1.ObjectReference, 0, len(clusters))
+	for _, cluster := range clusters {
+		c.Clusters = append(c.Clusters, cluster.identity)
+	}
+	return c.save()
+}
+
+// setSourcePaused records that all the Clusters in the source management cluster are paused.
+func (c *moveCheckpoint) setSourcePaused() error {
+	if c == nil {
+		return nil
+	}
+
+	c.SourcePaused = true
+	return c.save()
+}
+
+// isSourcePaused returns true if all the Clusters in the source management cluster are already paused.
+func (c *moveCheckpoint) isSourcePaused() bool {
+	return c != nil && c.SourcePaused
+}
+
+// addPendingObject records an object before creating it in the target management cluster, so it is deleted in
+// case of rollback even if the move fails before completing the creation.
+func (c *moveCheckpoint) addPendingObject(n *node) error {
+	if c == nil || c.getObject(n) != nil {
+		return nil
+	}
+
+	c.Objects = append(c.Objects, moveCheckpointObject{
+		Identity:           n.identity,
+		Global:             n.isGlobal || n.isGlobalHierarchy,
+		Shared:             n.isShared,
+		Existing:           n.existingInTarget,
+		ManagedEtcdCluster: n.isManagedEtcdCluster,
+		Pending:            true,
+	})
+	return c.save()
+}
+
+// addObject records an object created in the target management cluster.
+func (c *moveCheckpoint) addObject(n *node) error {
+	if c == nil {
+		return nil
+	}
+
+	obj := c.getObject(n)
+	if obj == nil {
+		c.Objects = append(c.Objects, moveCheckpointObject{
+			Identity:           n.identity,
+			Global:             n.isGlobal || n.isGlobalHierarchy,
+			Shared:             n.isShared,
+			Existing:           n.existingInTarget,
+			ManagedEtcdCluster: n.isManagedEtcdCluster,
+		})
+		obj = &c.Objects[len(c.Objects)-1]
+	}
+	obj.TargetUID = n.newUID
+	obj.EtcdEndpoints = n.etcdEndpoints
+	obj.Pending = false
+	return c.save()
+}
+
+// getObject returns the record of the object corresponding to the node, if any.
+func (c *moveCheckpoint) getObject(n *node) *moveCheckpointObject {
+	if c == nil {
+		return nil
+	}
+
+	for i := range c.Objects {
+		if c.Objects[i].Identity.UID == n.identity.UID {
+			return &c.Objects[i]
+		}
+	}
+	return nil
+}
+
+// hasObject returns true if the object corresponding to the node is already created in the target management cluster.
+func (c *moveCheckpoint) hasObject(n *node) bool {
+	obj := c.getObject(n)
+	return obj != nil && !obj.Pending
+}
+
+// restore restores the information about the objects already created in the target management cluster into
+// the nodes of an object graph, so they can be used while creating the remaining objects (e.g. for rebuilding
+// the owner reference chain).
+func (c *moveCheckpoint) restore(graph *objectGraph) {
+	if c == nil {
+		return
+	}
+
+	for i := range c.Objects {
+		obj := c.Objects[i]
+		n, ok := graph.uidToNode[obj.Identity.UID]
+		if !ok {
+			continue
+		}
+		n.newUID = obj.TargetUID
+		n.existingInTarget = obj.Existing
+		n.etcdEndpoints = obj.EtcdEndpoints
+	}
+}
+
+// getObjectGraph returns an object graph built from the objects recorded in the checkpoint; this is
+// used when the objects in the source management cluster could already be partially deleted.
+func (c *moveCheckpoint) getObjectGraph(proxy Proxy, providerInventory InventoryClient) *objectGraph {
+	graph := newObjectGraph(proxy, providerInventory)
+	for i := range c.Objects {
+		obj := c.Objects[i]
+		graph.uidToNode[obj.Identity.UID] = &node{
+			identity:             obj.Identity,
+			isGlobal:             obj.Global,
+			isShared:             obj.Shared,
+			newUID:               obj.TargetUID,
+			existingInTarget:     obj.Existing,
+			isManagedEtcdCluster: obj.ManagedEtcdCluster,
+			etcdEndpoints:        obj.EtcdEndpoints,
+		}
+	}
+	return graph
+}
+
+// getMoveSequence returns the move sequence for an object graph built from the checkpoint, with
+// one object for each group in the same order the objects were created in the target management cluster.
+func (c *moveCheckpoint) getMoveSequence(graph *objectGraph) *moveSequence {
+	moveSequence := &moveSequence{
+		groups:   []moveGroup{},
+		nodesMap: make(map[*node]empty),
+	}
+	for i := range c.Objects {
+		if n, ok := graph.uidToNode[c.Objects[i].Identity.UID]; ok {
+			moveSequence.addGroup(moveGroup{n})
+		}
+	}
+	return moveSequence
+}
+
+// getClusters returns the nodes corresponding to the Clusters being moved.
+func (c *moveCheckpoint) getClusters() []*node {
+	clusters := make([]*node, 0, len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		clusters = append(clusters, &node{identity: cluster})
+	}
+	return clusters
+}
@@ -0,0 +1,419 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_moveCheckpoint_readWrite(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("/tmp", "cluster-api")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint", "move-checkpoint.yaml")
+
+	_, err = readMoveCheckpoint(path)
+	g.Expect(err).To(HaveOccurred())
+
+	source := moveCheckpointCluster{Kubeconfig: "source.kubeconfig", Server: "https://source:6443"}
+	target := moveCheckpointCluster{Kubeconfig: "target.kubeconfig", Context: "target", Server: "https://target:6443"}
+	checkpoint, err := newMoveCheckpoint(path, "ns1", nil, source, target)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(checkpoint.reached(moveCheckpointPausing)).To(BeTrue())
+	g.Expect(checkpoint.reached(moveCheckpointCreating)).To(BeFalse())
+
+	cluster := &node{identity: corev1.ObjectReference{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster", Namespace: "ns1", Name: "foo", UID: "uid"}}
+	g.Expect(checkpoint.setClusters([]*node{cluster})).To(Succeed())
+	g.Expect(checkpoint.setSourcePaused()).To(Succeed())
+	g.Expect(checkpoint.setPhase(moveCheckpointCreating)).To(Succeed())
+	// Objects are recorded before creating them, and then updated once created in the target cluster.
+	g.Expect(checkpoint.addPendingObject(cluster)).To(Succeed())
+	g.Expect(checkpoint.hasObject(cluster)).To(BeFalse())
+	cluster.newUID = "new-uid"
+	g.Expect(checkpoint.addObject(cluster)).To(Succeed())
+	g.Expect(checkpoint.hasObject(cluster)).To(BeTrue())
+
+	// A new move fails if there is a checkpoint of a previous move.
+	_, err = newMoveCheckpoint(path, "ns1", nil, source, target)
+	g.Expect(err).To(HaveOccurred())
+
+	got, err := readMoveCheckpoint(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.Namespace).To(Equal("ns1"))
+	g.Expect(got.Phase).To(Equal(moveCheckpointCreating))
+	g.Expect(got.isSourcePaused()).To(BeTrue())
+	g.Expect(got.Clusters).To(ConsistOf(cluster.identity))
+	g.Expect(got.Objects).To(HaveLen(1))
+	g.Expect(got.hasObject(cluster)).To(BeTrue())
+	g.Expect(got.Objects[0].TargetUID).To(BeEquivalentTo("new-uid"))
+
+	// The checkpoint can be used only for the same source and target management clusters.
+	g.Expect(got.checkClusters(source, target)).To(Succeed())
+	g.Expect(got.checkClusters(target, source)).NotTo(Succeed())
+	g.Expect(got.checkClusters(source, moveCheckpointCluster{Kubeconfig: "target.kubeconfig", Server: "https://target:6443"})).NotTo(Succeed())
+	g.Expect(got.checkClusters(source, moveCheckpointCluster{Kubeconfig: "target.kubeconfig", Context: "target", Server: "https://other:6443"})).NotTo(Succeed())
+
+	g.Expect(got.complete()).To(Succeed())
+	_, err = os.Stat(path)
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// A nil checkpoint is a no-op.
+	var nilCheckpoint *moveCheckpoint
+	g.Expect(nilCheckpoint.setPhase(moveCheckpointDeleting)).To(Succeed())
+	g.Expect(nilCheckpoint.addObject(cluster)).To(Succeed())
+	g.Expect(nilCheckpoint.reached(moveCheckpointPausing)).To(BeFalse())
+	g.Expect(nilCheckpoint.complete()).To(Succeed())
+}
+
+// getMoveTestObjectGraph returns an object graph discovered from a source cluster with the given objects.
+func getMoveTestObjectGraph(g *WithT, objs []client.Object) *objectGraph {
+	graph := getObjectGraphWithObjs(objs)
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+	return graph
+}
+
+// rediscoverMoveTestObjectGraph returns a new object graph discovered from the same source cluster of a graph.
+func rediscoverMoveTestObjectGraph(g *WithT, graph *objectGraph) *objectGraph {
+	newGraph := newObjectGraph(graph.proxy, graph.providerInventory)
+	g.Expect(getFakeDiscoveryTypes(newGraph)).To(Succeed())
+	g.Expect(newGraph.Discovery("")).To(Succeed())
+	return newGraph
+}
+
+// startMoveWithCheckpoint simulates a move failing after creating the given number of groups in the target cluster.
+func startMoveWithCheckpoint(g *WithT, graph *objectGraph, toProxy Proxy, path string, groups int) {
+	checkpoint, err := newMoveCheckpoint(path, "", nil, moveCheckpointCluster{}, moveCheckpointCluster{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	mover := objectMover{
+		fromProxy:  graph.proxy,
+		checkpoint: checkpoint,
+	}
+
+	clusters := graph.getClusters()
+	g.Expect(checkpoint.setClusters(clusters)).To(Succeed())
+	g.Expect(setClusterPause(graph.proxy, clusters, true, false)).To(Succeed())
+	g.Expect(checkpoint.setSourcePaused()).To(Succeed())
+	g.Expect(mover.ensureNamespaces(graph, toProxy)).To(Succeed())
+	g.Expect(checkpoint.setPhase(moveCheckpointCreating)).To(Succeed())
+
+	moveSequence, err := getMoveSequence(graph)
+	g.Expect(err).ToNot(HaveOccurred())
+	for i := 0; i < groups && i < len(moveSequence.groups); i++ {
+		g.Expect(mover.createGroup(moveSequence.getGroup(i), toProxy)).To(Succeed())
+	}
+}
+
+func getMoveTestObject(g *WithT, proxy Proxy, n *node) (*unstructured.Unstructured, error) {
+	c, err := proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(n.identity.APIVersion)
+	obj.SetKind(n.identity.Kind)
+	err = c.Get(ctx, client.ObjectKey{Namespace: n.identity.Namespace, Name: n.identity.Name}, obj)
+	return obj, err
+}
+
+func Test_objectMover_move_withCheckpoint(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("/tmp", "cluster-api")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "move-checkpoint.yaml")
+
+	graph := getMoveTestObjectGraph(g, test.NewFakeCluster("ns1", "foo").Objs())
+	toProxy := getFakeProxyWithCRDs()
+
+	checkpoint, err := newMoveCheckpoint(path, "", nil, moveCheckpointCluster{}, moveCheckpointCluster{})
+	g.Expect(err).NotTo(HaveOccurred())
+	mover := objectMover{
+		fromProxy:  graph.proxy,
+		checkpoint: checkpoint,
+	}
+	g.Expect(mover.move(graph, toProxy)).To(Succeed())
+
+	// The checkpoint is deleted once the move is completed.
+	_, err = os.Stat(path)
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+	for _, n := range graph.uidToNode {
+		_, err := getMoveTestObject(g, toProxy, n)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+}
+
+func Test_objectMover_resumeMove(t *testing.T) {
+	tests := []struct {
+		name           string
+		objs           []client.Object
+		createdGroups  int
+		deletedObjects int
+	}{
+		{
+			name:          "resume after pausing the source cluster",
+			objs:          test.NewFakeCluster("ns1", "foo").Objs(),
+			createdGroups: 0,
+		},
+		{
+			name:          "resume after creating some objects in the target cluster",
+			objs:          test.NewFakeCluster("ns1", "foo").WithMachineSets(test.NewFakeMachineSet("ms1").WithMachines(test.NewFakeMachine("m1"))).Objs(),
+			createdGroups: 2,
+		},
+		{
+			name:           "resume after deleting some objects from the source cluster",
+			objs:           test.NewFakeCluster("ns1", "foo").WithMachineSets(test.NewFakeMachineSet("ms1").WithMachines(test.NewFakeMachine("m1"))).Objs(),
+			createdGroups:  100,
+			deletedObjects: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := ioutil.TempDir("/tmp", "cluster-api")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "move-checkpoint.yaml")
+
+			graph := getMoveTestObjectGraph(g, tt.objs)
+			toProxy := getFakeProxyWithCRDs()
+
+			// Simulates a move failing halfway through.
+			startMoveWithCheckpoint(g, graph, toProxy, path, tt.createdGroups)
+			if tt.deletedObjects > 0 {
+				checkpoint, err := readMoveCheckpoint(path)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(checkpoint.setPhase(moveCheckpointDeleting)).To(Succeed())
+
+				mover := objectMover{fromProxy: graph.proxy}
+				for i := len(checkpoint.Objects) - 1; i >= len(checkpoint.Objects)-tt.deletedObjects; i-- {
+					g.Expect(mover.deleteSourceObject(graph.uidToNode[checkpoint.Objects[i].Identity.UID])).To(Succeed())
+				}
+			}
+
+			created, err := readMoveCheckpoint(path)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// Resumes the move; this mirrors ResumeMove, without checking the providers in the target cluster.
+			checkpoint, err := readMoveCheckpoint(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			var resumeGraph *objectGraph
+			if checkpoint.reached(moveCheckpointDeleting) {
+				resumeGraph = checkpoint.getObjectGraph(graph.proxy, graph.providerInventory)
+			} else {
+				resumeGraph = rediscoverMoveTestObjectGraph(g, graph)
+				checkpoint.restore(resumeGraph)
+			}
+			mover := objectMover{
+				fromProxy:  graph.proxy,
+				checkpoint: checkpoint,
+			}
+			g.Expect(mover.move(resumeGraph, toProxy)).To(Succeed())
+
+			// The checkpoint is deleted once the move is completed.
+			_, err = os.Stat(path)
+			g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+			// All the objects are deleted from the source cluster and created in the target cluster.
+			for _, n := range graph.uidToNode {
+				_, err := getMoveTestObject(g, graph.proxy, n)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+				_, err = getMoveTestObject(g, toProxy, n)
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+
+			// The objects created before the failure are not created again.
+			for _, o := range created.Objects {
+				obj, err := getMoveTestObject(g, toProxy, &node{identity: o.Identity})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(obj.GetUID()).To(Equal(o.TargetUID))
+			}
+
+			// The Clusters in the target cluster are resumed.
+			for _, n := range graph.getClusters() {
+				obj, err := getMoveTestObject(g, toProxy, n)
+				g.Expect(err).NotTo(HaveOccurred())
+				paused, _, err := unstructured.NestedBool(obj.Object, "spec", "paused")
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(paused).To(BeFalse())
+			}
+		})
+	}
+}
+
+func Test_objectMover_rollback(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("/tmp", "cluster-api")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "move-checkpoint.yaml")
+
+	graph := getMoveTestObjectGraph(g, test.NewFakeCluster("ns1", "foo").WithMachineSets(test.NewFakeMachineSet("ms1").WithMachines(test.NewFakeMachine("m1"))).Objs())
+	toProxy := getFakeProxyWithCRDs()
+
+	// Simulates a move failing halfway through.
+	startMoveWithCheckpoint(g, graph, toProxy, path, 2)
+
+	// Rolls back the move; this mirrors RollbackMove.
+	checkpoint, err := readMoveCheckpoint(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(checkpoint.Objects).NotTo(BeEmpty())
+	mover := objectMover{
+		fromProxy:  graph.proxy,
+		checkpoint: checkpoint,
+	}
+	g.Expect(mover.rollback(checkpoint.getObjectGraph(graph.proxy, graph.providerInventory), toProxy)).To(Succeed())
+
+	// The checkpoint is deleted once the rollback is completed.
+	_, err = os.Stat(path)
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// All the objects are kept in the source cluster and deleted from the target cluster.
+	for _, n := range graph.uidToNode {
+		_, err := getMoveTestObject(g, graph.proxy, n)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		_, err = getMoveTestObject(g, toProxy, n)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	}
+
+	// The Clusters in the source cluster are resumed.
+	for _, n := range graph.getClusters() {
+		obj, err := getMoveTestObject(g, graph.proxy, n)
+		g.Expect(err).NotTo(HaveOccurred())
+		paused, _, err := unstructured.NestedBool(obj.Object, "spec", "paused")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(paused).To(BeFalse())
+	}
+}
+
+func Test_objectMover_rollback_pendingObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("/tmp", "cluster-api")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "move-checkpoint.yaml")
+
+	graph := getMoveTestObjectGraph(g, test.NewFakeCluster("ns1", "foo").WithMachineSets(test.NewFakeMachineSet("ms1")).Objs())
+	toProxy := getFakeProxyWithCRDs()
+	startMoveWithCheckpoint(g, graph, toProxy, path, 1)
+
+	moveSequence, err := getMoveSequence(graph)
+	g.Expect(err).ToNot(HaveOccurred())
+	group := moveSequence.getGroup(1)
+	g.Expect(len(group)).To(BeNumerically(">", 1))
+
+	// One of the objects already exists in the target cluster before the move.
+	preExisting := group[0]
+	preExistingObj, err := getMoveTestObject(g, graph.proxy, preExisting)
+	g.Expect(err).NotTo(HaveOccurred())
+	preExistingObj.SetResourceVersion("")
+	preExistingObj.SetUID("")
+	cTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cTo.Create(ctx, preExistingObj)).To(Succeed())
+
+	// Simulates a move failing after creating the other objects, but before recording them as created.
+	checkpoint, err := readMoveCheckpoint(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	mover := objectMover{
+		fromProxy:  graph.proxy,
+		checkpoint: checkpoint,
+	}
+	for _, n := range group {
+		g.Expect(mover.checkExistingInTarget(n, toProxy)).To(Succeed())
+		g.Expect(checkpoint.addPendingObject(n)).To(Succeed())
+		if n != preExisting {
+			g.Expect(mover.createTargetObject(n, toProxy)).To(Succeed())
+		}
+	}
+
+	// Resuming the creation does not consider the objects created before the failure as existing.
+	checkpoint, err = readMoveCheckpoint(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	resumeGraph := rediscoverMoveTestObjectGraph(g, graph)
+	checkpoint.restore(resumeGraph)
+	mover = objectMover{
+		fromProxy:  graph.proxy,
+		checkpoint: checkpoint,
+	}
+	resumeGroup := moveGroup{}
+	for _, n := range group {
+		resumeGroup = append(resumeGroup, resumeGraph.uidToNode[n.identity.UID])
+	}
+	g.Expect(mover.createGroup(resumeGroup, toProxy)).To(Succeed())
+	for _, n := range resumeGroup {
+		g.Expect(checkpoint.hasObject(n)).To(BeTrue())
+		g.Expect(n.existingInTarget).To(Equal(n.identity.UID == preExisting.identity.UID))
+	}
+
+	// Rollback deletes the objects created by the move, but not the object existing before the move.
+	checkpoint, err = readMoveCheckpoint(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	mover = objectMover{
+		fromProxy:  graph.proxy,
+		checkpoint: checkpoint,
+	}
+	g.Expect(mover.rollback(checkpoint.getObjectGraph(graph.proxy, graph.providerInventory), toProxy)).To(Succeed())
+	for _, n := range group {
+		_, err := getMoveTestObject(g, toProxy, n)
+		if n == preExisting {
+			g.Expect(err).NotTo(HaveOccurred())
+			continue
+		}
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	}
+}
+
+func Test_objectMover_RollbackMove_afterDeletingObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("/tmp", "cluster-api")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "move-checkpoint.yaml")
+
+	checkpoint, err := newMoveCheckpoint(path, "ns1", nil, moveCheckpointCluster{}, moveCheckpointCluster{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(checkpoint.setPhase(moveCheckpointDeleting)).To(Succeed())
+
+	mover := objectMover{}
+	g.Expect(mover.RollbackMove(nil, path)).NotTo(Succeed())
+
+	// The checkpoint is kept, so the move can be resumed.
+	_, err = os.Stat(path)
+	g.Expect(err).NotTo(HaveOccurred())
+}
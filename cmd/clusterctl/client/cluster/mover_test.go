@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -30,11 +31,15 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	fakeetcd "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/etcd"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
+	"sigs.k8s.io/cluster-api/internal/backup"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -265,6 +270,59 @@ var moveTests = []struct {
 		},
 		wantErr: false,
 	},
+	{
+		name: "Cluster with Control Plane and managed external etcd",
+		fields: moveTestsFields{
+			objs: test.NewFakeCluster("ns1", "cluster1").
+				WithControlPlane(
+					test.NewFakeControlPlane("cp1").
+						WithMachines(
+							test.NewFakeMachine("m1"),
+						),
+				).
+				WithManagedExternalEtcd(
+					test.NewFakeManagedExternalEtcd("etcd1").
+						WithMachines("etcd1-m1", "etcd1-m2"),
+				).Objs(),
+		},
+		wantMoveGroups: [][]string{
+			{ // group 1
+				"cluster.x-k8s.io/v1beta1, Kind=Cluster, ns1/cluster1",
+			},
+			{ // group 2 (objects with ownerReferences in group 1)
+				// owned by Clusters
+				"/v1, Kind=Secret, ns1/cluster1-ca",
+				"/v1, Kind=Secret, ns1/cluster1-etcd-init",
+				"etcdcluster.cluster.x-k8s.io/v1beta1, Kind=GenericEtcdCluster, ns1/etcd1",
+				"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureCluster, ns1/cluster1",
+				"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureMachineTemplate, ns1/cp1",
+			},
+			{ // group 3 (objects with ownerReferences in group 1,2)
+				// owned by GenericEtcdClusters
+				"/v1, Kind=Secret, ns1/cluster1-managed-etcd",
+				"cluster.x-k8s.io/v1beta1, Kind=Machine, ns1/etcd1-m1",
+				"cluster.x-k8s.io/v1beta1, Kind=Machine, ns1/etcd1-m2",
+			},
+			{ // group 4 (objects with ownerReferences in group 1, and moved after the managed external etcd objects in group 2,3)
+				"controlplane.cluster.x-k8s.io/v1beta1, Kind=GenericControlPlane, ns1/cp1",
+			},
+			{ // group 5 (objects with ownerReferences in group 1,2,3,4)
+				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+				"/v1, Kind=Secret, ns1/cluster1-sa",
+				"cluster.x-k8s.io/v1beta1, Kind=Machine, ns1/m1",
+			},
+			{ // group 6 (objects with ownerReferences in group 1,2,3,4,5)
+				// owned by Machines
+				"bootstrap.cluster.x-k8s.io/v1beta1, Kind=GenericBootstrapConfig, ns1/m1",
+				"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureMachine, ns1/m1",
+			},
+			{ // group 7 (objects with ownerReferences in group 1,2,3,4,5,6)
+				// owned by GenericBootstrapConfigs
+				"/v1, Kind=Secret, ns1/m1",
+			},
+		},
+		wantErr: false,
+	},
 	{
 		name: "Cluster with MachinePool",
 		fields: moveTestsFields{
@@ -556,7 +614,7 @@ func Test_objectMover_backupTargetObject(t *testing.T) {
 			defer os.RemoveAll(dir)
 
 			for _, node := range graph.uidToNode {
-				err = mover.backupTargetObject(node, dir)
+				err = mover.backupTargetObject(node, backup.NewLocalDestination(dir))
 				if tt.wantErr {
 					g.Expect(err).To(HaveOccurred())
 					return
@@ -591,7 +649,7 @@ func Test_objectMover_backupTargetObject(t *testing.T) {
 				time.Sleep(time.Millisecond * 5)
 
 				// Running backupTargetObject should override any existing files since it represents a new backup
-				err = mover.backupTargetObject(node, dir)
+				err = mover.backupTargetObject(node, backup.NewLocalDestination(dir))
 				if tt.wantErr {
 					g.Expect(err).To(HaveOccurred())
 					return
@@ -750,7 +808,7 @@ func Test_objectMover_backup(t *testing.T) {
 			}
 			defer os.RemoveAll(dir)
 
-			err = mover.backup(graph, dir)
+			err = mover.backup(graph, backup.NewLocalDestination(dir))
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				return
@@ -799,6 +857,87 @@ func Test_objectMover_backup(t *testing.T) {
 	}
 }
 
+func Test_objectMover_backup_pausedClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	// Create an objectGraph bound a source cluster with a paused Cluster and a Cluster not paused.
+	objs := test.NewFakeCluster("ns1", "paused").Objs()
+	for _, o := range objs {
+		if c, ok := o.(*clusterv1.Cluster); ok {
+			c.Spec.Paused = true
+		}
+	}
+	objs = append(objs, test.NewFakeCluster("ns1", "running").Objs()...)
+	graph := getObjectGraphWithObjs(objs)
+
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+	archive := backup.NewArchiveWriter(&bytes.Buffer{})
+	g.Expect(mover.backup(graph, archive)).To(Succeed())
+
+	// The Cluster paused before the backup is still paused, the other one is resumed.
+	c, err := graph.proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	paused := &clusterv1.Cluster{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "paused"}, paused)).To(Succeed())
+	g.Expect(paused.Spec.Paused).To(BeTrue())
+
+	running := &clusterv1.Cluster{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "running"}, running)).To(Succeed())
+	g.Expect(running.Spec.Paused).To(BeFalse())
+}
+
+func Test_objectMover_backupArchive(t *testing.T) {
+	for _, tt := range backupRestoreTests {
+		if tt.wantErr {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.fields.objs)
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			// Run backup to an archive
+			mover := objectMover{
+				fromProxy: graph.proxy,
+			}
+
+			buf := &bytes.Buffer{}
+			archive := backup.NewArchiveWriter(buf)
+			g.Expect(mover.backup(graph, archive)).To(Succeed())
+			g.Expect(archive.Close()).To(Succeed())
+
+			// check that the archive contains all the objects
+			files, err := backup.ReadArchive(buf)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			want := []string{}
+			for filename := range tt.files {
+				want = append(want, filename)
+			}
+			got := []string{}
+			for _, f := range files {
+				obj := &unstructured.Unstructured{}
+				g.Expect(obj.UnmarshalJSON(f)).To(Succeed())
+				got = append(got, fmt.Sprintf("%s_%s_%s.yaml", obj.GetKind(), obj.GetNamespace(), obj.GetName()))
+			}
+			g.Expect(got).To(ConsistOf(want))
+		})
+	}
+}
+
 func Test_objectMover_filesToObjs(t *testing.T) {
 	// NB. we are testing the move and move sequence using the same set of moveTests, but checking the results at different stages of the move process
 	for _, tt := range backupRestoreTests {
@@ -956,7 +1095,8 @@ func Test_getMoveSequence(t *testing.T) {
 			// trigger discovery the content of the source cluster
 			g.Expect(graph.Discovery("")).To(Succeed())
 
-			moveSequence := getMoveSequence(graph)
+			moveSequence, err := getMoveSequence(graph)
+			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(moveSequence.groups).To(HaveLen(len(tt.wantMoveGroups)))
 
 			for i, gotGroup := range moveSequence.groups {
@@ -972,6 +1112,83 @@ func Test_getMoveSequence(t *testing.T) {
 	}
 }
 
+func Test_getMoveSequence_managedEtcdObjectsOwnedByTheControlPlane(t *testing.T) {
+	g := NewWithT(t)
+
+	// A Secret owned both by the managed external etcd and by the control plane can't be moved before the control plane.
+	objs := test.NewFakeCluster("ns1", "cluster1").
+		WithControlPlane(test.NewFakeControlPlane("cp1")).
+		WithManagedExternalEtcd(test.NewFakeManagedExternalEtcd("etcd1")).
+		Objs()
+	objs = append(objs, &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "shared",
+			UID:       "/v1, Kind=Secret, ns1/shared",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "etcdcluster.cluster.x-k8s.io/v1beta1",
+					Kind:       "GenericEtcdCluster",
+					Name:       "etcd1",
+					UID:        "etcdcluster.cluster.x-k8s.io/v1beta1, Kind=GenericEtcdCluster, ns1/etcd1",
+				},
+				{
+					APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+					Kind:       "GenericControlPlane",
+					Name:       "cp1",
+					UID:        "controlplane.cluster.x-k8s.io/v1beta1, Kind=GenericControlPlane, ns1/cp1",
+				},
+			},
+		},
+	})
+	graph := getObjectGraphWithObjs(objs)
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	moveSequence, err := getMoveSequence(graph)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	groupOf := func(uid string) int {
+		for i, group := range moveSequence.groups {
+			for _, n := range group {
+				if string(n.identity.UID) == uid {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	controlPlaneGroup := groupOf("controlplane.cluster.x-k8s.io/v1beta1, Kind=GenericControlPlane, ns1/cp1")
+	g.Expect(controlPlaneGroup).To(BeNumerically(">", groupOf("etcdcluster.cluster.x-k8s.io/v1beta1, Kind=GenericEtcdCluster, ns1/etcd1")))
+	g.Expect(groupOf("/v1, Kind=Secret, ns1/shared")).To(BeNumerically(">", controlPlaneGroup))
+}
+
+func Test_getMoveSequence_failsIfObjectsCannotBePlaced(t *testing.T) {
+	g := NewWithT(t)
+
+	objs := test.NewFakeCluster("ns1", "cluster1").
+		WithControlPlane(test.NewFakeControlPlane("cp1")).
+		WithManagedExternalEtcd(test.NewFakeManagedExternalEtcd("etcd1")).
+		Objs()
+	graph := getObjectGraphWithObjs(objs)
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	// Create a loop in the move order constraints, so neither the control plane nor the managed external etcd can be placed.
+	etcdCluster := graph.getManagedEtcdClusters()[0]
+	controlPlane := graph.getNodeByRef(graph.getClusters()[0].controlPlaneRef)
+	etcdCluster.moveAfter[controlPlane] = empty{}
+
+	_, err := getMoveSequence(graph)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("GenericControlPlane ns1/cp1"))
+	g.Expect(err.Error()).To(ContainSubstring("GenericEtcdCluster ns1/etcd1"))
+}
+
 func Test_objectMover_move_dryRun(t *testing.T) {
 	// NB. we are testing the move and move sequence using the same set of moveTests, but checking the results at different stages of the move process
 	for _, tt := range moveTests {
@@ -1065,7 +1282,9 @@ func Test_objectMover_move(t *testing.T) {
 
 			// Run move
 			mover := objectMover{
-				fromProxy: graph.proxy,
+				fromProxy:            graph.proxy,
+				pollImmediateWaiter:  wait.PollImmediate,
+				etcdEndpointsTimeout: 100 * time.Millisecond,
 			}
 
 			err := mover.move(graph, toProxy)
@@ -1344,6 +1563,106 @@ func Test_objectMover_checkProvisioningCompleted(t *testing.T) {
 	}
 }
 
+func Test_objectMover_checkManagedEtcdEndpoints(t *testing.T) {
+	tests := []struct {
+		name            string
+		targetEndpoints string
+		wantErr         bool
+	}{
+		{
+			name:            "Pass if the managed external etcd endpoints are not changed",
+			targetEndpoints: "https://10.0.0.1:2379",
+			wantErr:         false,
+		},
+		{
+			name:            "Fails if the managed external etcd endpoints are changed",
+			targetEndpoints: "https://10.0.0.2:2379",
+			wantErr:         true,
+		},
+		{
+			name:            "Fails if the managed external etcd endpoints are not reported before the timeout",
+			targetEndpoints: "",
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			objs := test.NewFakeCluster("ns1", "cluster1").
+				WithManagedExternalEtcd(test.NewFakeManagedExternalEtcd("etcd1")).
+				Objs()
+			graph := getObjectGraphWithObjs(objs)
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			// Simulate the endpoints stored when moving the etcd cluster object.
+			etcdClusters := graph.getManagedEtcdClusters()
+			g.Expect(etcdClusters).To(HaveLen(1))
+			etcdClusters[0].etcdEndpoints = []string{"https://10.0.0.1:2379"}
+
+			// gets a fakeProxy to a cluster with the etcd cluster object reporting the target endpoints
+			toProxy := getFakeProxyWithCRDs()
+			for _, o := range objs {
+				if etcdCluster, ok := o.(*fakeetcd.GenericEtcdCluster); ok {
+					etcdCluster = etcdCluster.DeepCopy()
+					etcdCluster.Status.Endpoints = tt.targetEndpoints
+					toProxy.WithObjs(etcdCluster)
+				}
+			}
+
+			mover := objectMover{
+				fromProxy:            graph.proxy,
+				pollImmediateWaiter:  wait.PollImmediate,
+				etcdEndpointsTimeout: 100 * time.Millisecond,
+			}
+
+			err := mover.checkManagedEtcdEndpoints(graph, toProxy)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func Test_getEtcdEndpointsTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *fakeConfigClient
+		want   time.Duration
+	}{
+		{
+			name:   "no custom value set for timeout",
+			config: newFakeConfig(),
+			want:   defaultEtcdEndpointsTimeout,
+		},
+		{
+			name:   "a custom value of timeout is set",
+			config: newFakeConfig().WithVar(config.EtcdEndpointsTimeoutVariable, "20m"),
+			want:   20 * time.Minute,
+		},
+		{
+			name:   "invalid custom value of timeout is set",
+			config: newFakeConfig().WithVar(config.EtcdEndpointsTimeoutVariable, "foo"),
+			want:   defaultEtcdEndpointsTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(getEtcdEndpointsTimeout(tt.config)).To(Equal(tt.want))
+		})
+	}
+}
+
 func Test_objectsMoverService_checkTargetProviders(t *testing.T) {
 	type fields struct {
 		fromProxy Proxy
@@ -1971,3 +2290,57 @@ func Test_deleteSourceObject(t *testing.T) {
 		})
 	}
 }
+
+func Test_objectMover_move_withClusterSelection(t *testing.T) {
+	g := NewWithT(t)
+
+	graph := getObjectGraphWithObjs(getClusterSelectionTestObjs())
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+	g.Expect(graph.setClusterSelection(&ClusterSelector{Names: []string{"cluster1"}})).To(Succeed())
+
+	toProxy := getFakeProxyWithCRDs()
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+	g.Expect(mover.move(graph, toProxy)).To(Succeed())
+
+	csFrom, err := graph.proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	exists := func(c client.Client, apiVersion, kind, name string) bool {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		err := c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, obj)
+		if err != nil {
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			return false
+		}
+		return true
+	}
+
+	// The selected cluster is moved.
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "Cluster", "cluster1")).To(BeFalse())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Cluster", "cluster1")).To(BeTrue())
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "Machine", "m1")).To(BeFalse())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Machine", "m1")).To(BeTrue())
+
+	// The objects shared with the other cluster are copied.
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "ClusterClass", "class1")).To(BeTrue())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "ClusterClass", "class1")).To(BeTrue())
+	g.Expect(exists(csFrom, "addons.cluster.x-k8s.io/v1beta1", "ClusterResourceSet", "crs1")).To(BeTrue())
+	g.Expect(exists(csTo, "addons.cluster.x-k8s.io/v1beta1", "ClusterResourceSet", "crs1")).To(BeTrue())
+
+	// The other cluster and the objects used only by it are left untouched.
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Cluster", "cluster2")).To(BeFalse())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Machine", "m2")).To(BeFalse())
+	g.Expect(exists(csTo, "addons.cluster.x-k8s.io/v1beta1", "ClusterResourceSetBinding", "cluster2")).To(BeFalse())
+
+	cluster2 := &clusterv1.Cluster{}
+	g.Expect(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "cluster2"}, cluster2)).To(Succeed())
+	g.Expect(cluster2.Spec.Paused).To(BeFalse())
+}
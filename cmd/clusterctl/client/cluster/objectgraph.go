@@ -26,7 +26,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
@@ -75,6 +78,9 @@ type node struct {
 	// newID stores the new UID the objects gets once created in the target cluster.
 	newUID types.UID
 
+	// existingInTarget is set to true if the object already existed in the target cluster before being moved.
+	existingInTarget bool
+
 	// tenant define the list of objects which are tenant for the node, no matter if the node has a direct OwnerReference to the object or if
 	// the node is linked to a object indirectly in the OwnerReference chain.
 	tenant map[*node]empty
@@ -82,6 +88,39 @@ type node struct {
 	// restoreObject holds the object that is referenced when creating a node during restore from file.
 	// the object can then be referenced latter when restoring objects to a target management cluster
 	restoreObject *unstructured.Unstructured
+
+	// moveAfter contains the list of nodes that must be moved before the current node, even if they are not owners of the current node.
+	// E.g. the control plane of a Cluster must be moved after the objects of the managed external etcd of the Cluster.
+	moveAfter map[*node]empty
+
+	// controlPlaneRef and managedEtcdRef store the references defined in the spec of a Cluster object.
+	controlPlaneRef *corev1.ObjectReference
+	managedEtcdRef  *corev1.ObjectReference
+
+	// isManagedEtcd is set to true if this object is part of the managed external etcd of a Cluster, e.g. the object
+	// referenced by Cluster.Spec.ManagedExternalEtcdRef, the etcd Machines or the etcd Secrets.
+	isManagedEtcd bool
+
+	// isManagedEtcdCluster is set to true if this object is referenced by Cluster.Spec.ManagedExternalEtcdRef.
+	isManagedEtcdCluster bool
+
+	// etcdEndpoints stores the endpoints reported by a managed external etcd cluster object before the move operation,
+	// so it is possible to check they are not changed after the move.
+	etcdEndpoints []string
+
+	// labels stores the labels of a Cluster object, so it is possible to select the Clusters to be moved.
+	labels map[string]string
+
+	// clusterClassRef stores the reference to the ClusterClass defined in the topology of a Cluster object.
+	clusterClassRef *corev1.ObjectReference
+
+	// templateRefs stores the references to the templates defined in the spec of a ClusterClass object.
+	templateRefs []*corev1.ObjectReference
+
+	// isShared is set to true if this object is shared between the Clusters being moved and other Clusters, e.g. a ClusterClass
+	// or a ClusterResourceSet used also by Clusters not being moved.
+	// When this flag is true the object is copied to the target cluster, but it should not be deleted from the source cluster.
+	isShared bool
 }
 
 type discoveryTypeInfo struct {
@@ -194,6 +233,7 @@ func (o *objectGraph) ownerToVirtualNode(owner metav1.OwnerReference) *node {
 		owners:     make(map[*node]ownerReferenceAttributes),
 		softOwners: make(map[*node]empty),
 		tenant:     make(map[*node]empty),
+		moveAfter:  make(map[*node]empty),
 		virtual:    true,
 		// NOTE: deferring initialization of fields derived from object meta to when the node reference is actually processed.
 	}
@@ -229,6 +269,7 @@ func (o *objectGraph) objToNode(obj *unstructured.Unstructured) *node {
 		owners:     make(map[*node]ownerReferenceAttributes),
 		softOwners: make(map[*node]empty),
 		tenant:     make(map[*node]empty),
+		moveAfter:  make(map[*node]empty),
 		virtual:    false,
 	}
 	o.objMetaToNode(obj, newNode)
@@ -245,6 +286,30 @@ func (o *objectGraph) objMetaToNode(obj *unstructured.Unstructured, n *node) {
 	if _, ok := obj.GetLabels()[clusterctlv1.ClusterctlMoveHierarchyLabelName]; ok {
 		n.forceMoveHierarchy = true
 	}
+	if _, ok := obj.GetLabels()[clusterv1.MachineEtcdClusterLabelName]; ok {
+		n.isManagedEtcd = true
+	}
+
+	// Keep track of the control plane and of the managed external etcd of a Cluster, so it is possible to
+	// move the managed external etcd objects before the control plane.
+	if obj.GroupVersionKind().GroupKind() == clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+		n.controlPlaneRef = getObjectReference(obj, "spec", "controlPlaneRef")
+		n.managedEtcdRef = getObjectReference(obj, "spec", "managedExternalEtcdRef")
+		n.labels = obj.GetLabels()
+		if class, ok, _ := unstructured.NestedString(obj.Object, "spec", "topology", "class"); ok && class != "" {
+			n.clusterClassRef = &corev1.ObjectReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "ClusterClass",
+				Namespace:  obj.GetNamespace(),
+				Name:       class,
+			}
+		}
+	}
+
+	// Keep track of the templates of a ClusterClass, so it is possible to move them together with the ClusterClass.
+	if obj.GroupVersionKind().GroupKind() == clusterv1.GroupVersion.WithKind("ClusterClass").GroupKind() {
+		n.templateRefs = getClusterClassTemplateRefs(obj)
+	}
 
 	kindAPIStr := getKindAPIString(metav1.TypeMeta{Kind: obj.GetKind(), APIVersion: obj.GetAPIVersion()})
 	if discoveryType, ok := o.types[kindAPIStr]; ok {
@@ -262,6 +327,55 @@ func (o *objectGraph) objMetaToNode(obj *unstructured.Unstructured, n *node) {
 	}
 }
 
+// getObjectReference returns the ObjectReference defined in the given field of an object, if any.
+// NOTE: If the reference does not define a namespace, the namespace of the object is used.
+func getObjectReference(obj *unstructured.Unstructured, fields ...string) *corev1.ObjectReference {
+	refMap, found, err := unstructured.NestedMap(obj.Object, fields...)
+	if err != nil || !found {
+		return nil
+	}
+	ref := &corev1.ObjectReference{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(refMap, ref); err != nil {
+		return nil
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = obj.GetNamespace()
+	}
+	return ref
+}
+
+// getClusterClassTemplateRefs returns the references to the templates defined in the spec of a ClusterClass.
+func getClusterClassTemplateRefs(obj *unstructured.Unstructured) []*corev1.ObjectReference {
+	clusterClass := &clusterv1.ClusterClass{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, clusterClass); err != nil {
+		return nil
+	}
+
+	refs := []*corev1.ObjectReference{
+		clusterClass.Spec.Infrastructure.Ref,
+		clusterClass.Spec.ControlPlane.Ref,
+	}
+	if clusterClass.Spec.ControlPlane.MachineInfrastructure != nil {
+		refs = append(refs, clusterClass.Spec.ControlPlane.MachineInfrastructure.Ref)
+	}
+	for _, md := range clusterClass.Spec.Workers.MachineDeployments {
+		refs = append(refs, md.Template.Bootstrap.Ref, md.Template.Infrastructure.Ref)
+	}
+
+	templateRefs := []*corev1.ObjectReference{}
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		templateRef := ref.DeepCopy()
+		if templateRef.Namespace == "" {
+			templateRef.Namespace = obj.GetNamespace()
+		}
+		templateRefs = append(templateRefs, templateRef)
+	}
+	return templateRefs
+}
+
 // getDiscoveryTypes returns the list of TypeMeta to be considered for the the move discovery phase.
 // This list includes all the types defines by the CRDs installed by clusterctl and the ConfigMap/Secret core types.
 func (o *objectGraph) getDiscoveryTypes() error {
@@ -283,9 +397,9 @@ func (o *objectGraph) getDiscoveryTypes() error {
 
 			// If a CRD is labeled with force move-hierarchy, keep track of this so all the objects of this kind could be moved
 			// together with their descendants identified via the owner chain.
-			// NOTE: Cluster and ClusterResourceSet are automatically considered as force move-hierarchy.
+			// NOTE: Cluster, ClusterClass and ClusterResourceSet are automatically considered as force move-hierarchy.
 			forceMoveHierarchy := false
-			if crd.Spec.Group == clusterv1.GroupVersion.Group && crd.Spec.Names.Kind == "Cluster" {
+			if crd.Spec.Group == clusterv1.GroupVersion.Group && (crd.Spec.Names.Kind == "Cluster" || crd.Spec.Names.Kind == "ClusterClass") {
 				forceMoveHierarchy = true
 			}
 			if crd.Spec.Group == addonsv1.GroupVersion.Group && crd.Spec.Names.Kind == "ClusterResourceSet" {
@@ -406,6 +520,12 @@ func (o *objectGraph) Discovery(namespace string) error {
 	// by a naming convention (without any explicit OwnerReference).
 	o.setSoftOwnership()
 
+	// Completes the graph by grouping the objects of the managed external etcd of each Cluster.
+	o.setManagedEtcdGroups()
+
+	// Completes the graph by linking the ClusterClasses with their templates and with the Clusters using them.
+	o.setClusterClassSoftOwnership()
+
 	// Completes the graph by setting for each node the list of tenants the node belongs to.
 	o.setTenants()
 
@@ -484,6 +604,28 @@ func (o *objectGraph) getMoveNodes() []*node {
 	return nodes
 }
 
+// getManagedEtcdClusters returns the list of objects referenced by Cluster.Spec.ManagedExternalEtcdRef existing in the object graph.
+func (o *objectGraph) getManagedEtcdClusters() []*node {
+	etcdClusters := []*node{}
+	for _, node := range o.uidToNode {
+		if node.isManagedEtcdCluster {
+			etcdClusters = append(etcdClusters, node)
+		}
+	}
+	return etcdClusters
+}
+
+// getNodeByRef returns the node corresponding to the given object reference, if any.
+func (o *objectGraph) getNodeByRef(ref *corev1.ObjectReference) *node {
+	for _, node := range o.uidToNode {
+		if node.identity.GroupVersionKind().GroupKind() == ref.GroupVersionKind().GroupKind() &&
+			node.identity.Namespace == ref.Namespace && node.identity.Name == ref.Name {
+			return node
+		}
+	}
+	return nil
+}
+
 // getMachines returns the list of Machine existing in the object graph.
 func (o *objectGraph) getMachines() []*node {
 	machines := []*node{}
@@ -522,6 +664,118 @@ func (o *objectGraph) setSoftOwnership() {
 	}
 }
 
+// setManagedEtcdGroups identifies the objects of the managed external etcd of each Cluster, i.e. the object referenced by
+// Cluster.Spec.ManagedExternalEtcdRef with all its dependents (e.g. the etcd Machines), the etcd Machines owned by the Cluster
+// and the etcd Secrets, and ensures they are moved as a group before the control plane of the Cluster.
+// NOTE: The names of the etcd Secrets can't be parsed by setSoftOwnership, so the etcd Secrets without
+// an explicit OwnerReference or soft ownership are linked to the Cluster here; etcd Secrets with an owner
+// are moved with their owner.
+func (o *objectGraph) setManagedEtcdGroups() {
+	log := logf.Log
+	for _, cluster := range o.getClusters() {
+		if cluster.managedEtcdRef == nil {
+			continue
+		}
+
+		etcdCluster := o.getNodeByRef(cluster.managedEtcdRef)
+		if etcdCluster == nil {
+			log.V(5).Info("Managed external etcd not found, it won't be moved before the control plane", "Cluster", cluster.identity.Name, "Namespace", cluster.identity.Namespace)
+			continue
+		}
+		etcdCluster.isManagedEtcdCluster = true
+
+		group := map[*node]empty{}
+		o.addToManagedEtcdGroup(group, etcdCluster)
+
+		etcdSecretNames := sets.NewString(
+			fmt.Sprintf("%s-etcd-init", cluster.identity.Name),
+			secretutil.Name(cluster.identity.Name, secretutil.ManagedExternalEtcdCA),
+		)
+		for _, node := range o.getNodes() {
+			if node.identity.Namespace != cluster.identity.Namespace {
+				continue
+			}
+			isEtcdSecret := node.identity.APIVersion == "v1" && node.identity.Kind == "Secret" && etcdSecretNames.Has(node.identity.Name)
+			isEtcdMachine := node.isManagedEtcd && node.isOwnedBy(cluster)
+			switch {
+			case isEtcdSecret && len(node.owners) == 0:
+				if len(node.softOwners) == 0 {
+					node.addSoftOwner(cluster)
+				}
+				o.addToManagedEtcdGroup(group, node)
+			case isEtcdMachine:
+				o.addToManagedEtcdGroup(group, node)
+			}
+		}
+
+		// Ensure the control plane is moved after all the objects of the managed external etcd, so the control plane
+		// is not reconciled in the target management cluster before the etcd cluster.
+		if cluster.controlPlaneRef == nil {
+			continue
+		}
+		controlPlane := o.getNodeByRef(cluster.controlPlaneRef)
+		if controlPlane == nil {
+			continue
+		}
+		for node := range group {
+			if node != controlPlane {
+				controlPlane.moveAfter[node] = empty{}
+			}
+		}
+	}
+}
+
+// addToManagedEtcdGroup adds a node and all its dependents/softDependents to the group of objects of a managed external etcd.
+// NOTE: Dependents having owners outside of the group, e.g. objects owned both by the etcd cluster and by the control plane,
+// are not added to the group, because they can't be moved before the control plane.
+func (o *objectGraph) addToManagedEtcdGroup(group map[*node]empty, node *node) {
+	if _, ok := group[node]; ok {
+		return
+	}
+	node.isManagedEtcd = true
+	group[node] = empty{}
+	for _, other := range o.getNodes() {
+		if !other.isOwnedBy(node) && !other.isSoftOwnedBy(node) {
+			continue
+		}
+		ownersInGroup := true
+		for owner := range other.owners {
+			if _, ok := group[owner]; !ok {
+				ownersInGroup = false
+				break
+			}
+		}
+		if ownersInGroup {
+			o.addToManagedEtcdGroup(group, other)
+		}
+	}
+}
+
+// setClusterClassSoftOwnership links each ClusterClass with the templates referenced in its spec and with the Clusters
+// using it in their topology, so the ClusterClass is moved before the Clusters and all of them are tenants of the ClusterClass.
+func (o *objectGraph) setClusterClassSoftOwnership() {
+	log := logf.Log
+	for _, node := range o.getNodes() {
+		for _, ref := range node.templateRefs {
+			if template := o.getNodeByRef(ref); template != nil {
+				template.addSoftOwner(node)
+			}
+		}
+	}
+
+	for _, cluster := range o.getClusters() {
+		if cluster.clusterClassRef == nil {
+			continue
+		}
+		clusterClass := o.getNodeByRef(cluster.clusterClassRef)
+		if clusterClass == nil {
+			log.V(5).Info("ClusterClass not found, it won't be moved with the Cluster", "Cluster", cluster.identity.Name, "Namespace", cluster.identity.Namespace)
+			continue
+		}
+		cluster.addSoftOwner(clusterClass)
+	}
+}
+
 // setTenants identifies all the nodes linked to a parent with forceMoveHierarchy = true (e.g. Clusters or ClusterResourceSet)
 // via the owner ref chain.
 func (o *objectGraph) setTenants() {
@@ -543,6 +797,176 @@ func (o *objectGraph) setTenant(node, tenant *node, isGlobalHierarchy bool) {
 	}
 }
 
+// ClusterSelector defines the Clusters to be moved; an empty selector selects all the Clusters.
+type ClusterSelector struct {
+	// Names of the Clusters to be moved.
+	Names []string `json:"names,omitempty"`
+
+	// LabelSelector selecting the Clusters to be moved, e.g. "env=test".
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// IsEmpty returns true if the selector selects all the Clusters.
+func (s *ClusterSelector) IsEmpty() bool {
+	return s == nil || (len(s.Names) == 0 && s.LabelSelector == "")
+}
+
+// String returns a description of the selector.
+func (s *ClusterSelector) String() string {
+	if s.IsEmpty() {
+		return "all"
+	}
+	parts := []string{}
+	if len(s.Names) > 0 {
+		parts = append(parts, fmt.Sprintf("names=%s", strings.Join(s.Names, ",")))
+	}
+	if s.LabelSelector != "" {
+		parts = append(parts, fmt.Sprintf("selector=%s", s.LabelSelector))
+	}
+	return strings.Join(parts, " ")
+}
+
+// getSelectedClusters returns the Clusters in the object graph selected either by name or by label.
+func (o *objectGraph) getSelectedClusters(selector *ClusterSelector) (map[*node]empty, error) {
+	labelSelector := labels.Nothing()
+	if selector.LabelSelector != "" {
+		var err error
+		labelSelector, err = labels.Parse(selector.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid Cluster label selector %q", selector.LabelSelector)
+		}
+	}
+
+	names := sets.NewString(selector.Names...)
+	selected := map[*node]empty{}
+	for _, cluster := range o.getClusters() {
+		if names.Has(cluster.identity.Name) || labelSelector.Matches(labels.Set(cluster.labels)) {
+			selected[cluster] = empty{}
+			names.Delete(cluster.identity.Name)
+		}
+	}
+
+	if names.Len() > 0 {
+		return nil, errors.Errorf("failed to find Clusters %s", strings.Join(names.List(), ", "))
+	}
+	if len(selected) == 0 {
+		return nil, errors.Errorf("no Clusters matching the label selector %q", selector.LabelSelector)
+	}
+	return selected, nil
+}
+
+// setClusterSelection restricts the object graph to the selected Clusters with all their dependents, and to the objects
+// used by the selected Clusters, e.g. ClusterClasses or ClusterResourceSets.
+// Objects used both by selected and not selected Clusters are marked as shared, so they are copied to the target cluster
+// but not deleted from the source cluster; all the other objects are removed from the graph.
+// NOTE: This func must be called after setTenants.
+func (o *objectGraph) setClusterSelection(selector *ClusterSelector) error {
+	if selector.IsEmpty() {
+		return nil
+	}
+
+	log := logf.Log
+	selected, err := o.getSelectedClusters(selector)
+	if err != nil {
+		return err
+	}
+
+	// Identifies the Clusters using each tenant which is not a Cluster (e.g. a ClusterClass or a ClusterResourceSet)
+	// by looking at the nodes belonging to both, e.g. the Cluster itself for a ClusterClass or the ClusterResourceSetBinding
+	// for a ClusterResourceSet.
+	usedBy := map[*node]map[*node]empty{}
+	for _, n := range o.getNodes() {
+		clusters, others := splitClusterTenants(n)
+		for _, other := range others {
+			if _, ok := usedBy[other]; !ok {
+				usedBy[other] = map[*node]empty{}
+			}
+			for _, cluster := range clusters {
+				usedBy[other][cluster] = empty{}
+			}
+		}
+	}
+
+	for _, node := range o.getMoveNodes() {
+		clusters, others := splitClusterTenants(node)
+
+		// If the node belongs to at least one Cluster, the Clusters it belongs to determine if the node should be moved.
+		if len(clusters) == 0 {
+			// Otherwise the node is used by the Clusters using its tenants; if it is not possible to determine which Clusters use
+			// the node, e.g. for an identity object that is referenced only in the spec of the infrastructure objects, the node is
+			// considered as shared.
+			// NOTE: a node without tenants gets here because it is labeled for force move.
+			if len(others) == 0 {
+				node.isShared = true
+				continue
+			}
+			unknown := false
+			for _, other := range others {
+				if len(usedBy[other]) == 0 {
+					unknown = true
+				}
+				for cluster := range usedBy[other] {
+					clusters = append(clusters, cluster)
+				}
+			}
+			if unknown {
+				node.isShared = true
+				continue
+			}
+		}
+
+		isSelected, isNotSelected := false, false
+		for _, cluster := range clusters {
+			if _, ok := selected[cluster]; ok {
+				isSelected = true
+			} else {
+				isNotSelected = true
+			}
+		}
+		switch {
+		case isSelected && isNotSelected:
+			node.isShared = true
+		case !isSelected:
+			o.removeNode(node)
+		}
+	}
+
+	// Removes the references to the tenants not existing anymore in the graph.
+	for _, node := range o.getNodes() {
+		for tenant := range node.tenant {
+			if _, ok := o.uidToNode[tenant.identity.UID]; !ok {
+				delete(node.tenant, tenant)
+			}
+		}
+	}
+
+	log.V(1).Info("Selected Clusters", "Selector", selector.String(), "Count", len(selected))
+	return nil
+}
+
+// splitClusterTenants returns the tenants of a node that are Clusters and the other tenants.
+func splitClusterTenants(n *node) ([]*node, []*node) {
+	clusters, others := []*node{}, []*node{}
+	for tenant := range n.tenant {
+		if tenant.identity.GroupVersionKind().GroupKind() == clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+			clusters = append(clusters, tenant)
+			continue
+		}
+		others = append(others, tenant)
+	}
+	return clusters, others
+}
+
+// removeNode removes a node from the object graph, together with all the references to it from the other nodes.
+func (o *objectGraph) removeNode(n *node) {
+	delete(o.uidToNode, n.identity.UID)
+	for _, other := range o.uidToNode {
+		delete(other.owners, n)
+		delete(other.softOwners, n)
+		delete(other.moveAfter, n)
+	}
+}
+
 // checkVirtualNode logs if nodes are still virtual.
 func (o *objectGraph) checkVirtualNode() {
 	log := logf.Log
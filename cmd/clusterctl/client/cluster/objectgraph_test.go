@@ -2044,3 +2044,139 @@ func Test_objectGraph_setGlobalIdentityTenants(t *testing.T) {
 		})
 	}
 }
+
+// getClusterSelectionTestObjs returns two clusters using the same ClusterClass and a ClusterResourceSet applied to
+// both the clusters.
+func getClusterSelectionTestObjs() []client.Object {
+	objs := []client.Object{}
+	objs = append(objs, test.NewFakeClusterClass("ns1", "class1").Objs()...)
+	objs = append(objs, test.NewFakeCluster("ns1", "cluster1").
+		WithLabels(map[string]string{"env": "test"}).
+		WithTopologyClass("class1").
+		WithMachineSets(
+			test.NewFakeMachineSet("ms1").
+				WithMachines(test.NewFakeMachine("m1")),
+		).Objs()...)
+	objs = append(objs, test.NewFakeCluster("ns1", "cluster2").
+		WithTopologyClass("class1").
+		WithMachineSets(
+			test.NewFakeMachineSet("ms2").
+				WithMachines(test.NewFakeMachine("m2")),
+		).Objs()...)
+
+	objs = append(objs, test.NewFakeClusterResourceSet("ns1", "crs1").
+		WithSecret("resource-s1").
+		ApplyToCluster(test.SelectClusterObj(objs, "ns1", "cluster1")).
+		ApplyToCluster(test.SelectClusterObj(objs, "ns1", "cluster2")).
+		Objs()...)
+	return objs
+}
+
+func Test_objectGraph_setClusterSelection(t *testing.T) {
+	// Objects moved together with cluster1.
+	cluster1Objs := []string{
+		"cluster.x-k8s.io/v1beta1, Kind=Cluster, ns1/cluster1",
+		"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureCluster, ns1/cluster1",
+		"/v1, Kind=Secret, ns1/cluster1-ca",
+		"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+		"cluster.x-k8s.io/v1beta1, Kind=MachineSet, ns1/ms1",
+		"cluster.x-k8s.io/v1beta1, Kind=Machine, ns1/m1",
+		"addons.cluster.x-k8s.io/v1beta1, Kind=ClusterResourceSetBinding, ns1/cluster1",
+	}
+	// Objects used by both the clusters.
+	sharedObjs := []string{
+		"cluster.x-k8s.io/v1beta1, Kind=ClusterClass, ns1/class1",
+		"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureMachineTemplate, ns1/class1-worker",
+		"bootstrap.cluster.x-k8s.io/v1beta1, Kind=GenericBootstrapConfigTemplate, ns1/class1-worker",
+		"addons.cluster.x-k8s.io/v1beta1, Kind=ClusterResourceSet, ns1/crs1",
+		"/v1, Kind=Secret, ns1/resource-s1",
+	}
+	// Objects used only by cluster2.
+	cluster2Objs := []string{
+		"cluster.x-k8s.io/v1beta1, Kind=Cluster, ns1/cluster2",
+		"cluster.x-k8s.io/v1beta1, Kind=MachineSet, ns1/ms2",
+		"cluster.x-k8s.io/v1beta1, Kind=Machine, ns1/m2",
+		"addons.cluster.x-k8s.io/v1beta1, Kind=ClusterResourceSetBinding, ns1/cluster2",
+	}
+
+	tests := []struct {
+		name        string
+		selector    *ClusterSelector
+		wantMoved   []string
+		wantShared  []string
+		wantRemoved []string
+		wantErr     bool
+	}{
+		{
+			name:      "Empty selector selects all the clusters",
+			selector:  &ClusterSelector{},
+			wantMoved: append(append(append([]string{}, cluster1Objs...), sharedObjs...), cluster2Objs...),
+		},
+		{
+			name:        "Select a cluster by name",
+			selector:    &ClusterSelector{Names: []string{"cluster1"}},
+			wantMoved:   cluster1Objs,
+			wantShared:  sharedObjs,
+			wantRemoved: cluster2Objs,
+		},
+		{
+			name:        "Select a cluster by label",
+			selector:    &ClusterSelector{LabelSelector: "env=test"},
+			wantMoved:   cluster1Objs,
+			wantShared:  sharedObjs,
+			wantRemoved: cluster2Objs,
+		},
+		{
+			name:      "Select all the clusters by name",
+			selector:  &ClusterSelector{Names: []string{"cluster1", "cluster2"}},
+			wantMoved: append(append(append([]string{}, cluster1Objs...), sharedObjs...), cluster2Objs...),
+		},
+		{
+			name:     "Fails if a cluster does not exist",
+			selector: &ClusterSelector{Names: []string{"cluster1", "cluster3"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Fails if no cluster matches the label selector",
+			selector: &ClusterSelector{LabelSelector: "env=prod"},
+			wantErr:  true,
+		},
+		{
+			name:     "Fails if the label selector is not valid",
+			selector: &ClusterSelector{LabelSelector: "env=="},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			graph := getObjectGraphWithObjs(getClusterSelectionTestObjs())
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			err := graph.setClusterSelection(tt.selector)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			moveNodes := map[string]*node{}
+			for _, n := range graph.getMoveNodes() {
+				moveNodes[string(n.identity.UID)] = n
+			}
+			for _, uid := range tt.wantMoved {
+				g.Expect(moveNodes).To(HaveKey(uid))
+				g.Expect(moveNodes[uid].isShared).To(BeFalse(), "%s should not be shared", uid)
+			}
+			for _, uid := range tt.wantShared {
+				g.Expect(moveNodes).To(HaveKey(uid))
+				g.Expect(moveNodes[uid].isShared).To(BeTrue(), "%s should be shared", uid)
+			}
+			for _, uid := range tt.wantRemoved {
+				g.Expect(graph.uidToNode).NotTo(HaveKey(types.UID(uid)))
+			}
+		})
+	}
+}
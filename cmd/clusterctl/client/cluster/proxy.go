@@ -48,6 +48,9 @@ type proxy struct {
 	kubeconfig         Kubeconfig
 	timeout            time.Duration
 	configLoadingRules *clientcmd.ClientConfigLoadingRules
+
+	// restConfig, if set, is used instead of the one read from the kubeconfig file.
+	restConfig *rest.Config
 }
 
 var _ Proxy = &proxy{}
@@ -107,6 +110,10 @@ func (k *proxy) ValidateKubernetesVersion() error {
 
 // GetConfig returns the config for a kubernetes client.
 func (k *proxy) GetConfig() (*rest.Config, error) {
+	if k.restConfig != nil {
+		return rest.CopyConfig(k.restConfig), nil
+	}
+
 	config, err := k.configLoadingRules.Load()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load Kubeconfig")
@@ -323,6 +330,19 @@ func InjectKubeconfigPaths(paths []string) ProxyOption {
 	}
 }
 
+// InjectRESTConfig sets the rest.Config used for connecting to the management cluster, e.g. the in-cluster
+// config of a controller, instead of reading it from the kubeconfig file.
+func InjectRESTConfig(config *rest.Config) ProxyOption {
+	return func(p *proxy) {
+		p.restConfig = config
+	}
+}
+
+// NewProxy returns a Proxy for the management cluster defined by the kubeconfig or by the injected rest.Config.
+func NewProxy(kubeconfig Kubeconfig, opts ...ProxyOption) Proxy {
+	return newProxy(kubeconfig, opts...)
+}
+
 func newProxy(kubeconfig Kubeconfig, opts ...ProxyOption) Proxy {
 	// If a kubeconfig file isn't provided, find one in the standard locations.
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
@@ -0,0 +1,290 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
+	"sigs.k8s.io/cluster-api/controllers/topology"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// TopologyClient has methods to work with managed topologies, e.g. to preview
+// the effects of changes to Clusters and ClusterClasses before applying them.
+type TopologyClient interface {
+	// Plan computes the changes the topology controller would apply to the Clusters affected by the
+	// given objects, without applying them.
+	Plan(in *TopologyPlanInput) (*TopologyPlanOutput, error)
+}
+
+// TopologyPlanInput defines the input for the Plan function.
+type TopologyPlanInput struct {
+	// Objs are the modified (or new) objects, e.g. Clusters, ClusterClasses or templates, to be used
+	// instead of the corresponding objects in the management cluster.
+	Objs []*unstructured.Unstructured
+
+	// TargetClusterName is the name of the Cluster to compute the plan for; if empty, the plan is computed
+	// for all the Clusters affected by the given objects.
+	TargetClusterName string
+
+	// TargetNamespace is the namespace used for objects without a namespace.
+	TargetNamespace string
+}
+
+// TopologyPlanOutput defines the output of the Plan function.
+type TopologyPlanOutput struct {
+	// Plans contains the plan for each of the affected Clusters.
+	Plans []*topology.Plan
+}
+
+// topologyClient implements TopologyClient.
+type topologyClient struct {
+	proxy Proxy
+}
+
+// ensure topologyClient implements TopologyClient.
+var _ TopologyClient = &topologyClient{}
+
+// newTopologyClient returns a topologyClient.
+func newTopologyClient(proxy Proxy) *topologyClient {
+	return &topologyClient{
+		proxy: proxy,
+	}
+}
+
+func (t *topologyClient) Plan(in *TopologyPlanInput) (*TopologyPlanOutput, error) {
+	if len(in.Objs) == 0 {
+		return nil, errors.New("invalid Plan operation: objects are required")
+	}
+
+	c, err := t.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set the namespace of the input objects, if missing.
+	for _, obj := range in.Objs {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(in.TargetNamespace)
+		}
+	}
+
+	reader := newOverlayReader(c, scheme.Scheme, in.Objs)
+
+	clusters, err := t.affectedClusters(reader, in)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, errors.New("the given objects do not affect any Cluster with a managed topology")
+	}
+
+	out := &TopologyPlanOutput{}
+	for _, cluster := range clusters {
+		plan, err := topology.ComputePlan(ctx, reader, scheme.Scheme, cluster)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute the plan for Cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+		out.Plans = append(out.Plans, plan)
+	}
+	return out, nil
+}
+
+// affectedClusters returns the Clusters with a managed topology which are defined in the input objects,
+// or which are using one of the ClusterClasses defined in the input objects.
+func (t *topologyClient) affectedClusters(reader client.Reader, in *TopologyPlanInput) ([]*clusterv1.Cluster, error) {
+	clusterGK := clusterv1.GroupVersion.WithKind("Cluster").GroupKind()
+	clusterClassGK := clusterv1.GroupVersion.WithKind("ClusterClass").GroupKind()
+
+	namespaces := map[string]bool{}
+	classes := map[string]bool{}
+	for _, obj := range in.Objs {
+		switch obj.GroupVersionKind().GroupKind() {
+		case clusterGK:
+			namespaces[obj.GetNamespace()] = true
+		case clusterClassGK:
+			namespaces[obj.GetNamespace()] = true
+			classes[client.ObjectKeyFromObject(obj).String()] = true
+		}
+	}
+
+	clusters := []*clusterv1.Cluster{}
+	for namespace := range namespaces {
+		clusterList := &clusterv1.ClusterList{}
+		if err := reader.List(ctx, clusterList, client.InNamespace(namespace)); err != nil {
+			return nil, errors.Wrapf(err, "failed to list Clusters in namespace %q", namespace)
+		}
+		for i := range clusterList.Items {
+			cluster := &clusterList.Items[i]
+			if cluster.Spec.Topology == nil {
+				continue
+			}
+			if in.TargetClusterName != "" && cluster.Name != in.TargetClusterName {
+				continue
+			}
+			if !isInputObject(in.Objs, clusterGK, cluster) &&
+				!classes[client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.Topology.Class}.String()] {
+				continue
+			}
+
+			// Apply the same defaulting the webhook would apply to the Cluster.
+			cluster.Default()
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+func isInputObject(objs []*unstructured.Unstructured, gk schema.GroupKind, obj client.Object) bool {
+	for _, o := range objs {
+		if o.GroupVersionKind().GroupKind() == gk && o.GetNamespace() == obj.GetNamespace() && o.GetName() == obj.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// overlayReader is a client.Reader returning the given objects instead of the corresponding objects
+// read from the underlying reader, as if the objects were applied to the management cluster.
+type overlayReader struct {
+	client.Reader
+	scheme *runtime.Scheme
+	objs   []*unstructured.Unstructured
+}
+
+// newOverlayReader returns an overlayReader.
+func newOverlayReader(reader client.Reader, scheme *runtime.Scheme, objs []*unstructured.Unstructured) *overlayReader {
+	return &overlayReader{
+		Reader: reader,
+		scheme: scheme,
+		objs:   objs,
+	}
+}
+
+func (r *overlayReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	for _, o := range r.objs {
+		if o.GroupVersionKind().GroupKind() != gvk.GroupKind() || client.ObjectKeyFromObject(o) != key {
+			continue
+		}
+		return r.convert(o, gvk, obj)
+	}
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func (r *overlayReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := r.Reader.List(ctx, list, opts...); err != nil {
+		return err
+	}
+
+	gvk, err := apiutil.GVKForObject(list, r.scheme)
+	if err != nil {
+		return err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, o := range r.objs {
+		if o.GroupVersionKind().GroupKind() != gvk.GroupKind() {
+			continue
+		}
+		if listOpts.Namespace != "" && o.GetNamespace() != listOpts.Namespace {
+			continue
+		}
+		if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+
+		item, err := r.newObject(list, gvk)
+		if err != nil {
+			return err
+		}
+		if err := r.convert(o, gvk, item); err != nil {
+			return err
+		}
+
+		// Replace the corresponding item in the list, if any, otherwise add the object to the list.
+		replaced := false
+		for i := range items {
+			existing, ok := items[i].(client.Object)
+			if ok && client.ObjectKeyFromObject(existing) == client.ObjectKeyFromObject(o) {
+				items[i] = item
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			items = append(items, item)
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return meta.SetList(list, items)
+}
+
+// newObject returns a new object of the given kind, using the same representation (typed or unstructured) of the list.
+func (r *overlayReader) newObject(list client.ObjectList, gvk schema.GroupVersionKind) (client.Object, error) {
+	if _, ok := list.(*unstructured.UnstructuredList); ok {
+		return &unstructured.Unstructured{}, nil
+	}
+	obj, err := r.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	clientObj, ok := obj.(client.Object)
+	if !ok {
+		return nil, errors.Errorf("%T is not a client.Object", obj)
+	}
+	return clientObj, nil
+}
+
+// convert copies the content of an input object into obj.
+func (r *overlayReader) convert(in *unstructured.Unstructured, gvk schema.GroupVersionKind, obj client.Object) error {
+	if in.GroupVersionKind() != gvk {
+		return errors.Errorf("%s %s/%s must be defined using apiVersion %s", in.GetKind(), in.GetNamespace(), in.GetName(), gvk.GroupVersion())
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		in.DeepCopyInto(u)
+		return nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(in.UnstructuredContent(), obj); err != nil {
+		return errors.Wrapf(err, "failed to convert %s %s/%s", in.GetKind(), in.GetNamespace(), in.GetName())
+	}
+	return nil
+}
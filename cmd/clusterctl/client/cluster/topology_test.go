@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_overlayReader(t *testing.T) {
+	g := NewWithT(t)
+
+	liveClass := &clusterv1.ClusterClass{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterClass", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "class1"},
+	}
+	liveCluster := fakeTopologyCluster("ns1", "cluster1", "class1", "v1.21.1")
+
+	modifiedCluster := toUnstructured(g, fakeTopologyCluster("ns1", "cluster1", "class1", "v1.22.0"))
+	newCluster := toUnstructured(g, fakeTopologyCluster("ns1", "cluster2", "class1", "v1.22.0"))
+
+	proxy := test.NewFakeProxy().WithObjs(liveClass, liveCluster)
+	c, err := proxy.NewClient()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	reader := newOverlayReader(c, scheme.Scheme, []*unstructured.Unstructured{modifiedCluster, newCluster})
+
+	// Objects not in the input are read from the management cluster.
+	class := &clusterv1.ClusterClass{}
+	g.Expect(reader.Get(ctx, client.ObjectKeyFromObject(liveClass), class)).To(Succeed())
+	g.Expect(class.Name).To(Equal("class1"))
+
+	// Objects in the input are returned instead of the objects in the management cluster.
+	cluster := &clusterv1.Cluster{}
+	g.Expect(reader.Get(ctx, client.ObjectKeyFromObject(liveCluster), cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Topology.Version).To(Equal("v1.22.0"))
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("Cluster"))
+	g.Expect(reader.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "cluster2"}, u)).To(Succeed())
+	g.Expect(u.GetName()).To(Equal("cluster2"))
+
+	// Lists include the input objects, replacing the corresponding objects in the management cluster.
+	clusters := &clusterv1.ClusterList{}
+	g.Expect(reader.List(ctx, clusters, client.InNamespace("ns1"))).To(Succeed())
+	g.Expect(clusters.Items).To(HaveLen(2))
+	for _, cluster := range clusters.Items {
+		g.Expect(cluster.Spec.Topology.Version).To(Equal("v1.22.0"))
+	}
+
+	clusters = &clusterv1.ClusterList{}
+	g.Expect(reader.List(ctx, clusters, client.InNamespace("ns2"))).To(Succeed())
+	g.Expect(clusters.Items).To(BeEmpty())
+
+	// Input objects must use the requested apiVersion.
+	oldCluster := modifiedCluster.DeepCopy()
+	oldCluster.SetAPIVersion("cluster.x-k8s.io/v1alpha4")
+	reader = newOverlayReader(c, scheme.Scheme, []*unstructured.Unstructured{oldCluster})
+	g.Expect(reader.Get(ctx, client.ObjectKeyFromObject(liveCluster), &clusterv1.Cluster{})).ToNot(Succeed())
+}
+
+func Test_topologyClient_affectedClusters(t *testing.T) {
+	class1 := &clusterv1.ClusterClass{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterClass", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "class1"},
+	}
+	cluster1 := fakeTopologyCluster("ns1", "cluster1", "class1", "v1.21.1")
+	cluster2 := fakeTopologyCluster("ns1", "cluster2", "class1", "v1.21.1")
+	cluster3 := fakeTopologyCluster("ns1", "cluster3", "class2", "v1.21.1")
+	unmanagedCluster := &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster4"},
+	}
+
+	tests := []struct {
+		name              string
+		objs              func(g *WithT) []*unstructured.Unstructured
+		targetClusterName string
+		want              []string
+	}{
+		{
+			name: "Return the Clusters in the input",
+			objs: func(g *WithT) []*unstructured.Unstructured {
+				return []*unstructured.Unstructured{toUnstructured(g, cluster3)}
+			},
+			want: []string{"cluster3"},
+		},
+		{
+			name: "Return the Clusters using a ClusterClass in the input",
+			objs: func(g *WithT) []*unstructured.Unstructured {
+				return []*unstructured.Unstructured{toUnstructured(g, class1)}
+			},
+			want: []string{"cluster1", "cluster2"},
+		},
+		{
+			name: "Return only the target Cluster",
+			objs: func(g *WithT) []*unstructured.Unstructured {
+				return []*unstructured.Unstructured{toUnstructured(g, class1)}
+			},
+			targetClusterName: "cluster2",
+			want:              []string{"cluster2"},
+		},
+		{
+			name: "Ignore Clusters without a managed topology",
+			objs: func(g *WithT) []*unstructured.Unstructured {
+				return []*unstructured.Unstructured{toUnstructured(g, unmanagedCluster)}
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().WithObjs(class1, cluster1, cluster2, cluster3, unmanagedCluster)
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			in := &TopologyPlanInput{
+				Objs:              tt.objs(g),
+				TargetClusterName: tt.targetClusterName,
+			}
+			topologyClient := newTopologyClient(proxy)
+			clusters, err := topologyClient.affectedClusters(newOverlayReader(c, scheme.Scheme, in.Objs), in)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := []string{}
+			for _, cluster := range clusters {
+				got = append(got, cluster.Name)
+			}
+			g.Expect(got).To(ConsistOf(tt.want))
+		})
+	}
+}
+
+func fakeTopologyCluster(namespace, name, class, version string) *clusterv1.Cluster {
+	return &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{Class: class, Version: version},
+		},
+	}
+}
+
+func toUnstructured(g *WithT, obj runtime.Object) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	g.Expect(err).ToNot(HaveOccurred())
+	return &unstructured.Unstructured{Object: content}
+}
@@ -150,7 +150,8 @@ type GetClusterTemplateOptions struct {
 	ListVariablesOnly bool
 
 	// YamlProcessor defines the yaml processor to use for the cluster
-	// template processing. If not defined, SimpleProcessor will be used.
+	// template processing. If not defined, the processor configured for the provider
+	// in the clusterctl config will be used, defaulting to SimpleProcessor.
 	YamlProcessor Processor
 }
 
@@ -322,6 +323,20 @@ func (c *clusterctlClient) getTemplateFromRepository(cluster cluster.Client, opt
 		return nil, err
 	}
 
+	// If no yaml processor is explicitly set, use the one configured for the provider, if any.
+	if processor == nil {
+		processorConfig, err := c.configClient.Processors().Get(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		if processorConfig != nil {
+			processor, err = yaml.NewProcessor(processorConfig.Type, processorConfig.Command)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid processor configuration for the %s provider", providerConfig.ManifestLabel())
+			}
+		}
+	}
+
 	repo, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig, Processor: processor})
 	if err != nil {
 		return nil, err
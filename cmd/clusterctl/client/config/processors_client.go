@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ProcessorsConfigKey defines the name of the top level config key for the yaml processors configuration.
+const ProcessorsConfigKey = "processors"
+
+// ProcessorsClient has methods to work with the yaml processors configurations.
+type ProcessorsClient interface {
+	// Get returns the configuration of the yaml processor to be used for the cluster templates of a provider.
+	// If no yaml processor is configured for the provider, nil is returned.
+	Get(provider Provider) (*ProcessorConfig, error)
+}
+
+// ProcessorConfig defines the yaml processor to be used for the cluster templates of a provider.
+type ProcessorConfig struct {
+	// Type of the yaml processor, e.g. simple, go-template or external.
+	Type string `json:"type"`
+
+	// Command to be executed by an external yaml processor.
+	Command string `json:"command,omitempty"`
+}
+
+// processorsClient implements ProcessorsClient.
+type processorsClient struct {
+	reader Reader
+}
+
+// ensure processorsClient implements ProcessorsClient.
+var _ ProcessorsClient = &processorsClient{}
+
+func newProcessorsClient(reader Reader) *processorsClient {
+	return &processorsClient{
+		reader: reader,
+	}
+}
+
+func (p *processorsClient) Get(provider Provider) (*ProcessorConfig, error) {
+	processors := map[string]ProcessorConfig{}
+	if err := p.reader.UnmarshalKey(ProcessorsConfigKey, &processors); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal processors from the clusterctl configuration file")
+	}
+
+	// Processors are configured using the provider label, e.g. infrastructure-aws.
+	processor, ok := processors[provider.ManifestLabel()]
+	if !ok {
+		return nil, nil
+	}
+	if processor.Type == "" {
+		return nil, errors.Errorf("invalid processor configuration for the %s provider: type value cannot be empty", provider.ManifestLabel())
+	}
+	return &processor, nil
+}
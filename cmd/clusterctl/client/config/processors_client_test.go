@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_processorsClient_Get(t *testing.T) {
+	provider := NewProvider("aws", "url", clusterctlv1.InfrastructureProviderType)
+
+	tests := []struct {
+		name    string
+		reader  Reader
+		want    *ProcessorConfig
+		wantErr bool
+	}{
+		{
+			name:   "no processors config: returns nil",
+			reader: test.NewFakeReader(),
+			want:   nil,
+		},
+		{
+			name:   "processor config for another provider: returns nil",
+			reader: test.NewFakeReader().WithVar(ProcessorsConfigKey, "infrastructure-docker:\n  type: go-template\n"),
+			want:   nil,
+		},
+		{
+			name:   "processor config for the provider: returns the processor config",
+			reader: test.NewFakeReader().WithVar(ProcessorsConfigKey, "infrastructure-aws:\n  type: external\n  command: /bin/processor\n"),
+			want:   &ProcessorConfig{Type: "external", Command: "/bin/processor"},
+		},
+		{
+			name:    "processor config without type: returns error",
+			reader:  test.NewFakeReader().WithVar(ProcessorsConfigKey, "infrastructure-aws:\n  command: /bin/processor\n"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid processors config: returns error",
+			reader:  test.NewFakeReader().WithVar(ProcessorsConfigKey, "infrastructure-aws: not-an-object\n"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			p := newProcessorsClient(tt.reader)
+			got, err := p.Get(provider)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
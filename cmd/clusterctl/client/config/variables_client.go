@@ -19,6 +19,49 @@ package config
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token.
 	GitHubTokenVariable = "github-token"
+
+	// GitLabTokenVariable defines a variable hosting the GitLab access token.
+	GitLabTokenVariable = "gitlab-token"
+
+	// HTTPSProxyVariable defines a variable hosting the proxy used to reach HTTP(S), GitLab and OCI repositories.
+	HTTPSProxyVariable = "https-proxy"
+
+	// CABundleVariable defines a variable hosting the path of a PEM file with additional CA certificates
+	// trusted when connecting to HTTP(S), GitLab and OCI repositories.
+	CABundleVariable = "ca-bundle"
+
+	// OCIUsernameVariable defines a variable hosting the username used to authenticate to OCI registries.
+	OCIUsernameVariable = "oci-username"
+
+	// OCIPasswordVariable defines a variable hosting the password used to authenticate to OCI registries.
+	OCIPasswordVariable = "oci-password"
+
+	// OCIInsecureVariable defines a variable that, when true, allows to access OCI registries using plain HTTP.
+	// Local registries, e.g. localhost:5000, are always allowed to use plain HTTP.
+	OCIInsecureVariable = "oci-insecure"
+
+	// S3EndpointVariable defines a variable hosting the URL of the S3 compatible object storage used for backups, e.g. a MinIO server.
+	// If not defined, the AWS S3 endpoint for the region is used.
+	S3EndpointVariable = "s3-endpoint"
+
+	// S3RegionVariable defines a variable hosting the region of the S3 bucket used for backups.
+	S3RegionVariable = "s3-region"
+
+	// S3AccessKeyIDVariable defines a variable hosting the access key id used to authenticate to the S3 compatible object storage.
+	S3AccessKeyIDVariable = "s3-access-key-id"
+
+	// S3SecretAccessKeyVariable defines a variable hosting the secret access key used to authenticate to the S3 compatible object storage.
+	S3SecretAccessKeyVariable = "s3-secret-access-key"
+
+	// S3SessionTokenVariable defines a variable hosting the optional session token used to authenticate to the S3 compatible object storage.
+	S3SessionTokenVariable = "s3-session-token"
+
+	// PGPPassphraseVariable defines a variable hosting the passphrase of the PGP private key used for decrypting backups.
+	PGPPassphraseVariable = "pgp-passphrase"
+
+	// EtcdEndpointsTimeoutVariable defines a variable hosting the time to wait at the end of a move for the managed
+	// external etcd clusters to report their endpoints in the target management cluster, e.g. 15m.
+	EtcdEndpointsTimeoutVariable = "etcd-endpoints-timeout"
 )
 
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 )
 
@@ -788,6 +789,12 @@ func newFakeClientWithoutCluster(configClient config.Client) *fakeClient {
 			if _, ok := fake.repositories[input.Provider.ManifestLabel()]; !ok {
 				return nil, errors.Errorf("Repository for kubeconfig %q does not exist.", input.Provider.ManifestLabel())
 			}
+			// if a yaml processor is provided, use it in place of the one defined in the fake repository.
+			if r, ok := fake.repositories[input.Provider.ManifestLabel()].(*fakeRepositoryClient); ok && input.Processor != nil {
+				repo := *r
+				repo.processor = input.Processor
+				return &repo, nil
+			}
 			return fake.repositories[input.Provider.ManifestLabel()], nil
 		}),
 	)
@@ -886,6 +893,82 @@ func Test_clusterctlClient_GetClusterTemplate_withoutCluster(t *testing.T) {
 	}
 }
 
+func Test_clusterctlClient_GetClusterTemplate_withProcessors(t *testing.T) {
+	rawTemplate := []byte("apiVersion: v1\n" +
+		"kind: Cluster\n" +
+		"metadata:\n" +
+		"  name: {{ .CLUSTER_NAME }}\n" +
+		"  namespace: {{ .NAMESPACE | default \"ns3\" }}")
+
+	config1 := newFakeConfig().
+		WithProvider(infraProviderConfig)
+
+	repository1 := newFakeRepository(infraProviderConfig, config1).
+		WithPaths("root", "components").
+		WithDefaultVersion("v3.0.0").
+		WithFile("v3.0.0", "cluster-template.yaml", rawTemplate)
+
+	tests := []struct {
+		name          string
+		processors    string
+		yamlProcessor Processor
+		wantVariables []string
+		wantYaml      []byte
+		wantErr       bool
+	}{
+		{
+			name:          "uses the processor configured for the provider",
+			processors:    "infrastructure-infra:\n  type: go-template\n",
+			wantVariables: []string{"CLUSTER_NAME", "NAMESPACE"},
+			wantYaml:      templateYAML("ns1", "test"),
+		},
+		{
+			name:          "uses the processor explicitly set in the options",
+			yamlProcessor: yaml.NewGoTemplateProcessor(),
+			wantVariables: []string{"CLUSTER_NAME", "NAMESPACE"},
+			wantYaml:      templateYAML("ns1", "test"),
+		},
+		{
+			name:       "fails if the processor configured for the provider is not valid",
+			processors: "infrastructure-infra:\n  type: not-valid\n",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.processors != "" {
+				config1.WithVar(config.ProcessorsConfigKey, tt.processors)
+			}
+			defer config1.WithVar(config.ProcessorsConfigKey, "")
+
+			client := newFakeClientWithoutCluster(config1).
+				WithRepository(repository1)
+
+			got, err := client.GetClusterTemplate(GetClusterTemplateOptions{
+				ProviderRepositorySource: &ProviderRepositorySourceOptions{
+					InfrastructureProvider: "infra:v3.0.0",
+				},
+				ClusterName:     "test",
+				TargetNamespace: "ns1",
+				YamlProcessor:   tt.yamlProcessor,
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(got.Variables()).To(Equal(tt.wantVariables))
+
+			gotYaml, err := got.Yaml()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gotYaml).To(Equal(tt.wantYaml))
+		})
+	}
+}
+
 func Test_clusterctlClient_ProcessYAML(t *testing.T) {
 	g := NewWithT(t)
 	template := `v1: ${VAR1:=default1}
@@ -17,9 +17,24 @@ limitations under the License.
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
 // DescribeClusterOptions carries the options supported by DescribeCluster.
@@ -49,6 +64,74 @@ type DescribeClusterOptions struct {
 
 // DescribeCluster returns the object tree representing the status of a Cluster API cluster.
 func (c *clusterctlClient) DescribeCluster(options DescribeClusterOptions) (*tree.ObjectTree, error) {
+	// gets access to the management cluster
+	cluster, err := c.getDescribeClusterClient(&options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the Cluster client.
+	client, err := cluster.Proxy().NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Gets the object tree representing the status of a Cluster API cluster.
+	return tree.Discovery(context.TODO(), client, options.Namespace, options.ClusterName, options.toDiscoverOptions())
+}
+
+// WatchCluster calls onChange with the object tree representing the status of a Cluster API cluster, and then
+// every time the tree changes, until the context is done or onChange returns an error.
+func (c *clusterctlClient) WatchCluster(ctx context.Context, options DescribeClusterOptions, onChange func(*tree.ObjectTree) error) error {
+	// gets access to the management cluster
+	cluster, err := c.getDescribeClusterClient(&options)
+	if err != nil {
+		return err
+	}
+
+	config, err := cluster.Proxy().GetConfig()
+	if err != nil {
+		return err
+	}
+
+	// Create a cache for the objects in the Cluster's namespace; the informers for each kind are created
+	// the first time the kind is read, i.e. while discovering the object tree.
+	informerCache, err := cache.New(config, cache.Options{Scheme: scheme.Scheme, Namespace: options.Namespace})
+	if err != nil {
+		return errors.Wrap(err, "failed to create the cache for watching the Cluster")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		_ = informerCache.Start(ctx)
+	}()
+	if !informerCache.WaitForCacheSync(ctx) {
+		return errors.New("failed to start the cache for watching the Cluster")
+	}
+
+	// Fetch a Cluster client reading from the cache, including the unstructured objects, e.g. the control plane.
+	directClient, err := cluster.Proxy().NewClient()
+	if err != nil {
+		return err
+	}
+	client, err := ctrlclient.NewDelegatingClient(ctrlclient.NewDelegatingClientInput{
+		CacheReader:       informerCache,
+		Client:            directClient,
+		CacheUnstructured: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return watchObjectTree(ctx, client, informerCache, func(ctx context.Context, c ctrlclient.Client) (*tree.ObjectTree, error) {
+		return tree.Discovery(ctx, c, options.Namespace, options.ClusterName, options.toDiscoverOptions())
+	}, onChange)
+}
+
+// getDescribeClusterClient returns the client for the management cluster, and eventually sets the Namespace
+// option to the current namespace.
+func (c *clusterctlClient) getDescribeClusterClient(options *DescribeClusterOptions) (cluster.Client, error) {
 	// gets access to the management cluster
 	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
@@ -68,17 +151,132 @@ func (c *clusterctlClient) DescribeCluster(options DescribeClusterOptions) (*tre
 		}
 		options.Namespace = currentNamespace
 	}
+	return cluster, nil
+}
 
-	// Fetch the Cluster client.
-	client, err := cluster.Proxy().NewClient()
-	if err != nil {
-		return nil, err
+func (o DescribeClusterOptions) toDiscoverOptions() tree.DiscoverOptions {
+	return tree.DiscoverOptions{
+		ShowOtherConditions: o.ShowOtherConditions,
+		DisableNoEcho:       o.DisableNoEcho,
+		DisableGrouping:     o.DisableGrouping,
 	}
+}
 
-	// Gets the object tree representing the status of a Cluster API cluster.
-	return tree.Discovery(context.TODO(), client, options.Namespace, options.ClusterName, tree.DiscoverOptions{
-		ShowOtherConditions: options.ShowOtherConditions,
-		DisableNoEcho:       options.DisableNoEcho,
-		DisableGrouping:     options.DisableGrouping,
-	})
+// watchDebounce is the time waited after a change before discovering the object tree again,
+// so a burst of changes, e.g. when a Machine is created, triggers a single discovery.
+var watchDebounce = 500 * time.Millisecond
+
+// watchObjectTree discovers the object tree and calls onChange if it changed, and then waits for a change
+// to any object of the kinds read during the discovery before discovering the object tree again.
+func watchObjectTree(ctx context.Context, c ctrlclient.Client, informers cache.Informers, discover func(context.Context, ctrlclient.Client) (*tree.ObjectTree, error), onChange func(*tree.ObjectTree) error) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}
+
+	reader := &kindRecordingClient{Client: c, kinds: map[watchedKind]bool{}}
+	watched := map[watchedKind]bool{}
+	var last []byte
+	for {
+		objectTree, err := discover(ctx, reader)
+		if err != nil {
+			return err
+		}
+
+		// Watch the kinds read for the first time.
+		for kind := range reader.kinds {
+			if watched[kind] {
+				continue
+			}
+			informer, err := kind.getInformer(ctx, informers)
+			if err != nil {
+				return errors.Wrapf(err, "failed to watch %s", kind.gvk)
+			}
+			informer.AddEventHandler(handler)
+			watched[kind] = true
+		}
+
+		// Call onChange only if the object tree actually changed, e.g. not on resyncs.
+		current, err := json.Marshal(objectTree.ToObjectNode())
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, last) {
+			if err := onChange(objectTree); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchDebounce):
+		}
+		select {
+		case <-changed:
+		default:
+		}
+	}
+}
+
+// watchedKind is a kind read while discovering the object tree.
+type watchedKind struct {
+	gvk          schema.GroupVersionKind
+	unstructured bool
+}
+
+// getInformer returns the informer for the kind; unstructured objects, e.g. the control plane, have separate informers.
+func (k watchedKind) getInformer(ctx context.Context, informers cache.Informers) (cache.Informer, error) {
+	if k.unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(k.gvk)
+		return informers.GetInformer(ctx, obj)
+	}
+	return informers.GetInformerForKind(ctx, k.gvk)
+}
+
+// kindRecordingClient is a client recording the kinds of the objects read; kinds not served by
+// the API server, e.g. experimental kinds whose CRD is not installed, are not recorded.
+type kindRecordingClient struct {
+	ctrlclient.Client
+	kinds map[watchedKind]bool
+}
+
+func (c *kindRecordingClient) Get(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object) error {
+	err := c.Client.Get(ctx, key, obj)
+	c.record(obj, err)
+	return err
+}
+
+func (c *kindRecordingClient) List(ctx context.Context, list ctrlclient.ObjectList, opts ...ctrlclient.ListOption) error {
+	err := c.Client.List(ctx, list, opts...)
+	c.record(list, err)
+	return err
+}
+
+func (c *kindRecordingClient) record(obj runtime.Object, err error) {
+	if meta.IsNoMatchError(err) {
+		return
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	_, isUnstructured := obj.(runtime.Unstructured)
+	c.kinds[watchedKind{gvk: gvk, unstructured: isUnstructured}] = true
 }
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeInformers records the informers requested and their event handlers.
+type fakeInformers struct {
+	cache.Informers
+
+	lock     sync.Mutex
+	kinds    map[schema.GroupVersionKind]bool
+	handlers []toolscache.ResourceEventHandler
+}
+
+func (f *fakeInformers) GetInformer(ctx context.Context, obj ctrlclient.Object) (cache.Informer, error) {
+	return f.GetInformerForKind(ctx, obj.GetObjectKind().GroupVersionKind())
+}
+
+func (f *fakeInformers) GetInformerForKind(_ context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.kinds[gvk] = true
+	return &fakeInformer{informers: f}, nil
+}
+
+// notify sends an update event to all the event handlers.
+func (f *fakeInformers) notify() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, h := range f.handlers {
+		h.OnUpdate(nil, nil)
+	}
+}
+
+type fakeInformer struct {
+	cache.Informer
+	informers *fakeInformers
+}
+
+func (f *fakeInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	f.informers.lock.Lock()
+	defer f.informers.lock.Unlock()
+	f.informers.handlers = append(f.informers.handlers, handler)
+}
+
+func Test_watchObjectTree(t *testing.T) {
+	g := NewWithT(t)
+
+	defer func(d time.Duration) { watchDebounce = d }(watchDebounce)
+	watchDebounce = 10 * time.Millisecond
+
+	c, err := test.NewFakeProxy().WithObjs(
+		test.NewFakeCluster("ns1", "cluster1").
+			WithControlPlane(
+				test.NewFakeControlPlane("cp").
+					WithMachines(test.NewFakeMachine("cp1")),
+			).
+			Objs()...,
+	).NewClient()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	informers := &fakeInformers{kinds: map[schema.GroupVersionKind]bool{}}
+	discoveries := make(chan struct{}, 10)
+	discover := func(ctx context.Context, c ctrlclient.Client) (*tree.ObjectTree, error) {
+		defer func() { discoveries <- struct{}{} }()
+		return tree.Discovery(ctx, c, "ns1", "cluster1", tree.DiscoverOptions{})
+	}
+	trees := make(chan *tree.ObjectTree, 10)
+	onChange := func(objectTree *tree.ObjectTree) error {
+		trees <- objectTree
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watchObjectTree(ctx, c, informers, discover, onChange)
+	}()
+
+	// The object tree is returned immediately, and the kinds read during the discovery are watched.
+	<-discoveries
+	first := <-trees
+	g.Expect(tree.GetReadyCondition(first.GetRoot())).To(BeNil())
+	informers.lock.Lock()
+	g.Expect(informers.kinds).To(HaveKey(clusterv1.GroupVersion.WithKind("Cluster")))
+	g.Expect(informers.kinds).To(HaveKey(clusterv1.GroupVersion.WithKind("Machine")))
+	g.Expect(informers.kinds).To(HaveKey(schema.GroupVersionKind{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Kind: "GenericControlPlane"}))
+	informers.lock.Unlock()
+
+	// An event not changing the object tree triggers a discovery, but not onChange.
+	informers.notify()
+	<-discoveries
+	g.Consistently(trees, 50*time.Millisecond).ShouldNot(Receive())
+
+	// An event changing the object tree triggers onChange.
+	cluster := &clusterv1.Cluster{}
+	g.Expect(c.Get(ctx, ctrlclient.ObjectKey{Namespace: "ns1", Name: "cluster1"}, cluster)).To(Succeed())
+	conditions.MarkTrue(cluster, clusterv1.ReadyCondition)
+	g.Expect(c.Update(ctx, cluster)).To(Succeed())
+	informers.notify()
+	<-discoveries
+	var second *tree.ObjectTree
+	g.Eventually(trees).Should(Receive(&second))
+	g.Expect(tree.GetReadyCondition(second.GetRoot())).ToNot(BeNil())
+
+	cancel()
+	g.Expect(<-done).To(Succeed())
+}
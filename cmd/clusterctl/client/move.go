@@ -17,9 +17,13 @@ limitations under the License.
 package client
 
 import (
+	"bytes"
 	"os"
 
+	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/internal/backup"
 )
 
 // MoveOptions carries the options supported by move.
@@ -36,8 +40,28 @@ type MoveOptions struct {
 	// namespace will be used.
 	Namespace string
 
+	// ClusterNames defines the names of the Clusters to be moved, together with their dependents. If both ClusterNames
+	// and ClusterLabelSelector are empty, all the Clusters in the namespace will be moved.
+	// NOTE: Objects used also by Clusters not being moved, e.g. ClusterClasses or ClusterResourceSets, are copied
+	// to the target management cluster, but not deleted from the source management cluster.
+	ClusterNames []string
+
+	// ClusterLabelSelector defines a label selector for the Clusters to be moved, together with their dependents.
+	ClusterLabelSelector string
+
 	// DryRun means the move action is a dry run, no real action will be performed
 	DryRun bool
+
+	// CheckpointFile defines the local file where the progress of the move is recorded, so it is possible to resume
+	// or to rollback the move in case of failures. If empty, no checkpoint is recorded.
+	CheckpointFile string
+
+	// Resume resumes a failed move from the checkpoint recorded in CheckpointFile.
+	Resume bool
+
+	// Rollback rolls back a failed move recorded in CheckpointFile, deleting the objects already created in the
+	// target management cluster and resuming the Clusters in the source management cluster.
+	Rollback bool
 }
 
 // BackupOptions holds options supported by backup.
@@ -52,6 +76,16 @@ type BackupOptions struct {
 
 	// Directory defines the local directory to store the cluster objects
 	Directory string
+
+	// Archive defines the location of a gzip'd tar archive to store the cluster objects; it can be either a local
+	// file path or a s3://bucket/path/to/archive URL. Only one of Directory and Archive can be set.
+	Archive string
+
+	// AgeRecipients defines the age recipients, in the format "age1...", the archive is encrypted for.
+	AgeRecipients []string
+
+	// PGPPublicKeyFile defines the path of a file with the armored PGP public keys the archive is encrypted for.
+	PGPPublicKeyFile string
 }
 
 // RestoreOptions holds options supported by restore.
@@ -62,9 +96,35 @@ type RestoreOptions struct {
 
 	// Directory defines the local directory to restore cluster objects from
 	Directory string
+
+	// Archive defines the location of a gzip'd tar archive to restore cluster objects from; it can be either a local
+	// file path or a s3://bucket/path/to/archive URL. Only one of Directory and Archive can be set.
+	Archive string
+
+	// AgeIdentityFile defines the path of a file with the age identities used for decrypting the archive.
+	AgeIdentityFile string
+
+	// PGPPrivateKeyFile defines the path of a file with the armored PGP private keys used for decrypting the archive;
+	// if the keys are protected, the passphrase is read from the pgp-passphrase variable.
+	PGPPrivateKeyFile string
 }
 
 func (c *clusterctlClient) Move(options MoveOptions) error {
+	if options.Resume || options.Rollback {
+		if options.Resume && options.Rollback {
+			return errors.New("resume and rollback cannot be used at the same time")
+		}
+		if options.DryRun {
+			return errors.New("resume and rollback cannot be used with dry run")
+		}
+		if options.CheckpointFile == "" {
+			return errors.New("a checkpoint file is required for resuming or rolling back a move")
+		}
+		if len(options.ClusterNames) > 0 || options.ClusterLabelSelector != "" {
+			return errors.New("the Clusters to move cannot be selected when resuming or rolling back a move")
+		}
+	}
+
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.FromKubeconfig})
 	if err != nil {
@@ -100,6 +160,14 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		}
 	}
 
+	// The namespace of a move being resumed or rolled back is read from the checkpoint.
+	if options.Resume {
+		return fromCluster.ObjectMover().ResumeMove(toCluster, options.CheckpointFile)
+	}
+	if options.Rollback {
+		return fromCluster.ObjectMover().RollbackMove(toCluster, options.CheckpointFile)
+	}
+
 	// If the option specifying the Namespace is empty, try to detect it.
 	if options.Namespace == "" {
 		currentNamespace, err := fromCluster.Proxy().CurrentNamespace()
@@ -109,10 +177,25 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		options.Namespace = currentNamespace
 	}
 
-	return fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun)
+	selector := &cluster.ClusterSelector{
+		Names:         options.ClusterNames,
+		LabelSelector: options.ClusterLabelSelector,
+	}
+
+	return fromCluster.ObjectMover().Move(options.Namespace, selector, toCluster, options.DryRun, options.CheckpointFile)
 }
 
 func (c *clusterctlClient) Backup(options BackupOptions) error {
+	if (options.Directory == "") == (options.Archive == "") {
+		return errors.New("exactly one of directory and archive must be defined")
+	}
+	if options.Archive == "" && (len(options.AgeRecipients) > 0 || options.PGPPublicKeyFile != "") {
+		return errors.New("encryption is supported only when backing up to an archive")
+	}
+	if len(options.AgeRecipients) > 0 && options.PGPPublicKeyFile != "" {
+		return errors.New("age recipients and PGP public keys cannot be used at the same time")
+	}
+
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.FromKubeconfig})
 	if err != nil {
@@ -138,6 +221,10 @@ func (c *clusterctlClient) Backup(options BackupOptions) error {
 		options.Namespace = currentNamespace
 	}
 
+	if options.Archive != "" {
+		return c.backupArchive(fromCluster, options)
+	}
+
 	if _, err := os.Stat(options.Directory); os.IsNotExist(err) {
 		return err
 	}
@@ -145,7 +232,55 @@ func (c *clusterctlClient) Backup(options BackupOptions) error {
 	return fromCluster.ObjectMover().Backup(options.Namespace, options.Directory)
 }
 
+// backupArchive saves the cluster objects to a gzip'd tar archive, optionally encrypted, and stores it in the archive location.
+// NOTE: The archive is kept in memory, so the cluster objects are never written unencrypted to the local disk.
+func (c *clusterctlClient) backupArchive(fromCluster cluster.Client, options BackupOptions) error {
+	destination, name, err := backup.ParseLocation(options.Archive, c.s3Options())
+	if err != nil {
+		return err
+	}
+
+	var encrypter backup.Encrypter
+	switch {
+	case len(options.AgeRecipients) > 0:
+		if encrypter, err = backup.NewAgeEncrypter(options.AgeRecipients...); err != nil {
+			return err
+		}
+	case options.PGPPublicKeyFile != "":
+		publicKeys, err := os.ReadFile(options.PGPPublicKeyFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the PGP public key file %q", options.PGPPublicKeyFile)
+		}
+		if encrypter, err = backup.NewPGPEncrypter(publicKeys); err != nil {
+			return err
+		}
+	}
+
+	archive := &bytes.Buffer{}
+	if err := fromCluster.ObjectMover().BackupArchive(options.Namespace, archive); err != nil {
+		return err
+	}
+
+	data := archive.Bytes()
+	if encrypter != nil {
+		if data, err = encrypter.Encrypt(data); err != nil {
+			return err
+		}
+	}
+	return destination.Write(name, data)
+}
+
 func (c *clusterctlClient) Restore(options RestoreOptions) error {
+	if (options.Directory == "") == (options.Archive == "") {
+		return errors.New("exactly one of directory and archive must be defined")
+	}
+	if options.Archive == "" && (options.AgeIdentityFile != "" || options.PGPPrivateKeyFile != "") {
+		return errors.New("decryption is supported only when restoring from an archive")
+	}
+	if options.AgeIdentityFile != "" && options.PGPPrivateKeyFile != "" {
+		return errors.New("age identities and PGP private keys cannot be used at the same time")
+	}
+
 	// Get the client for interacting with the source management cluster.
 	toCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.ToKubeconfig})
 	if err != nil {
@@ -162,9 +297,72 @@ func (c *clusterctlClient) Restore(options RestoreOptions) error {
 		return err
 	}
 
+	if options.Archive != "" {
+		return c.restoreArchive(toCluster, options)
+	}
+
 	if _, err := os.Stat(options.Directory); os.IsNotExist(err) {
 		return err
 	}
 
 	return toCluster.ObjectMover().Restore(toCluster, options.Directory)
 }
+
+// restoreArchive reads a gzip'd tar archive from the archive location, decrypts it if necessary, and restores the cluster objects.
+func (c *clusterctlClient) restoreArchive(toCluster cluster.Client, options RestoreOptions) error {
+	destination, name, err := backup.ParseLocation(options.Archive, c.s3Options())
+	if err != nil {
+		return err
+	}
+
+	data, err := destination.Read(name)
+	if err != nil {
+		return err
+	}
+
+	if backup.IsEncrypted(data) {
+		var decrypter backup.Decrypter
+		switch {
+		case options.AgeIdentityFile != "":
+			identities, err := os.ReadFile(options.AgeIdentityFile)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read the age identity file %q", options.AgeIdentityFile)
+			}
+			if decrypter, err = backup.NewAgeDecrypter(identities); err != nil {
+				return err
+			}
+		case options.PGPPrivateKeyFile != "":
+			privateKeys, err := os.ReadFile(options.PGPPrivateKeyFile)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read the PGP private key file %q", options.PGPPrivateKeyFile)
+			}
+			passphrase, _ := c.configClient.Variables().Get(config.PGPPassphraseVariable)
+			if decrypter, err = backup.NewPGPDecrypter(privateKeys, passphrase); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("the archive %q is encrypted, an age identity file or a PGP private key file is required", options.Archive)
+		}
+
+		if data, err = decrypter.Decrypt(data); err != nil {
+			return err
+		}
+	}
+
+	return toCluster.ObjectMover().RestoreArchive(toCluster, bytes.NewReader(data))
+}
+
+// s3Options returns the options for connecting to the S3 compatible object storage used for backups, as defined in the clusterctl config.
+func (c *clusterctlClient) s3Options() backup.S3Options {
+	get := func(key string) string {
+		value, _ := c.configClient.Variables().Get(key)
+		return value
+	}
+	return backup.S3Options{
+		Endpoint:        get(config.S3EndpointVariable),
+		Region:          get(config.S3RegionVariable),
+		AccessKeyID:     get(config.S3AccessKeyIDVariable),
+		SecretAccessKey: get(config.S3SecretAccessKeyVariable),
+		SessionToken:    get(config.S3SessionTokenVariable),
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package client
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -26,6 +28,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/internal/backup"
 )
 
 func Test_clusterctlClient_Move(t *testing.T) {
@@ -80,6 +83,82 @@ func Test_clusterctlClient_Move(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "does not return error when resuming a move",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					CheckpointFile: "checkpoint.yaml",
+					Resume:         true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "does not return error when moving selected clusters",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig:       Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:         Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					ClusterNames:         []string{"cluster1"},
+					ClusterLabelSelector: "env=test",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if clusters are selected when resuming a move",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					CheckpointFile: "checkpoint.yaml",
+					Resume:         true,
+					ClusterNames:   []string{"cluster1"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if resume and rollback are used at the same time",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					CheckpointFile: "checkpoint.yaml",
+					Resume:         true,
+					Rollback:       true,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if rollback is used without a checkpoint file",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Rollback:       true,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -143,6 +222,48 @@ func Test_clusterctlClient_Backup(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "does not return error when backing up to an encrypted archive",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: BackupOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					Archive:        filepath.Join(dir, "backup.tar.gz.age"),
+					AgeRecipients:  []string{testAgeRecipient},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if both directory and archive are defined",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: BackupOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					Directory:      dir,
+					Archive:        filepath.Join(dir, "backup.tar.gz"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if encryption is requested when backing up to a directory",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: BackupOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					Directory:      dir,
+					AgeRecipients:  []string{testAgeRecipient},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -222,6 +343,49 @@ func Test_clusterctlClient_Restore(t *testing.T) {
 	}
 }
 
+// The test age identity and recipient were generated with age-keygen.
+const (
+	testAgeIdentity  = "AGE-SECRET-KEY-1VPEN9T2JX788Y362PLPM246UH74GUZKCMSM57R092L7AR6VFE5UQ6PDH6S"
+	testAgeRecipient = "age1xcpsvm5cq8quwrmczan9qjzg3wpk00auqlyn7d44tvtkw75seycs0du9ea"
+)
+
+func Test_clusterctlClient_BackupRestoreArchive(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("/tmp", "cluster-api")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	identityFile := filepath.Join(dir, "identity.txt")
+	g.Expect(os.WriteFile(identityFile, []byte(testAgeIdentity), 0600)).To(Succeed())
+	archive := filepath.Join(dir, "backup.tar.gz.age")
+
+	client := fakeClientForMove()
+
+	// Backup to an encrypted archive.
+	g.Expect(client.Backup(BackupOptions{
+		FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		Archive:        archive,
+		AgeRecipients:  []string{testAgeRecipient},
+	})).To(Succeed())
+
+	data, err := os.ReadFile(archive)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(backup.IsEncrypted(data)).To(BeTrue())
+
+	// Restoring an encrypted archive requires an identity.
+	g.Expect(client.Restore(RestoreOptions{
+		ToKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		Archive:      archive,
+	})).ToNot(Succeed())
+
+	g.Expect(client.Restore(RestoreOptions{
+		ToKubeconfig:    Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		Archive:         archive,
+		AgeIdentityFile: identityFile,
+	})).To(Succeed())
+}
+
 func fakeClientForMove() *fakeClient {
 	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
 	infra := config.NewProvider("infra", "https://somewhere.com", clusterctlv1.InfrastructureProviderType)
@@ -255,7 +419,15 @@ type fakeObjectMover struct {
 	restoerErr error
 }
 
-func (f *fakeObjectMover) Move(namespace string, toCluster cluster.Client, dryRun bool) error {
+func (f *fakeObjectMover) Move(namespace string, selector *cluster.ClusterSelector, toCluster cluster.Client, dryRun bool, checkpointFile string) error {
+	return f.moveErr
+}
+
+func (f *fakeObjectMover) ResumeMove(toCluster cluster.Client, checkpointFile string) error {
+	return f.moveErr
+}
+
+func (f *fakeObjectMover) RollbackMove(toCluster cluster.Client, checkpointFile string) error {
 	return f.moveErr
 }
 
@@ -263,6 +435,21 @@ func (f *fakeObjectMover) Backup(namespace string, directory string) error {
 	return f.backupErr
 }
 
+func (f *fakeObjectMover) BackupArchive(namespace string, w io.Writer) error {
+	if f.backupErr != nil {
+		return f.backupErr
+	}
+	return backup.NewArchiveWriter(w).Close()
+}
+
 func (f *fakeObjectMover) Restore(toCluster cluster.Client, directory string) error {
 	return f.restoerErr
 }
+
+func (f *fakeObjectMover) RestoreArchive(toCluster cluster.Client, r io.Reader) error {
+	if f.restoerErr != nil {
+		return f.restoerErr
+	}
+	_, err := backup.ReadArchive(r)
+	return err
+}
@@ -164,6 +164,33 @@ func repositoryFactory(providerConfig config.Provider, configVariablesClient con
 		return repo, err
 	}
 
+	// if the url is a GitLab repository
+	if rURL.Scheme == httpsScheme && isGitLabReleasesPath(rURL.Path) {
+		repo, err := newGitLabRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the GitLab repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is a generic HTTP(S) repository
+	if rURL.Scheme == httpsScheme || rURL.Scheme == httpScheme {
+		repo, err := newHTTPRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the HTTP(S) repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is an OCI repository
+	if rURL.Scheme == ociScheme {
+		repo, err := newOCIRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the OCI repository client")
+		}
+		return repo, err
+	}
+
 	// if the url is a local filesystem repository
 	if rURL.Scheme == "file" || rURL.Scheme == "" {
 		repo, err := newLocalRepository(providerConfig, configVariablesClient)
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+// newHTTPClient returns an http client honoring the proxy and the CA bundle defined in the clusterctl config.
+func newHTTPClient(configVariablesClient config.VariablesClient) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxy, err := configVariablesClient.Get(config.HTTPSProxyVariable); err == nil && proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s variable %q", config.HTTPSProxyVariable, proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if caBundle, err := configVariablesClient.Get(config.CABundleVariable); err == nil && caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the CA bundle defined in the %s variable", config.CABundleVariable)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("failed to parse the CA bundle defined in the %s variable: no valid certificates found", config.CABundleVariable)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// httpGet reads the content at the given url, failing if the response is not 200 OK.
+func httpGet(client *http.Client, url string, header http.Header) ([]byte, http.Header, error) {
+	request, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create request for %q", url)
+	}
+	for k, v := range header {
+		request.Header[k] = v
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get %q", url)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("failed to get %q: unexpected status %q", url, response.Status)
+	}
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read %q", url)
+	}
+	return content, response.Header, nil
+}
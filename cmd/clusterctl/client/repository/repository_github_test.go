@@ -718,4 +718,5 @@ func resetCaches() {
 	cacheVersions = map[string][]string{}
 	cacheReleases = map[string]*github.RepositoryRelease{}
 	cacheFiles = map[string][]byte{}
+	cacheGitLabReleases = map[string]*gitLabRelease{}
 }
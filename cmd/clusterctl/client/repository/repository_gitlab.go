@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	gitLabReleasesSeparator  = "/-/releases/"
+	gitLabDownloadsPath      = "downloads"
+	gitLabLatestReleaseLabel = "latest"
+	gitLabTokenHeader        = "PRIVATE-TOKEN"
+	gitLabReleasesPageSize   = 100
+)
+
+var (
+	// Cache used to limit the number of GitLab API calls.
+
+	cacheGitLabReleases = map[string]*gitLabRelease{}
+)
+
+// gitLabRepository provides support for providers hosted on GitLab, including self-hosted GitLab instances.
+//
+// We support GitLab repositories that use the release feature to publish artifacts and versions, with the
+// components YAML, the metadata YAML and eventually the workload cluster templates linked as release assets.
+type gitLabRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	host                  string
+	project               string
+	token                 string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+}
+
+var _ Repository = &gitLabRepository{}
+
+type gitLabRepositoryOption func(*gitLabRepository)
+
+func injectGitLabHTTPClient(c *http.Client) gitLabRepositoryOption {
+	return func(g *gitLabRepository) {
+		g.httpClient = c
+	}
+}
+
+// gitLabRelease is the subset of a GitLab release used to retrieve the files of a provider version.
+type gitLabRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []gitLabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+// gitLabReleaseLink is an asset linked to a GitLab release.
+type gitLabReleaseLink struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+}
+
+// DefaultVersion returns defaultVersion field of gitLabRepository struct.
+func (g *gitLabRepository) DefaultVersion() string {
+	return g.defaultVersion
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+func (g *gitLabRepository) GetVersions() ([]string, error) {
+	versions, err := g.getVersions()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+	return versions, nil
+}
+
+// RootPath returns rootPath field of gitLabRepository struct.
+func (g *gitLabRepository) RootPath() string {
+	return g.rootPath
+}
+
+// ComponentsPath returns componentsPath field of gitLabRepository struct.
+func (g *gitLabRepository) ComponentsPath() string {
+	return g.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (g *gitLabRepository) GetFile(version, path string) ([]byte, error) {
+	release, err := g.getReleaseByTag(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get GitLab release %s", version)
+	}
+
+	// download files from the release
+	files, err := g.downloadFilesFromRelease(release, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download files from GitLab release %s", version)
+	}
+
+	return files, nil
+}
+
+// newGitLabRepository returns a gitLabRepository implementation.
+func newGitLabRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...gitLabRepositoryOption) (*gitLabRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	// Check if the url is a GitLab repository
+	if rURL.Scheme != httpsScheme || !isGitLabReleasesPath(rURL.Path) {
+		return nil, errors.Errorf("invalid url: a GitLab repository url should start with https:// and contain %s", gitLabReleasesSeparator)
+	}
+
+	// Check if the path is in the expected format.
+	split := strings.SplitN(rURL.Path, gitLabReleasesSeparator, 2)
+	project := strings.Trim(split[0], "/")
+	urlSplit := strings.Split(split[1], "/")
+	if project == "" || len(urlSplit) < 3 || urlSplit[0] == "" || urlSplit[1] != gitLabDownloadsPath {
+		return nil, errors.Errorf(
+			"invalid url: a GitLab repository url should be in the form https://{host}/{owner}/{project}%s{latest|version-tag}/%s/{componentsClient.yaml}",
+			gitLabReleasesSeparator, gitLabDownloadsPath,
+		)
+	}
+
+	// Extract all the info from url split.
+	defaultVersion := urlSplit[0]
+	path := strings.Join(urlSplit[2:], "/")
+
+	// use path's directory as a rootPath
+	rootPath := filepath.Dir(path)
+	// use the file name (if any) as componentsPath
+	componentsPath := getComponentsPath(path, rootPath)
+
+	repo := &gitLabRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		host:                  rURL.Host,
+		project:               project,
+		defaultVersion:        defaultVersion,
+		rootPath:              rootPath,
+		componentsPath:        componentsPath,
+	}
+
+	// process gitLabRepositoryOptions
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if repo.httpClient == nil {
+		repo.httpClient, err = newHTTPClient(configVariablesClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if token, err := configVariablesClient.Get(config.GitLabTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	if defaultVersion == gitLabLatestReleaseLabel {
+		repo.defaultVersion, err = latestContractRelease(repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// isGitLabReleasesPath returns true if the path of a url points to GitLab releases.
+func isGitLabReleasesPath(path string) bool {
+	return strings.Contains(path, gitLabReleasesSeparator)
+}
+
+// projectURL returns the url of the GitLab API for the project.
+func (g *gitLabRepository) projectURL() string {
+	return fmt.Sprintf("https://%s/api/v4/projects/%s", g.host, url.PathEscape(g.project))
+}
+
+// header returns the header to be used for GitLab API calls.
+func (g *gitLabRepository) header() http.Header {
+	header := http.Header{}
+	if g.token != "" {
+		header.Set(gitLabTokenHeader, g.token)
+	}
+	return header
+}
+
+// getVersions returns all the release versions for a GitLab repository.
+func (g *gitLabRepository) getVersions() ([]string, error) {
+	cacheID := fmt.Sprintf("%s/%s", g.host, g.project)
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	// get all the releases, following the pagination
+	versions := []string{}
+	page := "1"
+	for page != "" {
+		content, header, err := httpGet(g.httpClient, fmt.Sprintf("%s/releases?per_page=%d&page=%s", g.projectURL(), gitLabReleasesPageSize, page), g.header())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the list of releases")
+		}
+		releases := []gitLabRelease{}
+		if err := json.Unmarshal(content, &releases); err != nil {
+			return nil, errors.Wrap(err, "failed to decode the list of releases")
+		}
+		for _, r := range releases {
+			if _, err := version.ParseSemantic(r.TagName); err != nil {
+				// Discard releases with tags that are not a valid semantic versions (the user can point explicitly to such releases).
+				continue
+			}
+			versions = append(versions, r.TagName)
+		}
+		page = header.Get("X-Next-Page")
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// getReleaseByTag returns the GitLab repository release with a specific tag name.
+func (g *gitLabRepository) getReleaseByTag(tag string) (*gitLabRelease, error) {
+	cacheID := fmt.Sprintf("%s/%s:%s", g.host, g.project, tag)
+	if release, ok := cacheGitLabReleases[cacheID]; ok {
+		return release, nil
+	}
+
+	content, _, err := httpGet(g.httpClient, fmt.Sprintf("%s/releases/%s", g.projectURL(), url.PathEscape(tag)), g.header())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read release %q", tag)
+	}
+	release := &gitLabRelease{}
+	if err := json.Unmarshal(content, release); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode release %q", tag)
+	}
+
+	cacheGitLabReleases[cacheID] = release
+	return release, nil
+}
+
+// downloadFilesFromRelease download a file from release.
+func (g *gitLabRepository) downloadFilesFromRelease(release *gitLabRelease, fileName string) ([]byte, error) {
+	cacheID := fmt.Sprintf("%s/%s:%s:%s", g.host, g.project, release.TagName, fileName)
+	if content, ok := cacheFiles[cacheID]; ok {
+		return content, nil
+	}
+
+	absoluteFileName := filepath.Join(g.rootPath, fileName)
+
+	// search for the file into the release assets, retrieving the download url
+	var assetURL string
+	for _, l := range release.Assets.Links {
+		if l.Name == absoluteFileName {
+			assetURL = l.DirectAssetURL
+			if assetURL == "" {
+				assetURL = l.URL
+			}
+			break
+		}
+	}
+	if assetURL == "" {
+		return nil, errors.Errorf("failed to get file %q from %q release", fileName, release.TagName)
+	}
+
+	// the token is sent only to the GitLab instance, given that assets can be hosted elsewhere.
+	var header http.Header
+	if u, err := url.Parse(assetURL); err == nil && u.Host == g.host {
+		header = g.header()
+	}
+	content, _, err := httpGet(g.httpClient, assetURL, header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q from %q release", fileName, release.TagName)
+	}
+
+	cacheFiles[cacheID] = content
+	return content, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_gitLabRepository_newGitLabRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		variableClient     config.VariablesClient
+		wantHost           string
+		wantProject        string
+		wantDefaultVersion string
+		wantRootPath       string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name:               "can create a new GitLab repo",
+			url:                "https://gitlab.example.com/group/subgroup/project/-/releases/v1.0.0/downloads/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient(),
+			wantHost:           "gitlab.example.com",
+			wantProject:        "group/subgroup/project",
+			wantDefaultVersion: "v1.0.0",
+			wantRootPath:       ".",
+			wantComponentsPath: "core-components.yaml",
+		},
+		{
+			name:           "missing variableClient",
+			url:            "https://gitlab.example.com/group/project/-/releases/v1.0.0/downloads/core-components.yaml",
+			variableClient: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should be in https",
+			url:            "http://gitlab.example.com/group/project/-/releases/v1.0.0/downloads/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should point to release downloads",
+			url:            "https://gitlab.example.com/group/project/-/releases/v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should have a project",
+			url:            "https://gitlab.example.com/-/releases/v1.0.0/downloads/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			repo, err := newGitLabRepository(providerConfig, tt.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.host).To(Equal(tt.wantHost))
+			g.Expect(repo.project).To(Equal(tt.wantProject))
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(repo.RootPath()).To(Equal(tt.wantRootPath))
+			g.Expect(repo.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_gitLabRepository(t *testing.T) {
+	g := NewWithT(t)
+	resetCaches()
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.Header.Get(gitLabTokenHeader) != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fproject/releases":
+			// Releases are returned in two pages.
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprint(w, `[{"tag_name": "v1.0.0"}, {"tag_name": "not-a-version"}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"tag_name": "v0.4.0"}]`)
+		case "/api/v4/projects/group%2Fproject/releases/v1.0.0":
+			fmt.Fprintf(w, `{"tag_name": "v1.0.0", "assets": {"links": [{"name": "core-components.yaml", "direct_asset_url": "%s/group/project/-/releases/v1.0.0/downloads/core-components.yaml"}]}}`, server.URL)
+		case "/group/project/-/releases/v1.0.0/downloads/core-components.yaml":
+			fmt.Fprint(w, "components")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	variableClient := test.NewFakeVariableClient().WithVar(config.GitLabTokenVariable, "token")
+	providerConfig := config.NewProvider("test", server.URL+"/group/project/-/releases/latest/downloads/core-components.yaml", clusterctlv1.CoreProviderType)
+	repo, err := newGitLabRepository(providerConfig, variableClient, injectGitLabHTTPClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repo.host).To(Equal(strings.TrimPrefix(server.URL, "https://")))
+
+	// latest resolves to the latest release.
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.0.0"))
+
+	versions, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v0.4.0", "v1.0.0"))
+
+	content, err := repo.GetFile("v1.0.0", "core-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("components"))
+
+	_, err = repo.GetFile("v1.0.0", "cluster-template.yaml")
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = repo.GetFile("v2.0.0", "core-components.yaml")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	httpScheme             = "http"
+	httpIndexFile          = "index.yaml"
+	httpLatestReleaseLabel = "latest"
+)
+
+// httpRepository provides support for providers hosted on a generic HTTP(S) server.
+//
+// The server is expected to host a folder for each provider version, containing the components YAML, the metadata YAML
+// and eventually the workload cluster templates, and an index file listing the available versions, e.g.
+//
+//	https://example.com/cluster-api/index.yaml
+//	https://example.com/cluster-api/v1.0.0/core-components.yaml
+//	https://example.com/cluster-api/v1.0.0/metadata.yaml
+//
+// where the index file has the following format:
+//
+//	versions:
+//	- v1.0.0
+type httpRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	baseURL               string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+}
+
+var _ Repository = &httpRepository{}
+
+type httpRepositoryOption func(*httpRepository)
+
+func injectHTTPClient(c *http.Client) httpRepositoryOption {
+	return func(h *httpRepository) {
+		h.httpClient = c
+	}
+}
+
+// httpIndex defines the content of the index file listing the versions hosted on a HTTP(S) repository.
+type httpIndex struct {
+	Versions []string `json:"versions"`
+}
+
+// DefaultVersion returns defaultVersion field of httpRepository struct.
+func (h *httpRepository) DefaultVersion() string {
+	return h.defaultVersion
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+func (h *httpRepository) GetVersions() ([]string, error) {
+	versions, err := h.getVersions()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+	return versions, nil
+}
+
+// RootPath returns rootPath field of httpRepository struct.
+func (h *httpRepository) RootPath() string {
+	return h.rootPath
+}
+
+// ComponentsPath returns componentsPath field of httpRepository struct.
+func (h *httpRepository) ComponentsPath() string {
+	return h.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (h *httpRepository) GetFile(version, path string) ([]byte, error) {
+	fileURL := strings.Join([]string{h.baseURL, version, path}, "/")
+	if content, ok := cacheFiles[fileURL]; ok {
+		return content, nil
+	}
+
+	content, _, err := httpGet(h.httpClient, fileURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q for version %s", path, version)
+	}
+
+	cacheFiles[fileURL] = content
+	return content, nil
+}
+
+// newHTTPRepository returns a httpRepository implementation.
+func newHTTPRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...httpRepositoryOption) (*httpRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	// Check if the url is a HTTP(S) repository
+	if (rURL.Scheme != httpsScheme && rURL.Scheme != httpScheme) || rURL.Host == "" {
+		return nil, errors.New("invalid url: a HTTP(S) repository url should start with https:// or http://")
+	}
+
+	// Check if the path is in the expected format,
+	// url's path has an extra leading slash at the end which we need to clean up before splitting.
+	urlSplit := strings.Split(strings.TrimPrefix(rURL.Path, "/"), "/")
+	if len(urlSplit) < 2 || urlSplit[len(urlSplit)-2] == "" || urlSplit[len(urlSplit)-1] == "" {
+		return nil, errors.New("invalid url: a HTTP(S) repository url should be in the form https://{host}/{path}/{latest|version-tag}/{componentsClient.yaml}")
+	}
+
+	// Extract all the info from url split.
+	defaultVersion := urlSplit[len(urlSplit)-2]
+	componentsPath := urlSplit[len(urlSplit)-1]
+	baseURL := url.URL{
+		Scheme: rURL.Scheme,
+		User:   rURL.User,
+		Host:   rURL.Host,
+		Path:   "/" + strings.Join(urlSplit[:len(urlSplit)-2], "/"),
+	}
+
+	repo := &httpRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		baseURL:               strings.TrimSuffix(baseURL.String(), "/"),
+		defaultVersion:        defaultVersion,
+		rootPath:              ".",
+		componentsPath:        componentsPath,
+	}
+
+	// process httpRepositoryOptions
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if repo.httpClient == nil {
+		repo.httpClient, err = newHTTPClient(configVariablesClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if defaultVersion == httpLatestReleaseLabel {
+		repo.defaultVersion, err = latestContractRelease(repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get HTTP(S) latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// getVersions returns all the versions listed in the index file of a HTTP(S) repository.
+func (h *httpRepository) getVersions() ([]string, error) {
+	cacheID := h.baseURL
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	content, _, err := httpGet(h.httpClient, h.baseURL+"/"+httpIndexFile, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the index file")
+	}
+	index := &httpIndex{}
+	if err := yaml.Unmarshal(content, index); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the index file")
+	}
+
+	versions := []string{}
+	for _, v := range index.Versions {
+		if _, err := version.ParseSemantic(v); err != nil {
+			// Discard versions that are not a valid semantic versions (the user can point explicitly to such versions).
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_httpRepository_newHTTPRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		variableClient     config.VariablesClient
+		wantBaseURL        string
+		wantDefaultVersion string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name:               "can create a new HTTPS repo",
+			url:                "https://example.com/capi/cluster-api/v1.0.0/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient(),
+			wantBaseURL:        "https://example.com/capi/cluster-api",
+			wantDefaultVersion: "v1.0.0",
+			wantComponentsPath: "core-components.yaml",
+		},
+		{
+			name:               "can create a new HTTP repo at the root of the server",
+			url:                "http://example.com:8080/v1.0.0/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient(),
+			wantBaseURL:        "http://example.com:8080",
+			wantDefaultVersion: "v1.0.0",
+			wantComponentsPath: "core-components.yaml",
+		},
+		{
+			name:           "missing variableClient",
+			url:            "https://example.com/capi/cluster-api/v1.0.0/core-components.yaml",
+			variableClient: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should use http or https",
+			url:            "ftp://example.com/capi/cluster-api/v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should have a version and a components path",
+			url:            "https://example.com/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "proxy should be a valid url",
+			url:            "https://example.com/capi/cluster-api/v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPSProxyVariable, "%gh&%ij"),
+			wantErr:        true,
+		},
+		{
+			name:           "CA bundle should exist",
+			url:            "https://example.com/capi/cluster-api/v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient().WithVar(config.CABundleVariable, "/does-not-exist"),
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			repo, err := newHTTPRepository(providerConfig, tt.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.baseURL).To(Equal(tt.wantBaseURL))
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(repo.RootPath()).To(Equal("."))
+			g.Expect(repo.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_httpRepository(t *testing.T) {
+	g := NewWithT(t)
+	resetCaches()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/capi/cluster-api/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, "versions:\n- v0.4.0\n- v1.0.0\n- not-a-version\n")
+	})
+	mux.HandleFunc("/capi/cluster-api/v1.0.0/core-components.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, "components")
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	// Trust the certificate of the test server using the CA bundle.
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	g.Expect(os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)).To(Succeed())
+	variableClient := test.NewFakeVariableClient().WithVar(config.CABundleVariable, caBundle)
+
+	providerConfig := config.NewProvider("test", server.URL+"/capi/cluster-api/latest/core-components.yaml", clusterctlv1.CoreProviderType)
+	repo, err := newHTTPRepository(providerConfig, variableClient)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// latest resolves to the latest version in the index.
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.0.0"))
+
+	versions, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v0.4.0", "v1.0.0"))
+
+	content, err := repo.GetFile("v1.0.0", "core-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("components"))
+
+	_, err = repo.GetFile("v1.0.0", "cluster-template.yaml")
+	g.Expect(err).To(HaveOccurred())
+
+	// Without the CA bundle the certificate of the test server is not trusted.
+	resetCaches()
+	_, err = newHTTPRepository(providerConfig, test.NewFakeVariableClient())
+	g.Expect(err).To(HaveOccurred())
+}
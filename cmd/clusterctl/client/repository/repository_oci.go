@@ -0,0 +1,497 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociScheme                = "oci"
+	ociLatestLabel           = "latest"
+	ociTitleAnnotation       = "org.opencontainers.image.title"
+	ociManifestMediaType     = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType  = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTagsPageSize          = 1000
+	ociBearerChallengePrefix = "bearer "
+	ociBasicChallengePrefix  = "basic "
+)
+
+var (
+	ociChallengeParamsRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	ociNextLinkRegexp        = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// ociRepository provides support for providers hosted in an OCI registry.
+//
+// Each provider version is expected to be published as an OCI artifact tagged with the version, with a layer for
+// each file in the repository (components YAML, metadata.yaml, cluster templates) named according to the
+// org.opencontainers.image.title annotation, as e.g. the artifacts pushed with `oras push`.
+type ociRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	scheme                string
+	registry              string
+	repository            string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+	username              string
+	password              string
+	token                 string
+	versions              []string
+	manifests             map[string]*ociManifest
+}
+
+var _ Repository = &ociRepository{}
+
+// ociManifest is the subset of an OCI image manifest used to retrieve the files of a provider version.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor describes a blob stored in an OCI registry.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociTagList is the response of the tags list API.
+type ociTagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// DefaultVersion returns defaultVersion field of ociRepository struct.
+func (o *ociRepository) DefaultVersion() string {
+	return o.defaultVersion
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+func (o *ociRepository) GetVersions() ([]string, error) {
+	versions, err := o.getVersions()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+	return versions, nil
+}
+
+// RootPath returns rootPath field of ociRepository struct.
+func (o *ociRepository) RootPath() string {
+	return o.rootPath
+}
+
+// ComponentsPath returns componentsPath field of ociRepository struct.
+func (o *ociRepository) ComponentsPath() string {
+	return o.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (o *ociRepository) GetFile(version, filePath string) ([]byte, error) {
+	manifest, err := o.getManifest(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get OCI artifact %s", o.reference(version))
+	}
+
+	// Layer titles use forward slashes on every OS, as the paths in the repository URL.
+	fileName := path.Join(o.rootPath, filePath)
+	for _, layer := range manifest.Layers {
+		if layer.Annotations[ociTitleAnnotation] != fileName {
+			continue
+		}
+		content, err := o.getBlob(layer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download file %q from OCI artifact %s", fileName, o.reference(version))
+		}
+		return content, nil
+	}
+	return nil, errors.Errorf("failed to get file %q from OCI artifact %s", fileName, o.reference(version))
+}
+
+// newOCIRepository returns an ociRepository implementation.
+func newOCIRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient) (*ociRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	// Check if the url is an OCI repository
+	if rURL.Scheme != ociScheme || rURL.Host == "" {
+		return nil, errors.New("invalid url: an OCI repository url should start with oci://{registry}")
+	}
+
+	// Check if the path is in the expected format, i.e. the repository name, followed by the tag and by the
+	// path of the components file inside the artifact.
+	urlSplit := strings.Split(strings.TrimPrefix(rURL.Path, "/"), "/")
+	tagIndex := -1
+	for i, s := range urlSplit {
+		if strings.Contains(s, ":") {
+			tagIndex = i
+			break
+		}
+	}
+	if tagIndex < 0 || tagIndex == len(urlSplit)-1 {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}/{componentsClient.yaml}")
+	}
+	nameAndTag := strings.SplitN(urlSplit[tagIndex], ":", 2)
+	if nameAndTag[0] == "" || nameAndTag[1] == "" {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}/{componentsClient.yaml}")
+	}
+
+	// Extract all the info from url split.
+	repository := strings.Join(append(urlSplit[:tagIndex:tagIndex], nameAndTag[0]), "/")
+	defaultVersion := nameAndTag[1]
+	filePath := strings.Join(urlSplit[tagIndex+1:], "/")
+
+	// use path's directory as a rootPath
+	rootPath := path.Dir(filePath)
+	// use the file name (if any) as componentsPath
+	componentsPath := getComponentsPath(filePath, rootPath)
+
+	// Registries are accessed using HTTPS, except for local registries (e.g. localhost:5000) that are accessed
+	// using plain HTTP; plain HTTP can be enabled for other registries using the oci-insecure variable.
+	scheme := "https"
+	if isLocalRegistry(rURL.Host) {
+		scheme = "http"
+	}
+	if insecure, err := configVariablesClient.Get(config.OCIInsecureVariable); err == nil && insecure != "" {
+		insecureValue, err := strconv.ParseBool(insecure)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s variable %q", config.OCIInsecureVariable, insecure)
+		}
+		if insecureValue {
+			scheme = "http"
+		}
+	}
+
+	httpClient, err := newHTTPClient(configVariablesClient)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		httpClient:            httpClient,
+		scheme:                scheme,
+		registry:              rURL.Host,
+		repository:            repository,
+		defaultVersion:        defaultVersion,
+		rootPath:              rootPath,
+		componentsPath:        componentsPath,
+		manifests:             map[string]*ociManifest{},
+	}
+
+	if username, err := configVariablesClient.Get(config.OCIUsernameVariable); err == nil {
+		repo.username = username
+	}
+	if password, err := configVariablesClient.Get(config.OCIPasswordVariable); err == nil {
+		repo.password = password
+	}
+
+	if defaultVersion == ociLatestLabel {
+		repo.defaultVersion, err = latestContractRelease(repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get OCI latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// reference returns the reference of the OCI artifact for a provider version.
+func (o *ociRepository) reference(version string) string {
+	return fmt.Sprintf("%s/%s:%s", o.registry, o.repository, version)
+}
+
+// getVersions returns all the versions for an OCI repository, according to the tags of the repository.
+func (o *ociRepository) getVersions() ([]string, error) {
+	if o.versions != nil {
+		return o.versions, nil
+	}
+
+	versions := []string{}
+	next := fmt.Sprintf("%s/tags/list?n=%d", o.repositoryURL(), ociTagsPageSize)
+	for next != "" {
+		response, err := o.get(next)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the list of tags")
+		}
+		tagList := &ociTagList{}
+		err = json.NewDecoder(response.Body).Decode(tagList)
+		response.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode the list of tags")
+		}
+		for _, tag := range tagList.Tags {
+			if _, err := version.ParseSemantic(tag); err != nil {
+				// Discard tags that are not a valid semantic versions (the user can point explicitly to such tags).
+				continue
+			}
+			versions = append(versions, tag)
+		}
+
+		// Follow the link to the next page of results, if any.
+		next, err = nextPageURL(response)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o.versions = versions
+	return versions, nil
+}
+
+// getManifest returns the manifest of the OCI artifact for a provider version.
+func (o *ociRepository) getManifest(version string) (*ociManifest, error) {
+	if manifest, ok := o.manifests[version]; ok {
+		return manifest, nil
+	}
+
+	response, err := o.get(fmt.Sprintf("%s/manifests/%s", o.repositoryURL(), version), ociManifestMediaType, dockerManifestMediaType)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	manifest := &ociManifest{}
+	if err := json.NewDecoder(response.Body).Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the manifest")
+	}
+
+	o.manifests[version] = manifest
+	return manifest, nil
+}
+
+// getBlob downloads a blob, checking its content matches the digest.
+func (o *ociRepository) getBlob(descriptor ociDescriptor) ([]byte, error) {
+	algorithm, hash := "", ""
+	if parts := strings.SplitN(descriptor.Digest, ":", 2); len(parts) == 2 {
+		algorithm, hash = parts[0], parts[1]
+	}
+	if algorithm != "sha256" {
+		return nil, errors.Errorf("unsupported digest %q", descriptor.Digest)
+	}
+
+	response, err := o.get(fmt.Sprintf("%s/blobs/%s", o.repositoryURL(), descriptor.Digest))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the blob")
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != hash {
+		return nil, errors.Errorf("the content of the blob does not match the digest %q", descriptor.Digest)
+	}
+	return content, nil
+}
+
+// repositoryURL returns the base URL of the registry API for the repository.
+func (o *ociRepository) repositoryURL() string {
+	return fmt.Sprintf("%s://%s/v2/%s", o.scheme, o.registry, o.repository)
+}
+
+// isLocalRegistry returns true if the registry host, that can include a port, is the local host.
+func isLocalRegistry(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+// get sends a GET request to the registry, authenticating if required by the registry.
+func (o *ociRepository) get(url string, accept ...string) (*http.Response, error) {
+	newRequest := func() (*http.Request, error) {
+		request, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create request for %q", url)
+		}
+		for _, a := range accept {
+			request.Header.Add("Accept", a)
+		}
+		o.setAuthorization(request)
+		return request, nil
+	}
+
+	request, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	response, err := o.httpClient.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q", url)
+	}
+
+	// If the registry requires authentication, get the credentials requested by the challenge and retry.
+	if response.StatusCode == http.StatusUnauthorized {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+		if err := o.authenticate(challenge); err != nil {
+			return nil, err
+		}
+
+		request, err = newRequest()
+		if err != nil {
+			return nil, err
+		}
+		response, err = o.httpClient.Do(request)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %q", url)
+		}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, errors.Errorf("failed to get %q: unexpected status %q", url, response.Status)
+	}
+	return response, nil
+}
+
+// setAuthorization sets the authorization header using the token or the credentials for the registry, if any.
+func (o *ociRepository) setAuthorization(request *http.Request) {
+	if o.token != "" {
+		request.Header.Set("Authorization", "Bearer "+o.token)
+		return
+	}
+	if o.username != "" || o.password != "" {
+		request.SetBasicAuth(o.username, o.password)
+	}
+}
+
+// authenticate handles the authentication challenge returned by the registry.
+// Registries using basic authentication are supported, as well as registries using the token authentication
+// (https://docs.docker.com/registry/spec/auth/token/); in the latter case the token is requested to the
+// authorization service using the credentials for the registry, if any.
+func (o *ociRepository) authenticate(challenge string) error {
+	switch {
+	case strings.HasPrefix(strings.ToLower(challenge), ociBasicChallengePrefix):
+		// Credentials, if any, are sent with every request, so they are missing or not valid.
+		if o.username == "" && o.password == "" {
+			return errors.Errorf("the registry %s requires authentication; please set the %s and %s variables", o.registry, config.OCIUsernameVariable, config.OCIPasswordVariable)
+		}
+		return errors.Errorf("failed to authenticate to the registry %s using the credentials defined in the %s and %s variables", o.registry, config.OCIUsernameVariable, config.OCIPasswordVariable)
+	case strings.HasPrefix(strings.ToLower(challenge), ociBearerChallengePrefix):
+		params := map[string]string{}
+		for _, match := range ociChallengeParamsRegexp.FindAllStringSubmatch(challenge[len(ociBearerChallengePrefix):], -1) {
+			params[match[1]] = match[2]
+		}
+		if params["realm"] == "" {
+			return errors.Errorf("invalid authentication challenge from the registry %s: missing realm", o.registry)
+		}
+		token, err := o.getToken(params["realm"], params["service"], params["scope"])
+		if err != nil {
+			return errors.Wrapf(err, "failed to authenticate to the registry %s", o.registry)
+		}
+		o.token = token
+		return nil
+	default:
+		return errors.Errorf("unsupported authentication challenge %q from the registry %s", challenge, o.registry)
+	}
+}
+
+// getToken requests a token to the authorization service of the registry.
+func (o *ociRepository) getToken(realm, service, scope string) (string, error) {
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid realm %q", realm)
+	}
+	query := tokenURL.Query()
+	if service != "" {
+		query.Set("service", service)
+	}
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", o.repository)
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, tokenURL.String(), http.NoBody)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create request for %q", tokenURL.String())
+	}
+	if o.username != "" || o.password != "" {
+		request.SetBasicAuth(o.username, o.password)
+	}
+	response, err := o.httpClient.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get token")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to get token: unexpected status %q", response.Status)
+	}
+
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&tokenResponse); err != nil {
+		return "", errors.Wrap(err, "failed to decode token")
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	if tokenResponse.AccessToken != "" {
+		return tokenResponse.AccessToken, nil
+	}
+	return "", errors.New("failed to get token: empty token")
+}
+
+// nextPageURL returns the URL of the next page of results defined in the Link header of a response, if any.
+func nextPageURL(response *http.Response) (string, error) {
+	match := ociNextLinkRegexp.FindStringSubmatch(response.Header.Get("Link"))
+	if match == nil {
+		return "", nil
+	}
+	next, err := response.Request.URL.Parse(match[1])
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid link to the next page %q", match[1])
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,423 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_ociRepository_newOCIRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		variableClient     config.VariablesClient
+		wantRegistry       string
+		wantRepository     string
+		wantDefaultVersion string
+		wantRootPath       string
+		wantComponentsPath string
+		wantScheme         string
+		wantErr            bool
+	}{
+		{
+			name:               "can create a new OCI repo",
+			url:                "oci://registry.example.com:5000/capi/cluster-api:v1.0.0/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient(),
+			wantRegistry:       "registry.example.com:5000",
+			wantRepository:     "capi/cluster-api",
+			wantDefaultVersion: "v1.0.0",
+			wantRootPath:       ".",
+			wantComponentsPath: "core-components.yaml",
+			wantScheme:         "https",
+		},
+		{
+			name:               "can create a new OCI repo with files in a folder",
+			url:                "oci://registry.example.com/cluster-api:v1.0.0/manifests/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient(),
+			wantRegistry:       "registry.example.com",
+			wantRepository:     "cluster-api",
+			wantDefaultVersion: "v1.0.0",
+			wantRootPath:       "manifests",
+			wantComponentsPath: "core-components.yaml",
+			wantScheme:         "https",
+		},
+		{
+			name:               "local registries use plain HTTP",
+			url:                "oci://localhost:5000/cluster-api:v1.0.0/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient(),
+			wantRegistry:       "localhost:5000",
+			wantRepository:     "cluster-api",
+			wantDefaultVersion: "v1.0.0",
+			wantRootPath:       ".",
+			wantComponentsPath: "core-components.yaml",
+			wantScheme:         "http",
+		},
+		{
+			name:               "plain HTTP can be enabled for other registries",
+			url:                "oci://registry.example.com/cluster-api:v1.0.0/core-components.yaml",
+			variableClient:     test.NewFakeVariableClient().WithVar(config.OCIInsecureVariable, "true"),
+			wantRegistry:       "registry.example.com",
+			wantRepository:     "cluster-api",
+			wantDefaultVersion: "v1.0.0",
+			wantRootPath:       ".",
+			wantComponentsPath: "core-components.yaml",
+			wantScheme:         "http",
+		},
+		{
+			name:           "invalid oci-insecure variable",
+			url:            "oci://registry.example.com/cluster-api:v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIInsecureVariable, "foo"),
+			wantErr:        true,
+		},
+		{
+			name:           "missing variableClient",
+			url:            "oci://registry.example.com/cluster-api:v1.0.0/core-components.yaml",
+			variableClient: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should use the oci scheme",
+			url:            "https://registry.example.com/cluster-api:v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should have a registry",
+			url:            "oci:///cluster-api:v1.0.0/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should have a tag",
+			url:            "oci://registry.example.com/cluster-api/core-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should have a components path",
+			url:            "oci://registry.example.com/cluster-api:v1.0.0",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			repo, err := newOCIRepository(providerConfig, tt.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.registry).To(Equal(tt.wantRegistry))
+			g.Expect(repo.repository).To(Equal(tt.wantRepository))
+			g.Expect(repo.scheme).To(Equal(tt.wantScheme))
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(repo.RootPath()).To(Equal(tt.wantRootPath))
+			g.Expect(repo.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_ociRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	registry := newFakeOCIRegistry().
+		WithArtifact("capi/cluster-api", "v0.4.0", map[string]string{"core-components.yaml": "v0.4.0"}).
+		WithArtifact("capi/cluster-api", "v1.0.0", map[string]string{"core-components.yaml": "v1.0.0"}).
+		WithArtifact("capi/cluster-api", "v1.0.1", map[string]string{"core-components.yaml": "v1.0.1"}).
+		WithArtifact("capi/cluster-api", "not-a-version", map[string]string{"core-components.yaml": "foo"}).
+		WithPageSize(2)
+	defer registry.Close()
+
+	repo, err := newOCIRepository(registry.Provider("capi/cluster-api:latest/core-components.yaml"), test.NewFakeVariableClient())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Versions are read from all the pages of tags, discarding the tags that are not a valid semantic version.
+	versions, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v0.4.0", "v1.0.0", "v1.0.1"))
+
+	// latest resolves to the latest version.
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.0.1"))
+}
+
+func Test_ociRepository_GetFile(t *testing.T) {
+	registry := newFakeOCIRegistry().
+		WithArtifact("capi/cluster-api", "v1.0.0", map[string]string{
+			"core-components.yaml":   "components",
+			"metadata.yaml":          "metadata",
+			"templates/cluster.yaml": "template",
+		}).
+		WithArtifact("capi/cluster-api", "v1.0.1", map[string]string{
+			"core-components.yaml": "components v1.0.1",
+		})
+	defer registry.Close()
+	registry.CorruptBlob("capi/cluster-api", "v1.0.1", "core-components.yaml")
+
+	tests := []struct {
+		name    string
+		version string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "get the components file",
+			version: "v1.0.0",
+			path:    "core-components.yaml",
+			want:    "components",
+		},
+		{
+			name:    "get the metadata file",
+			version: "v1.0.0",
+			path:    "metadata.yaml",
+			want:    "metadata",
+		},
+		{
+			name:    "get a file in a folder",
+			version: "v1.0.0",
+			path:    "templates/cluster.yaml",
+			want:    "template",
+		},
+		{
+			name:    "file does not exist",
+			version: "v1.0.0",
+			path:    "cluster-template.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "version does not exist",
+			version: "v2.0.0",
+			path:    "core-components.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "file does not match the digest",
+			version: "v1.0.1",
+			path:    "core-components.yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := newOCIRepository(registry.Provider("capi/cluster-api:v1.0.0/core-components.yaml"), test.NewFakeVariableClient())
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.path)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_ociRepository_authentication(t *testing.T) {
+	tests := []struct {
+		name           string
+		variableClient config.VariablesClient
+		wantErr        bool
+	}{
+		{
+			name:           "get a token using the credentials",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password"),
+		},
+		{
+			name:           "fails with invalid credentials",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "wrong"),
+			wantErr:        true,
+		},
+		{
+			name:           "fails without credentials",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			registry := newFakeOCIRegistry().
+				WithArtifact("capi/cluster-api", "v1.0.0", map[string]string{"core-components.yaml": "components"}).
+				WithCredentials("user", "password")
+			defer registry.Close()
+
+			repo, err := newOCIRepository(registry.Provider("capi/cluster-api:v1.0.0/core-components.yaml"), tt.variableClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile("v1.0.0", "core-components.yaml")
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal("components"))
+		})
+	}
+}
+
+// fakeOCIRegistry is an in-process registry implementing the subset of the OCI distribution API used by ociRepository,
+// served over plain HTTP on the loopback interface as local registries are.
+type fakeOCIRegistry struct {
+	server    *httptest.Server
+	manifests map[string]map[string]*ociManifest
+	blobs     map[string][]byte
+	pageSize  int
+	username  string
+	password  string
+}
+
+func newFakeOCIRegistry() *fakeOCIRegistry {
+	f := &fakeOCIRegistry{
+		manifests: map[string]map[string]*ociManifest{},
+		blobs:     map[string][]byte{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeOCIRegistry) WithArtifact(repository, tag string, files map[string]string) *fakeOCIRegistry {
+	manifest := &ociManifest{}
+	for name, content := range files {
+		sum := sha256.Sum256([]byte(content))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		f.blobs[digest] = []byte(content)
+		manifest.Layers = append(manifest.Layers, ociDescriptor{
+			MediaType:   "application/vnd.oci.image.layer.v1.tar",
+			Digest:      digest,
+			Size:        int64(len(content)),
+			Annotations: map[string]string{ociTitleAnnotation: name},
+		})
+	}
+	if f.manifests[repository] == nil {
+		f.manifests[repository] = map[string]*ociManifest{}
+	}
+	f.manifests[repository][tag] = manifest
+	return f
+}
+
+func (f *fakeOCIRegistry) WithPageSize(pageSize int) *fakeOCIRegistry {
+	f.pageSize = pageSize
+	return f
+}
+
+func (f *fakeOCIRegistry) WithCredentials(username, password string) *fakeOCIRegistry {
+	f.username = username
+	f.password = password
+	return f
+}
+
+// CorruptBlob changes the content of a file, so it does not match the digest anymore.
+func (f *fakeOCIRegistry) CorruptBlob(repository, tag, name string) {
+	for _, layer := range f.manifests[repository][tag].Layers {
+		if layer.Annotations[ociTitleAnnotation] == name {
+			f.blobs[layer.Digest] = []byte("corrupted")
+		}
+	}
+}
+
+func (f *fakeOCIRegistry) Provider(path string) config.Provider {
+	return config.NewProvider("test", fmt.Sprintf("oci://%s/%s", strings.TrimPrefix(f.server.URL, "http://"), path), clusterctlv1.CoreProviderType)
+}
+
+func (f *fakeOCIRegistry) Close() {
+	f.server.Close()
+}
+
+func (f *fakeOCIRegistry) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	const token = "fake-token"
+
+	if r.URL.Path == "/token" {
+		if username, password, ok := r.BasicAuth(); !ok || username != f.username || password != f.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+		return
+	}
+
+	if f.username != "" && r.Header.Get("Authorization") != "Bearer "+token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, f.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(path, "/tags/list"):
+		repository := strings.TrimSuffix(path, "/tags/list")
+		tags := []string{}
+		for tag := range f.manifests[repository] {
+			if tag > r.URL.Query().Get("last") {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		if f.pageSize > 0 && (n == 0 || n > f.pageSize) {
+			n = f.pageSize
+		}
+		if n > 0 && len(tags) > n {
+			tags = tags[:n]
+			w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=%d&last=%s>; rel="next"`, repository, n, tags[n-1]))
+		}
+		_ = json.NewEncoder(w).Encode(&ociTagList{Name: repository, Tags: tags})
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		manifest, ok := f.manifests[path[:i]][path[i+len("/manifests/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ociManifestMediaType)
+		_ = json.NewEncoder(w).Encode(manifest)
+	case strings.Contains(path, "/blobs/"):
+		i := strings.LastIndex(path, "/blobs/")
+		blob, ok := f.blobs[path[i+len("/blobs/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
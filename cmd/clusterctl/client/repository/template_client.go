@@ -26,6 +26,9 @@ import (
 // TemplateClient has methods to work with cluster templates hosted on a provider repository.
 // Templates are yaml files to be used for creating a guest cluster.
 type TemplateClient interface {
+	// Raw returns the template for the flavor specified, without any processing.
+	Raw(flavor string) ([]byte, error)
+
 	Get(flavor, targetNamespace string, listVariablesOnly bool) (Template, error)
 }
 
@@ -62,16 +65,37 @@ func newTemplateClient(input TemplateClientInput) *templateClient {
 	}
 }
 
+// Raw return the template for the flavor specified, without applying any processing.
+// In case the template does not exists, an error is returned.
+func (c *templateClient) Raw(flavor string) ([]byte, error) {
+	return c.getRawBytes(flavor)
+}
+
 // Get return the template for the flavor specified.
 // In case the template does not exists, an error is returned.
 // Get assumes the following naming convention for templates: cluster-template[-<flavor_name>].yaml.
 func (c *templateClient) Get(flavor, targetNamespace string, skipTemplateProcess bool) (Template, error) {
-	log := logf.Log
-
 	if targetNamespace == "" {
 		return nil, errors.New("invalid arguments: please provide a targetNamespace")
 	}
 
+	rawArtifact, err := c.getRawBytes(flavor)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTemplate(TemplateInput{
+		rawArtifact,
+		c.configVariablesClient,
+		c.processor,
+		targetNamespace,
+		skipTemplateProcess,
+	})
+}
+
+func (c *templateClient) getRawBytes(flavor string) ([]byte, error) {
+	log := logf.Log
+
 	version := c.version
 	name := c.processor.GetTemplateName(version, flavor)
 
@@ -95,12 +119,5 @@ func (c *templateClient) Get(flavor, targetNamespace string, skipTemplateProcess
 	} else {
 		log.V(1).Info("Using", "Override", name, "Provider", c.provider.ManifestLabel(), "Version", version)
 	}
-
-	return NewTemplate(TemplateInput{
-		rawArtifact,
-		c.configVariablesClient,
-		c.processor,
-		targetNamespace,
-		skipTemplateProcess,
-	})
+	return rawArtifact, nil
 }
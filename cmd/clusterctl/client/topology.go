@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// TopologyPlanOptions define options for TopologyPlan.
+type TopologyPlanOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Objs are the modified (or new) Clusters, ClusterClasses or templates to be used instead of the corresponding
+	// objects in the management cluster.
+	Objs []unstructured.Unstructured
+
+	// Cluster is the name of the Cluster to compute the plan for. If empty, the plan is computed
+	// for all the Clusters affected by the given objects.
+	Cluster string
+
+	// Namespace is the namespace used for objects without a namespace. If unspecified, the current namespace will be used.
+	Namespace string
+}
+
+// TopologyPlan computes the changes the topology controller would apply to the Clusters affected by the
+// given objects, using the current state of the management cluster, without applying them.
+func (c *clusterctlClient) TopologyPlan(options TopologyPlanOptions) (*TopologyPlanOutput, error) {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().CheckCAPIContract(); err != nil {
+		return nil, err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	if len(options.Objs) == 0 {
+		return nil, errors.New("at least one object must be provided")
+	}
+	objs := make([]*unstructured.Unstructured, 0, len(options.Objs))
+	for i := range options.Objs {
+		objs = append(objs, options.Objs[i].DeepCopy())
+	}
+
+	out, err := clusterClient.Topology().Plan(&cluster.TopologyPlanInput{
+		Objs:              objs,
+		TargetClusterName: options.Cluster,
+		TargetNamespace:   options.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*TopologyPlanOutput)(out), nil
+}
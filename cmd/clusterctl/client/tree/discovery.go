@@ -18,10 +18,19 @@ package tree
 
 import (
 	"context"
+	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -97,19 +106,65 @@ func Discovery(ctx context.Context, c client.Client, namespace, name string, opt
 		addMachineFunc(controlPLane, cp)
 	}
 
-	if len(machinesList.Items) == len(controlPlaneMachines) {
-		return tree, nil
+	// Adds managed external etcd and etcd machines.
+	if etcdCluster, err := external.Get(ctx, c, cluster.Spec.ManagedExternalEtcdRef, cluster.Namespace); err == nil {
+		tree.Add(cluster, etcdCluster, ObjectMetaName("ManagedExternalEtcd"), GroupingObject(true))
+
+		etcdMachines := selectEtcdMachines(machinesList)
+		for i := range etcdMachines {
+			addMachineFunc(etcdCluster, etcdMachines[i])
+		}
 	}
 
-	workers := VirtualObject(cluster.Namespace, "WorkerGroup", "Workers")
-	tree.Add(cluster, workers)
+	// Adds the ClusterResourceSets applied to the cluster.
+	if err := addClusterResourceSets(ctx, c, tree, cluster); err != nil {
+		return nil, err
+	}
+
+	// Adds the MachineHealthChecks for the cluster.
+	if err := addMachineHealthChecks(ctx, c, tree, cluster, machinesList); err != nil {
+		return nil, err
+	}
 
-	// Adds worker machines.
 	machinesDeploymentList, err := getMachineDeploymentsInCluster(ctx, c, cluster.Namespace, cluster.Name)
 	if err != nil {
 		return nil, err
 	}
 
+	// Adds the ClusterClass and the objects owned by the cluster topology.
+	if cluster.Spec.Topology != nil {
+		addTopology(ctx, c, tree, cluster, controlPLane, machinesDeploymentList)
+	}
+
+	machinePoolList, err := getMachinePoolsInCluster(ctx, c, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(machinesList.Items) == len(machineMap) && len(machinePoolList.Items) == 0 {
+		return tree, nil
+	}
+
+	workers := VirtualObject(cluster.Namespace, "WorkerGroup", "Workers")
+	tree.Add(cluster, workers)
+
+	// Adds machine pools.
+	for i := range machinePoolList.Items {
+		mp := &machinePoolList.Items[i]
+		_, visible := tree.Add(workers, mp, GroupingObject(true))
+
+		if visible {
+			if machinePoolInfra, err := external.Get(ctx, c, &mp.Spec.Template.Spec.InfrastructureRef, cluster.Namespace); err == nil {
+				tree.Add(mp, machinePoolInfra, ObjectMetaName("MachinePoolInfrastructure"), NoEcho(true))
+			}
+
+			if machinePoolBootstrap, err := external.Get(ctx, c, mp.Spec.Template.Spec.Bootstrap.ConfigRef, cluster.Namespace); err == nil {
+				tree.Add(mp, machinePoolBootstrap, ObjectMetaName("BootstrapConfig"), NoEcho(true))
+			}
+		}
+	}
+
+	// Adds worker machines.
 	machineSetList, err := getMachineSetsInCluster(ctx, c, cluster.Namespace, cluster.Name)
 	if err != nil {
 		return nil, err
@@ -147,6 +202,157 @@ func Discovery(ctx context.Context, c client.Client, namespace, name string, opt
 	return tree, nil
 }
 
+// addClusterResourceSets adds the ClusterResourceSets applied to the cluster, each one with the resources
+// it defines and whether they have been applied, as recorded in the ClusterResourceSetBinding for the cluster.
+func addClusterResourceSets(ctx context.Context, c client.Client, tree *ObjectTree, cluster *clusterv1.Cluster) error {
+	binding := &addonsv1.ClusterResourceSetBinding{}
+	bindingKey := client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name,
+	}
+	if err := c.Get(ctx, bindingKey, binding); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if len(binding.Spec.Bindings) == 0 {
+		return nil
+	}
+
+	clusterResourceSets := VirtualObject(cluster.Namespace, "ClusterResourceSetGroup", "ClusterResourceSets")
+	tree.Add(cluster, clusterResourceSets)
+
+	for _, b := range binding.Spec.Bindings {
+		var crs client.Object = &addonsv1.ClusterResourceSet{}
+		crsKey := client.ObjectKey{
+			Namespace: cluster.Namespace,
+			Name:      b.ClusterResourceSetName,
+		}
+		if err := c.Get(ctx, crsKey, crs); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			// Shows the resources applied also if the ClusterResourceSet has been deleted in the meantime.
+			crs = virtualChild(clusterResourceSets, "ClusterResourceSet", b.ClusterResourceSetName)
+		}
+		tree.Add(clusterResourceSets, crs, GroupingObject(true))
+
+		for _, r := range b.Resources {
+			resource := virtualChild(crs, "Resource", fmt.Sprintf("%s/%s", r.Kind, r.Name))
+			if r.Applied {
+				ready := conditions.TrueCondition(clusterv1.ReadyCondition)
+				if r.LastAppliedTime != nil {
+					ready.LastTransitionTime = *r.LastAppliedTime
+				}
+				setReadyCondition(resource, ready)
+			} else {
+				setReadyCondition(resource, conditions.FalseCondition(clusterv1.ReadyCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, "Resource not applied to the cluster"))
+			}
+			tree.Add(crs, resource)
+		}
+	}
+	return nil
+}
+
+// addMachineHealthChecks adds the MachineHealthChecks for the cluster, each one with the machines it targets;
+// the ready condition of a target shows if the remediation is in progress, otherwise the result of the health check.
+func addMachineHealthChecks(ctx context.Context, c client.Client, tree *ObjectTree, cluster *clusterv1.Cluster, machineList *clusterv1.MachineList) error {
+	machineHealthCheckList := &clusterv1.MachineHealthCheckList{}
+	if err := c.List(ctx, machineHealthCheckList, client.InNamespace(cluster.Namespace)); err != nil {
+		return err
+	}
+
+	machines := map[string]*clusterv1.Machine{}
+	for i := range machineList.Items {
+		m := &machineList.Items[i]
+		machines[m.Name] = m
+	}
+
+	for i := range machineHealthCheckList.Items {
+		mhc := &machineHealthCheckList.Items[i]
+		if mhc.Spec.ClusterName != cluster.Name {
+			continue
+		}
+		tree.Add(cluster, mhc, GroupingObject(true))
+
+		for _, name := range mhc.Status.Targets {
+			target := virtualChild(mhc, "Machine", fmt.Sprintf("Machine/%s", name))
+			if m, ok := machines[name]; ok {
+				if ready := getRemediationCondition(m); ready != nil {
+					setReadyCondition(target, ready)
+				}
+			}
+			tree.Add(mhc, target)
+		}
+	}
+	return nil
+}
+
+// getRemediationCondition returns the OwnerRemediated condition as a ready condition if the remediation of the machine
+// is in progress, otherwise the HealthCheckSucceeded condition.
+func getRemediationCondition(m *clusterv1.Machine) *clusterv1.Condition {
+	condition := conditions.Get(m, clusterv1.MachineOwnerRemediatedCondition)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		condition = conditions.Get(m, clusterv1.MachineHealthCheckSuccededCondition)
+	}
+	if condition == nil {
+		return nil
+	}
+	ready := condition.DeepCopy()
+	ready.Type = clusterv1.ReadyCondition
+	return ready
+}
+
+// addTopology adds the ClusterClass of a cluster with a managed topology and the templates owned by the topology.
+func addTopology(ctx context.Context, c client.Client, tree *ObjectTree, cluster *clusterv1.Cluster, controlPlane *unstructured.Unstructured, machineDeploymentList *clusterv1.MachineDeploymentList) {
+	topology := VirtualObject(cluster.Namespace, "TopologyGroup", "Topology")
+	tree.Add(cluster, topology)
+
+	clusterClass := &clusterv1.ClusterClass{}
+	clusterClassKey := client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Spec.Topology.Class,
+	}
+	if err := c.Get(ctx, clusterClassKey, clusterClass); err == nil {
+		tree.Add(topology, clusterClass, ObjectMetaName("ClusterClass"))
+	}
+
+	addTopologyOwnedFunc := func(ref *corev1.ObjectReference, metaName string) {
+		obj, err := external.Get(ctx, c, ref, cluster.Namespace)
+		if err != nil {
+			return
+		}
+		if _, ok := obj.GetLabels()[clusterv1.ClusterTopologyOwnedLabel]; ok {
+			tree.Add(topology, obj, ObjectMetaName(metaName))
+		}
+	}
+
+	if controlPlane != nil {
+		ref := &corev1.ObjectReference{}
+		if fields, ok, err := unstructured.NestedStringMap(controlPlane.Object, "spec", "machineTemplate", "infrastructureRef"); err == nil && ok {
+			ref.APIVersion = fields["apiVersion"]
+			ref.Kind = fields["kind"]
+			ref.Name = fields["name"]
+			addTopologyOwnedFunc(ref, "ControlPlaneInfrastructureTemplate")
+		}
+	}
+
+	for i := range machineDeploymentList.Items {
+		md := &machineDeploymentList.Items[i]
+		addTopologyOwnedFunc(&md.Spec.Template.Spec.InfrastructureRef, "MachineInfrastructureTemplate")
+		addTopologyOwnedFunc(md.Spec.Template.Spec.Bootstrap.ConfigRef, "BootstrapConfigTemplate")
+	}
+}
+
+// virtualChild returns a virtual object with an UID unique for the given parent, thus allowing
+// to show the same item, e.g. a Machine, under different parents.
+func virtualChild(parent client.Object, kind, name string) *unstructured.Unstructured {
+	obj := VirtualObject(parent.GetNamespace(), kind, name)
+	obj.SetUID(types.UID(fmt.Sprintf("%s, %s/%s/%s/%s", obj.GetAPIVersion(), parent.GetNamespace(), parent.GetObjectKind().GroupVersionKind().Kind, parent.GetName(), name)))
+	return obj
+}
+
 func getMachinesInCluster(ctx context.Context, c client.Client, namespace, name string) (*clusterv1.MachineList, error) {
 	if name == "" {
 		return nil, nil
@@ -177,6 +383,18 @@ func getMachineDeploymentsInCluster(ctx context.Context, c client.Client, namesp
 	return machineDeploymentList, nil
 }
 
+func getMachinePoolsInCluster(ctx context.Context, c client.Client, namespace, name string) (*expv1.MachinePoolList, error) {
+	machinePoolList := &expv1.MachinePoolList{}
+	labels := map[string]string{clusterv1.ClusterLabelName: name}
+
+	// NOTE: MachinePools are an experimental feature, so the CRD could be not installed.
+	if err := c.List(ctx, machinePoolList, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	return machinePoolList, nil
+}
+
 func getMachineSetsInCluster(ctx context.Context, c client.Client, namespace, name string) (*clusterv1.MachineSetList, error) {
 	if name == "" {
 		return nil, nil
@@ -203,6 +421,17 @@ func selectControlPlaneMachines(machineList *clusterv1.MachineList) []*clusterv1
 	return machines
 }
 
+func selectEtcdMachines(machineList *clusterv1.MachineList) []*clusterv1.Machine {
+	machines := []*clusterv1.Machine{}
+	for i := range machineList.Items {
+		m := &machineList.Items[i]
+		if util.IsEtcdMachine(m) {
+			machines = append(machines, m)
+		}
+	}
+	return machines
+}
+
 func selectMachinesSetsControlledBy(machineSetList *clusterv1.MachineSetList, controller client.Object) []*clusterv1.MachineSet {
 	machineSets := []*clusterv1.MachineSet{}
 	for i := range machineSetList.Items {
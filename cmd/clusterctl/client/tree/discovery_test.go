@@ -18,12 +18,18 @@ package tree
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -268,6 +274,180 @@ func Test_Discovery(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Discovery with machine pools and managed external etcd",
+			args: args{
+				discoverOptions: DiscoverOptions{},
+				objs: test.NewFakeCluster("ns1", "cluster1").
+					WithManagedExternalEtcd(
+						test.NewFakeManagedExternalEtcd("etcd").
+							WithMachines("etcd1"),
+					).
+					WithMachinePools(
+						test.NewFakeMachinePool("mp1"),
+					).
+					Objs(),
+			},
+			wantTree: map[string][]string{
+				// Cluster should be parent of InfrastructureCluster, ManagedExternalEtcd, and WorkerNodes
+				"cluster.x-k8s.io/v1beta1, Kind=Cluster, ns1/cluster1": {
+					"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureCluster, ns1/cluster1",
+					"etcdcluster.cluster.x-k8s.io/v1beta1, Kind=GenericEtcdCluster, ns1/etcd",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/Workers",
+				},
+				// ManagedExternalEtcd should have the etcd machine
+				"etcdcluster.cluster.x-k8s.io/v1beta1, Kind=GenericEtcdCluster, ns1/etcd": {
+					"cluster.x-k8s.io/v1beta1, Kind=Machine, ns1/etcd1",
+				},
+				// Workers should have a machine pool
+				"virtual.cluster.x-k8s.io/v1beta1, ns1/Workers": {
+					"cluster.x-k8s.io/v1beta1, Kind=MachinePool, ns1/mp1",
+				},
+				// Machine pool should be leaf (no echo)
+				"cluster.x-k8s.io/v1beta1, Kind=MachinePool, ns1/mp1": {},
+			},
+			wantNodeCheck: map[string]nodeCheck{
+				// ManagedExternalEtcd should have a meta name, be a grouping object
+				"etcdcluster.cluster.x-k8s.io/v1beta1, Kind=GenericEtcdCluster, ns1/etcd": func(g *WithT, obj client.Object) {
+					g.Expect(GetMetaName(obj)).To(Equal("ManagedExternalEtcd"))
+					g.Expect(IsGroupingObject(obj)).To(BeTrue())
+				},
+				// Machine pool should be a grouping object
+				"cluster.x-k8s.io/v1beta1, Kind=MachinePool, ns1/mp1": func(g *WithT, obj client.Object) {
+					g.Expect(IsGroupingObject(obj)).To(BeTrue())
+				},
+			},
+		},
+		{
+			name: "Discovery with ClusterResourceSets and MachineHealthChecks",
+			args: args{
+				discoverOptions: DiscoverOptions{},
+				objs: func() []client.Object {
+					objs := test.NewFakeCluster("ns1", "cluster1").
+						WithMachineDeployments(
+							test.NewFakeMachineDeployment("md1").
+								WithMachineSets(
+									test.NewFakeMachineSet("ms1").
+										WithMachines(
+											test.NewFakeMachine("m1"),
+											test.NewFakeMachine("m2"),
+											test.NewFakeMachine("m3"),
+										),
+								),
+						).
+						Objs()
+					cluster := objs[0].(*clusterv1.Cluster)
+
+					objs = append(objs, test.NewFakeClusterResourceSet("ns1", "crs1").
+						WithSecret("s1").
+						WithConfigMap("cm1").
+						ApplyToCluster(cluster).
+						Objs()...)
+					setResourceApplied(objs, "Secret", "s1")
+
+					objs = append(objs, fakeMachineHealthCheck("mhc1", "cluster1", "m1", "m2", "m3"))
+					setMachineConditions(objs, "m1", conditions.TrueCondition(clusterv1.MachineHealthCheckSuccededCondition))
+					setMachineConditions(objs, "m2", conditions.TrueCondition(clusterv1.MachineHealthCheckSuccededCondition))
+					setMachineConditions(objs, "m3",
+						conditions.FalseCondition(clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, ""),
+						conditions.FalseCondition(clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, ""),
+					)
+					return objs
+				}(),
+			},
+			wantTree: map[string][]string{
+				// Cluster should be parent of InfrastructureCluster, ClusterResourceSets, MachineHealthChecks and WorkerNodes
+				"cluster.x-k8s.io/v1beta1, Kind=Cluster, ns1/cluster1": {
+					"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureCluster, ns1/cluster1",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/ClusterResourceSets",
+					"cluster.x-k8s.io/v1beta1, Kind=MachineHealthCheck, ns1/mhc1",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/Workers",
+				},
+				// ClusterResourceSets should have the ClusterResourceSet applied to the cluster
+				"virtual.cluster.x-k8s.io/v1beta1, ns1/ClusterResourceSets": {
+					"addons.cluster.x-k8s.io/v1beta1, Kind=ClusterResourceSet, ns1/crs1",
+				},
+				// ClusterResourceSet should have a resource for each resource in the binding
+				"addons.cluster.x-k8s.io/v1beta1, Kind=ClusterResourceSet, ns1/crs1": {
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/ClusterResourceSet/crs1/Secret/s1",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/ClusterResourceSet/crs1/ConfigMap/cm1",
+				},
+				// MachineHealthCheck should have a group of healthy machines and the machine being remediated
+				"cluster.x-k8s.io/v1beta1, Kind=MachineHealthCheck, ns1/mhc1": {
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/zz_True",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/MachineHealthCheck/mhc1/Machine/m3",
+				},
+			},
+			wantNodeCheck: map[string]nodeCheck{
+				// Applied resources should be ready
+				"virtual.cluster.x-k8s.io/v1beta1, ns1/ClusterResourceSet/crs1/Secret/s1": func(g *WithT, obj client.Object) {
+					g.Expect(GetReadyCondition(obj).Status).To(Equal(corev1.ConditionTrue))
+				},
+				// Resources not applied should not be ready
+				"virtual.cluster.x-k8s.io/v1beta1, ns1/ClusterResourceSet/crs1/ConfigMap/cm1": func(g *WithT, obj client.Object) {
+					g.Expect(GetReadyCondition(obj).Status).To(Equal(corev1.ConditionFalse))
+					g.Expect(GetReadyCondition(obj).Reason).To(Equal(addonsv1.ApplyFailedReason))
+				},
+				// MachineHealthCheck should be a grouping object
+				"cluster.x-k8s.io/v1beta1, Kind=MachineHealthCheck, ns1/mhc1": func(g *WithT, obj client.Object) {
+					g.Expect(IsGroupingObject(obj)).To(BeTrue())
+				},
+				// Machine being remediated should show the remediation in progress
+				"virtual.cluster.x-k8s.io/v1beta1, ns1/MachineHealthCheck/mhc1/Machine/m3": func(g *WithT, obj client.Object) {
+					g.Expect(GetReadyCondition(obj).Status).To(Equal(corev1.ConditionFalse))
+					g.Expect(GetReadyCondition(obj).Reason).To(Equal(clusterv1.WaitingForRemediationReason))
+				},
+			},
+		},
+		{
+			name: "Discovery with topology",
+			args: args{
+				discoverOptions: DiscoverOptions{},
+				objs: func() []client.Object {
+					infrastructureTemplate := test.NewFakeInfrastructureTemplate("md1-infra")
+					infrastructureTemplate.Labels = map[string]string{clusterv1.ClusterTopologyOwnedLabel: ""}
+
+					objs := test.NewFakeCluster("ns1", "cluster1").
+						WithTopologyClass("class1").
+						WithMachineDeployments(
+							test.NewFakeMachineDeployment("md1").
+								WithInfrastructureTemplate(infrastructureTemplate).
+								WithMachineSets(
+									test.NewFakeMachineSet("ms1").
+										WithMachines(
+											test.NewFakeMachine("m1"),
+										),
+								),
+						).
+						Objs()
+					objs = append(objs, infrastructureTemplate)
+					return append(objs, test.NewFakeClusterClass("ns1", "class1").Objs()...)
+				}(),
+			},
+			wantTree: map[string][]string{
+				// Cluster should be parent of InfrastructureCluster, Topology and WorkerNodes
+				"cluster.x-k8s.io/v1beta1, Kind=Cluster, ns1/cluster1": {
+					"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureCluster, ns1/cluster1",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/Topology",
+					"virtual.cluster.x-k8s.io/v1beta1, ns1/Workers",
+				},
+				// Topology should have the ClusterClass and the templates owned by the topology
+				"virtual.cluster.x-k8s.io/v1beta1, ns1/Topology": {
+					"cluster.x-k8s.io/v1beta1, Kind=ClusterClass, ns1/class1",
+					"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureMachineTemplate, ns1/md1-infra",
+				},
+			},
+			wantNodeCheck: map[string]nodeCheck{
+				// ClusterClass should have a meta name
+				"cluster.x-k8s.io/v1beta1, Kind=ClusterClass, ns1/class1": func(g *WithT, obj client.Object) {
+					g.Expect(GetMetaName(obj)).To(Equal("ClusterClass"))
+				},
+				// Templates owned by the topology should have a meta name
+				"infrastructure.cluster.x-k8s.io/v1beta1, Kind=GenericInfrastructureMachineTemplate, ns1/md1-infra": func(g *WithT, obj client.Object) {
+					g.Expect(GetMetaName(obj)).To(Equal("MachineInfrastructureTemplate"))
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -303,3 +483,50 @@ func Test_Discovery(t *testing.T) {
 		})
 	}
 }
+
+func fakeMachineHealthCheck(name, clusterName string, targets ...string) *clusterv1.MachineHealthCheck {
+	mhc := &clusterv1.MachineHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachineHealthCheck",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns1",
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: clusterName,
+		},
+		Status: clusterv1.MachineHealthCheckStatus{
+			Targets: targets,
+		},
+	}
+	mhc.SetUID(types.UID(fmt.Sprintf("%s, %s/%s", mhc.GroupVersionKind().String(), mhc.Namespace, mhc.Name)))
+	return mhc
+}
+
+func setMachineConditions(objs []client.Object, name string, c ...*clusterv1.Condition) {
+	for _, o := range objs {
+		if m, ok := o.(*clusterv1.Machine); ok && m.Name == name {
+			for i := range c {
+				conditions.Set(m, c[i])
+			}
+		}
+	}
+}
+
+func setResourceApplied(objs []client.Object, kind, name string) {
+	for _, o := range objs {
+		binding, ok := o.(*addonsv1.ClusterResourceSetBinding)
+		if !ok {
+			continue
+		}
+		for _, b := range binding.Spec.Bindings {
+			for i := range b.Resources {
+				if b.Resources[i].Kind == kind && b.Resources[i].Name == name {
+					b.Resources[i].Applied = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"sort"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectNode is a serializable representation of an object in the object tree, e.g. for the json or yaml output.
+type ObjectNode struct {
+	// APIVersion and Kind of the object; virtual objects have the virtual.cluster.x-k8s.io group, while
+	// group objects have the kind of the grouped objects followed by Group, e.g. MachineGroup.
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Name of the object; it is empty for group objects.
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the object.
+	Namespace string `json:"namespace,omitempty"`
+
+	// MetaName is the name used for the object in the presentation layer, e.g. ControlPlane.
+	MetaName string `json:"metaName,omitempty"`
+
+	// Virtual is true if the object does not correspond to any real object, e.g. Workers.
+	Virtual bool `json:"virtual,omitempty"`
+
+	// Deleting is true if the object is being deleted.
+	Deleting bool `json:"deleting,omitempty"`
+
+	// Ready is the ready condition of the object, if any.
+	Ready *clusterv1.Condition `json:"ready,omitempty"`
+
+	// GroupItems are the names of the objects in a group object.
+	GroupItems []string `json:"groupItems,omitempty"`
+
+	// OtherConditions are the conditions of the object except the ready condition; they are reported
+	// only for the objects the presentation layer should show all the conditions for.
+	OtherConditions []clusterv1.Condition `json:"otherConditions,omitempty"`
+
+	// Children are the objects depending on the object.
+	Children []ObjectNode `json:"children,omitempty"`
+}
+
+// ToObjectNode returns a serializable representation of the object tree, starting from the root.
+// NOTE: Children are sorted by kind and name, so the representation of a tree is stable.
+func (od ObjectTree) ToObjectNode() ObjectNode {
+	return od.toObjectNode(od.root)
+}
+
+func (od ObjectTree) toObjectNode(obj client.Object) ObjectNode {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	node := ObjectNode{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		MetaName:   GetMetaName(obj),
+		Virtual:    IsVirtualObject(obj),
+		Deleting:   !obj.GetDeletionTimestamp().IsZero(),
+		Ready:      GetReadyCondition(obj),
+	}
+
+	// NOTE: The name of a group object is generated, so it is replaced by the names of the grouped objects.
+	if IsGroupObject(obj) {
+		node.Name = ""
+		node.GroupItems = strings.Split(GetGroupItems(obj), GroupItemsSeparator)
+	}
+
+	if IsShowConditionsObject(obj) {
+		for _, c := range GetOtherConditions(obj) {
+			node.OtherConditions = append(node.OtherConditions, *c)
+		}
+	}
+
+	for _, child := range od.GetObjectsByParent(obj.GetUID()) {
+		node.Children = append(node.Children, od.toObjectNode(child))
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		return node.Children[i].sortKey() < node.Children[j].sortKey()
+	})
+
+	return node
+}
+
+func (n ObjectNode) sortKey() string {
+	if len(n.GroupItems) > 0 {
+		return n.Kind + "/" + n.GroupItems[0]
+	}
+	return n.Kind + "/" + n.Name
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_ObjectTree_ToObjectNode(t *testing.T) {
+	g := NewWithT(t)
+
+	root := fakeCluster("cluster",
+		withClusterCondition(conditions.TrueCondition(clusterv1.ReadyCondition)),
+		withClusterCondition(conditions.FalseCondition(clusterv1.ControlPlaneReadyCondition, "Scaling", clusterv1.ConditionSeverityInfo, "")),
+	)
+	tree := NewObjectTree(root, ObjectTreeOptions{ShowOtherConditions: "Machine/m3"})
+
+	workers := VirtualObject("ns", "WorkerGroup", "Workers")
+	tree.Add(root, workers, GroupingObject(true))
+	tree.Add(workers, fakeMachine("m2", withMachineCondition(conditions.TrueCondition(clusterv1.ReadyCondition))))
+	tree.Add(workers, fakeMachine("m1", withMachineCondition(conditions.TrueCondition(clusterv1.ReadyCondition))))
+	tree.Add(workers, fakeMachine("m3",
+		withMachineCondition(conditions.FalseCondition(clusterv1.ReadyCondition, "Provisioning", clusterv1.ConditionSeverityInfo, "")),
+		withMachineCondition(conditions.FalseCondition(clusterv1.InfrastructureReadyCondition, "Provisioning", clusterv1.ConditionSeverityInfo, "")),
+	))
+
+	got := tree.ToObjectNode()
+
+	// The root should have the ready condition, but not the other conditions, because they are not requested.
+	g.Expect(got.Kind).To(Equal("Cluster"))
+	g.Expect(got.Name).To(Equal("cluster"))
+	g.Expect(got.Ready).ToNot(BeNil())
+	g.Expect(got.Ready.Status).To(BeEquivalentTo("True"))
+	g.Expect(got.OtherConditions).To(BeEmpty())
+
+	// The root should have the Workers virtual object as a child.
+	g.Expect(got.Children).To(HaveLen(1))
+	g.Expect(got.Children[0].Name).To(Equal("Workers"))
+	g.Expect(got.Children[0].Virtual).To(BeTrue())
+
+	// The Workers should have the group of ready machines, and then the machine not ready with all its conditions.
+	children := got.Children[0].Children
+	g.Expect(children).To(HaveLen(2))
+	g.Expect(children[0].Kind).To(Equal("Machine"))
+	g.Expect(children[0].Name).To(Equal("m3"))
+	g.Expect(children[0].Ready.Reason).To(Equal("Provisioning"))
+	g.Expect(children[0].OtherConditions).To(HaveLen(1))
+	g.Expect(children[0].OtherConditions[0].Type).To(Equal(clusterv1.InfrastructureReadyCondition))
+	g.Expect(children[1].Kind).To(Equal("MachineGroup"))
+	g.Expect(children[1].Name).To(BeEmpty())
+	g.Expect(children[1].GroupItems).To(Equal([]string{"m1", "m2"}))
+	g.Expect(children[1].Ready.Status).To(BeEquivalentTo("True"))
+}
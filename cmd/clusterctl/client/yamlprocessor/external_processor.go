@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExternalProcessorOperation defines the operations an external processor should support.
+type ExternalProcessorOperation string
+
+const (
+	// ExternalProcessorGetVariables is the operation requesting the variables used by a template,
+	// with their default values.
+	ExternalProcessorGetVariables ExternalProcessorOperation = "GetVariables"
+
+	// ExternalProcessorProcess is the operation requesting to process a template using the given
+	// variable values.
+	ExternalProcessorProcess ExternalProcessorOperation = "Process"
+)
+
+// ExternalProcessorRequest is the request sent by clusterctl to an external processor, encoded in JSON
+// on the standard input of the processor.
+type ExternalProcessorRequest struct {
+	// Operation to be performed by the external processor.
+	Operation ExternalProcessorOperation `json:"operation"`
+
+	// Template to be inspected or processed.
+	Template string `json:"template"`
+
+	// Variables defines the variable values to be used for processing the template; the values
+	// of the variables with a default value could be missing. Set only for the Process operation.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// ExternalProcessorResponse is the response an external processor should return, encoded in JSON
+// on its standard output.
+type ExternalProcessorResponse struct {
+	// Variables defines the variables used by the template, with their default value
+	// or null if the variable is required. Required for the GetVariables operation.
+	Variables map[string]*string `json:"variables,omitempty"`
+
+	// Template is the processed template. Required for the Process operation.
+	Template string `json:"template,omitempty"`
+
+	// Error reports an error occurred while performing the operation.
+	Error string `json:"error,omitempty"`
+}
+
+// ExternalProcessor is a yaml processor that delegates template processing to an external
+// binary, e.g. for supporting other templating languages.
+//
+// For each operation, the binary is executed reading an ExternalProcessorRequest from the standard
+// input and it is expected to write an ExternalProcessorResponse to the standard output.
+type ExternalProcessor struct {
+	command string
+}
+
+var _ Processor = &ExternalProcessor{}
+
+// NewExternalProcessor returns a new external template processor executing the given command.
+func NewExternalProcessor(command string) *ExternalProcessor {
+	return &ExternalProcessor{
+		command: command,
+	}
+}
+
+// GetTemplateName returns the name of the template that the external processor
+// uses. It follows the cluster template naming convention of
+// "cluster-template<-flavor>.yaml".
+func (tp *ExternalProcessor) GetTemplateName(version, flavor string) string {
+	return NewSimpleProcessor().GetTemplateName(version, flavor)
+}
+
+// GetVariables returns a list of the variables specified in the yaml.
+func (tp *ExternalProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	variables, err := tp.GetVariableMap(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+	varNames := make([]string, 0, len(variables))
+	for k := range variables {
+		varNames = append(varNames, k)
+	}
+	sort.Strings(varNames)
+	return varNames, nil
+}
+
+// GetVariableMap returns a map of the variables specified in the yaml.
+func (tp *ExternalProcessor) GetVariableMap(rawArtifact []byte) (map[string]*string, error) {
+	response, err := tp.run(ExternalProcessorRequest{
+		Operation: ExternalProcessorGetVariables,
+		Template:  string(rawArtifact),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Variables == nil {
+		return map[string]*string{}, nil
+	}
+	return response.Variables, nil
+}
+
+// Process returns the final yaml as processed by the external processor. If there are variables
+// without corresponding values and without a default, it will return the raw yaml along with an error.
+func (tp *ExternalProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	variables, err := tp.GetVariableMap(rawArtifact)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	var missingVariables []string
+	values := map[string]string{}
+	for name, defaultValue := range variables {
+		value, err := variablesClient(name)
+		if err != nil {
+			// add to missingVariables list if the variable does not exist in the
+			// variablesClient AND it does not have a default value
+			if defaultValue == nil {
+				missingVariables = append(missingVariables, name)
+			}
+			continue
+		}
+		values[name] = value
+	}
+
+	if len(missingVariables) > 0 {
+		return rawArtifact, &errMissingVariables{missingVariables}
+	}
+
+	response, err := tp.run(ExternalProcessorRequest{
+		Operation: ExternalProcessorProcess,
+		Template:  string(rawArtifact),
+		Variables: values,
+	})
+	if err != nil {
+		return rawArtifact, err
+	}
+	return []byte(response.Template), nil
+}
+
+// run executes the external processor for a request.
+func (tp *ExternalProcessor) run(request ExternalProcessorRequest) (*ExternalProcessorResponse, error) {
+	if tp.command == "" {
+		return nil, errors.New("invalid external processor: command can't be empty")
+	}
+
+	in, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode the %s request for the external processor", request.Operation)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(tp.command) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to run the external processor %q: %s", tp.command, strings.TrimSpace(stderr.String()))
+	}
+
+	response := &ExternalProcessorResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the %s response from the external processor %q", request.Operation, tp.command)
+	}
+	if response.Error != "" {
+		return nil, errors.Errorf("external processor %q failed the %s operation: %s", tp.command, request.Operation, response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// fakeExternalProcessorEnv instructs the test binary to behave as a fake external processor.
+const fakeExternalProcessorEnv = "CLUSTERCTL_FAKE_EXTERNAL_PROCESSOR"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeExternalProcessorEnv) != "" {
+		os.Exit(runFakeExternalProcessor())
+	}
+	os.Exit(m.Run())
+}
+
+// fakeExternalProcessorVariableRegEx defines the variables for the fake external processor, in the format @VAR@ or @VAR=default@.
+var fakeExternalProcessorVariableRegEx = regexp.MustCompile(`@([A-Z_]+)(=([a-z_]*))?@`)
+
+// runFakeExternalProcessor implements the external processor protocol for templates using the @VAR@ syntax.
+func runFakeExternalProcessor() int {
+	if os.Getenv(fakeExternalProcessorEnv) == "exit" {
+		fmt.Fprint(os.Stderr, "something went wrong")
+		return 1
+	}
+
+	request := &ExternalProcessorRequest{}
+	if err := json.NewDecoder(os.Stdin).Decode(request); err != nil {
+		return 1
+	}
+
+	response := &ExternalProcessorResponse{}
+	switch request.Operation {
+	case ExternalProcessorGetVariables:
+		response.Variables = map[string]*string{}
+		for _, match := range fakeExternalProcessorVariableRegEx.FindAllStringSubmatch(request.Template, -1) {
+			if match[2] == "" {
+				response.Variables[match[1]] = nil
+				continue
+			}
+			defaultValue := match[3]
+			response.Variables[match[1]] = &defaultValue
+		}
+	case ExternalProcessorProcess:
+		response.Template = fakeExternalProcessorVariableRegEx.ReplaceAllStringFunc(request.Template, func(s string) string {
+			match := fakeExternalProcessorVariableRegEx.FindStringSubmatch(s)
+			if v, ok := request.Variables[match[1]]; ok {
+				return v
+			}
+			return match[3]
+		})
+	default:
+		response.Error = fmt.Sprintf("unknown operation %q", request.Operation)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func setFakeExternalProcessor(g *WithT, mode string) *ExternalProcessor {
+	g.Expect(os.Setenv(fakeExternalProcessorEnv, mode)).To(Succeed())
+	return NewExternalProcessor(os.Args[0])
+}
+
+func TestExternalProcessor_GetVariableMap(t *testing.T) {
+	g := NewWithT(t)
+	defer os.Unsetenv(fakeExternalProcessorEnv)
+
+	p := setFakeExternalProcessor(g, "true")
+
+	got, err := p.GetVariableMap([]byte("yaml with @A@ @B=b@ @A@"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal(map[string]*string{"A": nil, "B": pointer.StringPtr("b")}))
+
+	variables, err := p.GetVariables([]byte("yaml with @A@ @B=b@ @A@"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(variables).To(Equal([]string{"A", "B"}))
+}
+
+func TestExternalProcessor_Process(t *testing.T) {
+	g := NewWithT(t)
+	defer os.Unsetenv(fakeExternalProcessorEnv)
+
+	p := setFakeExternalProcessor(g, "true")
+
+	got, err := p.Process([]byte("foo @BAR@ @BAZ=default_baz@"), test.NewFakeVariableClient().WithVar("BAR", "bar").Get)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]byte("foo bar default_baz")))
+
+	yaml := []byte("foo @BAR@ @BAZ@ @CAR@")
+	got, err = p.Process(yaml, test.NewFakeVariableClient().WithVar("CAR", "car").Get)
+	g.Expect(err).To(HaveOccurred())
+	e, ok := err.(*errMissingVariables)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(e.Missing).To(ConsistOf("BAR", "BAZ"))
+	g.Expect(got).To(Equal(yaml))
+}
+
+func TestExternalProcessor_Errors(t *testing.T) {
+	g := NewWithT(t)
+	defer os.Unsetenv(fakeExternalProcessorEnv)
+
+	p := setFakeExternalProcessor(g, "exit")
+	_, err := p.GetVariables([]byte("foo @BAR@"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("something went wrong"))
+
+	_, err = NewExternalProcessor("").GetVariables([]byte("foo @BAR@"))
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewExternalProcessor("not-existing-external-processor").GetVariables([]byte("foo @BAR@"))
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/pkg/errors"
+)
+
+// defaultFuncName is the name of the sprig function used for defining default values for variables.
+const defaultFuncName = "default"
+
+// GoTemplateProcessor is a yaml processor that uses Go templates, with the sprig functions,
+// to process the yaml; variables are referenced as fields of the top level context in the
+// format {{ .VAR }}, and they can have a default value if specified using the sprig default
+// function, in the format {{ .VAR | default "value" }}.
+// Variable values are always strings, so any non-empty value, including "false" and "0", is true in
+// conditionals; booleans and numbers should be converted explicitly, e.g. {{ if eq .VAR "true" }}
+// or {{ if gt (atoi .VAR) 1 }}.
+// See https://pkg.go.dev/text/template and http://masterminds.github.io/sprig/ for more details.
+type GoTemplateProcessor struct{}
+
+var _ Processor = &GoTemplateProcessor{}
+
+// NewGoTemplateProcessor returns a new Go template processor.
+func NewGoTemplateProcessor() *GoTemplateProcessor {
+	return &GoTemplateProcessor{}
+}
+
+// GetTemplateName returns the name of the template that the Go template processor
+// uses. It follows the cluster template naming convention of
+// "cluster-template<-flavor>.yaml".
+func (tp *GoTemplateProcessor) GetTemplateName(version, flavor string) string {
+	return NewSimpleProcessor().GetTemplateName(version, flavor)
+}
+
+// GetVariables returns a list of the variables specified in the yaml.
+func (tp *GoTemplateProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	variables, err := tp.GetVariableMap(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+	varNames := make([]string, 0, len(variables))
+	for k := range variables {
+		varNames = append(varNames, k)
+	}
+	sort.Strings(varNames)
+	return varNames, nil
+}
+
+// GetVariableMap returns a map of the variables specified in the yaml.
+func (tp *GoTemplateProcessor) GetVariableMap(rawArtifact []byte) (map[string]*string, error) {
+	t, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+	return inspectGoTemplateVariables(t), nil
+}
+
+// Process returns the final yaml generated by executing the Go template with the variable
+// values. If there are variables without corresponding values and without a default, it
+// will return the raw yaml along with an error.
+func (tp *GoTemplateProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	t, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	var missingVariables []string
+	values := map[string]interface{}{}
+	for name, defaultValue := range inspectGoTemplateVariables(t) {
+		value, err := variablesClient(name)
+		if err != nil {
+			// add to missingVariables list if the variable does not exist in the
+			// variablesClient AND it does not have a default value
+			if defaultValue == nil {
+				missingVariables = append(missingVariables, name)
+			}
+			continue
+		}
+		values[name] = value
+	}
+
+	if len(missingVariables) > 0 {
+		return rawArtifact, &errMissingVariables{missingVariables}
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, values); err != nil {
+		return rawArtifact, errors.Wrap(err, "failed to execute the Go template")
+	}
+	return out.Bytes(), nil
+}
+
+// goTemplateFuncs returns the functions available in Go templates, that are the sprig
+// functions without the ones reading from the environment, given that all the values
+// should be provided as variables.
+func goTemplateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+	return funcs
+}
+
+func parseGoTemplate(rawArtifact []byte) (*template.Template, error) {
+	t, err := template.New("template").Funcs(goTemplateFuncs()).Parse(string(rawArtifact))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the Go template")
+	}
+	return t, nil
+}
+
+// inspectGoTemplateVariables walks down the parse trees of a template, including the
+// trees of the templates defined inside it, and returns a map of the variable names
+// and their default values (nil if the variable is required).
+func inspectGoTemplateVariables(t *template.Template) map[string]*string {
+	variables := map[string]*string{}
+	for _, tt := range t.Templates() {
+		if tt.Tree == nil || tt.Tree.Root == nil {
+			continue
+		}
+		traverseGoTemplate(tt.Tree.Root, true, variables)
+	}
+	return variables
+}
+
+// traverseGoTemplate recursively walks down a node of the template parse tree; topLevel is true if
+// the dot in the node is the top level context, which is false e.g. in the body of a range action.
+func traverseGoTemplate(node parse.Node, topLevel bool, variables map[string]*string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, ln := range n.Nodes {
+			traverseGoTemplate(ln, topLevel, variables)
+		}
+	case *parse.ActionNode:
+		traverseGoTemplatePipe(n.Pipe, topLevel, variables)
+	case *parse.TemplateNode:
+		traverseGoTemplatePipe(n.Pipe, topLevel, variables)
+	case *parse.IfNode:
+		traverseGoTemplateBranch(&n.BranchNode, topLevel, topLevel, variables)
+	case *parse.RangeNode:
+		// In the body of a range action the dot is set to the successive elements.
+		traverseGoTemplateBranch(&n.BranchNode, topLevel, false, variables)
+	case *parse.WithNode:
+		// In the body of a with action the dot is set to the value of the pipeline.
+		traverseGoTemplateBranch(&n.BranchNode, topLevel, false, variables)
+	}
+}
+
+func traverseGoTemplateBranch(n *parse.BranchNode, topLevel, bodyTopLevel bool, variables map[string]*string) {
+	traverseGoTemplatePipe(n.Pipe, topLevel, variables)
+	traverseGoTemplate(n.List, bodyTopLevel, variables)
+	traverseGoTemplate(n.ElseList, topLevel, variables)
+}
+
+func traverseGoTemplatePipe(pipe *parse.PipeNode, topLevel bool, variables map[string]*string) {
+	if pipe == nil {
+		return
+	}
+
+	// Detects the arguments with a default value, defined either as {{ default "value" .VAR }} or as {{ .VAR | default "value" }}.
+	defaults := map[parse.Node]*string{}
+	for i, cmd := range pipe.Cmds {
+		if len(cmd.Args) < 2 {
+			continue
+		}
+		if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != defaultFuncName {
+			continue
+		}
+		switch {
+		case len(cmd.Args) == 3:
+			defaults[cmd.Args[2]] = goTemplateDefaultValue(cmd.Args[1])
+		case len(cmd.Args) == 2 && i > 0 && len(pipe.Cmds[i-1].Args) == 1:
+			defaults[pipe.Cmds[i-1].Args[0]] = goTemplateDefaultValue(cmd.Args[1])
+		}
+	}
+
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			traverseGoTemplateArg(arg, defaults[arg], topLevel, variables)
+		}
+	}
+}
+
+func traverseGoTemplateArg(arg parse.Node, defaultValue *string, topLevel bool, variables map[string]*string) {
+	if name := goTemplateVariableName(arg, topLevel); name != "" {
+		// A variable is required if at least one of its references does not have a default value.
+		if current, ok := variables[name]; !ok || (current != nil && defaultValue == nil) {
+			variables[name] = defaultValue
+		}
+		return
+	}
+	switch a := arg.(type) {
+	case *parse.PipeNode:
+		traverseGoTemplatePipe(a, topLevel, variables)
+	case *parse.ChainNode:
+		traverseGoTemplateArg(a.Node, nil, topLevel, variables)
+	}
+}
+
+// goTemplateVariableName returns the name of the variable referenced by a node, if any; variables are
+// fields of the top level context, e.g. .VAR (only if the dot is the top level context) or $.VAR.
+func goTemplateVariableName(node parse.Node, topLevel bool) string {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if topLevel {
+			return n.Ident[0]
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return n.Ident[1]
+		}
+	}
+	return ""
+}
+
+// goTemplateDefaultValue returns the default value defined by a node.
+func goTemplateDefaultValue(node parse.Node) *string {
+	defaultValue := node.String()
+	switch d := node.(type) {
+	case *parse.StringNode:
+		defaultValue = d.Text
+	case *parse.NumberNode:
+		defaultValue = d.Text
+	}
+	return &defaultValue
+}
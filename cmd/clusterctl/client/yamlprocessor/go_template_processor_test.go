@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func TestGoTemplateProcessor_GetTemplateName(t *testing.T) {
+	g := NewWithT(t)
+	p := NewGoTemplateProcessor()
+	g.Expect(p.GetTemplateName("some-version", "some-flavor")).To(Equal("cluster-template-some-flavor.yaml"))
+	g.Expect(p.GetTemplateName("", "")).To(Equal("cluster-template.yaml"))
+}
+
+func TestGoTemplateProcessor_GetVariableMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]*string
+		wantErr bool
+	}{
+		{
+			name: "variables are fields of the top level context",
+			data: "yaml with {{ .A }} {{ .B.C }} {{ $.D }} {{ printf \"%s-%s\" .E .F }}",
+			want: map[string]*string{"A": nil, "B": nil, "D": nil, "E": nil, "F": nil},
+		},
+		{
+			name: "variables with default values",
+			data: "yaml with {{ .A | default \"a\" }} {{ default \"b\" .B }} {{ .C | default 3 }} {{ .D | upper | default \"d\" }}",
+			want: map[string]*string{"A": pointer.StringPtr("a"), "B": pointer.StringPtr("b"), "C": pointer.StringPtr("3"), "D": nil},
+		},
+		{
+			name: "variables are required if at least one reference does not have a default value",
+			data: "yaml with {{ .A | default \"a\" }} {{ .A }} {{ .B }} {{ .B | default \"b\" }}",
+			want: map[string]*string{"A": nil, "B": nil},
+		},
+		{
+			name: "variables in conditionals and loops",
+			data: "{{ if .A }}{{ .B }}{{ else }}{{ .C }}{{ end }}{{ range .D }}{{ .name }}{{ $.E }}{{ end }}{{ with .F }}{{ .G }}{{ else }}{{ .H }}{{ end }}",
+			want: map[string]*string{"A": nil, "B": nil, "C": nil, "D": nil, "E": nil, "F": nil, "H": nil},
+		},
+		{
+			name: "variables in defined templates",
+			data: "{{ define \"t\" }}{{ .A }}{{ end }}{{ template \"t\" . }}{{ .B }}",
+			want: map[string]*string{"A": nil, "B": nil},
+		},
+		{
+			name: "variables in nested pipelines",
+			data: "{{ (.A | default \"a\") | upper }} {{ if and .B (eq .C \"c\") }}{{ end }}",
+			want: map[string]*string{"A": pointer.StringPtr("a"), "B": nil, "C": nil},
+		},
+		{
+			name:    "env functions are not available",
+			data:    "{{ env \"HOME\" }}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			data:    "{{ .A ",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			got, err := p.GetVariableMap([]byte(tt.data))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+
+			variables, err := p.GetVariables([]byte(tt.data))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(variables).To(HaveLen(len(tt.want)))
+		})
+	}
+}
+
+func TestGoTemplateProcessor_Process(t *testing.T) {
+	tests := []struct {
+		name                  string
+		yaml                  []byte
+		configVariablesClient config.VariablesClient
+		want                  []byte
+		wantErr               bool
+		missingVariables      []string
+	}{
+		{
+			name: "replaces variables",
+			yaml: []byte("foo {{ .BAR }} {{ .BAR | upper }}"),
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("BAR", "bar"),
+			want: []byte("foo bar BAR"),
+		},
+		{
+			name: "uses default values if variable doesn't exist in variables client",
+			yaml: []byte("foo {{ .BAR | default \"default_bar\" }} {{ .BAZ | default \"default_baz\" }}"),
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("BAR", "bar"),
+			want: []byte("foo bar default_baz"),
+		},
+		{
+			name: "supports conditionals and loops",
+			yaml: []byte("{{ if eq .ENABLED \"true\" }}enabled{{ end }}{{ range $i, $e := until (atoi .COUNT) }} {{ $.PREFIX }}-{{ $i }}{{ end }}"),
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("ENABLED", "true").WithVar("COUNT", "2").WithVar("PREFIX", "md"),
+			want: []byte("enabled md-0 md-1"),
+		},
+		{
+			name: "variable values are strings",
+			yaml: []byte("{{ if .DISABLED }}set{{ end }} {{ if eq .DISABLED \"true\" }}disabled{{ else }}enabled{{ end }} {{ if .ENABLED | default \"false\" | eq \"true\" }}enabled{{ end }} {{ if gt (atoi .REPLICAS) 1 }}ha{{ end }}"),
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("DISABLED", "false").WithVar("REPLICAS", "3"),
+			want: []byte("set enabled  ha"),
+		},
+		{
+			name: "returns error with missing template variables listed (for better ux)",
+			yaml: []byte("foo {{ .BAR }} {{ .BAZ }} {{ .CAR }}"),
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("CAR", "car"),
+			wantErr:          true,
+			missingVariables: []string{"BAR", "BAZ"},
+		},
+		{
+			name: "returns error when the template fails",
+			yaml: []byte("foo {{ fail .BAR }}"),
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("BAR", "bar"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			got, err := p.Process(tt.yaml, tt.configVariablesClient.Get)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				if len(tt.missingVariables) != 0 {
+					e, ok := err.(*errMissingVariables)
+					g.Expect(ok).To(BeTrue())
+					g.Expect(e.Missing).To(ConsistOf(tt.missingVariables))
+				}
+				// we want to ensure that we keep returning the original yaml
+				// as per the intended behavior of Process
+				g.Expect(got).To(Equal(tt.yaml))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
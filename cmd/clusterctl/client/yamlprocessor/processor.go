@@ -17,6 +17,12 @@ limitations under the License.
 // Package yamlprocessor implements YAML processing.
 package yamlprocessor
 
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 // Processor defines the methods necessary for creating a specific yaml
 // processor.
 type Processor interface {
@@ -36,3 +42,37 @@ type Processor interface {
 	// yaml with values retrieved from the values getter
 	Process([]byte, func(string) (string, error)) ([]byte, error)
 }
+
+const (
+	// SimpleProcessorType is the type of the yaml processor replacing variables in the ${VAR} format.
+	SimpleProcessorType = "simple"
+
+	// GoTemplateProcessorType is the type of the yaml processor using Go templates.
+	GoTemplateProcessorType = "go-template"
+
+	// ExternalProcessorType is the type of the yaml processor delegating to an external binary.
+	ExternalProcessorType = "external"
+)
+
+// ProcessorTypes returns the list of the supported yaml processor types.
+func ProcessorTypes() []string {
+	return []string{SimpleProcessorType, GoTemplateProcessorType, ExternalProcessorType}
+}
+
+// NewProcessor returns a yaml processor of the given type; command is
+// required only by the external processor.
+func NewProcessor(processorType, command string) (Processor, error) {
+	switch processorType {
+	case SimpleProcessorType:
+		return NewSimpleProcessor(), nil
+	case GoTemplateProcessorType:
+		return NewGoTemplateProcessor(), nil
+	case ExternalProcessorType:
+		if command == "" {
+			return nil, errors.Errorf("invalid %s processor: command can't be empty", ExternalProcessorType)
+		}
+		return NewExternalProcessor(command), nil
+	default:
+		return nil, errors.Errorf("invalid processor type %q, supported values are %s", processorType, strings.Join(ProcessorTypes(), ", "))
+	}
+}
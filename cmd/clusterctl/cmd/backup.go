@@ -27,6 +27,9 @@ type backupOptions struct {
 	fromKubeconfigContext string
 	namespace             string
 	directory             string
+	archive               string
+	ageRecipients         []string
+	pgpPublicKeyFile      string
 }
 
 var buo = &backupOptions{}
@@ -39,7 +42,14 @@ var backupCmd = &cobra.Command{
 
 	Example: Examples(`
 		Backup Cluster API objects and all dependencies from a management cluster.
-		clusterctl backup --directory=/tmp/backup-directory`),
+		clusterctl backup --directory=/tmp/backup-directory
+
+		Backup Cluster API objects to a gzip'd tar archive encrypted for an age recipient.
+		clusterctl backup --archive=/tmp/backup.tar.gz.age --age-recipient=age1...
+
+		Backup Cluster API objects to an archive encrypted with a PGP public key, stored in a S3 bucket.
+		The S3 credentials are read from the S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY variables.
+		clusterctl backup --archive=s3://my-bucket/backups/backup.tar.gz.pgp --pgp-public-key=/path/to/key.asc`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runBackup()
@@ -55,13 +65,19 @@ func init() {
 		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
 	backupCmd.Flags().StringVar(&buo.directory, "directory", "",
 		"The directory to save Cluster API objects to as yaml files")
+	backupCmd.Flags().StringVar(&buo.archive, "archive", "",
+		"The gzip'd tar archive to save Cluster API objects to, either a local file or a s3://bucket/path/to/archive URL. Cannot be used with --directory")
+	backupCmd.Flags().StringSliceVar(&buo.ageRecipients, "age-recipient", nil,
+		"The age recipients, in the format age1..., the archive is encrypted for")
+	backupCmd.Flags().StringVar(&buo.pgpPublicKeyFile, "pgp-public-key", "",
+		"Path to a file with the armored PGP public keys the archive is encrypted for")
 
 	RootCmd.AddCommand(backupCmd)
 }
 
 func runBackup() error {
-	if buo.directory == "" {
-		return errors.New("please specify a directory to backup cluster API objects to using the --directory flag")
+	if buo.directory == "" && buo.archive == "" {
+		return errors.New("please specify a directory or an archive to backup cluster API objects to using the --directory or the --archive flag")
 	}
 
 	c, err := client.New(cfgFile)
@@ -70,8 +86,11 @@ func runBackup() error {
 	}
 
 	return c.Backup(client.BackupOptions{
-		FromKubeconfig: client.Kubeconfig{Path: buo.fromKubeconfig, Context: buo.fromKubeconfigContext},
-		Namespace:      buo.namespace,
-		Directory:      buo.directory,
+		FromKubeconfig:   client.Kubeconfig{Path: buo.fromKubeconfig, Context: buo.fromKubeconfigContext},
+		Namespace:        buo.namespace,
+		Directory:        buo.directory,
+		Archive:          buo.archive,
+		AgeRecipients:    buo.ageRecipients,
+		PGPPublicKeyFile: buo.pgpPublicKeyFile,
 	})
 }
@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Create and import bundles for air-gapped environments.",
+	Long: LongDesc(`
+		Create and import bundles for air-gapped environments.
+
+		A bundle packages the provider components, metadata, cluster templates, the cert-manager manifest
+		and the list of the required container images, so it is possible to run clusterctl init without
+		access to the provider repositories.`),
+}
+
+func init() {
+	RootCmd.AddCommand(bundleCmd)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type bundleCreateOptions struct {
+	coreProvider            string
+	bootstrapProviders      []string
+	controlPlaneProviders   []string
+	infrastructureProviders []string
+	flavors                 []string
+	output                  string
+}
+
+var bundleCreateOpts = &bundleCreateOptions{}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a bundle for running init in an air-gapped environment.",
+	Long: LongDesc(`
+		Create a bundle for running init in an air-gapped environment.
+
+		Reads the provider components, metadata and cluster templates from the provider repositories,
+		the cert-manager manifest and the list of the required container images, and writes everything
+		into a gzipped tarball.
+
+		Like in clusterctl init, the Cluster API core provider, the kubeadm bootstrap provider and
+		the kubeadm control plane provider are added to the bundle if not explicitly specified.
+
+		NOTE: The container images are not included in the bundle; use the images.txt file in the bundle
+		for mirroring them into a registry reachable from the air-gapped environment.`),
+
+	Example: Examples(`
+		# Create a bundle with the given infrastructure provider.
+		clusterctl bundle create --infrastructure aws --output bundle.tar.gz
+
+		# Create a bundle with specific versions of the providers and an additional cluster template flavor.
+		clusterctl bundle create --core cluster-api:v1.0.0 --infrastructure aws:v1.0.0 --flavor machinepool --output bundle.tar.gz`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundleCreate()
+	},
+}
+
+func init() {
+	bundleCreateCmd.Flags().StringVar(&bundleCreateOpts.coreProvider, "core", "",
+		"Core provider version (e.g. cluster-api:v0.3.0) to add to the bundle. If unspecified, Cluster API's latest release is used.")
+	bundleCreateCmd.Flags().StringSliceVarP(&bundleCreateOpts.infrastructureProviders, "infrastructure", "i", nil,
+		"Infrastructure providers and versions (e.g. aws:v0.5.0) to add to the bundle.")
+	bundleCreateCmd.Flags().StringSliceVarP(&bundleCreateOpts.bootstrapProviders, "bootstrap", "b", nil,
+		"Bootstrap providers and versions (e.g. kubeadm:v0.3.0) to add to the bundle. If unspecified, Kubeadm bootstrap provider's latest release is used.")
+	bundleCreateCmd.Flags().StringSliceVarP(&bundleCreateOpts.controlPlaneProviders, "control-plane", "c", nil,
+		"Control plane providers and versions (e.g. kubeadm:v0.3.0) to add to the bundle. If unspecified, the Kubeadm control plane provider's latest release is used.")
+	bundleCreateCmd.Flags().StringSliceVarP(&bundleCreateOpts.flavors, "flavor", "f", nil,
+		"Cluster template flavors to add to the bundle for each infrastructure provider, in addition to the default template.")
+	bundleCreateCmd.Flags().StringVarP(&bundleCreateOpts.output, "output", "o", "bundle.tar.gz",
+		"The path of the bundle to be created.")
+
+	bundleCmd.AddCommand(bundleCreateCmd)
+}
+
+func runBundleCreate() error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.BundleCreate(client.BundleCreateOptions{
+		CoreProvider:            bundleCreateOpts.coreProvider,
+		BootstrapProviders:      bundleCreateOpts.bootstrapProviders,
+		ControlPlaneProviders:   bundleCreateOpts.controlPlaneProviders,
+		InfrastructureProviders: bundleCreateOpts.infrastructureProviders,
+		Flavors:                 bundleCreateOpts.flavors,
+		OutputFile:              bundleCreateOpts.output,
+	})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type bundleImportOptions struct {
+	directory       string
+	imageRepository string
+	listImages      bool
+}
+
+var bundleImportOpts = &bundleImportOptions{}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import BUNDLE",
+	Short: "Import a bundle for running init in an air-gapped environment.",
+	Long: LongDesc(`
+		Import a bundle for running init in an air-gapped environment.
+
+		Extracts the bundle into a local repository and generates a clusterctl configuration file
+		serving the providers and cert-manager from it; the configuration file can then be used
+		with clusterctl init --config.
+
+		If an image repository is specified, the generated configuration overrides the repository
+		of all the container images, so they can be pulled from a mirror.`),
+
+	Example: Examples(`
+		# Import a bundle into the given directory.
+		clusterctl bundle import bundle.tar.gz --directory /opt/clusterctl
+
+		# Import a bundle using images mirrored into a local registry, then initialize a management cluster.
+		clusterctl bundle import bundle.tar.gz --directory /opt/clusterctl --image-repository registry.local/cluster-api
+		clusterctl init --config /opt/clusterctl/clusterctl.yaml --infrastructure aws
+
+		# Lists the source and the target of the container images required by the bundle.
+		clusterctl bundle import bundle.tar.gz --directory /opt/clusterctl --image-repository registry.local/cluster-api --list-images`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundleImport(args[0])
+	},
+}
+
+func init() {
+	bundleImportCmd.Flags().StringVarP(&bundleImportOpts.directory, "directory", "d", "",
+		"The directory where the bundle should be extracted.")
+	bundleImportCmd.Flags().StringVar(&bundleImportOpts.imageRepository, "image-repository", "",
+		"The repository hosting a mirror of the container images in the bundle (e.g. registry.local/cluster-api). If unspecified, image references are not changed.")
+	bundleImportCmd.Flags().BoolVar(&bundleImportOpts.listImages, "list-images", false,
+		"Lists the source and the target of the container images required by the bundle.")
+	_ = bundleImportCmd.MarkFlagRequired("directory")
+
+	bundleCmd.AddCommand(bundleImportCmd)
+}
+
+func runBundleImport(bundleFile string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.BundleImport(client.BundleImportOptions{
+		BundleFile:      bundleFile,
+		Directory:       bundleImportOpts.directory,
+		ImageRepository: bundleImportOpts.imageRepository,
+	})
+	if err != nil {
+		return err
+	}
+
+	if bundleImportOpts.listImages {
+		for _, i := range result.Images {
+			fmt.Printf("%s %s\n", i.Source, i.Target)
+		}
+		return nil
+	}
+
+	fmt.Printf("Bundle imported; use clusterctl init --config %s for initializing a management cluster from the bundle.\n", result.ConfigFile)
+	return nil
+}
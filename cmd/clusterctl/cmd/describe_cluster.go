@@ -17,14 +17,21 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/gobuffalo/flect"
 	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
@@ -32,6 +39,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -39,6 +47,9 @@ const (
 	lastElemPrefix  = `└─`
 	indent          = "  "
 	pipe            = `│ `
+
+	// clearScreen moves the cursor to the top left corner and clears the terminal.
+	clearScreen = "\033[H\033[2J"
 )
 
 var (
@@ -58,8 +69,22 @@ type describeClusterOptions struct {
 	showOtherConditions string
 	disableNoEcho       bool
 	disableGrouping     bool
+	output              string
+	watch               bool
 }
 
+const (
+	// DescribeClusterOutputJSON is an option used to print the object tree in json format.
+	DescribeClusterOutputJSON = "json"
+	// DescribeClusterOutputYaml is an option used to print the object tree in yaml format.
+	DescribeClusterOutputYaml = "yaml"
+)
+
+var (
+	// DescribeClusterOutputs is a list of valid describe cluster outputs, in addition to the default tree view.
+	DescribeClusterOutputs = []string{DescribeClusterOutputJSON, DescribeClusterOutputYaml}
+)
+
 var dc = &describeClusterOptions{}
 
 var describeClusterClusterCmd = &cobra.Command{
@@ -86,7 +111,13 @@ var describeClusterClusterCmd = &cobra.Command{
 
 		# Describe the cluster named test-1 disabling automatic echo suppression
         # e.g. show the infrastructure machine objects, no matter if the current state is already reported by the machine's Ready condition.
-		clusterctl describe cluster test-1`),
+		clusterctl describe cluster test-1
+
+		# Describe the cluster named test-1 in json format, e.g. for processing the output with other tools.
+		clusterctl describe cluster test-1 -o json
+
+		# Describe the cluster named test-1 and describe it again every time it changes.
+		clusterctl describe cluster test-1 --watch`),
 
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -108,6 +139,10 @@ func init() {
 		"Disable hiding of a MachineInfrastructure and BootstrapConfig when ready condition is true or it has the Status, Severity and Reason of the machine's object.")
 	describeClusterClusterCmd.Flags().BoolVar(&dc.disableGrouping, "disable-grouping", false,
 		"Disable grouping machines when ready condition has the same Status, Severity and Reason.")
+	describeClusterClusterCmd.Flags().StringVarP(&dc.output, "output", "o", "",
+		fmt.Sprintf("Output format. Valid values: %v. If empty, the cluster is described as a tree.", DescribeClusterOutputs))
+	describeClusterClusterCmd.Flags().BoolVarP(&dc.watch, "watch", "w", false,
+		"Describe the cluster again every time any of the objects in the tree changes.")
 
 	// completions
 	describeClusterClusterCmd.ValidArgsFunction = resourceNameCompletionFunc(
@@ -122,25 +157,71 @@ func init() {
 }
 
 func runDescribeCluster(name string) error {
+	if dc.output != "" && dc.output != DescribeClusterOutputJSON && dc.output != DescribeClusterOutputYaml {
+		return errors.Errorf("invalid output format %q. Valid values: %v", dc.output, DescribeClusterOutputs)
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
 	}
 
-	tree, err := c.DescribeCluster(client.DescribeClusterOptions{
+	options := client.DescribeClusterOptions{
 		Kubeconfig:          client.Kubeconfig{Path: dc.kubeconfig, Context: dc.kubeconfigContext},
 		Namespace:           dc.namespace,
 		ClusterName:         name,
 		ShowOtherConditions: dc.showOtherConditions,
 		DisableNoEcho:       dc.disableNoEcho,
 		DisableGrouping:     dc.disableGrouping,
-	})
+	}
+
+	if dc.watch {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		return c.WatchCluster(ctx, options, func(objectTree *tree.ObjectTree) error {
+			// Clear the terminal before printing the tree view again, while other formats are printed as a stream of documents.
+			if dc.output == "" {
+				fmt.Fprint(color.Error, clearScreen)
+			}
+			return printDescribeClusterOutput(os.Stdout, objectTree, dc.output, dc.watch)
+		})
+	}
+
+	tree, err := c.DescribeCluster(options)
 	if err != nil {
 		return err
 	}
 
-	printObjectTree(tree)
-	return nil
+	return printDescribeClusterOutput(os.Stdout, tree, dc.output, false)
+}
+
+// printDescribeClusterOutput prints the object tree in the given format; json and yaml are printed to w,
+// while the tree view is printed to stderr.
+// NOTE: When watching, yaml documents are separated by ---, so the output is a valid multi-document yaml.
+func printDescribeClusterOutput(w io.Writer, objectTree *tree.ObjectTree, output string, watch bool) error {
+	switch output {
+	case DescribeClusterOutputJSON:
+		j, err := json.MarshalIndent(objectTree.ToObjectNode(), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(j))
+		return err
+	case DescribeClusterOutputYaml:
+		y, err := yaml.Marshal(objectTree.ToObjectNode())
+		if err != nil {
+			return err
+		}
+		if watch {
+			y = append([]byte("---\n"), y...)
+		}
+		_, err = fmt.Fprint(w, string(y))
+		return err
+	default:
+		printObjectTree(objectTree)
+		return nil
+	}
 }
 
 // printObjectTree prints the cluster status to stdout.
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"testing"
@@ -290,6 +291,50 @@ func Test_TreePrefix(t *testing.T) {
 	}
 }
 
+func Test_printDescribeClusterOutput(t *testing.T) {
+	root := fakeObject("root", withCondition(conditions.TrueCondition(clusterv1.ReadyCondition)))
+	objectTree := tree.NewObjectTree(root, tree.ObjectTreeOptions{})
+	objectTree.Add(root, fakeObject("child", withAnnotation(tree.ObjectMetaNameAnnotation, "MetaName")))
+
+	tests := []struct {
+		name   string
+		output string
+		watch  bool
+		expect []string
+	}{
+		{
+			name:   "json output",
+			output: DescribeClusterOutputJSON,
+			expect: []string{`"kind": "Object"`, `"name": "root"`, `"type": "Ready"`, `"metaName": "MetaName"`},
+		},
+		{
+			name:   "yaml output",
+			output: DescribeClusterOutputYaml,
+			expect: []string{"kind: Object", "name: root", "type: Ready", "metaName: MetaName"},
+		},
+		{
+			name:   "yaml output when watching starts with a document separator",
+			output: DescribeClusterOutputYaml,
+			watch:  true,
+			expect: []string{"---\n", "kind: Object"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			out := &bytes.Buffer{}
+			g.Expect(printDescribeClusterOutput(out, objectTree, tt.output, tt.watch)).To(Succeed())
+			for _, e := range tt.expect {
+				g.Expect(out.String()).To(ContainSubstring(e))
+			}
+			if !tt.watch {
+				g.Expect(out.String()).ToNot(HavePrefix("---"))
+			}
+		})
+	}
+}
+
 type objectOption func(object ctrlclient.Object)
 
 func fakeObject(name string, options ...objectOption) ctrlclient.Object {
@@ -18,9 +18,12 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 )
 
 type generateClusterOptions struct {
@@ -39,6 +42,9 @@ type generateClusterOptions struct {
 	configMapName      string
 	configMapDataKey   string
 
+	processor        string
+	processorCommand string
+
 	listVariables bool
 }
 
@@ -84,6 +90,12 @@ var generateClusterClusterCmd = &cobra.Command{
 		# Generates a yaml file for creating workload clusters using a template stored locally.
 		clusterctl generate cluster my-cluster --from ~/workspace/cluster-template.yaml
 
+		# Generates a yaml file for creating workload clusters using a template written with Go templates.
+		clusterctl generate cluster my-cluster --from ~/workspace/cluster-template.yaml --processor=go-template
+
+		# Generates a yaml file for creating workload clusters using an external template processor.
+		clusterctl generate cluster my-cluster --processor=external --processor-command=/usr/local/bin/my-processor
+
 		# Prints the list of variables required by the yaml file for creating workload cluster.
 		clusterctl generate cluster my-cluster --list-variables`),
 
@@ -127,6 +139,12 @@ func init() {
 	generateClusterClusterCmd.Flags().StringVar(&gc.configMapDataKey, "from-config-map-key", "",
 		fmt.Sprintf("The ConfigMap.Data key where the workload cluster template is hosted. If unspecified, %q will be used", client.DefaultCustomTemplateConfigMapKey))
 
+	// flags for the template processor
+	generateClusterClusterCmd.Flags().StringVar(&gc.processor, "processor", "",
+		fmt.Sprintf("The processor to be used for the workload cluster template, one of %s. If unspecified, the processor configured for the infrastructure provider or the simple processor will be used", strings.Join(yaml.ProcessorTypes(), ", ")))
+	generateClusterClusterCmd.Flags().StringVar(&gc.processorCommand, "processor-command", "",
+		"The command to be executed by the external processor. Required when using --processor=external")
+
 	// other flags
 	generateClusterClusterCmd.Flags().BoolVar(&gc.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
@@ -176,6 +194,16 @@ func runGenerateClusterTemplate(cmd *cobra.Command, name string) error {
 		}
 	}
 
+	if gc.processor != "" {
+		processor, err := yaml.NewProcessor(gc.processor, gc.processorCommand)
+		if err != nil {
+			return err
+		}
+		templateOptions.YamlProcessor = processor
+	} else if gc.processorCommand != "" {
+		return errors.New("--processor-command can be used only with --processor=external")
+	}
+
 	template, err := c.GetClusterTemplate(templateOptions)
 	if err != nil {
 		return err
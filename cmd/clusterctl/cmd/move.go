@@ -17,9 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"path/filepath"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 )
 
 type moveOptions struct {
@@ -28,7 +32,12 @@ type moveOptions struct {
 	toKubeconfig          string
 	toKubeconfigContext   string
 	namespace             string
+	clusters              []string
+	selector              string
 	dryRun                bool
+	checkpointFile        string
+	resume                bool
+	rollback              bool
 }
 
 var mo = &moveOptions{}
@@ -39,11 +48,28 @@ var moveCmd = &cobra.Command{
 	Long: LongDesc(`
 		Move Cluster API objects and all dependencies between management clusters.
 
-		Note: The destination cluster MUST have the required provider components installed.`),
+		Note: The destination cluster MUST have the required provider components installed.
+
+		The progress of the move is recorded in a checkpoint file, so if the move fails halfway through
+		it is possible to resume it or to roll it back.`),
 
 	Example: Examples(`
 		Move Cluster API objects and all dependencies between management clusters.
-		clusterctl move --to-kubeconfig=target-kubeconfig.yaml`),
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml
+
+		Move only the Cluster named my-cluster and all its dependencies; objects used also by other Clusters,
+		e.g. ClusterClasses or ClusterResourceSets, are copied to the destination management cluster.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --cluster=my-cluster
+
+		Move only the Clusters with the label env=test and all their dependencies.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --selector=env=test
+
+		Resume a move that failed halfway through.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --resume
+
+		Rollback a move that failed halfway through, deleting the objects already created in the destination
+		management cluster and resuming the Clusters in the source management cluster.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --rollback`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runMove()
@@ -61,8 +87,18 @@ func init() {
 		"Context to be used within the kubeconfig file for the destination management cluster. If empty, current context will be used.")
 	moveCmd.Flags().StringVarP(&mo.namespace, "namespace", "n", "",
 		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	moveCmd.Flags().StringSliceVar(&mo.clusters, "cluster", nil,
+		"The name of the Cluster to move together with its dependencies. If neither --cluster nor --selector are specified, all the Clusters in the namespace are moved.")
+	moveCmd.Flags().StringVarP(&mo.selector, "selector", "l", "",
+		"Label selector for the Clusters to move together with their dependencies, e.g. env=test.")
 	moveCmd.Flags().BoolVar(&mo.dryRun, "dry-run", false,
 		"Enable dry run, don't really perform the move actions")
+	moveCmd.Flags().StringVar(&mo.checkpointFile, "checkpoint-file", "",
+		"Path to the file where the progress of the move is recorded. If unspecified, $HOME/.cluster-api/move-checkpoint.yaml will be used.")
+	moveCmd.Flags().BoolVar(&mo.resume, "resume", false,
+		"Resume a failed move from the checkpoint file")
+	moveCmd.Flags().BoolVar(&mo.rollback, "rollback", false,
+		"Rollback a failed move recorded in the checkpoint file, deleting the objects already created in the destination management cluster and resuming the Clusters in the source management cluster")
 
 	RootCmd.AddCommand(moveCmd)
 }
@@ -73,15 +109,24 @@ func runMove() error {
 		return errors.New("please specify a target cluster using the --to-kubeconfig flag")
 	}
 
+	if mo.checkpointFile == "" {
+		mo.checkpointFile = filepath.Join(homedir.HomeDir(), config.ConfigFolder, "move-checkpoint.yaml")
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
 	}
 
 	return c.Move(client.MoveOptions{
-		FromKubeconfig: client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
-		ToKubeconfig:   client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
-		Namespace:      mo.namespace,
-		DryRun:         mo.dryRun,
+		FromKubeconfig:       client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
+		ToKubeconfig:         client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
+		Namespace:            mo.namespace,
+		ClusterNames:         mo.clusters,
+		ClusterLabelSelector: mo.selector,
+		DryRun:               mo.dryRun,
+		CheckpointFile:       mo.checkpointFile,
+		Resume:               mo.resume,
+		Rollback:             mo.rollback,
 	})
 }
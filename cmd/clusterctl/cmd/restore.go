@@ -26,6 +26,9 @@ type restoreOptions struct {
 	toKubeconfig        string
 	toKubeconfigContext string
 	directory           string
+	archive             string
+	ageIdentityFile     string
+	pgpPrivateKeyFile   string
 }
 
 var ro = &restoreOptions{}
@@ -38,7 +41,14 @@ var restoreCmd = &cobra.Command{
 		or in the provided directory.`),
 	Example: Examples(`
 		Restore Cluster API objects from file by glob. Object files are searched in config directory.
-		clusterctl restore my-cluster`),
+		clusterctl restore my-cluster
+
+		Restore Cluster API objects from a gzip'd tar archive encrypted for an age recipient.
+		clusterctl restore --archive=/tmp/backup.tar.gz.age --age-identity=/path/to/key.txt
+
+		Restore Cluster API objects from an archive encrypted with a PGP public key, stored in a S3 bucket.
+		The passphrase of the PGP private key, if any, is read from the PGP_PASSPHRASE variable.
+		clusterctl restore --archive=s3://my-bucket/backups/backup.tar.gz.pgp --pgp-private-key=/path/to/key.asc`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runRestore()
@@ -52,13 +62,19 @@ func init() {
 		"Context to be used within the kubeconfig file for the target management cluster. If empty, current context will be used.")
 	restoreCmd.Flags().StringVar(&ro.directory, "directory", "",
 		"The directory to target when restoring Cluster API object yaml files")
+	restoreCmd.Flags().StringVar(&ro.archive, "archive", "",
+		"The gzip'd tar archive to restore Cluster API objects from, either a local file or a s3://bucket/path/to/archive URL. Cannot be used with --directory")
+	restoreCmd.Flags().StringVar(&ro.ageIdentityFile, "age-identity", "",
+		"Path to a file with the age identities used for decrypting the archive")
+	restoreCmd.Flags().StringVar(&ro.pgpPrivateKeyFile, "pgp-private-key", "",
+		"Path to a file with the armored PGP private keys used for decrypting the archive")
 
 	RootCmd.AddCommand(restoreCmd)
 }
 
 func runRestore() error {
-	if ro.directory == "" {
-		return errors.New("please specify a directory to restore cluster API objects from using the --directory flag")
+	if ro.directory == "" && ro.archive == "" {
+		return errors.New("please specify a directory or an archive to restore cluster API objects from using the --directory or the --archive flag")
 	}
 
 	c, err := client.New(cfgFile)
@@ -67,7 +83,10 @@ func runRestore() error {
 	}
 
 	return c.Restore(client.RestoreOptions{
-		ToKubeconfig: client.Kubeconfig{Path: ro.toKubeconfig, Context: ro.toKubeconfigContext},
-		Directory:    ro.directory,
+		ToKubeconfig:      client.Kubeconfig{Path: ro.toKubeconfig, Context: ro.toKubeconfigContext},
+		Directory:         ro.directory,
+		Archive:           ro.archive,
+		AgeIdentityFile:   ro.ageIdentityFile,
+		PGPPrivateKeyFile: ro.pgpPrivateKeyFile,
 	})
 }
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/controllers/topology"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+type topologyPlanOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	files             []string
+	cluster           string
+	namespace         string
+}
+
+var tp = &topologyPlanOptions{}
+
+var topologyPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "List the changes to Clusters using managed topologies for the given input objects.",
+	Long: LongDesc(`
+		Provide a list of the changes the topology controller would apply to the Clusters using managed
+		topologies, if the given modified (or new) Cluster, ClusterClass and template objects were applied to the
+		management cluster.
+
+		The changes are computed running the same logic of the topology controller against the current state of
+		the management cluster, without applying any change; the output includes which objects would be created,
+		updated or deleted, which templates would be rotated and which MachineDeployments would roll out.`),
+
+	Example: Examples(`
+		# List the changes for the Clusters defined in the given file, or using the ClusterClasses defined in the given file.
+		clusterctl alpha topology plan -f modified-template.yaml
+
+		# List the changes only for the Cluster named test-1.
+		clusterctl alpha topology plan -f modified-template.yaml --cluster test-1
+
+		# Read the objects from the standard input.
+		cat modified-template.yaml | clusterctl alpha topology plan -f -`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTopologyPlan(os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	topologyPlanCmd.Flags().StringVar(&tp.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	topologyPlanCmd.Flags().StringVar(&tp.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	topologyPlanCmd.Flags().StringSliceVarP(&tp.files, "file", "f", nil,
+		"Path to the file(s) containing the modified objects; use - to read from the standard input.")
+	topologyPlanCmd.Flags().StringVar(&tp.cluster, "cluster", "",
+		"Name of the Cluster to compute the plan for. If empty, the plan is computed for all the Clusters affected by the given objects.")
+	topologyPlanCmd.Flags().StringVarP(&tp.namespace, "namespace", "n", "",
+		"Namespace to be used for objects without a namespace. If empty, the current namespace will be used.")
+	_ = topologyPlanCmd.MarkFlagRequired("file")
+}
+
+func runTopologyPlan(stdin io.Reader, out io.Writer) error {
+	objs, err := readTopologyPlanObjects(tp.files, stdin)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.TopologyPlan(client.TopologyPlanOptions{
+		Kubeconfig: client.Kubeconfig{Path: tp.kubeconfig, Context: tp.kubeconfigContext},
+		Objs:       objs,
+		Cluster:    tp.cluster,
+		Namespace:  tp.namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printTopologyPlan(out, plan.Plans)
+}
+
+// readTopologyPlanObjects reads the objects from the given files, or from stdin if the file is "-".
+func readTopologyPlanObjects(files []string, stdin io.Reader) ([]unstructured.Unstructured, error) {
+	objs := []unstructured.Unstructured{}
+	for _, f := range files {
+		var data []byte
+		var err error
+		if f == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(f)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %q", f)
+		}
+
+		fileObjs, err := utilyaml.ToUnstructured(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %q", f)
+		}
+		objs = append(objs, fileObjs...)
+	}
+	return objs, nil
+}
+
+// printTopologyPlan prints a summary of the changes for each Cluster, followed by the diff of every changed object.
+func printTopologyPlan(out io.Writer, plans []*topology.Plan) error {
+	for _, plan := range plans {
+		fmt.Fprintf(out, "Cluster %s/%s\n\n", plan.Cluster.Namespace, plan.Cluster.Name)
+		if !plan.HasChanges() {
+			fmt.Fprintf(out, "No changes.\n\n")
+			continue
+		}
+
+		w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "OPERATION\tKIND\tNAME\tDETAILS")
+		for _, change := range plan.Changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Type, change.Object.Kind, change.Object.Name, topologyPlanChangeDetails(change))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "")
+
+		for _, change := range plan.Changes {
+			if len(change.Diff) == 0 {
+				continue
+			}
+			diff, err := yaml.JSONToYAML(change.Diff)
+			if err != nil {
+				return errors.Wrapf(err, "failed to convert the changes for %s %s to yaml", change.Object.Kind, change.Object.Name)
+			}
+			fmt.Fprintf(out, "# %s %s %s/%s %s\n", change.Type, change.Object.Kind, change.Object.Namespace, change.Object.Name, topologyPlanChangeDetails(change))
+			fmt.Fprintln(out, strings.TrimSpace(string(diff)))
+			fmt.Fprintln(out, "")
+		}
+	}
+	return nil
+}
+
+func topologyPlanChangeDetails(change topology.PlanChange) string {
+	details := []string{}
+	if change.RotatedFrom != "" {
+		details = append(details, fmt.Sprintf("replaces %s", change.RotatedFrom))
+	}
+	if change.Subresource != "" {
+		details = append(details, fmt.Sprintf("%s only", change.Subresource))
+	}
+	if change.RollOut {
+		details = append(details, "triggers a rollout")
+	}
+	return strings.Join(details, ", ")
+}
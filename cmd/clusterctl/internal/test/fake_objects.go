@@ -29,6 +29,7 @@ import (
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	fakebootstrap "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/bootstrap"
 	fakecontrolplane "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/controlplane"
+	fakeetcd "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/etcd"
 	fakeexternal "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/external"
 	fakeinfrastructure "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
@@ -40,12 +41,15 @@ type FakeCluster struct {
 	namespace             string
 	name                  string
 	controlPlane          *FakeControlPlane
+	managedExternalEtcd   *FakeManagedExternalEtcd
 	machinePools          []*FakeMachinePool
 	machineDeployments    []*FakeMachineDeployment
 	machineSets           []*FakeMachineSet
 	machines              []*FakeMachine
 	withCloudConfigSecret bool
 	withCredentialSecret  bool
+	labels                map[string]string
+	topologyClass         string
 }
 
 // NewFakeCluster return a FakeCluster that can generate a cluster object, all its own ancillary objects:
@@ -66,6 +70,11 @@ func (f *FakeCluster) WithControlPlane(fakeControlPlane *FakeControlPlane) *Fake
 	return f
 }
 
+func (f *FakeCluster) WithManagedExternalEtcd(fakeManagedExternalEtcd *FakeManagedExternalEtcd) *FakeCluster {
+	f.managedExternalEtcd = fakeManagedExternalEtcd
+	return f
+}
+
 func (f *FakeCluster) WithMachinePools(fakeMachinePool ...*FakeMachinePool) *FakeCluster {
 	f.machinePools = append(f.machinePools, fakeMachinePool...)
 	return f
@@ -81,6 +90,16 @@ func (f *FakeCluster) WithCredentialSecret() *FakeCluster {
 	return f
 }
 
+func (f *FakeCluster) WithLabels(labels map[string]string) *FakeCluster {
+	f.labels = labels
+	return f
+}
+
+func (f *FakeCluster) WithTopologyClass(class string) *FakeCluster {
+	f.topologyClass = class
+	return f
+}
+
 func (f *FakeCluster) WithMachineDeployments(fakeMachineDeployment ...*FakeMachineDeployment) *FakeCluster {
 	f.machineDeployments = append(f.machineDeployments, fakeMachineDeployment...)
 	return f
@@ -118,6 +137,7 @@ func (f *FakeCluster) Objs() []client.Object {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      f.name,
 			Namespace: f.namespace,
+			Labels:    f.labels,
 			// Labels: cluster.x-k8s.io/cluster-name=cluster MISSING??
 		},
 		Spec: clusterv1.ClusterSpec{
@@ -130,6 +150,13 @@ func (f *FakeCluster) Objs() []client.Object {
 		},
 	}
 
+	if f.topologyClass != "" {
+		cluster.Spec.Topology = &clusterv1.Topology{
+			Class:   f.topologyClass,
+			Version: "v1.22.2",
+		}
+	}
+
 	// Ensure the cluster gets a UID to be used by dependant objects for creating OwnerReferences.
 	setUID(cluster)
 
@@ -230,6 +257,12 @@ func (f *FakeCluster) Objs() []client.Object {
 		objs = append(objs, kubeconfigSecret)
 	}
 
+	// if the cluster has a managed external etcd
+	if f.managedExternalEtcd != nil {
+		// Adds the objects for the managed external etcd
+		objs = append(objs, f.managedExternalEtcd.Objs(cluster)...)
+	}
+
 	// Adds the objects for the machinePools
 	for _, machinePool := range f.machinePools {
 		objs = append(objs, machinePool.Objs(cluster)...)
@@ -390,6 +423,132 @@ func (f *FakeControlPlane) Objs(cluster *clusterv1.Cluster) []client.Object {
 	return objs
 }
 
+type FakeManagedExternalEtcd struct {
+	name     string
+	machines []string
+}
+
+// NewFakeManagedExternalEtcd return a FakeManagedExternalEtcd that can generate an etcd cluster object, all its own ancillary objects:
+// - the etcd CA secret object
+// - the etcd init secret object
+// and the etcd Machines with the given names.
+func NewFakeManagedExternalEtcd(name string) *FakeManagedExternalEtcd {
+	return &FakeManagedExternalEtcd{
+		name: name,
+	}
+}
+
+func (f *FakeManagedExternalEtcd) WithMachines(names ...string) *FakeManagedExternalEtcd {
+	f.machines = append(f.machines, names...)
+	return f
+}
+
+func (f *FakeManagedExternalEtcd) Objs(cluster *clusterv1.Cluster) []client.Object {
+	etcdCluster := &fakeetcd.GenericEtcdCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: fakeetcd.GroupVersion.String(),
+			Kind:       "GenericEtcdCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.name,
+			Namespace: cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{ // Added by the cluster controller -- RECONCILED
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				},
+			},
+			Labels: map[string]string{ // cluster.x-k8s.io/cluster-name=cluster, Added by the cluster controller -- RECONCILED
+				clusterv1.ClusterLabelName: cluster.Name,
+			},
+		},
+		Status: fakeetcd.GenericEtcdClusterStatus{
+			Ready:     true,
+			Endpoints: "https://10.0.0.1:2379",
+		},
+	}
+
+	// Ensure the etcdCluster gets a UID to be used by dependant objects for creating OwnerReferences.
+	setUID(etcdCluster)
+
+	// sets the reference from the cluster to the etcd cluster object
+	cluster.Spec.ManagedExternalEtcdRef = &corev1.ObjectReference{
+		APIVersion: etcdCluster.APIVersion,
+		Kind:       etcdCluster.Kind,
+		Namespace:  etcdCluster.Namespace,
+		Name:       etcdCluster.Name,
+	}
+
+	// Adds the etcd CA secret object generated by the etcd provider -- ** NOT RECONCILED **
+	caSecret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.GetName() + "-managed-etcd",
+			Namespace: cluster.GetNamespace(),
+		},
+	}
+	caSecret.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(etcdCluster, etcdCluster.GroupVersionKind())})
+
+	// Adds the etcd init secret object generated by the machine controller -- ** NOT RECONCILED **
+	initSecret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.GetName() + "-etcd-init",
+			Namespace: cluster.GetNamespace(),
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: cluster.Name,
+			},
+		},
+	}
+	initSecret.SetOwnerReferences([]metav1.OwnerReference{
+		{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		},
+	})
+
+	objs := []client.Object{
+		etcdCluster,
+		caSecret,
+		initSecret,
+	}
+
+	// Adds the etcd machines controlled by the etcd cluster object
+	for _, name := range f.machines {
+		machine := &clusterv1.Machine{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Machine",
+				APIVersion: clusterv1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cluster.Namespace,
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName:            cluster.Name,
+					clusterv1.MachineEtcdClusterLabelName: "",
+				},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: cluster.Name,
+			},
+		}
+		machine.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(etcdCluster, etcdCluster.GroupVersionKind())})
+		objs = append(objs, machine)
+	}
+
+	return objs
+}
+
 type FakeMachinePool struct {
 	name string
 }
@@ -1142,6 +1301,84 @@ func (f *FakeClusterResourceSet) Objs() []client.Object {
 	return objs
 }
 
+type FakeClusterClass struct {
+	name      string
+	namespace string
+}
+
+// NewFakeClusterClass return a FakeClusterClass that can generate a ClusterClass object and the templates it references:
+// - the infrastructure machine template and the bootstrap config template for the worker machines.
+func NewFakeClusterClass(namespace, name string) *FakeClusterClass {
+	return &FakeClusterClass{
+		name:      name,
+		namespace: namespace,
+	}
+}
+
+func (f *FakeClusterClass) Objs() []client.Object {
+	infrastructureTemplate := NewFakeInfrastructureTemplate(f.name + "-worker")
+	infrastructureTemplate.Namespace = f.namespace
+
+	bootstrapTemplate := &fakebootstrap.GenericBootstrapConfigTemplate{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: fakebootstrap.GroupVersion.String(),
+			Kind:       "GenericBootstrapConfigTemplate",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.name + "-worker",
+			Namespace: f.namespace,
+		},
+	}
+
+	clusterClass := &clusterv1.ClusterClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ClusterClass",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.name,
+			Namespace: f.namespace,
+		},
+		Spec: clusterv1.ClusterClassSpec{
+			Workers: clusterv1.WorkersClass{
+				MachineDeployments: []clusterv1.MachineDeploymentClass{
+					{
+						Class: "worker",
+						Template: clusterv1.MachineDeploymentClassTemplate{
+							Bootstrap: clusterv1.LocalObjectTemplate{
+								Ref: &corev1.ObjectReference{
+									APIVersion: bootstrapTemplate.APIVersion,
+									Kind:       bootstrapTemplate.Kind,
+									Name:       bootstrapTemplate.Name,
+								},
+							},
+							Infrastructure: clusterv1.LocalObjectTemplate{
+								Ref: &corev1.ObjectReference{
+									APIVersion: infrastructureTemplate.APIVersion,
+									Kind:       infrastructureTemplate.Kind,
+									Name:       infrastructureTemplate.Name,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	objs := []client.Object{
+		clusterClass,
+		infrastructureTemplate,
+		bootstrapTemplate,
+	}
+
+	for _, o := range objs {
+		setUID(o)
+	}
+
+	return objs
+}
+
 type FakeExternalObject struct {
 	name      string
 	namespace string
@@ -1354,6 +1591,7 @@ func FakeCRDList() []*apiextensionsv1.CustomResourceDefinition {
 
 	return []*apiextensionsv1.CustomResourceDefinition{
 		FakeNamespacedCustomResourceDefinition(clusterv1.GroupVersion.Group, "Cluster", version),
+		FakeNamespacedCustomResourceDefinition(clusterv1.GroupVersion.Group, "ClusterClass", version),
 		FakeNamespacedCustomResourceDefinition(clusterv1.GroupVersion.Group, "Machine", version),
 		FakeNamespacedCustomResourceDefinition(clusterv1.GroupVersion.Group, "MachineDeployment", version),
 		FakeNamespacedCustomResourceDefinition(clusterv1.GroupVersion.Group, "MachineSet", version),
@@ -1361,6 +1599,7 @@ func FakeCRDList() []*apiextensionsv1.CustomResourceDefinition {
 		FakeNamespacedCustomResourceDefinition(addonsv1.GroupVersion.Group, "ClusterResourceSet", version),
 		FakeNamespacedCustomResourceDefinition(addonsv1.GroupVersion.Group, "ClusterResourceSetBinding", version),
 		FakeNamespacedCustomResourceDefinition(fakecontrolplane.GroupVersion.Group, "GenericControlPlane", version),
+		FakeNamespacedCustomResourceDefinition(fakeetcd.GroupVersion.Group, "GenericEtcdCluster", version),
 		FakeNamespacedCustomResourceDefinition(fakeinfrastructure.GroupVersion.Group, "GenericInfrastructureCluster", version),
 		FakeNamespacedCustomResourceDefinition(fakeinfrastructure.GroupVersion.Group, "GenericInfrastructureMachine", version),
 		FakeNamespacedCustomResourceDefinition(fakeinfrastructure.GroupVersion.Group, "GenericInfrastructureMachineTemplate", version),
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GenericEtcdClusterStatus contains a generic etcd cluster status.
+type GenericEtcdClusterStatus struct {
+	Ready     bool   `json:"ready,omitempty"`
+	Endpoints string `json:"endpoints,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GenericEtcdCluster is a generic representation of an etcd cluster.
+type GenericEtcdCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            GenericEtcdClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GenericEtcdClusterList is list of generic etcd clusters.
+type GenericEtcdClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GenericEtcdCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&GenericEtcdCluster{}, &GenericEtcdClusterList{},
+	)
+}
@@ -179,6 +179,7 @@ func patchCluster(ctx context.Context, patchHelper *patch.Helper, cluster *clust
 			clusterv1.ControlPlaneReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
 			clusterv1.ManagedExternalEtcdClusterReadyCondition,
+			clusterv1.ManagedExternalEtcdUpgradeCompletedCondition,
 		}},
 	)
 	return patchHelper.Patch(ctx, cluster, options...)
@@ -202,6 +203,7 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 		r.reconcileKubeconfig,
 		r.reconcileControlPlaneInitialized,
 		r.reconcileEtcdCluster,
+		r.reconcileEtcdUpgrade,
 	}
 
 	res := ctrl.Result{}
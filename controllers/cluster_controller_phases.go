@@ -28,6 +28,7 @@ import (
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/external/contract"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
@@ -216,7 +217,7 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, cluster *
 			}
 			return ctrl.Result{}, err
 		}
-		externalEtcdReady, err := external.IsReady(externalEtcd)
+		externalEtcdReady, err := contract.EtcdCluster().IsReady(externalEtcd)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -230,8 +231,7 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, cluster *
 				}
 				return ctrl.Result{}, err
 			}
-			controlPlane.SetAnnotations(map[string]string{clusterv1.PausedAnnotation: "true"})
-			if err := r.Client.Update(ctx, controlPlane, &client.UpdateOptions{}); err != nil {
+			if err := r.pauseControlPlane(ctx, controlPlane); err != nil {
 				log.Error(err, "error pausing control plane")
 				return ctrl.Result{Requeue: true}, err
 			}
@@ -314,15 +314,22 @@ func (r *ClusterReconciler) reconcileEtcdCluster(ctx context.Context, cluster *c
 		return ctrl.Result{}, nil
 	}
 
+	// Ensure the etcd cluster object satisfies the etcd provider contract.
+	if err := contract.EtcdCluster().Validate(etcdPlaneConfig); err != nil {
+		conditions.MarkFalse(cluster, clusterv1.ManagedExternalEtcdClusterReadyCondition, clusterv1.EtcdProviderContractViolationReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, err
+	}
+
 	// Determine if the etcd cluster is ready.
-	ready, err := external.IsReady(etcdPlaneConfig)
+	ready, err := contract.EtcdCluster().IsReady(etcdPlaneConfig)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	cluster.Status.ManagedExternalEtcdReady = ready
 
-	if ready {
-		// resume control plane
+	// Resume the control plane once the etcd cluster is ready, unless an etcd cluster upgrade is in progress;
+	// in this case the control plane is resumed by reconcileEtcdUpgrade at the right stage of the upgrade.
+	if ready && !conditions.IsFalse(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition) {
 		controlPlane, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef, cluster.Namespace)
 		if err != nil {
 			if apierrors.IsNotFound(errors.Cause(err)) {
@@ -331,12 +338,9 @@ func (r *ClusterReconciler) reconcileEtcdCluster(ctx context.Context, cluster *c
 			}
 			return ctrl.Result{}, err
 		}
-		if annotations.HasPausedAnnotation(controlPlane) {
-			unstructured.RemoveNestedField(controlPlane.Object, "metadata", "annotations", clusterv1.PausedAnnotation)
-			if err := r.Client.Update(ctx, controlPlane, &client.UpdateOptions{}); err != nil {
-				log.Error(err, "error resuming control plane")
-				return ctrl.Result{Requeue: true}, err
-			}
+		if err := r.resumeControlPlane(ctx, controlPlane); err != nil {
+			log.Error(err, "error resuming control plane")
+			return ctrl.Result{Requeue: true}, err
 		}
 	}
 
@@ -348,7 +352,7 @@ func (r *ClusterReconciler) reconcileEtcdCluster(ctx context.Context, cluster *c
 
 	// Update cluster.Status.ManagedExternalEtcdClusterInitializedCondition if it hasn't already been set
 	if !conditions.IsTrue(cluster, clusterv1.ManagedExternalEtcdClusterInitializedCondition) {
-		initialized, err := external.IsInitialized(etcdPlaneConfig)
+		initialized, err := contract.EtcdCluster().IsInitialized(etcdPlaneConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -363,6 +367,118 @@ func (r *ClusterReconciler) reconcileEtcdCluster(ctx context.Context, cluster *c
 	return ctrl.Result{}, nil
 }
 
+// reconcileEtcdUpgrade coordinates an upgrade of the managed external etcd cluster with the control plane rollout.
+// The control plane is paused while the etcd cluster is upgrading, and it is resumed once the upgraded etcd cluster
+// is ready and reports its endpoints, so the control plane can roll out using them; the upgrade is completed once
+// the control plane provider notifies the etcd cluster that the control plane rollout is completed, so the out-of-date
+// etcd members can be deleted.
+// Each stage of the upgrade is surfaced using the ManagedExternalEtcdUpgradeCompletedCondition.
+func (r *ClusterReconciler) reconcileEtcdUpgrade(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if cluster.Spec.ManagedExternalEtcdRef == nil || cluster.Spec.ControlPlaneRef == nil {
+		return ctrl.Result{}, nil
+	}
+
+	// An etcd cluster upgrade can happen only after the etcd cluster has been initialized.
+	if !conditions.IsTrue(cluster, clusterv1.ManagedExternalEtcdClusterInitializedCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	etcdRef := cluster.Spec.ManagedExternalEtcdRef
+	externalEtcd, err := external.Get(ctx, r.Client, etcdRef, cluster.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			log.Info("Could not find external object for cluster, requeuing", "refGroupVersionKind", etcdRef.GroupVersionKind(), "refName", etcdRef.Name)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	controlPlane, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			log.Info("Could not find control plane for cluster, requeuing", "refGroupVersionKind", cluster.Spec.ControlPlaneRef.GroupVersionKind(), "refName", cluster.Spec.ControlPlaneRef.Name)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// If the etcd cluster is upgrading, pause the control plane so it does not roll out before the upgraded
+	// etcd members are available; also drop the upgrade complete annotation left by a previous upgrade, so it
+	// is not mistaken for the control plane having rolled out using the endpoints of the upgraded etcd cluster.
+	if contract.EtcdCluster().IsUpgrading(externalEtcd) {
+		conditions.MarkFalse(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition, clusterv1.ManagedEtcdUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "Waiting for the etcd cluster upgrade to complete")
+		if err := r.pauseControlPlane(ctx, controlPlane); err != nil {
+			log.Error(err, "error pausing control plane")
+			return ctrl.Result{Requeue: true}, err
+		}
+		if contract.EtcdCluster().ControlPlaneUpgradeCompleted().Has(externalEtcd) {
+			contract.EtcdCluster().ControlPlaneUpgradeCompleted().Remove(externalEtcd)
+			if err := r.Client.Update(ctx, externalEtcd); err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to remove the upgrade complete annotation from the etcd cluster")
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// If there is no etcd cluster upgrade in progress, there is nothing to do.
+	if !conditions.IsFalse(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition) {
+		conditions.MarkTrue(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition)
+		return ctrl.Result{}, nil
+	}
+
+	// Wait for the upgraded etcd cluster to be ready and to report its endpoints.
+	ready, err := contract.EtcdCluster().IsReady(externalEtcd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	endpoints, err := contract.EtcdCluster().Endpoints().Get(externalEtcd)
+	if err != nil && !contract.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if !ready || len(endpoints) == 0 {
+		conditions.MarkFalse(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition, clusterv1.WaitingForManagedEtcdEndpointsReason, clusterv1.ConditionSeverityInfo, "Waiting for the etcd cluster to report the endpoints of the upgraded members")
+		return ctrl.Result{}, nil
+	}
+
+	// Resume the control plane so it can roll out using the new etcd endpoints, and wait for the rollout to complete;
+	// the control plane provider notifies the etcd cluster once the rollout is completed by adding the upgrade complete
+	// annotation, so the out-of-date etcd members can be deleted.
+	if err := r.resumeControlPlane(ctx, controlPlane); err != nil {
+		log.Error(err, "error resuming control plane")
+		return ctrl.Result{Requeue: true}, err
+	}
+	if !contract.EtcdCluster().ControlPlaneUpgradeCompleted().Has(externalEtcd) {
+		conditions.MarkFalse(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition, clusterv1.WaitingForControlPlaneRolloutReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane to roll out using the upgraded etcd cluster")
+		return ctrl.Result{}, nil
+	}
+	conditions.MarkTrue(cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition)
+	return ctrl.Result{}, nil
+}
+
+// pauseControlPlane adds the paused annotation to the control plane object, if not already present.
+func (r *ClusterReconciler) pauseControlPlane(ctx context.Context, controlPlane *unstructured.Unstructured) error {
+	if annotations.HasPausedAnnotation(controlPlane) {
+		return nil
+	}
+	controlPlaneAnnotations := controlPlane.GetAnnotations()
+	if controlPlaneAnnotations == nil {
+		controlPlaneAnnotations = map[string]string{}
+	}
+	controlPlaneAnnotations[clusterv1.PausedAnnotation] = "true"
+	controlPlane.SetAnnotations(controlPlaneAnnotations)
+	return r.Client.Update(ctx, controlPlane, &client.UpdateOptions{})
+}
+
+// resumeControlPlane removes the paused annotation from the control plane object, if present.
+func (r *ClusterReconciler) resumeControlPlane(ctx context.Context, controlPlane *unstructured.Unstructured) error {
+	if !annotations.HasPausedAnnotation(controlPlane) {
+		return nil
+	}
+	unstructured.RemoveNestedField(controlPlane.Object, "metadata", "annotations", clusterv1.PausedAnnotation)
+	return r.Client.Update(ctx, controlPlane, &client.UpdateOptions{})
+}
+
 func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
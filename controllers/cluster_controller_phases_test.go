@@ -25,8 +25,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external/contract"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -370,3 +373,164 @@ func TestClusterReconciler_reconcilePhase(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterReconcileEtcdUpgrade(t *testing.T) {
+	etcdRef := &corev1.ObjectReference{
+		APIVersion: "etcdcluster.cluster.x-k8s.io/v1beta1",
+		Kind:       "EtcdadmCluster",
+		Name:       "test-etcd",
+		Namespace:  "test-namespace",
+	}
+	controlPlaneRef := &corev1.ObjectReference{
+		APIVersion: builder.ControlPlaneGroupVersion.String(),
+		Kind:       builder.GenericControlPlaneKind,
+		Name:       "test-cp",
+		Namespace:  "test-namespace",
+	}
+
+	newEtcd := func(upgrading, ready bool, endpoints string) *unstructured.Unstructured {
+		etcd := &unstructured.Unstructured{}
+		etcd.SetAPIVersion(etcdRef.APIVersion)
+		etcd.SetKind(etcdRef.Kind)
+		etcd.SetNamespace(etcdRef.Namespace)
+		etcd.SetName(etcdRef.Name)
+		if upgrading {
+			etcd.SetAnnotations(map[string]string{"etcdcluster.cluster.x-k8s.io/upgrading": "true"})
+		}
+		g := NewWithT(t)
+		g.Expect(unstructured.SetNestedField(etcd.Object, ready, "status", "ready")).To(Succeed())
+		if endpoints != "" {
+			g.Expect(unstructured.SetNestedField(etcd.Object, endpoints, "status", "endpoints")).To(Succeed())
+		}
+		return etcd
+	}
+	// withUpgradeComplete adds the annotation set by the control plane provider once the control plane rollout is completed.
+	withUpgradeComplete := func(etcd *unstructured.Unstructured) *unstructured.Unstructured {
+		contract.EtcdCluster().ControlPlaneUpgradeCompleted().Set(etcd, "true")
+		return etcd
+	}
+	newControlPlane := func() *unstructured.Unstructured {
+		return builder.ControlPlane(controlPlaneRef.Namespace, controlPlaneRef.Name).Build()
+	}
+	newCluster := func(upgradeCondition *clusterv1.Condition) *clusterv1.Cluster {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "test-namespace",
+			},
+			Spec: clusterv1.ClusterSpec{
+				ControlPlaneRef:        controlPlaneRef,
+				ManagedExternalEtcdRef: etcdRef,
+			},
+		}
+		conditions.MarkTrue(cluster, clusterv1.ManagedExternalEtcdClusterInitializedCondition)
+		if upgradeCondition != nil {
+			conditions.Set(cluster, upgradeCondition)
+		}
+		return cluster
+	}
+	pausedControlPlane := newControlPlane()
+	pausedControlPlane.SetAnnotations(map[string]string{clusterv1.PausedAnnotation: "true"})
+
+	inProgress := conditions.FalseCondition(clusterv1.ManagedExternalEtcdUpgradeCompletedCondition, clusterv1.ManagedEtcdUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "")
+	waitingForRollout := conditions.FalseCondition(clusterv1.ManagedExternalEtcdUpgradeCompletedCondition, clusterv1.WaitingForControlPlaneRolloutReason, clusterv1.ConditionSeverityInfo, "")
+
+	tests := []struct {
+		name                    string
+		cluster                 *clusterv1.Cluster
+		etcd                    *unstructured.Unstructured
+		controlPlane            *unstructured.Unstructured
+		wantReason              string
+		wantControlPlanePaused  bool
+		wantEtcdUpgradeComplete bool
+	}{
+		{
+			name:         "no upgrade in progress",
+			cluster:      newCluster(nil),
+			etcd:         newEtcd(false, true, "https://1.1.1.1:2379"),
+			controlPlane: newControlPlane(),
+		},
+		{
+			name:                   "pause the control plane while the etcd cluster is upgrading",
+			cluster:                newCluster(nil),
+			etcd:                   newEtcd(true, true, "https://1.1.1.1:2379"),
+			controlPlane:           newControlPlane(),
+			wantReason:             clusterv1.ManagedEtcdUpgradeInProgressReason,
+			wantControlPlanePaused: true,
+		},
+		{
+			name:                   "wait for the upgraded etcd cluster to report its endpoints",
+			cluster:                newCluster(inProgress),
+			etcd:                   newEtcd(false, false, ""),
+			controlPlane:           pausedControlPlane,
+			wantReason:             clusterv1.WaitingForManagedEtcdEndpointsReason,
+			wantControlPlanePaused: true,
+		},
+		{
+			name:                   "remove the upgrade complete annotation left by a previous upgrade",
+			cluster:                newCluster(nil),
+			etcd:                   withUpgradeComplete(newEtcd(true, true, "https://1.1.1.1:2379")),
+			controlPlane:           newControlPlane(),
+			wantReason:             clusterv1.ManagedEtcdUpgradeInProgressReason,
+			wantControlPlanePaused: true,
+		},
+		{
+			name:         "resume the control plane and wait for it to roll out using the new etcd endpoints",
+			cluster:      newCluster(inProgress),
+			etcd:         newEtcd(false, true, "https://2.2.2.2:2379"),
+			controlPlane: pausedControlPlane,
+			wantReason:   clusterv1.WaitingForControlPlaneRolloutReason,
+		},
+		{
+			name:         "wait for the control plane provider to notify the etcd cluster the rollout is completed",
+			cluster:      newCluster(waitingForRollout),
+			etcd:         newEtcd(false, true, "https://2.2.2.2:2379"),
+			controlPlane: newControlPlane(),
+			wantReason:   clusterv1.WaitingForControlPlaneRolloutReason,
+		},
+		{
+			name:                    "complete the upgrade once the control plane provider notified the etcd cluster",
+			cluster:                 newCluster(waitingForRollout),
+			etcd:                    withUpgradeComplete(newEtcd(false, true, "https://2.2.2.2:2379")),
+			controlPlane:            newControlPlane(),
+			wantEtcdUpgradeComplete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().
+				WithObjects(tt.cluster, tt.etcd, tt.controlPlane).
+				Build()
+			r := &ClusterReconciler{
+				Client: c,
+			}
+
+			res, err := r.reconcileEtcdUpgrade(ctx, tt.cluster)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res).To(Equal(ctrl.Result{}))
+
+			if tt.wantReason == "" {
+				g.Expect(conditions.IsTrue(tt.cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition)).To(BeTrue())
+			} else {
+				g.Expect(conditions.IsFalse(tt.cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(tt.cluster, clusterv1.ManagedExternalEtcdUpgradeCompletedCondition)).To(Equal(tt.wantReason))
+			}
+
+			controlPlane := &unstructured.Unstructured{}
+			controlPlane.SetGroupVersionKind(tt.controlPlane.GroupVersionKind())
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(tt.controlPlane), controlPlane)).To(Succeed())
+			g.Expect(annotations.HasPausedAnnotation(controlPlane)).To(Equal(tt.wantControlPlanePaused))
+
+			etcd := &unstructured.Unstructured{}
+			etcd.SetGroupVersionKind(tt.etcd.GroupVersionKind())
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(tt.etcd), etcd)).To(Succeed())
+			if tt.wantEtcdUpgradeComplete {
+				g.Expect(etcd.GetAnnotations()).To(HaveKeyWithValue(clusterv1.ControlPlaneUpgradeCompletedAnnotation, "true"))
+			} else {
+				g.Expect(etcd.GetAnnotations()).ToNot(HaveKey(clusterv1.ControlPlaneUpgradeCompletedAnnotation))
+			}
+		})
+	}
+}
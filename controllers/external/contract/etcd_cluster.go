@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contract
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EtcdClusterContract encodes information about the Cluster API contract for managed external etcd cluster objects
+// like e.g. the EtcdadmCluster.
+//
+// The CustomResourceDefinition of a managed external etcd cluster object must have the Cluster API contract label,
+// e.g. cluster.x-k8s.io/v1beta1: v1beta1, and the object must implement:
+// - status.ready, set to true once all the etcd members are provisioned and healthy.
+// - status.endpoints, a comma separated list of the etcd client endpoints, required once the etcd cluster is ready.
+// - the etcdcluster.cluster.x-k8s.io/upgrading annotation, set while an etcd cluster upgrade is in progress.
+// Additionally, the etcd provider must read status.initMachineAddress and status.initialized, set by the Machine controller
+// with the name of the Secret holding the address of the first etcd member once it is known, and the
+// controlplane.cluster.x-k8s.io/upgrade-complete annotation, set by the control plane provider once the control plane
+// has been rolled out using the endpoints of the upgraded etcd cluster.
+// The etcd CA must be stored in the <cluster-name>-managed-etcd Secret in the Cluster namespace.
+type EtcdClusterContract struct{}
+
+var etcdCluster *EtcdClusterContract
+var onceEtcdCluster sync.Once
+
+// EtcdCluster provide access to the information about the Cluster API contract for managed external etcd cluster objects.
+func EtcdCluster() *EtcdClusterContract {
+	onceEtcdCluster.Do(func() {
+		etcdCluster = &EtcdClusterContract{}
+	})
+	return etcdCluster
+}
+
+// Ready provides access to the status.ready field in an etcd cluster object.
+func (c *EtcdClusterContract) Ready() *Bool {
+	return &Bool{
+		path: []string{"status", "ready"},
+	}
+}
+
+// Initialized provides access to the status.initialized field in an etcd cluster object.
+// NOTE: This field is set by the Machine controller once the address of the first etcd member is known.
+func (c *EtcdClusterContract) Initialized() *Bool {
+	return &Bool{
+		path: []string{"status", "initialized"},
+	}
+}
+
+// Endpoints provides access to the status.endpoints field in an etcd cluster object.
+func (c *EtcdClusterContract) Endpoints() *Endpoints {
+	return &Endpoints{
+		path: []string{"status", "endpoints"},
+	}
+}
+
+// InitMachineAddress provides access to the status.initMachineAddress field in an etcd cluster object.
+// NOTE: This field is set by the Machine controller with the name of the Secret holding the address of the first etcd member.
+func (c *EtcdClusterContract) InitMachineAddress() *String {
+	return &String{
+		path: []string{"status", "initMachineAddress"},
+	}
+}
+
+// Upgrading provides access to the annotation documenting an etcd cluster upgrade is in progress.
+func (c *EtcdClusterContract) Upgrading() *Annotation {
+	return &Annotation{
+		key: clusterv1.EtcdClusterUpgradingAnnotation,
+	}
+}
+
+// ControlPlaneUpgradeCompleted provides access to the annotation documenting the control plane has been rolled out
+// using the endpoints of the upgraded etcd cluster.
+func (c *EtcdClusterContract) ControlPlaneUpgradeCompleted() *Annotation {
+	return &Annotation{
+		key: clusterv1.ControlPlaneUpgradeCompletedAnnotation,
+	}
+}
+
+// CASecretKey returns the key of the Secret storing the etcd CA for the given Cluster.
+func (c *EtcdClusterContract) CASecretKey(cluster *clusterv1.Cluster) client.ObjectKey {
+	return client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      secret.Name(cluster.Name, secret.ManagedExternalEtcdCA),
+	}
+}
+
+// IsReady returns true if the etcd cluster is ready; a missing status.ready field is considered as false.
+func (c *EtcdClusterContract) IsReady(obj *unstructured.Unstructured) (bool, error) {
+	return getBoolOrFalse(c.Ready(), obj)
+}
+
+// IsInitialized returns true if the etcd cluster is initialized; a missing status.initialized field is considered as false.
+func (c *EtcdClusterContract) IsInitialized(obj *unstructured.Unstructured) (bool, error) {
+	return getBoolOrFalse(c.Initialized(), obj)
+}
+
+// IsUpgrading returns true if an etcd cluster upgrade is in progress.
+func (c *EtcdClusterContract) IsUpgrading(obj *unstructured.Unstructured) bool {
+	return c.Upgrading().Has(obj)
+}
+
+// Validate returns an error if the etcd cluster object does not satisfy the contract, e.g. if it is ready
+// but it does not report valid endpoints.
+func (c *EtcdClusterContract) Validate(obj *unstructured.Unstructured) error {
+	ready, err := c.IsReady(obj)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s does not satisfy the etcd provider contract", obj.GetKind(), obj.GetName())
+	}
+	if _, err := c.IsInitialized(obj); err != nil {
+		return errors.Wrapf(err, "%s %s does not satisfy the etcd provider contract", obj.GetKind(), obj.GetName())
+	}
+	if !ready {
+		return nil
+	}
+
+	endpoints, err := c.Endpoints().Get(obj)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s does not satisfy the etcd provider contract: %s is required when %s is true",
+			obj.GetKind(), obj.GetName(), c.Endpoints().Path(), c.Ready().Path())
+	}
+	for _, endpoint := range endpoints {
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.Errorf("%s %s does not satisfy the etcd provider contract: %s contains an invalid endpoint %q",
+				obj.GetKind(), obj.GetName(), c.Endpoints().Path(), endpoint)
+		}
+	}
+	return nil
+}
+
+func getBoolOrFalse(b *Bool, obj *unstructured.Unstructured) (bool, error) {
+	value, err := b.Get(obj)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return *value, nil
+}
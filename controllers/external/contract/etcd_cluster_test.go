@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contract
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEtcdCluster(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+
+	t.Run("Manages status.ready", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(EtcdCluster().Ready().Path()).To(Equal(Path{"status", "ready"}))
+
+		ready, err := EtcdCluster().IsReady(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ready).To(BeFalse())
+
+		err = EtcdCluster().Ready().Set(obj, true)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		ready, err = EtcdCluster().IsReady(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ready).To(BeTrue())
+	})
+	t.Run("Manages status.initialized", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(EtcdCluster().Initialized().Path()).To(Equal(Path{"status", "initialized"}))
+
+		initialized, err := EtcdCluster().IsInitialized(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(initialized).To(BeFalse())
+
+		err = EtcdCluster().Initialized().Set(obj, true)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		initialized, err = EtcdCluster().IsInitialized(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(initialized).To(BeTrue())
+	})
+	t.Run("Manages status.endpoints", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(EtcdCluster().Endpoints().Path()).To(Equal(Path{"status", "endpoints"}))
+
+		_, err := EtcdCluster().Endpoints().Get(obj)
+		g.Expect(IsNotFound(err)).To(BeTrue())
+
+		err = unstructured.SetNestedField(obj.Object, "https://2.2.2.2:2379, https://1.1.1.1:2379", "status", "endpoints")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		got, err := EtcdCluster().Endpoints().Get(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(Equal([]string{"https://1.1.1.1:2379", "https://2.2.2.2:2379"}))
+	})
+	t.Run("Manages status.initMachineAddress", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(EtcdCluster().InitMachineAddress().Path()).To(Equal(Path{"status", "initMachineAddress"}))
+
+		err := EtcdCluster().InitMachineAddress().Set(obj, "1.1.1.1")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		got, err := EtcdCluster().InitMachineAddress().Get(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).ToNot(BeNil())
+		g.Expect(*got).To(Equal("1.1.1.1"))
+	})
+	t.Run("Manages upgrade annotations", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(EtcdCluster().IsUpgrading(obj)).To(BeFalse())
+		obj.SetAnnotations(map[string]string{clusterv1.EtcdClusterUpgradingAnnotation: ""})
+		g.Expect(EtcdCluster().IsUpgrading(obj)).To(BeTrue())
+
+		g.Expect(EtcdCluster().ControlPlaneUpgradeCompleted().Has(obj)).To(BeFalse())
+		EtcdCluster().ControlPlaneUpgradeCompleted().Set(obj, "true")
+		g.Expect(obj.GetAnnotations()).To(HaveKeyWithValue(clusterv1.ControlPlaneUpgradeCompletedAnnotation, "true"))
+		g.Expect(obj.GetAnnotations()).To(HaveKey(clusterv1.EtcdClusterUpgradingAnnotation))
+	})
+	t.Run("Returns the etcd CA Secret key", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster1"}}
+		g.Expect(EtcdCluster().CASecretKey(cluster)).To(Equal(client.ObjectKey{Namespace: "ns1", Name: "cluster1-managed-etcd"}))
+	})
+}
+
+func TestEtcdClusterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Pass if the etcd cluster is not ready",
+			status:  map[string]interface{}{},
+			wantErr: false,
+		},
+		{
+			name: "Pass if the etcd cluster is ready and reports endpoints",
+			status: map[string]interface{}{
+				"ready":     true,
+				"endpoints": "https://1.1.1.1:2379,https://2.2.2.2:2379",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails if status.ready is not a bool",
+			status: map[string]interface{}{
+				"ready": "true",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails if the etcd cluster is ready and does not report endpoints",
+			status: map[string]interface{}{
+				"ready": true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails if the etcd cluster is ready and reports invalid endpoints",
+			status: map[string]interface{}{
+				"ready":     true,
+				"endpoints": "1.1.1.1",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": tt.status}}
+			obj.SetKind("EtcdadmCluster")
+			obj.SetName("etcd1")
+
+			err := EtcdCluster().Validate(obj)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contract
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var errNotFound = errors.New("not found")
+
+// IsNotFound returns true if the error is reporting a field not found in an object.
+func IsNotFound(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+// Path defines a how to access a field in an Unstructured object.
+type Path []string
+
+func (p Path) String() string {
+	return "." + strings.Join(p, ".")
+}
+
+// Bool represents an accessor to a bool path value.
+type Bool struct {
+	path Path
+}
+
+// Path returns the path to the bool value.
+func (b *Bool) Path() Path {
+	return b.path
+}
+
+// Get gets the bool value.
+func (b *Bool) Get(obj *unstructured.Unstructured) (*bool, error) {
+	value, ok, err := unstructured.NestedBool(obj.UnstructuredContent(), b.path...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s from object", b.path)
+	}
+	if !ok {
+		return nil, errors.Wrapf(errNotFound, "path %s", b.path)
+	}
+	return &value, nil
+}
+
+// Set sets the bool value in the path.
+func (b *Bool) Set(obj *unstructured.Unstructured, value bool) error {
+	if err := unstructured.SetNestedField(obj.UnstructuredContent(), value, b.path...); err != nil {
+		return errors.Wrapf(err, "failed to set path %s of object %v", b.path, obj.GroupVersionKind())
+	}
+	return nil
+}
+
+// String represents an accessor to a string path value.
+type String struct {
+	path Path
+}
+
+// Path returns the path to the string value.
+func (s *String) Path() Path {
+	return s.path
+}
+
+// Get gets the string value.
+func (s *String) Get(obj *unstructured.Unstructured) (*string, error) {
+	value, ok, err := unstructured.NestedString(obj.UnstructuredContent(), s.path...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s from object", s.path)
+	}
+	if !ok {
+		return nil, errors.Wrapf(errNotFound, "path %s", s.path)
+	}
+	return &value, nil
+}
+
+// Set sets the string value in the path.
+func (s *String) Set(obj *unstructured.Unstructured, value string) error {
+	if err := unstructured.SetNestedField(obj.UnstructuredContent(), value, s.path...); err != nil {
+		return errors.Wrapf(err, "failed to set path %s of object %v", s.path, obj.GroupVersionKind())
+	}
+	return nil
+}
+
+// Endpoints represents an accessor to a list of endpoints stored as a comma separated string.
+type Endpoints struct {
+	path Path
+}
+
+// Path returns the path to the endpoints value.
+func (e *Endpoints) Path() Path {
+	return e.path
+}
+
+// Get gets the list of endpoints, sorted.
+func (e *Endpoints) Get(obj *unstructured.Unstructured) ([]string, error) {
+	value, ok, err := unstructured.NestedString(obj.UnstructuredContent(), e.path...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s from object", e.path)
+	}
+	if !ok || value == "" {
+		return nil, errors.Wrapf(errNotFound, "path %s", e.path)
+	}
+
+	endpoints := []string{}
+	for _, endpoint := range strings.Split(value, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// Set sets the list of endpoints in the path.
+func (e *Endpoints) Set(obj *unstructured.Unstructured, endpoints []string) error {
+	if err := unstructured.SetNestedField(obj.UnstructuredContent(), strings.Join(endpoints, ","), e.path...); err != nil {
+		return errors.Wrapf(err, "failed to set path %s of object %v", e.path, obj.GroupVersionKind())
+	}
+	return nil
+}
+
+// Annotation represents an accessor to an annotation.
+type Annotation struct {
+	key string
+}
+
+// Key returns the key of the annotation.
+func (a *Annotation) Key() string {
+	return a.key
+}
+
+// Has returns true if the object has the annotation.
+func (a *Annotation) Has(obj *unstructured.Unstructured) bool {
+	_, ok := obj.GetAnnotations()[a.key]
+	return ok
+}
+
+// Set sets the annotation value.
+func (a *Annotation) Set(obj *unstructured.Unstructured, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[a.key] = value
+	obj.SetAnnotations(annotations)
+}
+
+// Remove removes the annotation.
+func (a *Annotation) Remove(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	delete(annotations, a.key)
+	obj.SetAnnotations(annotations)
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apiserver/pkg/storage/names"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external/contract"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -244,41 +245,31 @@ func IsInitialized(obj *unstructured.Unstructured) (bool, error) {
 	return initialized && found, nil
 }
 
+// GetExternalEtcdEndpoints returns the comma separated list of endpoints reported by a managed external etcd cluster object.
+//
+// Deprecated: use contract.EtcdCluster().Endpoints() instead.
 func GetExternalEtcdEndpoints(externalEtcd *unstructured.Unstructured) (string, bool, error) {
-	endpoints, found, err := unstructured.NestedString(externalEtcd.Object, "status", "endpoints")
+	endpoints, err := contract.EtcdCluster().Endpoints().Get(externalEtcd)
 	if err != nil {
+		if contract.IsNotFound(err) {
+			return "", false, nil
+		}
 		return "", false, errors.Wrapf(err, "failed to get external etcd endpoints from %v %q", externalEtcd.GroupVersionKind(),
 			externalEtcd.GetName())
 	}
-
-	return endpoints, found, nil
+	return strings.Join(endpoints, ","), true, nil
 }
 
+// IsExternalEtcdUpgrading returns true if a managed external etcd cluster upgrade is in progress.
+//
+// Deprecated: use contract.EtcdCluster().IsUpgrading() instead.
 func IsExternalEtcdUpgrading(externalEtcd *unstructured.Unstructured) (bool, error) {
-	annotations, hasAnnotations, err := unstructured.NestedStringMap(externalEtcd.Object, "metadata", "annotations")
-	if err != nil {
-		return false, errors.Wrapf(err, "failed to check if external etcd is undergoing upgrade %v %q", externalEtcd.GroupVersionKind(),
-			externalEtcd.GetName())
-	}
-
-	if !hasAnnotations {
-		return false, nil
-	}
-
-	_, hasUpgradingAnnotation := annotations["etcdcluster.cluster.x-k8s.io/upgrading"]
-	return hasUpgradingAnnotation, nil
+	return contract.EtcdCluster().IsUpgrading(externalEtcd), nil
 }
 
+// SetKCPUpdateCompleteAnnotationOnEtcdadmCluster sets the annotation documenting the control plane has been rolled out
+// using the endpoints of the upgraded etcd cluster on a managed external etcd cluster object.
 func SetKCPUpdateCompleteAnnotationOnEtcdadmCluster(externalEtcd *unstructured.Unstructured) error {
-	annotations, hasAnnotations, err := unstructured.NestedStringMap(externalEtcd.Object, "metadata", "annotations")
-	if err != nil {
-		return errors.Wrapf(err, "failed to update external etcd annotation after controlplane upgrade completed %v %q", externalEtcd.GroupVersionKind(),
-			externalEtcd.GetName())
-	}
-
-	if !hasAnnotations {
-		annotations = make(map[string]string)
-	}
-	annotations[clusterv1.ControlPlaneUpgradeCompletedAnnotation] = "true"
-	return unstructured.SetNestedStringMap(externalEtcd.UnstructuredContent(), annotations, "metadata", "annotations")
+	contract.EtcdCluster().ControlPlaneUpgradeCompleted().Set(externalEtcd, "true")
+	return nil
 }
@@ -30,6 +30,7 @@ import (
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/external/contract"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
@@ -321,12 +322,12 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		address, addressSet, err := unstructured.NestedFieldNoCopy(obj.Object, "status", "initMachineAddress")
-		if err != nil {
+		address, err := contract.EtcdCluster().InitMachineAddress().Get(obj)
+		if err != nil && !contract.IsNotFound(err) {
 			return ctrl.Result{}, err
 		}
 
-		if !addressSet || address == "" {
+		if address == nil || *address == "" {
 			etcdSecretName := fmt.Sprintf("%v-%v", cluster.Name, "etcd-init")
 			existingSecret := &corev1.Secret{}
 			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: etcdSecretName}, existingSecret); err != nil {
@@ -379,11 +380,11 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 					}
 
 					// set the Secret name on etcdCluster and update it so it receives a sync
-					if err := unstructured.SetNestedField(obj.Object, etcdSecretName, "status", "initMachineAddress"); err != nil {
+					if err := contract.EtcdCluster().InitMachineAddress().Set(obj, etcdSecretName); err != nil {
 						return ctrl.Result{}, err
 					}
 					// set Initialized to true on etcdCluster and update it so it receives a sync
-					if err := unstructured.SetNestedField(obj.Object, true, "status", "initialized"); err != nil {
+					if err := contract.EtcdCluster().Initialized().Set(obj, true); err != nil {
 						return ctrl.Result{}, err
 					}
 					// Always attempt to Patch the external object.
@@ -397,11 +398,11 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 			} else {
 				// secret exists but etcdcluster status field doesn't contain the secret name: can happen only after move
 				// set the Secret name on etcdCluster and update it so it receives a sync
-				if err := unstructured.SetNestedField(obj.Object, etcdSecretName, "status", "initMachineAddress"); err != nil {
+				if err := contract.EtcdCluster().InitMachineAddress().Set(obj, etcdSecretName); err != nil {
 					return ctrl.Result{}, err
 				}
 				// set Initialized to true on etcdCluster and update it so it receives a sync
-				if err := unstructured.SetNestedField(obj.Object, true, "status", "initialized"); err != nil {
+				if err := contract.EtcdCluster().Initialized().Set(obj, true); err != nil {
 					return ctrl.Result{}, err
 				}
 				// Always attempt to Patch the external object.
@@ -72,6 +72,9 @@ func (r *ClusterReconciler) getBlueprint(ctx context.Context, cluster *clusterv1
 		return nil, errors.Wrapf(err, "failed to get control plane template for %s", tlog.KObj{Obj: blueprint.ClusterClass})
 	}
 
+	// Get ClusterClass.spec.controlPlane.machineHealthCheck.
+	blueprint.ControlPlane.MachineHealthCheck = blueprint.ClusterClass.Spec.ControlPlane.MachineHealthCheck
+
 	// If the clusterClass mandates the controlPlane has infrastructureMachines, read it.
 	if blueprint.HasControlPlaneInfrastructureMachine() {
 		blueprint.ControlPlane.InfrastructureMachineTemplate, err = r.getReference(ctx, blueprint.ClusterClass.Spec.ControlPlane.MachineInfrastructure.Ref)
@@ -102,6 +105,9 @@ func (r *ClusterReconciler) getBlueprint(ctx context.Context, cluster *clusterv1
 			return nil, errors.Wrapf(err, "failed to get bootstrap machine template for %s, MachineDeployment class %q", tlog.KObj{Obj: blueprint.ClusterClass}, machineDeploymentClass.Class)
 		}
 
+		// Get the MachineHealthCheck definition.
+		machineDeploymentBlueprint.MachineHealthCheck = machineDeploymentClass.MachineHealthCheck
+
 		blueprint.MachineDeployments[machineDeploymentClass.Class] = machineDeploymentBlueprint
 	}
 
@@ -41,6 +41,7 @@ import (
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusterclasses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinehealthchecks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 // ClusterReconciler reconciles a managed topology for a Cluster object.
@@ -139,6 +140,11 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, s *scope.Scope) (ctrl
 		return ctrl.Result{}, errors.Wrap(err, "error reading the ClusterClass")
 	}
 
+	// If the Cluster is being rebased to a different ClusterClass, check the ClusterClasses are compatible.
+	if err := r.checkClusterClassRebase(ctx, s); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Gets the current state of the Cluster and store it in the request scope.
 	s.Current, err = r.getCurrentState(ctx, s)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
@@ -95,6 +96,12 @@ func (r *ClusterReconciler) getCurrentControlPlaneState(ctx context.Context, clu
 		return res, nil
 	}
 
+	// Get the MachineHealthCheck for the control plane, if any.
+	res.MachineHealthCheck, err = r.getCurrentMachineHealthCheck(ctx, client.ObjectKeyFromObject(res.Object))
+	if err != nil {
+		return nil, err
+	}
+
 	// Otherwise, get the control plane machine infrastructureMachine template.
 	machineInfrastructureRef, err := contract.ControlPlane().MachineTemplate().InfrastructureRef().Get(res.Object)
 	if err != nil {
@@ -167,11 +174,38 @@ func (r *ClusterReconciler) getCurrentMachineDeploymentState(ctx context.Context
 			return nil, errors.Wrap(err, fmt.Sprintf("%s Infrastructure reference could not be retrieved", tlog.KObj{Obj: m}))
 		}
 
+		// Gets the MachineHealthCheck, if any.
+		mhc, err := r.getCurrentMachineHealthCheck(ctx, client.ObjectKeyFromObject(m))
+		if err != nil {
+			return nil, err
+		}
+
 		state[mdTopologyName] = &scope.MachineDeploymentState{
 			Object:                        m,
 			BootstrapTemplate:             b,
 			InfrastructureMachineTemplate: i,
+			MachineHealthCheck:            mhc,
 		}
 	}
 	return state, nil
 }
+
+// getCurrentMachineHealthCheck gets the MachineHealthCheck with the given key; nil is returned if the MachineHealthCheck
+// does not exist or if it is not managed by the topology controller.
+// NOTE: MachineHealthChecks generated by the topology controller have the same name of the ControlPlane / MachineDeployment
+// object they are targeting.
+func (r *ClusterReconciler) getCurrentMachineHealthCheck(ctx context.Context, key client.ObjectKey) (*clusterv1.MachineHealthCheck, error) {
+	mhc := &clusterv1.MachineHealthCheck{}
+	if err := r.Client.Get(ctx, key, mhc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read MachineHealthCheck/%s", key.Name)
+	}
+
+	// Ignore MachineHealthChecks not managed by the topology controller.
+	if _, ok := mhc.Labels[clusterv1.ClusterTopologyOwnedLabel]; !ok {
+		return nil, nil
+	}
+	return mhc, nil
+}
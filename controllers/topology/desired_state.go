@@ -24,12 +24,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
 	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -62,19 +64,34 @@ func (r *ClusterReconciler) computeDesiredState(ctx context.Context, s *scope.Sc
 		return nil, err
 	}
 
+	// If required by the blueprint, compute the desired state of the MachineHealthCheck for the ControlPlane.
+	if mhcClass := s.Blueprint.ControlPlaneMachineHealthCheckClass(); mhcClass != nil {
+		desiredState.ControlPlane.MachineHealthCheck = computeMachineHealthCheck(s, desiredState.ControlPlane.Object.GetName(),
+			selectorForControlPlaneMHC(s.Current.Cluster), mhcClass)
+	}
+
 	// Compute the desired state for the Cluster object adding a reference to the
 	// InfrastructureCluster and the ControlPlane objects generated by the previous step.
 	desiredState.Cluster = computeCluster(ctx, s, desiredState.InfrastructureCluster, desiredState.ControlPlane.Object)
 
 	// If required by the blueprint, compute the desired state of the MachineDeployment objects for the worker nodes, if any.
-	if !s.Blueprint.HasMachineDeployments() {
-		return desiredState, nil
+	if s.Blueprint.HasMachineDeployments() {
+		// Compute the desired state of the MachineDeployments from the list of MachineDeploymentTopologies
+		// defined in the cluster.
+		desiredState.MachineDeployments, err = computeMachineDeployments(ctx, s, desiredState.ControlPlane)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Compute the desired state of the MachineDeployments from the list of MachineDeploymentTopologies
-	// defined in the cluster.
-	desiredState.MachineDeployments, err = computeMachineDeployments(ctx, s, desiredState.ControlPlane)
-	if err != nil {
+	// Apply the patches defined in the ClusterClass to the desired state.
+	if err := patches.Apply(ctx, s.Blueprint, desiredState); err != nil {
+		return nil, errors.Wrap(err, "failed to apply patches")
+	}
+
+	// If the Cluster is being rebased to a different ClusterClass, ensure MachineDeployments pick up
+	// the templates of the new ClusterClass in a controlled way.
+	if err := computeMachineDeploymentsRebase(s, desiredState); err != nil {
 		return nil, err
 	}
 
@@ -280,6 +297,11 @@ func computeCluster(_ context.Context, s *scope.Scope, infrastructureCluster, co
 
 // computeMachineDeployments computes the desired state of the list of MachineDeployments.
 func computeMachineDeployments(ctx context.Context, s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState) (scope.MachineDeploymentsStateMap, error) {
+	// Initialize the upgrade tracker with the upgrade strategy defined in the topology.
+	if err := initMachineDeploymentUpgradeTracker(s); err != nil {
+		return nil, err
+	}
+
 	machineDeploymentsStateMap := make(scope.MachineDeploymentsStateMap)
 	for _, mdTopology := range s.Blueprint.Topology.Workers.MachineDeployments {
 		desiredMachineDeployment, err := computeMachineDeployment(ctx, s, desiredControlPlaneState, mdTopology)
@@ -291,6 +313,44 @@ func computeMachineDeployments(ctx context.Context, s *scope.Scope, desiredContr
 	return machineDeploymentsStateMap, nil
 }
 
+// initMachineDeploymentUpgradeTracker sets the max concurrency for MachineDeployment upgrades and records the
+// upgrade order of the MachineDeployments which did not complete the upgrade to the topology version yet.
+func initMachineDeploymentUpgradeTracker(s *scope.Scope) error {
+	workers := s.Blueprint.Topology.Workers
+
+	if workers.Upgrade != nil && workers.Upgrade.MaxConcurrency != nil {
+		maxConcurrency, err := intstr.GetScaledValueFromIntOrPercent(workers.Upgrade.MaxConcurrency, len(workers.MachineDeployments), true)
+		if err != nil {
+			return errors.Wrap(err, "failed to compute max concurrency for MachineDeployment upgrades")
+		}
+		s.UpgradeTracker.MachineDeployments.SetMaxConcurrency(maxConcurrency)
+	}
+
+	for _, mdTopology := range workers.MachineDeployments {
+		currentMachineDeployment := s.Current.MachineDeployments[mdTopology.Name]
+		if currentMachineDeployment == nil || currentMachineDeployment.Object == nil {
+			continue
+		}
+		if !isMachineDeploymentUpgraded(currentMachineDeployment, s.Blueprint.Topology.Version) {
+			s.UpgradeTracker.MachineDeployments.MarkNotUpgraded(machineDeploymentUpgradeOrder(mdTopology))
+		}
+	}
+	return nil
+}
+
+// isMachineDeploymentUpgraded returns true if the MachineDeployment is at the given version and it is not rolling out.
+func isMachineDeploymentUpgraded(md *scope.MachineDeploymentState, version string) bool {
+	return md.Object.Spec.Template.Spec.Version != nil && *md.Object.Spec.Template.Spec.Version == version && !md.IsRollingOut()
+}
+
+// machineDeploymentUpgradeOrder returns the upgrade order of a MachineDeploymentTopology, defaulting to 0.
+func machineDeploymentUpgradeOrder(mdTopology clusterv1.MachineDeploymentTopology) int32 {
+	if mdTopology.UpgradeOrder == nil {
+		return 0
+	}
+	return *mdTopology.UpgradeOrder
+}
+
 // computeMachineDeployment computes the desired state for a MachineDeploymentTopology.
 // The generated machineDeployment object is calculated using the values from the machineDeploymentTopology and
 // the machineDeployment class.
@@ -346,7 +406,7 @@ func computeMachineDeployment(_ context.Context, s *scope.Scope, desiredControlP
 	// Add ClusterTopologyMachineDeploymentLabel to the generated InfrastructureMachine template
 	infraMachineTemplateLabels[clusterv1.ClusterTopologyMachineDeploymentLabelName] = machineDeploymentTopology.Name
 	desiredMachineDeployment.InfrastructureMachineTemplate.SetLabels(infraMachineTemplateLabels)
-	version, err := computeMachineDeploymentVersion(s, desiredControlPlaneState, currentMachineDeployment)
+	version, err := computeMachineDeploymentVersion(s, machineDeploymentTopology, desiredControlPlaneState, currentMachineDeployment)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to compute version for %s", machineDeploymentTopology.Name)
 	}
@@ -412,16 +472,22 @@ func computeMachineDeployment(_ context.Context, s *scope.Scope, desiredControlP
 	desiredMachineDeploymentObj.Spec.Replicas = machineDeploymentTopology.Replicas
 
 	desiredMachineDeployment.Object = desiredMachineDeploymentObj
+
+	// If required by the blueprint, compute the desired state of the MachineHealthCheck for the MachineDeployment.
+	if mhcClass := s.Blueprint.MachineDeploymentMachineHealthCheckClass(&machineDeploymentTopology); mhcClass != nil {
+		desiredMachineDeployment.MachineHealthCheck = computeMachineHealthCheck(s, desiredMachineDeploymentObj.Name,
+			selectorForMachineDeploymentMHC(s.Current.Cluster, machineDeploymentTopology.Name), mhcClass)
+	}
 	return desiredMachineDeployment, nil
 }
 
 // computeMachineDeploymentVersion calculates the version of the desired machine deployment.
 // The version is calculated using the state of the current machine deployments,
 // the current control plane and the version defined in the topology.
-// Nb: No MachineDeployment upgrades will be triggered while any MachineDeployment is in the middle
-// of an upgrade. Even if the number of MachineDeployments that are being upgraded is less
-// than the number of allowed concurrent upgrades.
-func computeMachineDeploymentVersion(s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState, currentMDState *scope.MachineDeploymentState) (string, error) {
+// Nb: MachineDeployment upgrades are triggered only if the number of MachineDeployments rolling out, including the ones
+// picking up the new version in the current reconcile, is less than the number of allowed concurrent upgrades, and if
+// all the MachineDeployments with a lower upgrade order completed the upgrade.
+func computeMachineDeploymentVersion(s *scope.Scope, machineDeploymentTopology clusterv1.MachineDeploymentTopology, desiredControlPlaneState *scope.ControlPlaneState, currentMDState *scope.MachineDeploymentState) (string, error) {
 	desiredVersion := s.Blueprint.Topology.Version
 	// If creating a new machine deployment, we can pick up the desired version
 	// Note: We are not blocking the creation of new machine deployments when
@@ -433,8 +499,10 @@ func computeMachineDeploymentVersion(s *scope.Scope, desiredControlPlaneState *s
 	// Get the current version of the machine deployment.
 	currentVersion := *currentMDState.Object.Spec.Template.Spec.Version
 
-	// Return early if we are not allowed to upgrade the machine deployment.
-	if !s.UpgradeTracker.MachineDeployments.AllowUpgrade() {
+	// Return early if we are not allowed to upgrade the machine deployment, either because the max concurrency
+	// has been already reached in the current reconcile or because of the upgrade order.
+	if !s.UpgradeTracker.MachineDeployments.AllowUpgrade(0) ||
+		!s.UpgradeTracker.MachineDeployments.AllowUpgradeOrder(machineDeploymentUpgradeOrder(machineDeploymentTopology)) {
 		return currentVersion, nil
 	}
 
@@ -444,70 +512,83 @@ func computeMachineDeploymentVersion(s *scope.Scope, desiredControlPlaneState *s
 		return currentVersion, nil
 	}
 
-	// If the control plane is being created (current control plane is nil), do not perform
-	// any machine deployment upgrade in this case.
-	// Return the current version of the machine deployment.
-	// NOTE: this case should never happen (upgrading a MachineDeployment) before creating a CP,
-	// but we are implementing this check for extra safety.
-	if s.Current.ControlPlane == nil || s.Current.ControlPlane.Object == nil {
-		return currentVersion, nil
-	}
-
-	// If the current control plane is upgrading, then do not pick up the desiredVersion yet.
-	// Return the current version of the machine deployment. We will pick up the new version after the control
-	// plane is stable.
-	cpUpgrading, err := contract.ControlPlane().IsUpgrading(s.Current.ControlPlane.Object)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to check if control plane is upgrading")
-	}
-	if cpUpgrading {
-		return currentVersion, nil
-	}
-
-	// If control plane supports replicas, check if the control plane is in the middle of a scale operation.
-	// If the current control plane is scaling, then do not pick up the desiredVersion yet.
-	// Return the current version of the machine deployment. We will pick up the new version after the control
-	// plane is stable.
-	if s.Blueprint.Topology.ControlPlane.Replicas != nil {
-		cpScaling, err := contract.ControlPlane().IsScaling(s.Current.ControlPlane.Object)
-		if err != nil {
-			return "", errors.Wrap(err, "failed to check if the control plane is scaling")
-		}
-		if cpScaling {
-			return currentVersion, nil
-		}
-	}
-
-	// Check if we are about to upgrade the control plane. In that case, do not upgrade the machine deployment yet.
-	// Wait for the new upgrade operation on the control plane to finish before picking up the new version for the
-	// machine deployment.
-	currentCPVersion, err := contract.ControlPlane().Version().Get(s.Current.ControlPlane.Object)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to get version of current control plane")
-	}
-	desiredCPVersion, err := contract.ControlPlane().Version().Get(desiredControlPlaneState.Object)
+	// If the control plane is not stable (being created, upgrading, scaling or about to be upgraded), do not pick up
+	// the desiredVersion yet. Return the current version of the machine deployment. We will pick up the new version
+	// after the control plane is stable.
+	cpStable, err := isControlPlaneStable(s, desiredControlPlaneState)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get version of desired control plane")
+		return "", err
 	}
-	if *currentCPVersion != *desiredCPVersion {
-		// The versions of the current and desired control planes do no match,
-		// implies we are about to upgrade the control plane.
+	if !cpStable {
 		return currentVersion, nil
 	}
 
 	// At this point the control plane is stable (not scaling, not upgrading, not being upgraded).
-	// Checking to see if the machine deployments are also stable.
-	// If any of the MachineDeployments is rolling out, do not upgrade the machine deployment yet.
-	if s.Current.MachineDeployments.IsAnyRollingOut() {
+	// Checking to see how many machine deployments are rolling out.
+	// If the number of MachineDeployments rolling out reached the max concurrency, do not upgrade the machine deployment yet.
+	if !s.UpgradeTracker.MachineDeployments.AllowUpgrade(len(s.Current.MachineDeployments.RollingOut())) {
 		return currentVersion, nil
 	}
 
-	// Control plane and machine deployments are stable.
+	// Control plane is stable and the max concurrency for machine deployments has not been reached.
 	// Ready to pick up the topology version.
 	s.UpgradeTracker.MachineDeployments.Insert(currentMDState.Object.Name)
 	return desiredVersion, nil
 }
 
+// computeMachineHealthCheck computes the desired state of a MachineHealthCheck for the Machines matching the given selector,
+// starting from the corresponding MachineHealthCheckClass.
+// NOTE: The MachineHealthCheck has the same name of the ControlPlane / MachineDeployment object it is targeting.
+// NOTE: OwnerRef can't be set at this stage; the OwnerReference to the ControlPlane / MachineDeployment object is added
+// when reconciling the MachineHealthCheck, while the MachineHealthCheck controller is going to add the OwnerReference to the Cluster.
+func computeMachineHealthCheck(s *scope.Scope, name string, selector *metav1.LabelSelector, check *clusterv1.MachineHealthCheckClass) *clusterv1.MachineHealthCheck {
+	cluster := s.Current.Cluster
+	return &clusterv1.MachineHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       clusterv1.GroupVersion.WithKind("MachineHealthCheck").Kind,
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:          cluster.Name,
+				clusterv1.ClusterTopologyOwnedLabel: "",
+			},
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName:         cluster.Name,
+			Selector:            *selector,
+			UnhealthyConditions: check.UnhealthyConditions,
+			MaxUnhealthy:        check.MaxUnhealthy,
+			UnhealthyRange:      check.UnhealthyRange,
+			NodeStartupTimeout:  check.NodeStartupTimeout,
+			RemediationTemplate: check.RemediationTemplate,
+		},
+	}
+}
+
+// selectorForControlPlaneMHC returns the selector for the control plane Machines of a Cluster.
+func selectorForControlPlaneMHC(cluster *clusterv1.Cluster) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			clusterv1.ClusterLabelName:             cluster.Name,
+			clusterv1.MachineControlPlaneLabelName: "",
+		},
+	}
+}
+
+// selectorForMachineDeploymentMHC returns the selector for the Machines of a MachineDeployment in a managed topology.
+func selectorForMachineDeploymentMHC(cluster *clusterv1.Cluster, mdTopologyName string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			clusterv1.ClusterLabelName:                          cluster.Name,
+			clusterv1.ClusterTopologyOwnedLabel:                 "",
+			clusterv1.ClusterTopologyMachineDeploymentLabelName: mdTopologyName,
+		},
+	}
+}
+
 type templateToInput struct {
 	template              *unstructured.Unstructured
 	templateClonedFromRef *corev1.ObjectReference
@@ -610,3 +691,46 @@ func mergeMap(a, b map[string]string) map[string]string {
 	}
 	return m
 }
+
+// isControlPlaneStable returns true if the control plane is not being created, upgrading, scaling or about to be upgraded.
+func isControlPlaneStable(s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState) (bool, error) {
+	// If the control plane is being created (current control plane is nil), it is not stable.
+	// NOTE: this case should never happen (upgrading a MachineDeployment) before creating a CP,
+	// but we are implementing this check for extra safety.
+	if s.Current.ControlPlane == nil || s.Current.ControlPlane.Object == nil {
+		return false, nil
+	}
+
+	// If the current control plane is upgrading, it is not stable.
+	cpUpgrading, err := contract.ControlPlane().IsUpgrading(s.Current.ControlPlane.Object)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check if control plane is upgrading")
+	}
+	if cpUpgrading {
+		return false, nil
+	}
+
+	// If control plane supports replicas, check if the control plane is in the middle of a scale operation.
+	if s.Blueprint.Topology.ControlPlane.Replicas != nil {
+		cpScaling, err := contract.ControlPlane().IsScaling(s.Current.ControlPlane.Object)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to check if the control plane is scaling")
+		}
+		if cpScaling {
+			return false, nil
+		}
+	}
+
+	// Check if we are about to upgrade the control plane.
+	currentCPVersion, err := contract.ControlPlane().Version().Get(s.Current.ControlPlane.Object)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get version of current control plane")
+	}
+	desiredCPVersion, err := contract.ControlPlane().Version().Get(desiredControlPlaneState.Object)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get version of desired control plane")
+	}
+	// The versions of the current and desired control planes do no match,
+	// implies we are about to upgrade the control plane.
+	return *currentCPVersion == *desiredCPVersion, nil
+}
@@ -19,11 +19,13 @@ package topology
 import (
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
@@ -1034,6 +1036,9 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		currentControlPlane           *unstructured.Unstructured
 		desiredControlPlane           *unstructured.Unstructured
 		topologyVersion               string
+		upgradeOrder                  *int32
+		maxConcurrency                int
+		notUpgradedOrder              *int32
 		expectedVersion               string
 	}{
 		{
@@ -1080,6 +1085,27 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 			topologyVersion:               "v1.2.3",
 			expectedVersion:               "v1.2.2",
 		},
+		{
+			name:                          "should return cluster.spec.topology.version if the number of machine deployments rolling out is less than the max concurrency",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			machineDeploymentsStateMap:    machineDeploymentsStateRollingOut,
+			currentControlPlane:           controlPlaneStable123,
+			desiredControlPlane:           controlPlaneDesired,
+			topologyVersion:               "v1.2.3",
+			maxConcurrency:                2,
+			expectedVersion:               "v1.2.3",
+		},
+		{
+			name:                          "should return machine deployment's spec.template.spec.version if machine deployments with a lower upgrade order did not complete the upgrade",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			machineDeploymentsStateMap:    machineDeploymentsStateStable,
+			currentControlPlane:           controlPlaneStable123,
+			desiredControlPlane:           controlPlaneDesired,
+			topologyVersion:               "v1.2.3",
+			upgradeOrder:                  pointer.Int32(1),
+			notUpgradedOrder:              pointer.Int32(0),
+			expectedVersion:               "v1.2.2",
+		},
 		{
 			name:                          "should return cluster.spec.topology.version if the control plane is not upgrading, not scaling, not ready to upgrade and none of the machine deployments are rolling out",
 			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
@@ -1108,14 +1134,243 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 				},
 				UpgradeTracker: scope.NewUpgradeTracker(),
 			}
+			if tt.maxConcurrency > 0 {
+				s.UpgradeTracker.MachineDeployments.SetMaxConcurrency(tt.maxConcurrency)
+			}
+			if tt.notUpgradedOrder != nil {
+				s.UpgradeTracker.MachineDeployments.MarkNotUpgraded(*tt.notUpgradedOrder)
+			}
+			mdTopology := clusterv1.MachineDeploymentTopology{UpgradeOrder: tt.upgradeOrder}
 			desiredControlPlaneState := &scope.ControlPlaneState{Object: tt.desiredControlPlane}
-			version, err := computeMachineDeploymentVersion(s, desiredControlPlaneState, tt.currentMachineDeploymentState)
+			version, err := computeMachineDeploymentVersion(s, mdTopology, desiredControlPlaneState, tt.currentMachineDeploymentState)
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(version).To(Equal(tt.expectedVersion))
 		})
 	}
 }
 
+func TestInitMachineDeploymentUpgradeTracker(t *testing.T) {
+	stableStatus := clusterv1.MachineDeploymentStatus{
+		ObservedGeneration: 2,
+		Replicas:           1,
+		UpdatedReplicas:    1,
+		AvailableReplicas:  1,
+		ReadyReplicas:      1,
+	}
+	mdUpgraded := builder.MachineDeployment("test-namespace", "md-upgraded").
+		WithGeneration(1).WithReplicas(1).WithVersion("v1.2.3").WithStatus(stableStatus).Build()
+	mdPending := builder.MachineDeployment("test-namespace", "md-pending").
+		WithGeneration(1).WithReplicas(1).WithVersion("v1.2.2").WithStatus(stableStatus).Build()
+
+	newScope := func(maxConcurrency *intstr.IntOrString) *scope.Scope {
+		return &scope.Scope{
+			Blueprint: &scope.ClusterBlueprint{Topology: &clusterv1.Topology{
+				Version: "v1.2.3",
+				Workers: &clusterv1.WorkersTopology{
+					MachineDeployments: []clusterv1.MachineDeploymentTopology{
+						{Name: "md1", UpgradeOrder: pointer.Int32(0)},
+						{Name: "md2", UpgradeOrder: pointer.Int32(1)},
+						{Name: "md3", UpgradeOrder: pointer.Int32(2)},
+						{Name: "md4", UpgradeOrder: pointer.Int32(2)},
+					},
+					Upgrade: &clusterv1.WorkersUpgrade{MaxConcurrency: maxConcurrency},
+				},
+			}},
+			Current: &scope.ClusterState{
+				MachineDeployments: scope.MachineDeploymentsStateMap{
+					"md1": {Object: mdUpgraded},
+					"md2": {Object: mdPending},
+					"md3": {Object: mdPending},
+				},
+			},
+			UpgradeTracker: scope.NewUpgradeTracker(),
+		}
+	}
+
+	t.Run("Computes max concurrency from a number", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := newScope(intOrStrPtr(intstr.FromInt(3)))
+		g.Expect(initMachineDeploymentUpgradeTracker(s)).To(Succeed())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade(2)).To(BeTrue())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade(3)).To(BeFalse())
+	})
+	t.Run("Computes max concurrency from a percentage, rounding up", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := newScope(intOrStrPtr(intstr.FromString("30%")))
+		g.Expect(initMachineDeploymentUpgradeTracker(s)).To(Succeed())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade(1)).To(BeTrue())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade(2)).To(BeFalse())
+	})
+	t.Run("Allows upgrades only for the lowest upgrade order not completed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := newScope(nil)
+		g.Expect(initMachineDeploymentUpgradeTracker(s)).To(Succeed())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade(0)).To(BeTrue())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade(1)).To(BeFalse())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgradeOrder(1)).To(BeTrue())
+		g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgradeOrder(2)).To(BeFalse())
+	})
+}
+
+func intOrStrPtr(i intstr.IntOrString) *intstr.IntOrString {
+	return &i
+}
+
+func TestComputeMachineHealthCheck(t *testing.T) {
+	classCheck := &clusterv1.MachineHealthCheckClass{
+		UnhealthyConditions: []clusterv1.UnhealthyCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+		},
+		NodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	maxUnhealthy := intstr.FromString("40%")
+	topologyCheck := clusterv1.MachineHealthCheckClass{
+		UnhealthyConditions: []clusterv1.UnhealthyCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Timeout: metav1.Duration{Duration: 10 * time.Minute}},
+		},
+		MaxUnhealthy: &maxUnhealthy,
+	}
+
+	tests := []struct {
+		name      string
+		class     *clusterv1.MachineHealthCheckClass
+		topology  *clusterv1.MachineHealthCheckTopology
+		wantCheck *clusterv1.MachineHealthCheckClass
+	}{
+		{
+			name:      "No MachineHealthCheck if not defined in the ClusterClass nor in the topology",
+			class:     nil,
+			topology:  nil,
+			wantCheck: nil,
+		},
+		{
+			name:      "Use the MachineHealthCheck defined in the ClusterClass",
+			class:     classCheck,
+			topology:  nil,
+			wantCheck: classCheck,
+		},
+		{
+			name:      "Use the MachineHealthCheck defined in the ClusterClass if the topology only enables it",
+			class:     classCheck,
+			topology:  &clusterv1.MachineHealthCheckTopology{Enable: pointer.Bool(true)},
+			wantCheck: classCheck,
+		},
+		{
+			name:      "Use the MachineHealthCheck defined in the topology",
+			class:     classCheck,
+			topology:  &clusterv1.MachineHealthCheckTopology{MachineHealthCheckClass: topologyCheck},
+			wantCheck: &topologyCheck,
+		},
+		{
+			name:      "Use the MachineHealthCheck defined in the topology even if not defined in the ClusterClass",
+			class:     nil,
+			topology:  &clusterv1.MachineHealthCheckTopology{MachineHealthCheckClass: topologyCheck},
+			wantCheck: &topologyCheck,
+		},
+		{
+			name:      "No MachineHealthCheck if disabled in the topology",
+			class:     classCheck,
+			topology:  &clusterv1.MachineHealthCheckTopology{Enable: pointer.Bool(false), MachineHealthCheckClass: topologyCheck},
+			wantCheck: nil,
+		},
+	}
+
+	t.Run("Compute the MachineHealthCheck for the control plane", func(t *testing.T) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				g := NewWithT(t)
+
+				infrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "infra-machine-template").Build()
+				controlPlane := builder.ControlPlane(metav1.NamespaceDefault, "cp1").Build()
+				cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+				cluster.Spec.Topology = &clusterv1.Topology{
+					ControlPlane: clusterv1.ControlPlaneTopology{MachineHealthCheck: tt.topology},
+				}
+
+				s := scope.New(cluster)
+				s.Blueprint = &scope.ClusterBlueprint{
+					Topology: cluster.Spec.Topology,
+					ClusterClass: builder.ClusterClass(metav1.NamespaceDefault, "class1").
+						WithControlPlaneTemplate(controlPlane).
+						WithControlPlaneInfrastructureMachineTemplate(infrastructureMachineTemplate).
+						Build(),
+					ControlPlane: &scope.ControlPlaneBlueprint{
+						Template:                      controlPlane,
+						InfrastructureMachineTemplate: infrastructureMachineTemplate,
+						MachineHealthCheck:            tt.class,
+					},
+				}
+
+				check := s.Blueprint.ControlPlaneMachineHealthCheckClass()
+				g.Expect(check).To(Equal(tt.wantCheck))
+				if check == nil {
+					return
+				}
+
+				got := computeMachineHealthCheck(s, "cp1", selectorForControlPlaneMHC(cluster), check)
+				g.Expect(got.Name).To(Equal("cp1"))
+				g.Expect(got.Namespace).To(Equal(cluster.Namespace))
+				g.Expect(got.Labels).To(HaveKeyWithValue(clusterv1.ClusterLabelName, cluster.Name))
+				g.Expect(got.Labels).To(HaveKey(clusterv1.ClusterTopologyOwnedLabel))
+				g.Expect(got.Spec.ClusterName).To(Equal(cluster.Name))
+				g.Expect(got.Spec.Selector.MatchLabels).To(Equal(map[string]string{
+					clusterv1.ClusterLabelName:             cluster.Name,
+					clusterv1.MachineControlPlaneLabelName: "",
+				}))
+				g.Expect(got.Spec.UnhealthyConditions).To(Equal(tt.wantCheck.UnhealthyConditions))
+				g.Expect(got.Spec.MaxUnhealthy).To(Equal(tt.wantCheck.MaxUnhealthy))
+				g.Expect(got.Spec.NodeStartupTimeout).To(Equal(tt.wantCheck.NodeStartupTimeout))
+			})
+		}
+	})
+
+	t.Run("Compute the MachineHealthCheck for a MachineDeployment", func(t *testing.T) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				g := NewWithT(t)
+
+				mdTopology := clusterv1.MachineDeploymentTopology{
+					Class:              "linux-worker",
+					Name:               "md1",
+					MachineHealthCheck: tt.topology,
+				}
+				cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+				cluster.Spec.Topology = &clusterv1.Topology{
+					Workers: &clusterv1.WorkersTopology{
+						MachineDeployments: []clusterv1.MachineDeploymentTopology{mdTopology},
+					},
+				}
+
+				s := scope.New(cluster)
+				s.Blueprint = &scope.ClusterBlueprint{
+					Topology: cluster.Spec.Topology,
+					MachineDeployments: map[string]*scope.MachineDeploymentBlueprint{
+						"linux-worker": {MachineHealthCheck: tt.class},
+					},
+				}
+
+				check := s.Blueprint.MachineDeploymentMachineHealthCheckClass(&mdTopology)
+				g.Expect(check).To(Equal(tt.wantCheck))
+				if check == nil {
+					return
+				}
+
+				got := computeMachineHealthCheck(s, "cluster1-md1-abcde", selectorForMachineDeploymentMHC(cluster, "md1"), check)
+				g.Expect(got.Name).To(Equal("cluster1-md1-abcde"))
+				g.Expect(got.Spec.Selector.MatchLabels).To(Equal(map[string]string{
+					clusterv1.ClusterLabelName:                          cluster.Name,
+					clusterv1.ClusterTopologyOwnedLabel:                 "",
+					clusterv1.ClusterTopologyMachineDeploymentLabelName: "md1",
+				}))
+				g.Expect(got.Spec.UnhealthyConditions).To(Equal(tt.wantCheck.UnhealthyConditions))
+			})
+		}
+	})
+}
+
 func TestTemplateToObject(t *testing.T) {
 	template := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infrastructureClusterTemplate").
 		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
@@ -19,6 +19,8 @@ package check
 
 import (
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -54,3 +56,12 @@ func ObjectsAreInTheSameNamespace(current, desired client.Object) error {
 	}
 	return nil
 }
+
+// ClusterClassesAreCompatible checks if a Cluster can be rebased from the current to the desired ClusterClass, meaning that
+// the templates for the InfrastructureCluster, the ControlPlane and the ControlPlane InfrastructureMachines are of the same
+// GroupKind, and that all the MachineDeploymentClasses of the current ClusterClass are preserved in the desired ClusterClass
+// with templates of the same GroupKind.
+// NOTE: This uses the same validation implemented by the Cluster webhook.
+func ClusterClassesAreCompatible(current, desired *clusterv1.ClusterClass) error {
+	return current.ValidateRebase(desired, field.NewPath("spec", "topology", "class")).ToAggregate()
+}
@@ -20,7 +20,10 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/builder"
 )
 
 type referencedObjectsCompatibilityTestCase struct {
@@ -179,3 +182,101 @@ func TestCheckReferencedObjectsAreStrictlyCompatible(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterClassesAreCompatible(t *testing.T) {
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infraclustertemplate1").Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "controlplanetemplate1").Build()
+	infrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "inframachinetemplate1").Build()
+	bootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "bootstraptemplate1").Build()
+
+	otherInfrastructureClusterTemplate := infrastructureClusterTemplate.DeepCopy()
+	otherInfrastructureClusterTemplate.SetKind("OtherInfrastructureClusterTemplate")
+
+	mdClass := func(class string, bootstrapTemplate *unstructured.Unstructured) clusterv1.MachineDeploymentClass {
+		return *builder.MachineDeploymentClass(metav1.NamespaceDefault, class).
+			WithClass(class).
+			WithInfrastructureTemplate(infrastructureMachineTemplate).
+			WithBootstrapTemplate(bootstrapTemplate).
+			Build()
+	}
+
+	current := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+		WithControlPlaneTemplate(controlPlaneTemplate).
+		WithControlPlaneInfrastructureMachineTemplate(infrastructureMachineTemplate).
+		WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{mdClass("linux-worker", bootstrapTemplate)}).
+		Build()
+
+	otherBootstrapTemplate := bootstrapTemplate.DeepCopy()
+	otherBootstrapTemplate.SetKind("OtherBootstrapTemplate")
+
+	tests := []struct {
+		name    string
+		desired *clusterv1.ClusterClass
+		wantErr bool
+	}{
+		{
+			name: "Pass if templates are of the same GroupKind and MachineDeployment classes are preserved",
+			desired: builder.ClusterClass(metav1.NamespaceDefault, "class2").
+				WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+				WithControlPlaneTemplate(controlPlaneTemplate).
+				WithControlPlaneInfrastructureMachineTemplate(infrastructureMachineTemplate).
+				WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{
+					mdClass("linux-worker", bootstrapTemplate),
+					mdClass("windows-worker", bootstrapTemplate),
+				}).
+				Build(),
+		},
+		{
+			name: "Fails if the InfrastructureClusterTemplate GroupKind changes",
+			desired: builder.ClusterClass(metav1.NamespaceDefault, "class2").
+				WithInfrastructureClusterTemplate(otherInfrastructureClusterTemplate).
+				WithControlPlaneTemplate(controlPlaneTemplate).
+				WithControlPlaneInfrastructureMachineTemplate(infrastructureMachineTemplate).
+				WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{mdClass("linux-worker", bootstrapTemplate)}).
+				Build(),
+			wantErr: true,
+		},
+		{
+			name: "Fails if the ControlPlane InfrastructureMachineTemplate is removed",
+			desired: builder.ClusterClass(metav1.NamespaceDefault, "class2").
+				WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+				WithControlPlaneTemplate(controlPlaneTemplate).
+				WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{mdClass("linux-worker", bootstrapTemplate)}).
+				Build(),
+			wantErr: true,
+		},
+		{
+			name: "Fails if a MachineDeployment class is removed",
+			desired: builder.ClusterClass(metav1.NamespaceDefault, "class2").
+				WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+				WithControlPlaneTemplate(controlPlaneTemplate).
+				WithControlPlaneInfrastructureMachineTemplate(infrastructureMachineTemplate).
+				WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{mdClass("windows-worker", bootstrapTemplate)}).
+				Build(),
+			wantErr: true,
+		},
+		{
+			name: "Fails if the BootstrapTemplate GroupKind of a MachineDeployment class changes",
+			desired: builder.ClusterClass(metav1.NamespaceDefault, "class2").
+				WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+				WithControlPlaneTemplate(controlPlaneTemplate).
+				WithControlPlaneInfrastructureMachineTemplate(infrastructureMachineTemplate).
+				WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{mdClass("linux-worker", otherBootstrapTemplate)}).
+				Build(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := ClusterClassesAreCompatible(current, tt.desired)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
@@ -201,3 +201,8 @@ func (h *Helper) Patch(ctx context.Context) error {
 
 	return h.client.Patch(ctx, h.original, client.RawPatch(types.MergePatchType, h.patch))
 }
+
+// Changes returns the merge patch in json format, containing only the changes relevant for the topology.
+func (h *Helper) Changes() []byte {
+	return h.patch
+}
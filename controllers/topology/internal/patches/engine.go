@@ -0,0 +1,302 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
+	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+)
+
+// templateLocation defines where a template is referenced in the ClusterClass;
+// it is used to match the PatchSelectorMatch of patch definitions.
+type templateLocation struct {
+	infrastructureCluster  bool
+	controlPlane           bool
+	machineDeploymentClass string
+}
+
+// matches returns true if the template location matches the given PatchSelectorMatch.
+func (l templateLocation) matches(match clusterv1.PatchSelectorMatch) bool {
+	if l.infrastructureCluster && match.InfrastructureCluster {
+		return true
+	}
+	if l.controlPlane && match.ControlPlane {
+		return true
+	}
+	if l.machineDeploymentClass != "" && match.MachineDeploymentClass != nil {
+		for _, name := range match.MachineDeploymentClass.Names {
+			if name == l.machineDeploymentClass {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply applies the patches defined in the ClusterClass to the desired state.
+// NOTE: Patches are written against templates, thus the InfrastructureCluster and the ControlPlane objects are
+// converted into the corresponding template before applying patches, and then the patched template spec is copied back.
+func Apply(_ context.Context, blueprint *scope.ClusterBlueprint, desired *scope.ClusterState) error {
+	clusterClass := blueprint.ClusterClass
+	if len(clusterClass.Spec.Patches) == 0 {
+		return nil
+	}
+
+	variables, err := clusterVariables(clusterClass, desired.Cluster, blueprint.Topology)
+	if err != nil {
+		return err
+	}
+
+	// Patch the InfrastructureCluster.
+	if desired.InfrastructureCluster != nil {
+		if err := applyPatchesToObject(clusterClass, desired.InfrastructureCluster, blueprint.InfrastructureClusterTemplate,
+			templateLocation{infrastructureCluster: true}, variables); err != nil {
+			return err
+		}
+	}
+
+	// Patch the ControlPlane and the InfrastructureMachineTemplate for the ControlPlane, if any.
+	if desired.ControlPlane != nil {
+		if desired.ControlPlane.Object != nil {
+			if err := applyPatchesToObject(clusterClass, desired.ControlPlane.Object, blueprint.ControlPlane.Template,
+				templateLocation{controlPlane: true}, variables,
+				// NOTE: The following fields are computed by the topology controller and they must be preserved.
+				contract.ControlPlane().MachineTemplate().InfrastructureRef().Path(),
+				contract.ControlPlane().MachineTemplate().Metadata().Path(),
+				contract.ControlPlane().Replicas().Path(),
+				contract.ControlPlane().Version().Path(),
+			); err != nil {
+				return err
+			}
+		}
+		if desired.ControlPlane.InfrastructureMachineTemplate != nil {
+			if err := applyPatchesToTemplate(clusterClass, desired.ControlPlane.InfrastructureMachineTemplate,
+				templateLocation{controlPlane: true}, variables); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Patch the templates of the MachineDeployments.
+	for _, md := range desired.MachineDeployments {
+		topologyName := md.Object.Labels[clusterv1.ClusterTopologyMachineDeploymentLabelName]
+		mdTopology, err := machineDeploymentTopology(blueprint, topologyName)
+		if err != nil {
+			return err
+		}
+
+		// Apply the variable overrides of the MachineDeployment on top of the Cluster level values.
+		mdVariables := variables.DeepCopy()
+		if mdTopology.Variables != nil {
+			if err := mdVariables.setValues(mdTopology.Variables.Overrides); err != nil {
+				return errors.Wrapf(err, "failed to set variable overrides for MachineDeployment topology %q", mdTopology.Name)
+			}
+		}
+		mdVariables.setBuiltin(mdTopology.Class, "machineDeployment", "class")
+		mdVariables.setBuiltin(mdTopology.Name, "machineDeployment", "topologyName")
+		if md.Object.Spec.Template.Spec.Version != nil {
+			mdVariables.setBuiltin(*md.Object.Spec.Template.Spec.Version, "machineDeployment", "version")
+		}
+		if md.Object.Spec.Replicas != nil {
+			mdVariables.setBuiltin(int64(*md.Object.Spec.Replicas), "machineDeployment", "replicas")
+		}
+
+		location := templateLocation{machineDeploymentClass: mdTopology.Class}
+		if err := applyPatchesToTemplate(clusterClass, md.BootstrapTemplate, location, mdVariables); err != nil {
+			return err
+		}
+		if err := applyPatchesToTemplate(clusterClass, md.InfrastructureMachineTemplate, location, mdVariables); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// machineDeploymentTopology returns the MachineDeploymentTopology with the given name.
+func machineDeploymentTopology(blueprint *scope.ClusterBlueprint, name string) (*clusterv1.MachineDeploymentTopology, error) {
+	if blueprint.Topology.Workers != nil {
+		for i := range blueprint.Topology.Workers.MachineDeployments {
+			if blueprint.Topology.Workers.MachineDeployments[i].Name == name {
+				return &blueprint.Topology.Workers.MachineDeployments[i], nil
+			}
+		}
+	}
+	return nil, errors.Errorf("failed to find MachineDeployment topology %q", name)
+}
+
+// applyPatchesToObject applies patches to an object generated from a template; the object is converted into a template
+// before applying patches, and then the patched spec is copied back into the object, preserving the given paths.
+func applyPatchesToObject(clusterClass *clusterv1.ClusterClass, obj, template *unstructured.Unstructured, location templateLocation, variables Variables, preserve ...contract.Path) error {
+	spec, _, err := unstructured.NestedMap(obj.UnstructuredContent(), "spec")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get spec from %s", tlog.KObj{Obj: obj})
+	}
+	if spec == nil {
+		spec = map[string]interface{}{}
+	}
+
+	objTemplate := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	objTemplate.SetAPIVersion(template.GetAPIVersion())
+	objTemplate.SetKind(template.GetKind())
+	objTemplate.SetNamespace(template.GetNamespace())
+	objTemplate.SetName(template.GetName())
+	if err := unstructured.SetNestedMap(objTemplate.UnstructuredContent(), spec, "spec", "template", "spec"); err != nil {
+		return errors.Wrapf(err, "failed to set spec.template.spec in %s", tlog.KObj{Obj: objTemplate})
+	}
+
+	if err := applyPatchesToTemplate(clusterClass, objTemplate, location, variables); err != nil {
+		return err
+	}
+
+	patchedSpec, _, err := unstructured.NestedMap(objTemplate.UnstructuredContent(), "spec", "template", "spec")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get spec.template.spec from %s", tlog.KObj{Obj: objTemplate})
+	}
+
+	// Preserve the given paths, restoring the values from the original object.
+	for _, path := range preserve {
+		value, ok, err := unstructured.NestedFieldNoCopy(obj.UnstructuredContent(), path...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s from %s", "."+strings.Join(path, "."), tlog.KObj{Obj: obj})
+		}
+		specPath := path[1:]
+		if !ok {
+			unstructured.RemoveNestedField(patchedSpec, specPath...)
+			continue
+		}
+		if err := unstructured.SetNestedField(patchedSpec, value, specPath...); err != nil {
+			return errors.Wrapf(err, "failed to set %s in %s", "."+strings.Join(path, "."), tlog.KObj{Obj: obj})
+		}
+	}
+
+	if err := unstructured.SetNestedMap(obj.UnstructuredContent(), patchedSpec, "spec"); err != nil {
+		return errors.Wrapf(err, "failed to set spec in %s", tlog.KObj{Obj: obj})
+	}
+	return nil
+}
+
+// applyPatchesToTemplate applies all the patches from the ClusterClass which are matching the template.
+func applyPatchesToTemplate(clusterClass *clusterv1.ClusterClass, template *unstructured.Unstructured, location templateLocation, variables Variables) error {
+	for _, patch := range clusterClass.Spec.Patches {
+		for _, definition := range patch.Definitions {
+			if !matchesSelector(definition.Selector, template, location) {
+				continue
+			}
+			for _, jsonPatch := range definition.JSONPatches {
+				if err := applyJSONPatch(template, jsonPatch, variables); err != nil {
+					return errors.Wrapf(err, "failed to apply patch %q to %s", patch.Name, tlog.KObj{Obj: template})
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesSelector returns true if the template matches the patch selector.
+func matchesSelector(selector clusterv1.PatchSelector, template *unstructured.Unstructured, location templateLocation) bool {
+	if selector.APIVersion != template.GetAPIVersion() || selector.Kind != template.GetKind() {
+		return false
+	}
+	return location.matches(selector.MatchResources)
+}
+
+// applyJSONPatch applies a single JSON patch operation to the template.
+// NOTE: If the value of the patch is read from an optional variable which is not set, the patch is skipped.
+func applyJSONPatch(template *unstructured.Unstructured, p clusterv1.JSONPatch, variables Variables) error {
+	operation := map[string]interface{}{
+		"op":   p.Op,
+		"path": p.Path,
+	}
+
+	switch {
+	case p.Value != nil:
+		var value interface{}
+		if err := json.Unmarshal(p.Value.Raw, &value); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal value for path %q", p.Path)
+		}
+		operation["value"] = value
+	case p.ValueFrom != nil:
+		value, ok := variables.Get(p.ValueFrom.Variable)
+		if !ok {
+			if strings.HasPrefix(p.ValueFrom.Variable, clusterv1.BuiltinVariablePrefix+".") {
+				return errors.Errorf("builtin variable %q is not available", p.ValueFrom.Variable)
+			}
+			return nil
+		}
+		operation["value"] = value
+	}
+
+	// Ensure the parent of the path exists, so it is possible to add fields not yet defined in the template.
+	if p.Op == clusterv1.JSONPatchOpAdd {
+		ensureParent(template.UnstructuredContent(), p.Path)
+	}
+
+	patchJSON, err := json.Marshal([]interface{}{operation})
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal patch for path %q", p.Path)
+	}
+	patch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode patch for path %q", p.Path)
+	}
+
+	templateJSON, err := json.Marshal(template.UnstructuredContent())
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal template")
+	}
+	patchedJSON, err := patch.Apply(templateJSON)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply patch for path %q", p.Path)
+	}
+	if err := template.UnmarshalJSON(patchedJSON); err != nil {
+		return errors.Wrap(err, "failed to unmarshal patched template")
+	}
+	return nil
+}
+
+// ensureParent creates the objects in the path, excluding the last segment, if they do not exist.
+func ensureParent(obj map[string]interface{}, path string) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	current := obj
+	for _, segment := range segments[:len(segments)-1] {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		value, ok := current[segment]
+		if !ok {
+			next := map[string]interface{}{}
+			current[segment] = next
+			current = next
+			continue
+		}
+		next, ok := value.(map[string]interface{})
+		if !ok {
+			// NOTE: Arrays and other values are left untouched; in this case the patch is applied as it is.
+			return
+		}
+		current = next
+	}
+}
@@ -0,0 +1,416 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/internal/builder"
+)
+
+func TestApply(t *testing.T) {
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra-cluster-template").Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "control-plane-template").Build()
+	bootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "bootstrap-template").Build()
+	infrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "infra-machine-template").Build()
+
+	tests := []struct {
+		name      string
+		variables []clusterv1.ClusterClassVariable
+		values    []clusterv1.ClusterVariable
+		overrides []clusterv1.ClusterVariable
+		patches   []clusterv1.ClusterClassPatch
+		// Expected spec fields, indexed by object.
+		wantInfrastructureCluster map[string]interface{}
+		wantControlPlane          map[string]interface{}
+		wantBootstrapTemplate     map[string]interface{}
+		wantErr                   bool
+	}{
+		{
+			name: "no-op without patches",
+		},
+		{
+			name: "Patch the InfrastructureCluster with a value",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion:     infrastructureClusterTemplate.GetAPIVersion(),
+								Kind:           infrastructureClusterTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{InfrastructureCluster: true},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:    "add",
+									Path:  "/spec/template/spec/resourceGroup",
+									Value: &apiextensionsv1.JSON{Raw: []byte(`"rg"`)},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantInfrastructureCluster: map[string]interface{}{"location": "eu", "resourceGroup": "rg"},
+		},
+		{
+			name: "Patch the ControlPlane with a variable and a builtin variable, preserving computed fields",
+			variables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "proxy",
+					Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]clusterv1.JSONSchemaProps{
+							"httpProxy": {Type: "string"},
+						},
+					}},
+				},
+			},
+			values: []clusterv1.ClusterVariable{
+				{Name: "proxy", Value: apiextensionsv1.JSON{Raw: []byte(`{"httpProxy":"http://proxy"}`)}},
+			},
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion:     controlPlaneTemplate.GetAPIVersion(),
+								Kind:           controlPlaneTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{ControlPlane: true},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/kubeadmConfigSpec/proxy",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "proxy.httpProxy"},
+								},
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/clusterName",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "builtin.cluster.name"},
+								},
+								{
+									Op:    "replace",
+									Path:  "/spec/template/spec/replicas",
+									Value: &apiextensionsv1.JSON{Raw: []byte(`5`)},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantControlPlane: map[string]interface{}{
+				"kubeadmConfigSpec": map[string]interface{}{"proxy": "http://proxy"},
+				"clusterName":       "cluster1",
+				"replicas":          int64(3),
+				"version":           "v1.21.2",
+			},
+		},
+		{
+			name: "Skip patches using optional variables which are not set",
+			variables: []clusterv1.ClusterClassVariable{
+				{
+					Name:   "optional",
+					Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{Type: "string"}},
+				},
+			},
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion:     infrastructureClusterTemplate.GetAPIVersion(),
+								Kind:           infrastructureClusterTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{InfrastructureCluster: true},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/resourceGroup",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "optional"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Patch the MachineDeployment templates matching the MachineDeployment class",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: bootstrapTemplate.GetAPIVersion(),
+								Kind:       bootstrapTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{Names: []string{"linux-worker"}},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/nodeName",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "builtin.machineDeployment.topologyName"},
+								},
+							},
+						},
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: bootstrapTemplate.GetAPIVersion(),
+								Kind:       bootstrapTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{Names: []string{"another-class"}},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:    "add",
+									Path:  "/spec/template/spec/notMatching",
+									Value: &apiextensionsv1.JSON{Raw: []byte(`true`)},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantBootstrapTemplate: map[string]interface{}{"nodeName": "md1"},
+		},
+		{
+			name: "Patch the MachineDeployment templates using variable overrides",
+			variables: []clusterv1.ClusterClassVariable{
+				{
+					Name:   "instanceType",
+					Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{Type: "string"}},
+				},
+			},
+			values: []clusterv1.ClusterVariable{
+				{Name: "instanceType", Value: apiextensionsv1.JSON{Raw: []byte(`"small"`)}},
+			},
+			overrides: []clusterv1.ClusterVariable{
+				{Name: "instanceType", Value: apiextensionsv1.JSON{Raw: []byte(`"large"`)}},
+			},
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion:     infrastructureClusterTemplate.GetAPIVersion(),
+								Kind:           infrastructureClusterTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{InfrastructureCluster: true},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/instanceType",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "instanceType"},
+								},
+							},
+						},
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: bootstrapTemplate.GetAPIVersion(),
+								Kind:       bootstrapTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{Names: []string{"linux-worker"}},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/instanceType",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "instanceType"},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantInfrastructureCluster: map[string]interface{}{"location": "eu", "instanceType": "small"},
+			wantBootstrapTemplate:     map[string]interface{}{"instanceType": "large"},
+		},
+		{
+			name: "Fails for builtin variables which are not available",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion:     infrastructureClusterTemplate.GetAPIVersion(),
+								Kind:           infrastructureClusterTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{InfrastructureCluster: true},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/name",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "builtin.machineDeployment.topologyName"},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails when removing a field which does not exist",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion:     infrastructureClusterTemplate.GetAPIVersion(),
+								Kind:           infrastructureClusterTemplate.GetKind(),
+								MatchResources: clusterv1.PatchSelectorMatch{InfrastructureCluster: true},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "remove",
+									Path: "/spec/template/spec/doesNotExist",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			blueprint := &scope.ClusterBlueprint{
+				Topology: &clusterv1.Topology{
+					Class:     "class1",
+					Version:   "v1.21.2",
+					Variables: tt.values,
+					Workers: &clusterv1.WorkersTopology{
+						MachineDeployments: []clusterv1.MachineDeploymentTopology{
+							{
+								Class:     "linux-worker",
+								Name:      "md1",
+								Variables: &clusterv1.MachineDeploymentVariables{Overrides: tt.overrides},
+							},
+						},
+					},
+				},
+				ClusterClass: &clusterv1.ClusterClass{
+					Spec: clusterv1.ClusterClassSpec{
+						Variables: tt.variables,
+						Patches:   tt.patches,
+					},
+				},
+				InfrastructureClusterTemplate: infrastructureClusterTemplate,
+				ControlPlane: &scope.ControlPlaneBlueprint{
+					Template: controlPlaneTemplate,
+				},
+			}
+
+			desired := &scope.ClusterState{
+				Cluster: builder.Cluster(metav1.NamespaceDefault, "cluster1").Build(),
+				InfrastructureCluster: builder.InfrastructureCluster(metav1.NamespaceDefault, "infra-cluster").
+					WithSpecFields(map[string]interface{}{"spec.location": "eu"}).
+					Build(),
+				ControlPlane: &scope.ControlPlaneState{
+					Object: builder.ControlPlane(metav1.NamespaceDefault, "control-plane").
+						WithSpecFields(map[string]interface{}{
+							"spec.replicas": int64(3),
+							"spec.version":  "v1.21.2",
+						}).
+						Build(),
+				},
+				MachineDeployments: scope.MachineDeploymentsStateMap{
+					"md1": {
+						Object: builder.MachineDeployment(metav1.NamespaceDefault, "md1").
+							WithLabels(map[string]string{clusterv1.ClusterTopologyMachineDeploymentLabelName: "md1"}).
+							Build(),
+						BootstrapTemplate:             bootstrapTemplate.DeepCopy(),
+						InfrastructureMachineTemplate: infrastructureMachineTemplate.DeepCopy(),
+					},
+				},
+			}
+			original := &scope.ClusterState{
+				InfrastructureCluster: desired.InfrastructureCluster.DeepCopy(),
+				ControlPlane:          &scope.ControlPlaneState{Object: desired.ControlPlane.Object.DeepCopy()},
+			}
+
+			err := Apply(context.Background(), blueprint, desired)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			expectSpec(g, desired.InfrastructureCluster, original.InfrastructureCluster, tt.wantInfrastructureCluster, "spec")
+			expectSpec(g, desired.ControlPlane.Object, original.ControlPlane.Object, tt.wantControlPlane, "spec")
+			expectSpec(g, desired.MachineDeployments["md1"].BootstrapTemplate, bootstrapTemplate, tt.wantBootstrapTemplate, "spec", "template", "spec")
+			g.Expect(desired.MachineDeployments["md1"].InfrastructureMachineTemplate).To(Equal(infrastructureMachineTemplate))
+		})
+	}
+}
+
+func TestEnsureParent(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"list": []interface{}{},
+		},
+	}
+	ensureParent(obj, "/spec/template/spec/files~1name/value")
+	ensureParent(obj, "/spec/list/-")
+
+	g.Expect(obj).To(Equal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"list": []interface{}{},
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"files/name": map[string]interface{}{},
+				},
+			},
+		},
+	}))
+}
+
+// expectSpec checks the spec of an object is equal to the expected one, or unchanged if nothing is expected.
+func expectSpec(g *WithT, obj, original *unstructured.Unstructured, want map[string]interface{}, fields ...string) {
+	got, _, err := unstructured.NestedMap(obj.UnstructuredContent(), fields...)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	if want == nil {
+		want, _, err = unstructured.NestedMap(original.UnstructuredContent(), fields...)
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+	g.Expect(got).To(Equal(want))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Variables holds the values of the variables which can be used in patches, indexed by name.
+// NOTE: builtin variables are stored as a nested object under the "builtin" key.
+type Variables map[string]interface{}
+
+// Get returns the value of a variable; nested fields of an object variable can be
+// accessed via dots, e.g. `proxy.httpProxy`.
+// It returns false if the variable (or one of the nested fields) is not set.
+func (v Variables) Get(name string) (interface{}, bool) {
+	segments := strings.Split(name, ".")
+
+	var value interface{} = map[string]interface{}(v)
+	for _, segment := range segments {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[segment]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// DeepCopy returns a copy of the Variables.
+func (v Variables) DeepCopy() Variables {
+	out := Variables{}
+	for name, value := range v {
+		out[name] = deepCopyValue(value)
+	}
+	return out
+}
+
+// setBuiltin sets a builtin variable at the given path under the "builtin" key, creating intermediate objects if required.
+func (v Variables) setBuiltin(value interface{}, path ...string) {
+	m, ok := v[clusterv1.BuiltinVariablePrefix].(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+		v[clusterv1.BuiltinVariablePrefix] = m
+	}
+	for _, segment := range path[:len(path)-1] {
+		next, ok := m[segment].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[segment] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// setValues sets the variables from a list of Cluster variables, overriding existing values.
+func (v Variables) setValues(values []clusterv1.ClusterVariable) error {
+	for _, variable := range values {
+		var value interface{}
+		if err := json.Unmarshal(variable.Value.Raw, &value); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal value of variable %q", variable.Name)
+		}
+		v[variable.Name] = value
+	}
+	return nil
+}
+
+// clusterVariables computes the variables defined at Cluster level, using default values from the ClusterClass
+// for the variables not set in the Cluster topology; it also adds builtin variables for the Cluster.
+func clusterVariables(clusterClass *clusterv1.ClusterClass, cluster *clusterv1.Cluster, topology *clusterv1.Topology) (Variables, error) {
+	variables := Variables{}
+
+	// Set default values for variables defined in the ClusterClass.
+	// NOTE: The Cluster defaulting webhook adds variables with a default value to the Cluster topology,
+	// but this is not possible if the ClusterClass is created after the Cluster.
+	for _, definition := range clusterClass.Spec.Variables {
+		if definition.Schema.OpenAPIV3Schema.Default == nil {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(definition.Schema.OpenAPIV3Schema.Default.Raw, &value); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal default value of variable %q", definition.Name)
+		}
+		variables[definition.Name] = value
+	}
+
+	if err := variables.setValues(topology.Variables); err != nil {
+		return nil, err
+	}
+
+	// Ensure all the required variables are set.
+	for _, definition := range clusterClass.Spec.Variables {
+		if _, ok := variables[definition.Name]; !ok && definition.Required {
+			return nil, errors.Errorf("required variable %q is not set", definition.Name)
+		}
+	}
+
+	variables.setBuiltin(cluster.Name, "cluster", "name")
+	variables.setBuiltin(cluster.Namespace, "cluster", "namespace")
+	variables.setBuiltin(topology.Class, "cluster", "topology", "class")
+	variables.setBuiltin(topology.Version, "cluster", "topology", "version")
+
+	return variables, nil
+}
+
+// deepCopyValue returns a deep copy of a value obtained by unmarshalling JSON.
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = deepCopyValue(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = deepCopyValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
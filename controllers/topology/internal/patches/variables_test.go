@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestClusterVariables(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		definitions []clusterv1.ClusterClassVariable
+		values      []clusterv1.ClusterVariable
+		want        Variables
+		wantErr     bool
+	}{
+		{
+			name: "Only builtin variables",
+			want: Variables{
+				"builtin": map[string]interface{}{
+					"cluster": map[string]interface{}{
+						"name":      "cluster1",
+						"namespace": metav1.NamespaceDefault,
+						"topology": map[string]interface{}{
+							"class":   "class1",
+							"version": "v1.21.2",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Values from the Cluster override defaults from the ClusterClass",
+			definitions: []clusterv1.ClusterClassVariable{
+				{
+					Name: "location",
+					Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:    "string",
+						Default: &apiextensionsv1.JSON{Raw: []byte(`"us"`)},
+					}},
+				},
+				{
+					Name: "replicas",
+					Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:    "integer",
+						Default: &apiextensionsv1.JSON{Raw: []byte(`3`)},
+					}},
+				},
+			},
+			values: []clusterv1.ClusterVariable{
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"eu"`)}},
+			},
+			want: Variables{
+				"location": "eu",
+				"replicas": float64(3),
+				"builtin": map[string]interface{}{
+					"cluster": map[string]interface{}{
+						"name":      "cluster1",
+						"namespace": metav1.NamespaceDefault,
+						"topology": map[string]interface{}{
+							"class":   "class1",
+							"version": "v1.21.2",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Fails if a required variable is not set",
+			definitions: []clusterv1.ClusterClassVariable{
+				{
+					Name:     "location",
+					Required: true,
+					Schema:   clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{Type: "string"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			clusterClass := &clusterv1.ClusterClass{Spec: clusterv1.ClusterClassSpec{Variables: tt.definitions}}
+			topology := &clusterv1.Topology{Class: "class1", Version: "v1.21.2", Variables: tt.values}
+
+			got, err := clusterVariables(clusterClass, cluster, topology)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestVariablesGet(t *testing.T) {
+	g := NewWithT(t)
+
+	variables := Variables{
+		"proxy": map[string]interface{}{
+			"httpProxy": "http://proxy",
+		},
+		"location": "eu",
+	}
+
+	value, ok := variables.Get("proxy.httpProxy")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(value).To(Equal("http://proxy"))
+
+	value, ok = variables.Get("location")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(value).To(Equal("eu"))
+
+	_, ok = variables.Get("proxy.httpsProxy")
+	g.Expect(ok).To(BeFalse())
+
+	_, ok = variables.Get("location.nested")
+	g.Expect(ok).To(BeFalse())
+}
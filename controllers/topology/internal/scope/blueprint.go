@@ -17,6 +17,8 @@ limitations under the License.
 package scope
 
 import (
+	"reflect"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -47,6 +49,9 @@ type ControlPlaneBlueprint struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure machine template for the control plane, if defined in the ClusterClass.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheckClass for the control plane, if defined in the ClusterClass.
+	MachineHealthCheck *clusterv1.MachineHealthCheckClass
 }
 
 // MachineDeploymentBlueprint holds the templates required for computing the desired state of a managed MachineDeployment;
@@ -62,6 +67,9 @@ type MachineDeploymentBlueprint struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure machine template for a MachineDeployment referenced from ClusterClass.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheckClass for a MachineDeployment, if defined in the ClusterClass.
+	MachineHealthCheck *clusterv1.MachineHealthCheckClass
 }
 
 // HasControlPlaneInfrastructureMachine checks whether the clusterClass mandates the controlPlane has infrastructureMachines.
@@ -73,3 +81,38 @@ func (b *ClusterBlueprint) HasControlPlaneInfrastructureMachine() bool {
 func (b *ClusterBlueprint) HasMachineDeployments() bool {
 	return b.Topology.Workers != nil && len(b.Topology.Workers.MachineDeployments) > 0
 }
+
+// ControlPlaneMachineHealthCheckClass returns the MachineHealthCheckClass to be used for the control plane,
+// or nil if a MachineHealthCheck should not be created for the control plane.
+// NOTE: MachineHealthChecks are supported only for Machine based control planes.
+func (b *ClusterBlueprint) ControlPlaneMachineHealthCheckClass() *clusterv1.MachineHealthCheckClass {
+	if !b.HasControlPlaneInfrastructureMachine() {
+		return nil
+	}
+	return machineHealthCheckClass(b.ControlPlane.MachineHealthCheck, b.Topology.ControlPlane.MachineHealthCheck)
+}
+
+// MachineDeploymentMachineHealthCheckClass returns the MachineHealthCheckClass to be used for a MachineDeployment,
+// or nil if a MachineHealthCheck should not be created for the MachineDeployment.
+func (b *ClusterBlueprint) MachineDeploymentMachineHealthCheckClass(mdTopology *clusterv1.MachineDeploymentTopology) *clusterv1.MachineHealthCheckClass {
+	var class *clusterv1.MachineHealthCheckClass
+	if mdBlueprint, ok := b.MachineDeployments[mdTopology.Class]; ok {
+		class = mdBlueprint.MachineHealthCheck
+	}
+	return machineHealthCheckClass(class, mdTopology.MachineHealthCheck)
+}
+
+// machineHealthCheckClass returns the MachineHealthCheckClass defined in the topology, if any, otherwise the one
+// defined in the ClusterClass; nil is returned if MachineHealthChecks are disabled in the topology.
+func machineHealthCheckClass(class *clusterv1.MachineHealthCheckClass, topology *clusterv1.MachineHealthCheckTopology) *clusterv1.MachineHealthCheckClass {
+	if topology == nil {
+		return class
+	}
+	if topology.Enable != nil && !*topology.Enable {
+		return nil
+	}
+	if !reflect.DeepEqual(topology.MachineHealthCheckClass, clusterv1.MachineHealthCheckClass{}) {
+		return &topology.MachineHealthCheckClass
+	}
+	return class
+}
@@ -46,6 +46,9 @@ type ControlPlaneState struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure template referenced by the ControlPlane object.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheck for the control plane Machines, if any.
+	MachineHealthCheck *clusterv1.MachineHealthCheck
 }
 
 // MachineDeploymentsStateMap holds a collection of MachineDeployment states.
@@ -62,6 +65,17 @@ func (mds MachineDeploymentsStateMap) IsAnyRollingOut() bool {
 	return false
 }
 
+// RollingOut returns the list of the names of the machine deployments which are rolling out.
+func (mds MachineDeploymentsStateMap) RollingOut() []string {
+	names := []string{}
+	for _, md := range mds {
+		if md.IsRollingOut() {
+			names = append(names, md.Object.Name)
+		}
+	}
+	return names
+}
+
 // MachineDeploymentState holds all the objects representing the state of a managed deployment.
 type MachineDeploymentState struct {
 	// Object holds the MachineDeployment object.
@@ -72,6 +86,9 @@ type MachineDeploymentState struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure machine template referenced by the MachineDeployment object.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheck for the MachineDeployment Machines, if any.
+	MachineHealthCheck *clusterv1.MachineHealthCheck
 }
 
 // IsRollingOut determines if the machine deployment is upgrading.
@@ -18,7 +18,7 @@ package scope
 
 import "k8s.io/apimachinery/pkg/util/sets"
 
-const maxMachineDeploymentUpgradeConcurrency = 1
+const defaultMachineDeploymentUpgradeConcurrency = 1
 
 // UpgradeTracker is a helper to capture the upgrade status and make upgrade decisions.
 type UpgradeTracker struct {
@@ -28,25 +28,77 @@ type UpgradeTracker struct {
 // MachineDeploymentUpgradeTracker holds the current upgrade status and makes upgrade
 // decisions for MachineDeployments.
 type MachineDeploymentUpgradeTracker struct {
+	// names is the set of MachineDeployments picking up the new version in the current reconcile.
 	names sets.String
+
+	// maxConcurrency is the maximum number of MachineDeployments which can be rolling out at the same time.
+	maxConcurrency int
+
+	// minUpgradeOrder is the lowest upgrade order of the MachineDeployments which did not complete the upgrade yet;
+	// nil if all the MachineDeployments completed the upgrade.
+	minUpgradeOrder *int32
+
+	// pendingRebase is the set of MachineDeployments still using the templates of the previous ClusterClass
+	// while the Cluster is being rebased to a different ClusterClass.
+	pendingRebase sets.String
 }
 
 // NewUpgradeTracker returns an upgrade tracker with empty tracking information.
 func NewUpgradeTracker() *UpgradeTracker {
 	return &UpgradeTracker{
 		MachineDeployments: MachineDeploymentUpgradeTracker{
-			names: sets.NewString(),
+			names:          sets.NewString(),
+			maxConcurrency: defaultMachineDeploymentUpgradeConcurrency,
+			pendingRebase:  sets.NewString(),
 		},
 	}
 }
 
+// SetMaxConcurrency sets the maximum number of MachineDeployments which can be rolling out at the same time.
+func (m *MachineDeploymentUpgradeTracker) SetMaxConcurrency(maxConcurrency int) {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	m.maxConcurrency = maxConcurrency
+}
+
+// MarkNotUpgraded records that a MachineDeployment with the given upgrade order did not complete the upgrade yet,
+// thus preventing MachineDeployments with an higher upgrade order from being upgraded.
+func (m *MachineDeploymentUpgradeTracker) MarkNotUpgraded(upgradeOrder int32) {
+	if m.minUpgradeOrder == nil || upgradeOrder < *m.minUpgradeOrder {
+		m.minUpgradeOrder = &upgradeOrder
+	}
+}
+
 // Insert adds name to the set of MachineDeployments that will be upgraded.
 func (m *MachineDeploymentUpgradeTracker) Insert(name string) {
 	m.names.Insert(name)
 }
 
-// AllowUpgrade returns true if a MachineDeployment is allowed to upgrade,
-// returns false otherwise.
-func (m *MachineDeploymentUpgradeTracker) AllowUpgrade() bool {
-	return m.names.Len() < maxMachineDeploymentUpgradeConcurrency
+// Has returns true if name is in the set of MachineDeployments that will be upgraded.
+func (m *MachineDeploymentUpgradeTracker) Has(name string) bool {
+	return m.names.Has(name)
+}
+
+// AllowUpgrade returns true if a MachineDeployment is allowed to upgrade given the number
+// of MachineDeployments already rolling out, returns false otherwise.
+func (m *MachineDeploymentUpgradeTracker) AllowUpgrade(rollingOut int) bool {
+	return m.names.Len()+rollingOut < m.maxConcurrency
+}
+
+// AllowUpgradeOrder returns true if a MachineDeployment with the given upgrade order is allowed to upgrade,
+// i.e. all the MachineDeployments with a lower upgrade order completed the upgrade; returns false otherwise.
+func (m *MachineDeploymentUpgradeTracker) AllowUpgradeOrder(upgradeOrder int32) bool {
+	return m.minUpgradeOrder == nil || upgradeOrder <= *m.minUpgradeOrder
+}
+
+// MarkPendingRebase records that a MachineDeployment is still using the templates of the previous ClusterClass
+// while the Cluster is being rebased to a different ClusterClass.
+func (m *MachineDeploymentUpgradeTracker) MarkPendingRebase(name string) {
+	m.pendingRebase.Insert(name)
+}
+
+// IsRebasePending returns true if any MachineDeployment is still using the templates of the previous ClusterClass.
+func (m *MachineDeploymentUpgradeTracker) IsRebasePending() bool {
+	return m.pendingRebase.Len() > 0
 }
@@ -0,0 +1,352 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/mergepatch"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// PlanChangeType defines the type of change the topology controller would apply to an object.
+type PlanChangeType string
+
+const (
+	// PlanCreate is used for objects which would be created.
+	PlanCreate PlanChangeType = "Create"
+
+	// PlanUpdate is used for objects which would be patched.
+	PlanUpdate PlanChangeType = "Update"
+
+	// PlanDelete is used for objects which would be deleted.
+	PlanDelete PlanChangeType = "Delete"
+
+	// PlanRotate is used for templates which would be replaced by a new template, according to
+	// Cluster API operational practices.
+	PlanRotate PlanChangeType = "Rotate"
+)
+
+// PlanChange describes a change the topology controller would apply to an object.
+type PlanChange struct {
+	// Type is the type of the change.
+	Type PlanChangeType
+
+	// Object is a reference to the object being changed; in case of template rotation,
+	// it refers to the new template.
+	Object corev1.ObjectReference
+
+	// RotatedFrom is the name of the template being replaced in case of template rotation.
+	RotatedFrom string
+
+	// Subresource is the subresource being changed, if any (e.g. status).
+	Subresource string
+
+	// Diff contains the JSON merge patch which would be applied to the object in case of update
+	// or template rotation, or the entire object in case of create.
+	Diff []byte
+
+	// RollOut is true if the change would trigger a rollout of the Machines of a MachineDeployment.
+	RollOut bool
+}
+
+// Plan describes the changes the topology controller would apply to a managed topology.
+type Plan struct {
+	// Cluster is the Cluster the plan has been computed for.
+	Cluster corev1.ObjectReference
+
+	// Changes is the ordered list of changes the topology controller would apply.
+	Changes []PlanChange
+}
+
+// HasChanges returns true if the topology controller would apply any change.
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// ComputePlan computes the changes the topology controller would apply to the managed topology of a Cluster,
+// without applying them.
+// The ClusterClass, the templates and the current state of the Cluster are read from the given reader, and then
+// the same logic used when reconciling is executed, recording all the write operations instead of executing them.
+// NOTE: The Cluster and the objects served by the reader can be modified versions of the objects in the management
+// cluster, thus allowing to preview the effects of a change before applying it.
+func ComputePlan(ctx context.Context, c client.Reader, scheme *runtime.Scheme, cluster *clusterv1.Cluster) (*Plan, error) {
+	if cluster.Spec.Topology == nil {
+		return nil, errors.Errorf("Cluster %s/%s does not use a managed topology", cluster.Namespace, cluster.Name)
+	}
+
+	dryRunClient := &dryRunClient{Reader: c, scheme: scheme}
+	r := &ClusterReconciler{
+		Client:                    dryRunClient,
+		APIReader:                 dryRunClient,
+		UnstructuredCachingClient: dryRunClient,
+	}
+
+	var err error
+	s := scope.New(cluster.DeepCopy())
+
+	s.Blueprint, err = r.getBlueprint(ctx, s.Current.Cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading the ClusterClass")
+	}
+
+	if err := r.checkClusterClassRebase(ctx, s); err != nil {
+		return nil, err
+	}
+
+	s.Current, err = r.getCurrentState(ctx, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading current state of the Cluster topology")
+	}
+
+	s.Desired, err = r.computeDesiredState(ctx, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error computing the desired state of the Cluster topology")
+	}
+
+	if err := r.reconcileState(ctx, s); err != nil {
+		return nil, errors.Wrap(err, "error reconciling the Cluster topology")
+	}
+
+	rotations, err := computeTemplateRotations(s)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Cluster: corev1.ObjectReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+			Namespace:  cluster.Namespace,
+			Name:       cluster.Name,
+		},
+	}
+	for _, op := range dryRunClient.operations {
+		change := PlanChange{
+			Type:        op.changeType,
+			Object:      op.ref,
+			Subresource: op.subresource,
+			Diff:        op.data,
+		}
+
+		switch op.changeType {
+		case PlanCreate:
+			// Templates created when reconciling an existing object are template rotations.
+			if rotation, ok := rotations[op.ref]; ok {
+				change.Type = PlanRotate
+				change.RotatedFrom = rotation.from
+				change.Diff = rotation.diff
+			}
+		case PlanUpdate:
+			// Changes to the Machine template of a MachineDeployment trigger a rollout.
+			if op.ref.Kind == "MachineDeployment" && op.subresource == "" {
+				change.RollOut, err = patchChangesPath(op.data, "spec", "template")
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	return plan, nil
+}
+
+type templateRotation struct {
+	from string
+	diff []byte
+}
+
+// computeTemplateRotations returns the templates which would be rotated, keyed by the reference to the new template.
+// NOTE: This func relies on reconcileReferencedTemplate assigning a new name to the desired template in case of rotation.
+func computeTemplateRotations(s *scope.Scope) (map[corev1.ObjectReference]templateRotation, error) {
+	rotations := map[corev1.ObjectReference]templateRotation{}
+
+	addRotation := func(current, desired *unstructured.Unstructured) error {
+		if current == nil || desired == nil || current.GetName() == desired.GetName() {
+			return nil
+		}
+		patchHelper, err := mergepatch.NewHelper(current, desired, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compute changes for %s/%s", current.GetKind(), current.GetName())
+		}
+		rotations[corev1.ObjectReference{
+			APIVersion: desired.GetAPIVersion(),
+			Kind:       desired.GetKind(),
+			Namespace:  desired.GetNamespace(),
+			Name:       desired.GetName(),
+		}] = templateRotation{from: current.GetName(), diff: patchHelper.Changes()}
+		return nil
+	}
+
+	if s.Current.ControlPlane != nil && s.Desired.ControlPlane != nil {
+		if err := addRotation(s.Current.ControlPlane.InfrastructureMachineTemplate, s.Desired.ControlPlane.InfrastructureMachineTemplate); err != nil {
+			return nil, err
+		}
+	}
+	for name, desiredMD := range s.Desired.MachineDeployments {
+		currentMD, ok := s.Current.MachineDeployments[name]
+		if !ok {
+			continue
+		}
+		if err := addRotation(currentMD.InfrastructureMachineTemplate, desiredMD.InfrastructureMachineTemplate); err != nil {
+			return nil, err
+		}
+		if err := addRotation(currentMD.BootstrapTemplate, desiredMD.BootstrapTemplate); err != nil {
+			return nil, err
+		}
+	}
+	return rotations, nil
+}
+
+// patchChangesPath returns true if the given JSON merge patch changes the given path.
+func patchChangesPath(patch []byte, path ...string) (bool, error) {
+	patchMap := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &patchMap); err != nil {
+		return false, errors.Wrap(err, "failed to unmarshal merge patch")
+	}
+	_, found, err := unstructured.NestedFieldNoCopy(patchMap, path...)
+	if err != nil {
+		// If an intermediate field is not a map, e.g. it is set to null, the path is changed.
+		return true, nil //nolint:nilerr
+	}
+	return found, nil
+}
+
+// dryRunOperation is a write operation recorded by the dryRunClient.
+type dryRunOperation struct {
+	changeType  PlanChangeType
+	ref         corev1.ObjectReference
+	subresource string
+	data        []byte
+}
+
+// dryRunClient is a client.Client reading objects from a client.Reader and recording
+// write operations instead of executing them.
+type dryRunClient struct {
+	client.Reader
+	scheme     *runtime.Scheme
+	operations []dryRunOperation
+}
+
+var _ client.Client = &dryRunClient{}
+
+// Create records the creation of an object.
+func (c *dryRunClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal object to json")
+	}
+	return c.record(PlanCreate, obj, "", data)
+}
+
+// Update records the update of an object.
+func (c *dryRunClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal object to json")
+	}
+	return c.record(PlanUpdate, obj, "", data)
+}
+
+// Patch records the patch of an object; empty patches are ignored.
+func (c *dryRunClient) Patch(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+	return c.recordPatch(obj, "", patch)
+}
+
+// Delete records the deletion of an object.
+func (c *dryRunClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	return c.record(PlanDelete, obj, "", nil)
+}
+
+// DeleteAllOf is not supported by the dryRunClient.
+func (c *dryRunClient) DeleteAllOf(_ context.Context, obj client.Object, _ ...client.DeleteAllOfOption) error {
+	return errors.Errorf("DeleteAllOf is not supported when computing a plan (%T)", obj)
+}
+
+// Status returns a client.StatusWriter recording write operations on the status subresource.
+func (c *dryRunClient) Status() client.StatusWriter {
+	return &dryRunStatusWriter{client: c}
+}
+
+// Scheme returns the scheme the dryRunClient is using.
+func (c *dryRunClient) Scheme() *runtime.Scheme {
+	return c.scheme
+}
+
+// RESTMapper returns nil, because the dryRunClient does not require a RESTMapper.
+func (c *dryRunClient) RESTMapper() meta.RESTMapper {
+	return nil
+}
+
+func (c *dryRunClient) recordPatch(obj client.Object, subresource string, patch client.Patch) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute patch data")
+	}
+	if len(data) == 0 || bytes.Equal(data, []byte("{}")) {
+		return nil
+	}
+	return c.record(PlanUpdate, obj, subresource, data)
+}
+
+func (c *dryRunClient) record(changeType PlanChangeType, obj client.Object, subresource string, data []byte) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	c.operations = append(c.operations, dryRunOperation{
+		changeType: changeType,
+		ref: corev1.ObjectReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		},
+		subresource: subresource,
+		data:        data,
+	})
+	return nil
+}
+
+// dryRunStatusWriter is a client.StatusWriter recording write operations on the status subresource.
+type dryRunStatusWriter struct {
+	client *dryRunClient
+}
+
+// Update records the update of the status of an object.
+func (w *dryRunStatusWriter) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal object to json")
+	}
+	return w.client.record(PlanUpdate, obj, "status", data)
+}
+
+// Patch records the patch of the status of an object; empty patches are ignored.
+func (w *dryRunStatusWriter) Patch(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+	return w.client.recordPatch(obj, "status", patch)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestComputePlan(t *testing.T) {
+	g := NewWithT(t)
+
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infraclustertemplate1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "controlplanetemplate1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	workerInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "workerinframachinetemplate1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.size": "small"}).
+		Build()
+	workerBootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "workerbootstraptemplate1").
+		Build()
+	machineDeploymentClass := builder.MachineDeploymentClass(metav1.NamespaceDefault, "linux-worker").
+		WithClass("linux-worker").
+		WithInfrastructureTemplate(workerInfrastructureMachineTemplate).
+		WithBootstrapTemplate(workerBootstrapTemplate).
+		Build()
+	clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+		WithControlPlaneTemplate(controlPlaneTemplate).
+		WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{*machineDeploymentClass}).
+		Build()
+
+	cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").
+		WithClusterClass(*clusterClass).
+		Build()
+	cluster.Spec.Topology.Version = "v1.21.2"
+	cluster.Spec.Topology.Workers = &clusterv1.WorkersTopology{
+		MachineDeployments: []clusterv1.MachineDeploymentTopology{
+			{Class: "linux-worker", Name: "md1", Replicas: pointer.Int32(1)},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(fakeScheme).
+		WithObjects(
+			builder.GenericInfrastructureClusterTemplateCRD,
+			builder.GenericInfrastructureClusterCRD,
+			builder.GenericInfrastructureMachineCRD,
+			builder.GenericControlPlaneTemplateCRD,
+			builder.GenericControlPlaneCRD,
+			builder.GenericBootstrapConfigTemplateCRD,
+			infrastructureClusterTemplate,
+			controlPlaneTemplate,
+			workerInfrastructureMachineTemplate,
+			workerBootstrapTemplate,
+			clusterClass,
+			cluster,
+		).
+		Build()
+
+	// The plan for a new Cluster creates all the objects of the managed topology.
+	plan, err := ComputePlan(ctx, fakeClient, fakeScheme, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(planChangesOfType(plan, PlanCreate)).To(ConsistOf(
+		builder.GenericInfrastructureClusterKind,
+		builder.GenericControlPlaneKind,
+		builder.GenericInfrastructureMachineKind,
+		builder.GenericBootstrapConfigTemplateKind,
+		"MachineDeployment",
+	))
+	g.Expect(planChangesOfType(plan, PlanUpdate)).To(ContainElement("Cluster"))
+	g.Expect(planChangesOfType(plan, PlanRotate)).To(BeEmpty())
+
+	// Computing the plan does not change the objects in the cluster.
+	mds := &clusterv1.MachineDeploymentList{}
+	g.Expect(fakeClient.List(ctx, mds)).To(Succeed())
+	g.Expect(mds.Items).To(BeEmpty())
+
+	// Reconcile the topology, and verify the plan does not report any change afterwards.
+	r := &ClusterReconciler{
+		Client:                    fakeClient,
+		APIReader:                 fakeClient,
+		UnstructuredCachingClient: fakeClient,
+	}
+	// NOTE: Reconcile twice, because the status of the MachineDeployments is reported only once they exist.
+	for i := 0; i < 2; i++ {
+		g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+		_, err = r.reconcile(ctx, scope.New(cluster))
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+	plan, err = ComputePlan(ctx, fakeClient, fakeScheme, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plan.HasChanges()).To(BeFalse())
+
+	// Change the InfrastructureMachineTemplate of the MachineDeploymentClass, and verify the plan reports the
+	// template rotation and the rollout of the MachineDeployment.
+	workerInfrastructureMachineTemplateWithChanges := &unstructured.Unstructured{}
+	workerInfrastructureMachineTemplateWithChanges.SetGroupVersionKind(workerInfrastructureMachineTemplate.GroupVersionKind())
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(workerInfrastructureMachineTemplate), workerInfrastructureMachineTemplateWithChanges)).To(Succeed())
+	g.Expect(unstructured.SetNestedField(workerInfrastructureMachineTemplateWithChanges.Object, "large", "spec", "template", "spec", "size")).To(Succeed())
+	g.Expect(fakeClient.Update(ctx, workerInfrastructureMachineTemplateWithChanges)).To(Succeed())
+
+	plan, err = ComputePlan(ctx, fakeClient, fakeScheme, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(planChangesOfType(plan, PlanCreate)).To(BeEmpty())
+	g.Expect(planChangesOfType(plan, PlanRotate)).To(ConsistOf(builder.GenericInfrastructureMachineKind))
+	for _, change := range plan.Changes {
+		switch change.Type {
+		case PlanRotate:
+			g.Expect(change.RotatedFrom).ToNot(BeEmpty())
+			g.Expect(string(change.Diff)).To(Equal(`{"spec":{"template":{"spec":{"size":"large"}}}}`))
+		case PlanUpdate:
+			g.Expect(change.Object.Kind).To(Equal("MachineDeployment"))
+			g.Expect(change.RollOut).To(BeTrue())
+		}
+	}
+}
+
+func planChangesOfType(plan *Plan, changeType PlanChangeType) []string {
+	kinds := []string{}
+	for _, change := range plan.Changes {
+		if change.Type == changeType {
+			kinds = append(kinds, change.Object.Kind)
+		}
+	}
+	return kinds
+}
+
+func TestComputePlanWithMachineHealthChecks(t *testing.T) {
+	g := NewWithT(t)
+
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infraclustertemplate1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	controlPlaneInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "cpinframachinetemplate1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.size": "small"}).
+		Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "controlplanetemplate1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+		WithControlPlaneTemplate(controlPlaneTemplate).
+		WithControlPlaneInfrastructureMachineTemplate(controlPlaneInfrastructureMachineTemplate).
+		Build()
+	clusterClass.Spec.ControlPlane.MachineHealthCheck = &clusterv1.MachineHealthCheckClass{
+		UnhealthyConditions: []clusterv1.UnhealthyCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+		},
+	}
+
+	cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").
+		WithClusterClass(*clusterClass).
+		Build()
+	cluster.Spec.Topology.Version = "v1.21.2"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(fakeScheme).
+		WithObjects(
+			builder.GenericInfrastructureClusterTemplateCRD,
+			builder.GenericInfrastructureClusterCRD,
+			builder.GenericInfrastructureMachineCRD,
+			builder.GenericControlPlaneTemplateCRD,
+			builder.GenericControlPlaneCRD,
+			infrastructureClusterTemplate,
+			controlPlaneInfrastructureMachineTemplate,
+			controlPlaneTemplate,
+			clusterClass,
+			cluster,
+		).
+		Build()
+
+	// The plan for a new Cluster with a MachineHealthCheck for the control plane does not fail, even if the control plane
+	// is not really created; the MachineHealthCheck is created only once the control plane exists.
+	plan, err := ComputePlan(ctx, fakeClient, fakeScheme, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(planChangesOfType(plan, PlanCreate)).To(ConsistOf(
+		builder.GenericInfrastructureClusterKind,
+		builder.GenericControlPlaneKind,
+		builder.GenericInfrastructureMachineKind,
+	))
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/check"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
+	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isRebasing returns true if the Cluster is being rebased to a different ClusterClass, i.e. the ClusterClass
+// defined in the topology is different from the ClusterClass reported in the topology status.
+// NOTE: The ClusterClass is reported in the topology status before creating any object of the managed topology,
+// see reconcileTopologyClass.
+func isRebasing(cluster *clusterv1.Cluster) bool {
+	return cluster.Status.Topology != nil &&
+		cluster.Status.Topology.Class != "" &&
+		cluster.Status.Topology.Class != cluster.Spec.Topology.Class
+}
+
+// checkClusterClassRebase checks if the ClusterClass the Cluster is being rebased from is compatible with
+// the ClusterClass defined in the topology.
+// NOTE: This check is already implemented in the Cluster webhook, but it is repeated here for extra safety.
+func (r *ClusterReconciler) checkClusterClassRebase(ctx context.Context, s *scope.Scope) error {
+	if !isRebasing(s.Current.Cluster) {
+		return nil
+	}
+
+	currentClass := &clusterv1.ClusterClass{}
+	key := client.ObjectKey{Name: s.Current.Cluster.Status.Topology.Class, Namespace: s.Current.Cluster.Namespace}
+	if err := r.Client.Get(ctx, key, currentClass); err != nil {
+		// If the ClusterClass the Cluster is being rebased from does not exist anymore, it is not possible to
+		// check compatibility; rely on the validation performed by the webhook when the class has been changed.
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve ClusterClass/%s", key.Name)
+	}
+
+	if err := check.ClusterClassesAreCompatible(currentClass, s.Blueprint.ClusterClass); err != nil {
+		return errors.Wrapf(err, "failed to rebase %s from %s to %s", tlog.KObj{Obj: s.Current.Cluster},
+			tlog.KObj{Obj: currentClass}, tlog.KObj{Obj: s.Blueprint.ClusterClass})
+	}
+	return nil
+}
+
+// computeMachineDeploymentsRebase ensures MachineDeployments pick up the templates of the ClusterClass the Cluster
+// is being rebased to in a controlled way, after the control plane has been rebased and it is stable, and respecting
+// the max concurrency defined for MachineDeployment upgrades. The MachineDeployments not allowed to rebase yet
+// keep using their current templates.
+// NOTE: This func must be called after patches are applied, so the templates of the MachineDeployments not
+// allowed to rebase yet are not changed by the patches of the new ClusterClass.
+func computeMachineDeploymentsRebase(s *scope.Scope, desiredState *scope.ClusterState) error {
+	if !isRebasing(s.Current.Cluster) || len(desiredState.MachineDeployments) == 0 {
+		return nil
+	}
+
+	cpStable, err := isControlPlaneStable(s, desiredState.ControlPlane)
+	if err != nil {
+		return err
+	}
+	cpRebased := isControlPlaneRebased(s)
+
+	for _, mdTopology := range s.Blueprint.Topology.Workers.MachineDeployments {
+		currentMD := s.Current.MachineDeployments[mdTopology.Name]
+		desiredMD := desiredState.MachineDeployments[mdTopology.Name]
+		if currentMD == nil || currentMD.Object == nil || desiredMD == nil ||
+			currentMD.BootstrapTemplate == nil || currentMD.InfrastructureMachineTemplate == nil {
+			continue
+		}
+
+		mdClass := s.Blueprint.MachineDeployments[mdTopology.Class]
+		if isTemplateClonedFrom(currentMD.BootstrapTemplate, contract.ObjToRef(mdClass.BootstrapTemplate)) &&
+			isTemplateClonedFrom(currentMD.InfrastructureMachineTemplate, contract.ObjToRef(mdClass.InfrastructureMachineTemplate)) {
+			continue
+		}
+
+		// The MachineDeployment can pick up the templates of the new ClusterClass if it is already rolling out
+		// because of a version upgrade, or if the control plane has been rebased and it is stable and
+		// the max concurrency for MachineDeployment upgrades has not been reached.
+		name := currentMD.Object.Name
+		if s.UpgradeTracker.MachineDeployments.Has(name) {
+			continue
+		}
+		if cpStable && cpRebased && s.UpgradeTracker.MachineDeployments.AllowUpgrade(len(s.Current.MachineDeployments.RollingOut())) {
+			s.UpgradeTracker.MachineDeployments.Insert(name)
+			continue
+		}
+
+		// Otherwise keep using the current templates.
+		desiredMD.BootstrapTemplate = currentMD.BootstrapTemplate.DeepCopy()
+		desiredMD.InfrastructureMachineTemplate = currentMD.InfrastructureMachineTemplate.DeepCopy()
+		desiredMD.Object.Spec.Template.Spec.Bootstrap.ConfigRef = currentMD.Object.Spec.Template.Spec.Bootstrap.ConfigRef.DeepCopy()
+		desiredMD.Object.Spec.Template.Spec.InfrastructureRef = currentMD.Object.Spec.Template.Spec.InfrastructureRef
+		s.UpgradeTracker.MachineDeployments.MarkPendingRebase(name)
+	}
+	return nil
+}
+
+// isControlPlaneRebased returns true if the current control plane has been generated from the ControlPlane template
+// of the ClusterClass the Cluster is being rebased to, as well as the InfrastructureMachineTemplate of the control plane,
+// if any.
+func isControlPlaneRebased(s *scope.Scope) bool {
+	if s.Current.ControlPlane == nil || s.Current.ControlPlane.Object == nil {
+		return false
+	}
+	if !isTemplateClonedFrom(s.Current.ControlPlane.Object, s.Blueprint.ClusterClass.Spec.ControlPlane.Ref) {
+		return false
+	}
+	if s.Blueprint.HasControlPlaneInfrastructureMachine() {
+		return isTemplateClonedFrom(s.Current.ControlPlane.InfrastructureMachineTemplate,
+			s.Blueprint.ClusterClass.Spec.ControlPlane.MachineInfrastructure.Ref)
+	}
+	return true
+}
+
+// isTemplateClonedFrom returns true if the template, or the object generated from a template, has been cloned from
+// the template with the given reference.
+func isTemplateClonedFrom(template *unstructured.Unstructured, ref *corev1.ObjectReference) bool {
+	if template == nil || ref == nil {
+		return false
+	}
+	annotations := template.GetAnnotations()
+	return annotations[clusterv1.TemplateClonedFromNameAnnotation] == ref.Name &&
+		annotations[clusterv1.TemplateClonedFromGroupKindAnnotation] == ref.GroupVersionKind().GroupKind().String()
+}
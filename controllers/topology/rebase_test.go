@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/internal/builder"
+)
+
+func TestComputeMachineDeploymentsRebase(t *testing.T) {
+	stableStatus := clusterv1.MachineDeploymentStatus{
+		ObservedGeneration: 2,
+		Replicas:           1,
+		UpdatedReplicas:    1,
+		AvailableReplicas:  1,
+		ReadyReplicas:      1,
+	}
+
+	// Templates of the ClusterClass the Cluster is being rebased to.
+	bootstrapTemplateV2 := builder.BootstrapTemplate(metav1.NamespaceDefault, "bootstrap-v2").Build()
+	infraMachineTemplateV2 := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "infra-v2").Build()
+	controlPlaneTemplateV2 := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp-v2").Build()
+	controlPlaneTemplateV1 := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp-v1").Build()
+
+	// clonedFrom returns a copy of the control plane annotated as cloned from the given template.
+	clonedFrom := func(controlPlane, template *unstructured.Unstructured) *unstructured.Unstructured {
+		controlPlane = controlPlane.DeepCopy()
+		controlPlane.SetAnnotations(map[string]string{
+			clusterv1.TemplateClonedFromNameAnnotation:      template.GetName(),
+			clusterv1.TemplateClonedFromGroupKindAnnotation: template.GroupVersionKind().GroupKind().String(),
+		})
+		return controlPlane
+	}
+
+	controlPlaneStable := builder.ControlPlane(metav1.NamespaceDefault, "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.21.2",
+			"spec.replicas": int64(1),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.21.2",
+			"status.replicas":        int64(1),
+			"status.updatedReplicas": int64(1),
+			"status.readyReplicas":   int64(1),
+		}).
+		Build()
+	controlPlaneUpgrading := builder.ControlPlane(metav1.NamespaceDefault, "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version": "v1.21.2",
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version": "v1.21.1",
+		}).
+		Build()
+	controlPlaneDesired := builder.ControlPlane(metav1.NamespaceDefault, "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version": "v1.21.2",
+		}).
+		Build()
+
+	// newMachineDeploymentState returns the current state of a MachineDeployment with templates cloned from the given templates.
+	newMachineDeploymentState := func(name string, bootstrapClonedFrom, infraClonedFrom *unstructured.Unstructured) *scope.MachineDeploymentState {
+		bootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, name+"-bootstrap").Build()
+		bootstrapTemplate.SetAnnotations(map[string]string{
+			clusterv1.TemplateClonedFromNameAnnotation:      bootstrapClonedFrom.GetName(),
+			clusterv1.TemplateClonedFromGroupKindAnnotation: bootstrapClonedFrom.GroupVersionKind().GroupKind().String(),
+		})
+		infraMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, name+"-infra").Build()
+		infraMachineTemplate.SetAnnotations(map[string]string{
+			clusterv1.TemplateClonedFromNameAnnotation:      infraClonedFrom.GetName(),
+			clusterv1.TemplateClonedFromGroupKindAnnotation: infraClonedFrom.GroupVersionKind().GroupKind().String(),
+		})
+		return &scope.MachineDeploymentState{
+			Object: builder.MachineDeployment(metav1.NamespaceDefault, name).
+				WithGeneration(1).WithReplicas(1).WithVersion("v1.21.2").WithStatus(stableStatus).
+				WithBootstrapTemplate(bootstrapTemplate).
+				WithInfrastructureTemplate(infraMachineTemplate).
+				Build(),
+			BootstrapTemplate:             bootstrapTemplate,
+			InfrastructureMachineTemplate: infraMachineTemplate,
+		}
+	}
+	bootstrapTemplateV1 := builder.BootstrapTemplate(metav1.NamespaceDefault, "bootstrap-v1").Build()
+	infraMachineTemplateV1 := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "infra-v1").Build()
+
+	tests := []struct {
+		name                string
+		currentControlPlane *unstructured.Unstructured
+		current             scope.MachineDeploymentsStateMap
+		wantRebasing        []string
+		wantPendingRebase   bool
+	}{
+		{
+			name:                "MachineDeployments keep the current templates while the control plane is not stable",
+			currentControlPlane: clonedFrom(controlPlaneUpgrading, controlPlaneTemplateV2),
+			current: scope.MachineDeploymentsStateMap{
+				"md1": newMachineDeploymentState("md1", bootstrapTemplateV1, infraMachineTemplateV1),
+				"md2": newMachineDeploymentState("md2", bootstrapTemplateV1, infraMachineTemplateV1),
+			},
+			wantRebasing:      []string{},
+			wantPendingRebase: true,
+		},
+		{
+			name:                "MachineDeployments keep the current templates while the control plane is not rebased",
+			currentControlPlane: clonedFrom(controlPlaneStable, controlPlaneTemplateV1),
+			current: scope.MachineDeploymentsStateMap{
+				"md1": newMachineDeploymentState("md1", bootstrapTemplateV1, infraMachineTemplateV1),
+				"md2": newMachineDeploymentState("md2", bootstrapTemplateV1, infraMachineTemplateV1),
+			},
+			wantRebasing:      []string{},
+			wantPendingRebase: true,
+		},
+		{
+			name:                "MachineDeployments pick up the new templates respecting the max concurrency",
+			currentControlPlane: clonedFrom(controlPlaneStable, controlPlaneTemplateV2),
+			current: scope.MachineDeploymentsStateMap{
+				"md1": newMachineDeploymentState("md1", bootstrapTemplateV1, infraMachineTemplateV1),
+				"md2": newMachineDeploymentState("md2", bootstrapTemplateV1, infraMachineTemplateV1),
+			},
+			wantRebasing:      []string{"md1"},
+			wantPendingRebase: true,
+		},
+		{
+			name:                "MachineDeployments already using the new templates are not changed",
+			currentControlPlane: clonedFrom(controlPlaneStable, controlPlaneTemplateV2),
+			current: scope.MachineDeploymentsStateMap{
+				"md1": newMachineDeploymentState("md1", bootstrapTemplateV2, infraMachineTemplateV2),
+				"md2": newMachineDeploymentState("md2", bootstrapTemplateV1, infraMachineTemplateV1),
+			},
+			wantRebasing:      []string{"md2"},
+			wantPendingRebase: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			cluster.Spec.Topology = &clusterv1.Topology{
+				Class:   "class-v2",
+				Version: "v1.21.2",
+				ControlPlane: clusterv1.ControlPlaneTopology{
+					Replicas: pointer.Int32(1),
+				},
+				Workers: &clusterv1.WorkersTopology{
+					MachineDeployments: []clusterv1.MachineDeploymentTopology{
+						{Class: "linux-worker", Name: "md1"},
+						{Class: "linux-worker", Name: "md2"},
+					},
+				},
+			}
+			cluster.Status.Topology = &clusterv1.TopologyStatus{Class: "class-v1"}
+
+			s := scope.New(cluster)
+			s.Blueprint = &scope.ClusterBlueprint{
+				Topology: cluster.Spec.Topology,
+				ClusterClass: builder.ClusterClass(metav1.NamespaceDefault, "class-v2").
+					WithControlPlaneTemplate(controlPlaneTemplateV2).
+					Build(),
+				MachineDeployments: map[string]*scope.MachineDeploymentBlueprint{
+					"linux-worker": {
+						BootstrapTemplate:             bootstrapTemplateV2,
+						InfrastructureMachineTemplate: infraMachineTemplateV2,
+					},
+				},
+			}
+			s.Current.ControlPlane = &scope.ControlPlaneState{Object: tt.currentControlPlane}
+			s.Current.MachineDeployments = tt.current
+
+			// The desired state uses new templates for all the MachineDeployments.
+			desiredState := &scope.ClusterState{
+				ControlPlane:       &scope.ControlPlaneState{Object: controlPlaneDesired},
+				MachineDeployments: scope.MachineDeploymentsStateMap{},
+			}
+			for name, currentMD := range tt.current {
+				desiredMD := &scope.MachineDeploymentState{
+					Object:                        currentMD.Object.DeepCopy(),
+					BootstrapTemplate:             builder.BootstrapTemplate(metav1.NamespaceDefault, name+"-bootstrap-new").Build(),
+					InfrastructureMachineTemplate: builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, name+"-infra-new").Build(),
+				}
+				desiredMD.Object.Spec.Template.Spec.Bootstrap.ConfigRef = contract.ObjToRef(desiredMD.BootstrapTemplate)
+				desiredMD.Object.Spec.Template.Spec.InfrastructureRef = *contract.ObjToRef(desiredMD.InfrastructureMachineTemplate)
+				desiredState.MachineDeployments[name] = desiredMD
+			}
+
+			g.Expect(computeMachineDeploymentsRebase(s, desiredState)).To(Succeed())
+
+			for name, desiredMD := range desiredState.MachineDeployments {
+				currentMD := tt.current[name]
+				isRebasing := false
+				for _, n := range tt.wantRebasing {
+					if n == name {
+						isRebasing = true
+					}
+				}
+				g.Expect(s.UpgradeTracker.MachineDeployments.Has(currentMD.Object.Name)).To(Equal(isRebasing))
+
+				// MachineDeployments already rebased or rebasing use the desired templates, while the
+				// other MachineDeployments keep using the current templates.
+				keepCurrent := !isRebasing && isTemplateClonedFrom(currentMD.BootstrapTemplate, contract.ObjToRef(bootstrapTemplateV1))
+				if keepCurrent {
+					g.Expect(desiredMD.BootstrapTemplate.GetName()).To(Equal(currentMD.BootstrapTemplate.GetName()))
+					g.Expect(desiredMD.InfrastructureMachineTemplate.GetName()).To(Equal(currentMD.InfrastructureMachineTemplate.GetName()))
+					g.Expect(desiredMD.Object.Spec.Template.Spec.Bootstrap.ConfigRef.Name).To(Equal(currentMD.BootstrapTemplate.GetName()))
+					g.Expect(desiredMD.Object.Spec.Template.Spec.InfrastructureRef.Name).To(Equal(currentMD.InfrastructureMachineTemplate.GetName()))
+				} else {
+					g.Expect(desiredMD.BootstrapTemplate.GetName()).To(Equal(name + "-bootstrap-new"))
+					g.Expect(desiredMD.Object.Spec.Template.Spec.InfrastructureRef.Name).To(Equal(name + "-infra-new"))
+				}
+			}
+			g.Expect(s.UpgradeTracker.MachineDeployments.IsRebasePending()).To(Equal(tt.wantPendingRebase))
+
+			// The ClusterClass the Cluster is being rebased from is reported until all the MachineDeployments are rebased.
+			wantClass := "class-v2"
+			if tt.wantPendingRebase {
+				wantClass = "class-v1"
+			}
+			g.Expect(computeTopologyStatus(s).Class).To(Equal(wantClass))
+		})
+	}
+}
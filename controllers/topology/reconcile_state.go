@@ -22,16 +22,21 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/storage/names"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/check"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
 	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/mergepatch"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
 // reconcileState reconciles the current and desired state of the managed Cluster topology.
@@ -42,6 +47,11 @@ func (r *ClusterReconciler) reconcileState(ctx context.Context, s *scope.Scope)
 	log := tlog.LoggerFrom(ctx)
 	log.Infof("Reconciling state for topology owned objects")
 
+	// Report the ClusterClass in the Cluster status before creating any object.
+	if err := r.reconcileTopologyClass(ctx, s); err != nil {
+		return err
+	}
+
 	// Reconcile desired state of the InfrastructureCluster object.
 	if err := r.reconcileInfrastructureCluster(ctx, s); err != nil {
 		return err
@@ -58,13 +68,19 @@ func (r *ClusterReconciler) reconcileState(ctx context.Context, s *scope.Scope)
 	}
 
 	// Reconcile desired state of the MachineDeployment objects.
-	return r.reconcileMachineDeployments(ctx, s)
+	if err := r.reconcileMachineDeployments(ctx, s); err != nil {
+		return err
+	}
+
+	// Report the status of the managed topology.
+	return r.reconcileTopologyStatus(ctx, s)
 }
 
 // reconcileInfrastructureCluster reconciles the desired state of the InfrastructureCluster object.
 func (r *ClusterReconciler) reconcileInfrastructureCluster(ctx context.Context, s *scope.Scope) error {
 	ctx, _ = tlog.LoggerFrom(ctx).WithObject(s.Desired.InfrastructureCluster).Into(ctx)
-	return r.reconcileReferencedObject(ctx, s.Current.InfrastructureCluster, s.Desired.InfrastructureCluster, mergepatch.IgnorePaths(contract.InfrastructureCluster().IgnorePaths()))
+	_, err := r.reconcileReferencedObject(ctx, s.Current.InfrastructureCluster, s.Desired.InfrastructureCluster, mergepatch.IgnorePaths(contract.InfrastructureCluster().IgnorePaths()))
+	return err
 }
 
 // reconcileControlPlane works to bring the current state of a managed topology in line with the desired state. This involves
@@ -107,7 +123,8 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, s *scope.
 
 	// Create or update the ControlPlaneObject for the ControlPlaneState.
 	ctx, _ = tlog.LoggerFrom(ctx).WithObject(s.Desired.ControlPlane.Object).Into(ctx)
-	if err := r.reconcileReferencedObject(ctx, s.Current.ControlPlane.Object, s.Desired.ControlPlane.Object); err != nil {
+	controlPlane, err := r.reconcileReferencedObject(ctx, s.Current.ControlPlane.Object, s.Desired.ControlPlane.Object)
+	if err != nil {
 		return kerrors.NewAggregate([]error{
 			errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: s.Desired.ControlPlane.Object}),
 			cleanup(),
@@ -116,7 +133,12 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, s *scope.
 
 	// At this point we've updated the ControlPlane object and, where required, the ControlPlane InfrastructureMachineTemplate
 	// without error. Run the cleanup in order to delete the old InfrastructureMachineTemplate if template rotation was done during update.
-	return cleanup()
+	if err := cleanup(); err != nil {
+		return err
+	}
+
+	// Create, update or delete the MachineHealthCheck for the control plane, owned by the control plane.
+	return r.reconcileMachineHealthCheck(ctx, s.Current.ControlPlane.MachineHealthCheck, s.Desired.ControlPlane.MachineHealthCheck, controlPlane)
 }
 
 // reconcileCluster reconciles the desired state of the Cluster object.
@@ -143,6 +165,87 @@ func (r *ClusterReconciler) reconcileCluster(ctx context.Context, s *scope.Scope
 	return nil
 }
 
+// reconcileTopologyStatus reports the ClusterClass and the upgrade status of the MachineDeployments in the Cluster status.
+func (r *ClusterReconciler) reconcileTopologyStatus(ctx context.Context, s *scope.Scope) error {
+	status := computeTopologyStatus(s)
+	if apiequality.Semantic.DeepEqual(s.Current.Cluster.Status.Topology, status) {
+		return nil
+	}
+
+	// NOTE: Using a merge patch so only status.topology is changed, without interfering with the other
+	// controllers updating the Cluster status.
+	cluster := s.Current.Cluster
+	patch := client.MergeFrom(cluster.DeepCopy())
+	cluster.Status.Topology = status
+	if err := r.Client.Status().Patch(ctx, cluster, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch status of %s", tlog.KObj{Obj: cluster})
+	}
+	return nil
+}
+
+// reconcileTopologyClass reports the ClusterClass in the Cluster status if it is not reported yet, so the objects of
+// the managed topology are never created without recording which ClusterClass they are based on; this ensures
+// a rebase to a different ClusterClass is detected even if it happens right after the Cluster has been created.
+func (r *ClusterReconciler) reconcileTopologyClass(ctx context.Context, s *scope.Scope) error {
+	cluster := s.Current.Cluster
+	if cluster.Status.Topology != nil && cluster.Status.Topology.Class != "" {
+		return nil
+	}
+
+	patch := client.MergeFrom(cluster.DeepCopy())
+	if cluster.Status.Topology == nil {
+		cluster.Status.Topology = &clusterv1.TopologyStatus{}
+	}
+	cluster.Status.Topology.Class = s.Blueprint.Topology.Class
+	if err := r.Client.Status().Patch(ctx, cluster, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch status of %s", tlog.KObj{Obj: cluster})
+	}
+	return nil
+}
+
+// computeTopologyStatus computes the ClusterClass the Cluster is based on, and which MachineDeployments are pending,
+// upgrading or upgraded to the version defined in the topology.
+// NOTE: While the Cluster is being rebased to a different ClusterClass, the previous ClusterClass is reported
+// until all the MachineDeployments picked up the templates of the new ClusterClass.
+func computeTopologyStatus(s *scope.Scope) *clusterv1.TopologyStatus {
+	status := &clusterv1.TopologyStatus{
+		Class: s.Blueprint.Topology.Class,
+	}
+	if isRebasing(s.Current.Cluster) && s.UpgradeTracker.MachineDeployments.IsRebasePending() {
+		status.Class = s.Current.Cluster.Status.Topology.Class
+	}
+
+	if !s.Blueprint.HasMachineDeployments() {
+		return status
+	}
+
+	version := s.Blueprint.Topology.Version
+	mdStatus := &clusterv1.MachineDeploymentsUpgradeStatus{}
+	for _, mdTopology := range s.Blueprint.Topology.Workers.MachineDeployments {
+		// NOTE: MachineDeployments created in the current reconcile are not reported, given that they are
+		// created with the version defined in the topology.
+		currentMachineDeployment := s.Current.MachineDeployments[mdTopology.Name]
+		if currentMachineDeployment == nil || currentMachineDeployment.Object == nil {
+			continue
+		}
+
+		name := currentMachineDeployment.Object.Name
+		switch {
+		case s.UpgradeTracker.MachineDeployments.Has(name):
+			mdStatus.Upgrading = append(mdStatus.Upgrading, name)
+		case isMachineDeploymentUpgraded(currentMachineDeployment, version):
+			mdStatus.Upgraded = append(mdStatus.Upgraded, name)
+		case currentMachineDeployment.Object.Spec.Template.Spec.Version != nil && *currentMachineDeployment.Object.Spec.Template.Spec.Version == version:
+			mdStatus.Upgrading = append(mdStatus.Upgrading, name)
+		default:
+			mdStatus.Pending = append(mdStatus.Pending, name)
+		}
+	}
+	status.MachineDeployments = mdStatus
+
+	return status
+}
+
 // reconcileMachineDeployments reconciles the desired state of the MachineDeployment objects.
 func (r *ClusterReconciler) reconcileMachineDeployments(ctx context.Context, s *scope.Scope) error {
 	diff := calculateMachineDeploymentDiff(s.Current.MachineDeployments, s.Desired.MachineDeployments)
@@ -195,7 +298,13 @@ func (r *ClusterReconciler) createMachineDeployment(ctx context.Context, md *sco
 
 	log = log.WithObject(md.Object)
 	log.Infof(fmt.Sprintf("Creating %s", tlog.KObj{Obj: md.Object}))
-	if err := r.Client.Create(ctx, md.Object.DeepCopy()); err != nil {
+	newMD := md.Object.DeepCopy()
+	if err := r.Client.Create(ctx, newMD); err != nil {
+		return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: md.Object})
+	}
+
+	// Create the MachineHealthCheck for the MachineDeployment, if required.
+	if err := r.reconcileMachineHealthCheck(ctx, nil, md.MachineHealthCheck, newMD); err != nil {
 		return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: md.Object})
 	}
 	return nil
@@ -227,6 +336,11 @@ func (r *ClusterReconciler) updateMachineDeployment(ctx context.Context, cluster
 		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: currentMD.Object})
 	}
 
+	// Create, update or delete the MachineHealthCheck for the MachineDeployment.
+	if err := r.reconcileMachineHealthCheck(ctx, currentMD.MachineHealthCheck, desiredMD.MachineHealthCheck, currentMD.Object); err != nil {
+		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: currentMD.Object})
+	}
+
 	// Check differences between current and desired MachineDeployment, and eventually patch the current object.
 	log = log.WithObject(desiredMD.Object)
 	patchHelper, err := mergepatch.NewHelper(currentMD.Object, desiredMD.Object, r.Client)
@@ -251,6 +365,11 @@ func (r *ClusterReconciler) updateMachineDeployment(ctx context.Context, cluster
 func (r *ClusterReconciler) deleteMachineDeployment(ctx context.Context, md *scope.MachineDeploymentState) error {
 	log := tlog.LoggerFrom(ctx).WithMachineDeployment(md.Object).WithObject(md.Object)
 
+	// Delete the MachineHealthCheck for the MachineDeployment, if any.
+	if err := r.reconcileMachineHealthCheck(ctx, md.MachineHealthCheck, nil, md.Object); err != nil {
+		return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: md.Object})
+	}
+
 	log.Infof("Deleting %s", tlog.KObj{Obj: md.Object})
 	if err := r.Client.Delete(ctx, md.Object); err != nil && !apierrors.IsNotFound(err) {
 		return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: md.Object})
@@ -258,6 +377,93 @@ func (r *ClusterReconciler) deleteMachineDeployment(ctx context.Context, md *sco
 	return nil
 }
 
+// reconcileMachineHealthCheck creates, updates or deletes a MachineHealthCheck according to the current and desired state.
+// The MachineHealthCheck is owned by the given ControlPlane / MachineDeployment object; the current MachineHealthCheck
+// is updated with the entire desired spec, so fields removed from the desired state are removed as well.
+func (r *ClusterReconciler) reconcileMachineHealthCheck(ctx context.Context, current, desired *clusterv1.MachineHealthCheck, owner client.Object) error {
+	log := tlog.LoggerFrom(ctx)
+
+	// If the MachineHealthCheck is not required anymore, delete it.
+	if desired == nil {
+		if current == nil {
+			return nil
+		}
+		log.Infof("Deleting %s", tlog.KObj{Obj: current})
+		if err := r.Client.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: current})
+		}
+		return nil
+	}
+
+	// Default the desired MachineHealthCheck the same way the webhook does, so the fields defaulted
+	// in the current MachineHealthCheck are not detected as changes.
+	desired = desired.DeepCopy()
+	desired.Default()
+
+	ownerGVK, err := apiutil.GVKForObject(owner, r.Client.Scheme())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get GroupVersionKind of %s", tlog.KObj{Obj: owner})
+	}
+	ownerRef := metav1.OwnerReference{
+		APIVersion: ownerGVK.GroupVersion().String(),
+		Kind:       ownerGVK.Kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+	}
+
+	// If there is no current MachineHealthCheck, create it.
+	if current == nil {
+		// NOTE: The owner does not have a UID when it has not been really created, e.g. when computing the plan
+		// with a dry run client; in this case the MachineHealthCheck is created in the next reconcile.
+		if owner.GetUID() == "" {
+			log.V(3).Infof("Skipping creation of %s, %s does not have a UID yet", tlog.KObj{Obj: desired}, tlog.KObj{Obj: owner})
+			return nil
+		}
+
+		// If a MachineHealthCheck with the same name exists but it is not managed by the topology controller,
+		// e.g. because it has been created by the user, leave it alone.
+		existing := &clusterv1.MachineHealthCheck{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(desired), existing); err == nil {
+			if _, ok := existing.Labels[clusterv1.ClusterTopologyOwnedLabel]; !ok {
+				log.Infof("Skipping creation of %s, a MachineHealthCheck with the same name not managed by the topology controller already exists", tlog.KObj{Obj: desired})
+			}
+			return nil
+		} else if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to read %s", tlog.KObj{Obj: desired})
+		}
+
+		desired.OwnerReferences = []metav1.OwnerReference{ownerRef}
+		log.Infof("Creating %s", tlog.KObj{Obj: desired})
+		if err := r.Client.Create(ctx, desired); err != nil {
+			return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: desired})
+		}
+		return nil
+	}
+
+	// Check differences between current and desired MachineHealthCheck, and eventually update the current object.
+	// NOTE: Labels and OwnerReferences added by other controllers, e.g. the OwnerReference to the Cluster added
+	// by the MachineHealthCheck controller, are preserved.
+	updated := current.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		updated.Labels[k] = v
+	}
+	updated.OwnerReferences = util.EnsureOwnerRef(updated.OwnerReferences, ownerRef)
+	updated.Spec = desired.Spec
+	if apiequality.Semantic.DeepEqual(current, updated) {
+		log.V(3).Infof("No changes for %s", tlog.KObj{Obj: current})
+		return nil
+	}
+
+	log.Infof("Updating %s", tlog.KObj{Obj: current})
+	if err := r.Client.Update(ctx, updated); err != nil {
+		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: current})
+	}
+	return nil
+}
+
 type machineDeploymentDiff struct {
 	toCreate, toUpdate, toDelete []string
 }
@@ -284,41 +490,43 @@ func calculateMachineDeploymentDiff(current, desired map[string]*scope.MachineDe
 	return diff
 }
 
-// reconcileReferencedObject reconciles the desired state of the referenced object.
+// reconcileReferencedObject reconciles the desired state of the referenced object, returning the created object
+// or the current object if it already exists.
 // NOTE: After a referenced object is created it is assumed that the reference should
 // never change (only the content of the object can eventually change). Thus, we are checking for strict compatibility.
-func (r *ClusterReconciler) reconcileReferencedObject(ctx context.Context, current, desired *unstructured.Unstructured, opts ...mergepatch.HelperOption) error {
+func (r *ClusterReconciler) reconcileReferencedObject(ctx context.Context, current, desired *unstructured.Unstructured, opts ...mergepatch.HelperOption) (*unstructured.Unstructured, error) {
 	log := tlog.LoggerFrom(ctx)
 
 	// If there is no current object, create it.
 	if current == nil {
 		log.Infof("Creating %s", tlog.KObj{Obj: desired})
-		if err := r.Client.Create(ctx, desired.DeepCopy()); err != nil {
-			return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: desired})
+		created := desired.DeepCopy()
+		if err := r.Client.Create(ctx, created); err != nil {
+			return nil, errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: desired})
 		}
-		return nil
+		return created, nil
 	}
 
 	// Check if the current and desired referenced object are compatible.
 	if err := check.ReferencedObjectsAreStrictlyCompatible(current, desired); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check differences between current and desired state, and eventually patch the current object.
 	patchHelper, err := mergepatch.NewHelper(current, desired, r.Client, opts...)
 	if err != nil {
-		return errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: current})
+		return nil, errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: current})
 	}
 	if !patchHelper.HasChanges() {
 		log.V(3).Infof("No changes for %s", tlog.KObj{Obj: desired})
-		return nil
+		return current, nil
 	}
 
 	log.Infof("Patching %s", tlog.KObj{Obj: desired})
 	if err := patchHelper.Patch(ctx); err != nil {
-		return errors.Wrapf(err, "failed to patch %s", tlog.KObj{Obj: current})
+		return nil, errors.Wrapf(err, "failed to patch %s", tlog.KObj{Obj: current})
 	}
-	return nil
+	return current, nil
 }
 
 type reconcileReferencedTemplateInput struct {
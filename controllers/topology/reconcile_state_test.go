@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -106,6 +109,127 @@ func TestReconcileCluster(t *testing.T) {
 	}
 }
 
+func TestReconcileTopologyStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	stableStatus := clusterv1.MachineDeploymentStatus{
+		ObservedGeneration: 2,
+		Replicas:           1,
+		UpdatedReplicas:    1,
+		AvailableReplicas:  1,
+		ReadyReplicas:      1,
+	}
+	rollingOutStatus := clusterv1.MachineDeploymentStatus{
+		ObservedGeneration: 2,
+		Replicas:           1,
+	}
+	mdUpgraded := builder.MachineDeployment(metav1.NamespaceDefault, "md-upgraded").
+		WithGeneration(1).WithReplicas(1).WithVersion("v1.21.2").WithStatus(stableStatus).Build()
+	mdUpgrading := builder.MachineDeployment(metav1.NamespaceDefault, "md-upgrading").
+		WithGeneration(1).WithReplicas(1).WithVersion("v1.21.2").WithStatus(rollingOutStatus).Build()
+	mdPickingUpVersion := builder.MachineDeployment(metav1.NamespaceDefault, "md-picking-up-version").
+		WithGeneration(1).WithReplicas(1).WithVersion("v1.20.0").WithStatus(stableStatus).Build()
+	mdPending := builder.MachineDeployment(metav1.NamespaceDefault, "md-pending").
+		WithGeneration(1).WithReplicas(1).WithVersion("v1.20.0").WithStatus(stableStatus).Build()
+
+	cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(fakeScheme).
+		WithObjects(cluster).
+		Build()
+
+	s := scope.New(cluster)
+	s.Blueprint = &scope.ClusterBlueprint{
+		Topology: &clusterv1.Topology{
+			Class:   "class1",
+			Version: "v1.21.2",
+			Workers: &clusterv1.WorkersTopology{
+				MachineDeployments: []clusterv1.MachineDeploymentTopology{
+					{Name: "upgraded"},
+					{Name: "upgrading"},
+					{Name: "picking-up-version"},
+					{Name: "pending"},
+					{Name: "created"},
+				},
+			},
+		},
+	}
+	s.Current.MachineDeployments = scope.MachineDeploymentsStateMap{
+		"upgraded":           {Object: mdUpgraded},
+		"upgrading":          {Object: mdUpgrading},
+		"picking-up-version": {Object: mdPickingUpVersion},
+		"pending":            {Object: mdPending},
+	}
+	s.UpgradeTracker.MachineDeployments.Insert(mdPickingUpVersion.Name)
+
+	r := ClusterReconciler{
+		Client: fakeClient,
+	}
+	g.Expect(r.reconcileTopologyStatus(ctx, s)).To(Succeed())
+
+	got := &clusterv1.Cluster{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+	g.Expect(got.Status.Topology).To(Equal(&clusterv1.TopologyStatus{
+		Class: "class1",
+		MachineDeployments: &clusterv1.MachineDeploymentsUpgradeStatus{
+			Pending:   []string{mdPending.Name},
+			Upgrading: []string{mdUpgrading.Name, mdPickingUpVersion.Name},
+			Upgraded:  []string{mdUpgraded.Name},
+		},
+	}))
+}
+
+func TestReconcileTopologyClass(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *clusterv1.TopologyStatus
+		want   string
+	}{
+		{
+			name:   "Report the ClusterClass if the topology status is not set",
+			status: nil,
+			want:   "class2",
+		},
+		{
+			name:   "Report the ClusterClass if the topology status does not report a ClusterClass yet",
+			status: &clusterv1.TopologyStatus{},
+			want:   "class2",
+		},
+		{
+			name:   "Keep the reported ClusterClass while a rebase is in progress",
+			status: &clusterv1.TopologyStatus{Class: "class1"},
+			want:   "class1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			cluster.Status.Topology = tt.status
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(fakeScheme).
+				WithObjects(cluster).
+				Build()
+
+			s := scope.New(cluster)
+			s.Blueprint = &scope.ClusterBlueprint{
+				Topology: &clusterv1.Topology{Class: "class2"},
+			}
+
+			r := ClusterReconciler{
+				Client: fakeClient,
+			}
+			g.Expect(r.reconcileTopologyClass(ctx, s)).To(Succeed())
+
+			got := &clusterv1.Cluster{}
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+			g.Expect(got.Status.Topology).ToNot(BeNil())
+			g.Expect(got.Status.Topology.Class).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestReconcileInfrastructureCluster(t *testing.T) {
 	g := NewWithT(t)
 
@@ -825,3 +949,160 @@ func toMachineDeploymentTopologyStateMap(states []*scope.MachineDeploymentState)
 	}
 	return ret
 }
+
+func TestReconcileMachineHealthCheck(t *testing.T) {
+	mhc := &clusterv1.MachineHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachineHealthCheck",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "md1",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:          "cluster1",
+				clusterv1.ClusterTopologyOwnedLabel: "",
+			},
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: "cluster1",
+			UnhealthyConditions: []clusterv1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+			},
+		},
+	}
+	mhcWithChanges := mhc.DeepCopy()
+	mhcWithChanges.Spec.UnhealthyConditions[0].Timeout = metav1.Duration{Duration: 10 * time.Minute}
+	mhcWithRemediationTemplate := mhc.DeepCopy()
+	mhcWithRemediationTemplate.Spec.RemediationTemplate = &corev1.ObjectReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+		Kind:       "GenericRemediationTemplate",
+		Namespace:  metav1.NamespaceDefault,
+		Name:       "remediation",
+	}
+
+	md := builder.MachineDeployment(metav1.NamespaceDefault, "md1").Build()
+	md.SetUID("md1-uid")
+	mdOwnerRef := metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "MachineDeployment",
+		Name:       "md1",
+		UID:        "md1-uid",
+	}
+	clusterOwnerRef := metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       "cluster1",
+		UID:        "cluster1-uid",
+	}
+	mhcOwnedByCluster := mhc.DeepCopy()
+	mhcOwnedByCluster.OwnerReferences = []metav1.OwnerReference{clusterOwnerRef}
+	mhcNotManaged := mhcWithChanges.DeepCopy()
+	mhcNotManaged.Labels = nil
+	mhcNotManaged.Default()
+	mdWithoutUID := builder.MachineDeployment(metav1.NamespaceDefault, "md1").Build()
+
+	tests := []struct {
+		name          string
+		current       *clusterv1.MachineHealthCheck
+		existing      *clusterv1.MachineHealthCheck
+		desired       *clusterv1.MachineHealthCheck
+		owner         client.Object
+		want          *clusterv1.MachineHealthCheck
+		wantOwnerRefs []metav1.OwnerReference
+	}{
+		{
+			name:          "Should create a MachineHealthCheck",
+			current:       nil,
+			desired:       mhc,
+			want:          mhc,
+			wantOwnerRefs: []metav1.OwnerReference{mdOwnerRef},
+		},
+		{
+			name:          "Should update a MachineHealthCheck",
+			current:       mhc,
+			desired:       mhcWithChanges,
+			want:          mhcWithChanges,
+			wantOwnerRefs: []metav1.OwnerReference{mdOwnerRef},
+		},
+		{
+			name:          "Should remove fields not in the desired MachineHealthCheck",
+			current:       mhcWithRemediationTemplate,
+			desired:       mhc,
+			want:          mhc,
+			wantOwnerRefs: []metav1.OwnerReference{mdOwnerRef},
+		},
+		{
+			name:          "Should preserve OwnerReferences added by other controllers",
+			current:       mhcOwnedByCluster,
+			desired:       mhc,
+			want:          mhc,
+			wantOwnerRefs: []metav1.OwnerReference{clusterOwnerRef, mdOwnerRef},
+		},
+		{
+			name:    "Should delete a MachineHealthCheck",
+			current: mhc,
+			desired: nil,
+			want:    nil,
+		},
+		{
+			name:    "Should not create a MachineHealthCheck if the owner does not have a UID yet",
+			current: nil,
+			desired: mhc,
+			owner:   mdWithoutUID,
+			want:    nil,
+		},
+		{
+			name:     "Should leave alone a MachineHealthCheck with the same name not managed by the topology controller",
+			current:  nil,
+			existing: mhcNotManaged,
+			desired:  mhc,
+			want:     mhcNotManaged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			fakeObjs := make([]client.Object, 0)
+			if tt.current != nil {
+				fakeObjs = append(fakeObjs, tt.current.DeepCopy())
+			}
+			if tt.existing != nil {
+				fakeObjs = append(fakeObjs, tt.existing.DeepCopy())
+			}
+			owner := tt.owner
+			if owner == nil {
+				owner = md
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(fakeScheme).
+				WithObjects(fakeObjs...).
+				Build()
+
+			r := ClusterReconciler{
+				Client: fakeClient,
+			}
+
+			var current *clusterv1.MachineHealthCheck
+			if tt.current != nil {
+				current = &clusterv1.MachineHealthCheck{}
+				g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(tt.current), current)).To(Succeed())
+			}
+			g.Expect(r.reconcileMachineHealthCheck(ctx, current, tt.desired, owner)).To(Succeed())
+
+			got := &clusterv1.MachineHealthCheck{}
+			err := fakeClient.Get(ctx, client.ObjectKeyFromObject(mhc), got)
+			if tt.want == nil {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got.Labels).To(Equal(tt.want.Labels))
+			want := tt.want.DeepCopy()
+			want.Default()
+			g.Expect(got.Spec).To(Equal(want.Spec))
+			g.Expect(got.OwnerReferences).To(Equal(tt.wantOwnerRefs))
+		})
+	}
+}
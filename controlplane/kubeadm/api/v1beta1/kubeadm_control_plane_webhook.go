@@ -91,6 +91,7 @@ func (in *KubeadmControlPlane) ValidateCreate() error {
 	spec := in.Spec
 	allErrs := validateKubeadmControlPlaneSpec(spec, in.Namespace, field.NewPath("spec"))
 	allErrs = append(allErrs, validateEtcd(&spec, nil)...)
+	allErrs = append(allErrs, spec.KubeadmConfigSpec.ValidateBottlerocket(nil, field.NewPath("spec", "kubeadmConfigSpec"))...)
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), in.Name, allErrs)
 	}
@@ -197,6 +198,7 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 
 	allErrs = append(allErrs, in.validateVersion(prev.Spec.Version)...)
 	allErrs = append(allErrs, validateEtcd(&in.Spec, &prev.Spec)...)
+	allErrs = append(allErrs, in.Spec.KubeadmConfigSpec.ValidateBottlerocket(&prev.Spec.KubeadmConfigSpec, field.NewPath(spec, kubeadmConfigSpec))...)
 	allErrs = append(allErrs, in.validateCoreDNSVersion(prev)...)
 
 	if len(allErrs) > 0 {
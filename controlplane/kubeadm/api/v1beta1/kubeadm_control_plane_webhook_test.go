@@ -932,6 +932,60 @@ func TestKubeadmControlPlaneValidateUpdateAfterDefaulting(t *testing.T) {
 	}
 }
 
+func TestKubeadmControlPlaneValidateBottlerocket(t *testing.T) {
+	g := NewWithT(t)
+
+	before := &KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "foo",
+		},
+		Spec: KubeadmControlPlaneSpec{
+			MachineTemplate: KubeadmControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "test/v1alpha1",
+					Kind:       "UnknownInfraMachine",
+					Namespace:  "foo",
+					Name:       "infraTemplate",
+				},
+			},
+			Replicas: pointer.Int32Ptr(1),
+			Version:  "v1.19.0",
+			RolloutStrategy: &RolloutStrategy{
+				Type: RollingUpdateStrategyType,
+				RollingUpdate: &RollingUpdate{
+					MaxSurge: &intstr.IntOrString{
+						IntVal: 1,
+					},
+				},
+			},
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				Format: bootstrapv1.Bottlerocket,
+			},
+		},
+	}
+	g.Expect(before.ValidateCreate()).To(Succeed())
+
+	withSudo := before.DeepCopy()
+	withSudo.Spec.KubeadmConfigSpec.Users = []bootstrapv1.User{{Name: "admin", Sudo: pointer.StringPtr("ALL=(ALL) NOPASSWD:ALL")}}
+	g.Expect(withSudo.ValidateCreate()).To(Succeed())
+	g.Expect(withSudo.ValidateUpdate(before.DeepCopy())).To(Succeed())
+
+	withTwoUsers := before.DeepCopy()
+	withTwoUsers.Spec.KubeadmConfigSpec.Users = []bootstrapv1.User{{Name: "admin"}, {Name: "user"}}
+	g.Expect(withTwoUsers.ValidateCreate()).NotTo(Succeed())
+	g.Expect(withTwoUsers.ValidateUpdate(before.DeepCopy())).NotTo(Succeed())
+
+	// Objects created before the validation existed must still accept unrelated updates.
+	scaled := withTwoUsers.DeepCopy()
+	scaled.Spec.Replicas = pointer.Int32Ptr(3)
+	g.Expect(scaled.ValidateUpdate(withTwoUsers.DeepCopy())).To(Succeed())
+
+	withNTP := before.DeepCopy()
+	withNTP.Spec.KubeadmConfigSpec.NTP = &bootstrapv1.NTP{Enabled: pointer.BoolPtr(false)}
+	g.Expect(withNTP.ValidateUpdate(before.DeepCopy())).NotTo(Succeed())
+}
+
 func TestPathsMatch(t *testing.T) {
 	tests := []struct {
 		name          string
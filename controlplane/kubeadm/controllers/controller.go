@@ -20,8 +20,6 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/blang/semver"
@@ -35,6 +33,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/external/contract"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
@@ -163,16 +162,14 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		endpoints, found, err := external.GetExternalEtcdEndpoints(externalEtcd)
+		currentEtcdEndpoints, err := contract.EtcdCluster().Endpoints().Get(externalEtcd)
 		if err != nil {
+			if contract.IsNotFound(err) {
+				log.Info("Etcd endpoints not available")
+				return ctrl.Result{Requeue: true}, nil
+			}
 			return ctrl.Result{}, err
 		}
-		if !found {
-			log.Info("Etcd endpoints not available")
-			return ctrl.Result{Requeue: true}, nil
-		}
-		currentEtcdEndpoints := strings.Split(endpoints, ",")
-		sort.Strings(currentEtcdEndpoints)
 		currentKCPEndpoints := kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.Endpoints
 		if !reflect.DeepEqual(currentEtcdEndpoints, currentKCPEndpoints) {
 			/* During upgrade, KCP spec's endpoints will again be an empty list, and will get populated by the cluster controller once the
@@ -181,11 +178,7 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 			etcd members that will get deleted during upgrade. Hence the controller checks and stalls if the etcd cluster is undergoing upgrade and proceeds
 			only after the etcd upgrade is completed as that guarantees that the KCP has latest set of endpoints.
 			*/
-			etcdUpgradeInProgress, err := external.IsExternalEtcdUpgrading(externalEtcd)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-			if etcdUpgradeInProgress {
+			if contract.EtcdCluster().IsUpgrading(externalEtcd) {
 				log.Info("Etcd undergoing upgrade, marking etcd endpoints available condition as false, since new endpoints will be available only after etcd upgrade")
 				if conditions.IsTrue(kcp, controlplanev1.ExternalEtcdEndpointsAvailable) || conditions.IsUnknown(kcp, controlplanev1.ExternalEtcdEndpointsAvailable) {
 					conditions.MarkFalse(kcp, controlplanev1.ExternalEtcdEndpointsAvailable, controlplanev1.ExternalEtcdUndergoingUpgrade, clusterv1.ConditionSeverityInfo, "")
@@ -389,41 +382,14 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		// NOTE: we are checking the condition already exists in order to avoid to set this condition at the first
 		// reconciliation/before a rolling upgrade actually starts.
 		if conditions.Has(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition) {
-			if conditions.IsFalse(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition) {
-				/* Once KCP upgrade has completed, the controller will annotate the external etcd object to indicate that the older KCP machines
-				are no longer part of the cluster, and so any older out-of-date etcd members and machines can be deleted
-				*/
-				if cluster.Spec.ManagedExternalEtcdRef != nil {
-					etcdRef := cluster.Spec.ManagedExternalEtcdRef
-					externalEtcd, err := external.Get(ctx, r.Client, etcdRef, cluster.Namespace)
-					if err != nil {
-						return ctrl.Result{}, err
-					}
-					log.Info("Adding upgrade complete annotation on etcdadmCluster")
-					if err := external.SetKCPUpdateCompleteAnnotationOnEtcdadmCluster(externalEtcd); err != nil {
-						return ctrl.Result{}, err
-					}
-					if err := r.Client.Update(ctx, externalEtcd); err != nil {
-						return ctrl.Result{}, err
-					}
-				}
-			}
 			conditions.MarkTrue(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition)
-			/* Once KCP upgrade has completed, the controller will annotate the external etcd object to indicate that the older KCP machines
-			are no longer part of the cluster, and so any older out-of-date etcd members and machines can be deleted
-			*/
-			if cluster.Spec.ManagedExternalEtcdRef != nil {
-				etcdRef := cluster.Spec.ManagedExternalEtcdRef
-				externalEtcd, err := external.Get(ctx, r.Client, etcdRef, cluster.Namespace)
-				if err != nil {
-					return ctrl.Result{}, err
-				}
-				if err := external.SetKCPUpdateCompleteAnnotationOnEtcdadmCluster(externalEtcd); err != nil {
-					return ctrl.Result{}, err
-				}
-				if err := r.Client.Update(ctx, externalEtcd); err != nil {
-					return ctrl.Result{}, err
-				}
+		}
+		// NOTE: When using a managed external etcd cluster, the etcd cluster is notified whenever all the machines are
+		// up to date, so the out-of-date etcd members can be deleted and the Cluster controller can complete the etcd
+		// cluster upgrade; this is idempotent, so a notification missed e.g. due to a failed update is retried.
+		if conditions.IsTrue(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition) {
+			if err := r.notifyEtcdUpgradeComplete(ctx, cluster); err != nil {
+				return ctrl.Result{}, err
 			}
 		}
 	}
@@ -610,6 +576,33 @@ func (r *KubeadmControlPlaneReconciler) reconcileControlPlaneConditions(ctx cont
 	return ctrl.Result{}, nil
 }
 
+// notifyEtcdUpgradeComplete adds the upgrade complete annotation to the managed external etcd cluster, if any, once
+// all the control plane machines have been rolled out, so the out-of-date etcd members can be deleted.
+// The annotation is added only if the etcd cluster is not upgrading and the annotation is not already present.
+func (r *KubeadmControlPlaneReconciler) notifyEtcdUpgradeComplete(ctx context.Context, cluster *clusterv1.Cluster) error {
+	if cluster.Spec.ManagedExternalEtcdRef == nil {
+		return nil
+	}
+
+	etcdRef := cluster.Spec.ManagedExternalEtcdRef
+	externalEtcd, err := external.Get(ctx, r.Client, etcdRef, cluster.Namespace)
+	if err != nil {
+		return err
+	}
+	if contract.EtcdCluster().IsUpgrading(externalEtcd) || contract.EtcdCluster().ControlPlaneUpgradeCompleted().Has(externalEtcd) {
+		return nil
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Adding upgrade complete annotation on etcd cluster", "refGroupVersionKind", etcdRef.GroupVersionKind(), "refName", etcdRef.Name)
+	if err := external.SetKCPUpdateCompleteAnnotationOnEtcdadmCluster(externalEtcd); err != nil {
+		return err
+	}
+	if err := r.Client.Update(ctx, externalEtcd); err != nil {
+		return errors.Wrapf(err, "failed to notify the etcd cluster the control plane upgrade is completed")
+	}
+	return nil
+}
+
 // reconcileEtcdMembers ensures the number of etcd members is in sync with the number of machines/nodes.
 // This is usually required after a machine deletion.
 //
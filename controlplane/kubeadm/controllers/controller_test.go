@@ -1361,6 +1361,83 @@ func TestKubeadmControlPlaneReconciler_reconcileDelete(t *testing.T) {
 	})
 }
 
+func TestKubeadmControlPlaneReconciler_notifyEtcdUpgradeComplete(t *testing.T) {
+	etcdRef := &corev1.ObjectReference{
+		APIVersion: "etcdcluster.cluster.x-k8s.io/v1beta1",
+		Kind:       "EtcdadmCluster",
+		Name:       "test-etcd",
+		Namespace:  metav1.NamespaceDefault,
+	}
+	newEtcd := func(annotations map[string]string) *unstructured.Unstructured {
+		etcd := &unstructured.Unstructured{}
+		etcd.SetAPIVersion(etcdRef.APIVersion)
+		etcd.SetKind(etcdRef.Kind)
+		etcd.SetNamespace(etcdRef.Namespace)
+		etcd.SetName(etcdRef.Name)
+		etcd.SetAnnotations(annotations)
+		return etcd
+	}
+
+	tests := []struct {
+		name                    string
+		etcd                    *unstructured.Unstructured
+		wantEtcdUpgradeComplete bool
+	}{
+		{
+			name:                    "adds the upgrade complete annotation if the etcd cluster is not upgrading",
+			etcd:                    newEtcd(nil),
+			wantEtcdUpgradeComplete: true,
+		},
+		{
+			name:                    "keeps the upgrade complete annotation if already present",
+			etcd:                    newEtcd(map[string]string{clusterv1.ControlPlaneUpgradeCompletedAnnotation: "true"}),
+			wantEtcdUpgradeComplete: true,
+		},
+		{
+			name:                    "does not add the upgrade complete annotation while the etcd cluster is upgrading",
+			etcd:                    newEtcd(map[string]string{clusterv1.EtcdClusterUpgradingAnnotation: "true"}),
+			wantEtcdUpgradeComplete: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster, _, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+			cluster.Spec.ManagedExternalEtcdRef = etcdRef
+
+			fakeClient := newFakeClient(cluster.DeepCopy(), tt.etcd.DeepCopy())
+			r := &KubeadmControlPlaneReconciler{
+				Client: fakeClient,
+			}
+
+			// Notifying more than once is idempotent.
+			g.Expect(r.notifyEtcdUpgradeComplete(ctx, cluster)).To(Succeed())
+			g.Expect(r.notifyEtcdUpgradeComplete(ctx, cluster)).To(Succeed())
+
+			etcd := &unstructured.Unstructured{}
+			etcd.SetGroupVersionKind(tt.etcd.GroupVersionKind())
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(tt.etcd), etcd)).To(Succeed())
+			if tt.wantEtcdUpgradeComplete {
+				g.Expect(etcd.GetAnnotations()).To(HaveKeyWithValue(clusterv1.ControlPlaneUpgradeCompletedAnnotation, "true"))
+			} else {
+				g.Expect(etcd.GetAnnotations()).NotTo(HaveKey(clusterv1.ControlPlaneUpgradeCompletedAnnotation))
+			}
+		})
+	}
+
+	t.Run("is a no-op if the cluster does not use a managed external etcd cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster, _, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+
+		r := &KubeadmControlPlaneReconciler{
+			Client: newFakeClient(cluster.DeepCopy()),
+		}
+		g.Expect(r.notifyEtcdUpgradeComplete(ctx, cluster)).To(Succeed())
+	})
+}
+
 // test utils
 
 func newFakeClient(initObjs ...client.Object) client.Client {
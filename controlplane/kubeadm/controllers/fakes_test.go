@@ -66,6 +66,9 @@ type fakeWorkloadCluster struct {
 	*internal.Workload
 	Status            internal.ClusterStatus
 	EtcdMembersResult []string
+	EtcdMembersErr    error
+	EtcdLeaderResult  string
+	EtcdLeaderErr     error
 }
 
 func (f fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, _ *clusterv1.Machine, _ *clusterv1.Machine) error {
@@ -113,7 +116,11 @@ func (f fakeWorkloadCluster) RemoveMachineFromKubeadmConfigMap(ctx context.Conte
 }
 
 func (f fakeWorkloadCluster) EtcdMembers(_ context.Context) ([]string, error) {
-	return f.EtcdMembersResult, nil
+	return f.EtcdMembersResult, f.EtcdMembersErr
+}
+
+func (f fakeWorkloadCluster) EtcdLeader(_ context.Context) (string, error) {
+	return f.EtcdLeaderResult, f.EtcdLeaderErr
 }
 
 type fakeMigrator struct {
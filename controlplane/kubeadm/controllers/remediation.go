@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -27,6 +28,7 @@ import (
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -74,12 +76,20 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		return ctrl.Result{}, nil
 	}
 
-	// Select the machine to be remediated, which is the oldest machine marked as unhealthy.
-	//
-	// NOTE: The current solution is considered acceptable for the most frequent use case (only one unhealthy machine),
-	// however, in the future this could potentially be improved for the scenario where more than one unhealthy machine exists
-	// by considering which machine has lower impact on etcd quorum.
-	machineToBeRemediated := unhealthyMachines.Oldest()
+	// If there is more than one unhealthy machine, gets the etcd status so it is possible to select the machine
+	// with the lowest impact on etcd quorum.
+	var etcdStatus *remediationEtcdStatus
+	var etcdStatusErr error
+	if controlPlane.IsEtcdManaged() && len(unhealthyMachines) > 1 {
+		etcdStatus, etcdStatusErr = r.getRemediationEtcdStatus(ctx, controlPlane)
+	}
+
+	// Select the machine to be remediated; if it is not possible to get the etcd status, fall back to the oldest
+	// machine marked as unhealthy, so the error is surfaced on it.
+	machineToBeRemediated, selectionReason := unhealthyMachines.Oldest(), ""
+	if etcdStatusErr == nil {
+		machineToBeRemediated, selectionReason = selectMachineForRemediation(controlPlane, unhealthyMachines, etcdStatus)
+	}
 
 	// Returns if the machine is in the process of being deleted.
 	if !machineToBeRemediated.ObjectMeta.DeletionTimestamp.IsZero() {
@@ -103,6 +113,11 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		}
 	}()
 
+	if etcdStatusErr != nil {
+		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationFailedReason, clusterv1.ConditionSeverityError, etcdStatusErr.Error())
+		return ctrl.Result{}, etcdStatusErr
+	}
+
 	// Before starting remediation, run preflight checks in order to verify it is safe to remediate.
 	// If any of the following checks fails, we'll surface the reason in the MachineOwnerRemediated condition.
 
@@ -179,11 +194,157 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		return ctrl.Result{}, errors.Wrapf(err, "failed to delete unhealthy machine %s", machineToBeRemediated.Name)
 	}
 
-	log.Info("Remediating unhealthy machine", "UnhealthyMachine", machineToBeRemediated.Name)
-	conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationInProgressReason, clusterv1.ConditionSeverityWarning, "")
+	log.Info("Remediating unhealthy machine", "UnhealthyMachine", machineToBeRemediated.Name, "SelectionReason", selectionReason)
+	conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationInProgressReason, clusterv1.ConditionSeverityWarning, selectionReason)
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// remediationEtcdStatus contains the etcd status used for selecting the machine to be remediated.
+type remediationEtcdStatus struct {
+	// members is the list of etcd members, as reported by etcd.
+	members []string
+
+	// leader is the name of the etcd leader; it is empty if the leader is unknown.
+	leader string
+}
+
+// getRemediationEtcdStatus gets the list of etcd members and the etcd leader from the workload cluster.
+// NOTE: the etcd leader is best-effort, e.g. it cannot be found while a leader election is in progress; if it is not known,
+// machines are ranked on the etcd member health and on the failure domain only.
+func (r *KubeadmControlPlaneReconciler) getRemediationEtcdStatus(ctx context.Context, controlPlane *internal.ControlPlane) (*remediationEtcdStatus, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get client for workload cluster %s", controlPlane.Cluster.Name)
+	}
+
+	members, err := workloadCluster.EtcdMembers(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get etcd members for workload cluster %s", controlPlane.Cluster.Name)
+	}
+
+	leader, err := workloadCluster.EtcdLeader(ctx)
+	if err != nil {
+		log.Info("Failed to get the etcd leader, selecting the machine to be remediated without it", "err", err.Error())
+		leader = ""
+	}
+
+	return &remediationEtcdStatus{members: members, leader: leader}, nil
+}
+
+// remediationImpact defines the impact on etcd quorum of remediating a machine; lower values have lower impact.
+type remediationImpact int
+
+const (
+	// notEtcdMemberImpact applies to machines without an etcd member; remediating them does not change the etcd cluster.
+	notEtcdMemberImpact remediationImpact = iota
+
+	// unhealthyEtcdMemberImpact applies to machines with an unhealthy etcd member, that is not contributing to quorum anyway.
+	unhealthyEtcdMemberImpact
+
+	// healthyEtcdMemberImpact applies to machines with a healthy etcd member, that reduces the etcd fault tolerance when removed.
+	healthyEtcdMemberImpact
+
+	// etcdLeaderImpact applies to the machine hosting the etcd leader, that requires a leader election when removed.
+	etcdLeaderImpact
+)
+
+// String returns the reason for selecting a machine with this impact.
+func (i remediationImpact) String() string {
+	switch i {
+	case notEtcdMemberImpact:
+		return "it does not host an etcd member"
+	case unhealthyEtcdMemberImpact:
+		return "it hosts an unhealthy etcd member"
+	case healthyEtcdMemberImpact:
+		return "it hosts a healthy etcd member that is not the etcd leader"
+	default:
+		return "it hosts the etcd leader"
+	}
+}
+
+// selectMachineForRemediation selects the unhealthy machine that has the lowest impact on etcd quorum; it returns
+// the machine as well as the reason for selecting it, that is empty if there is only one unhealthy machine.
+//
+// Machines are ranked by etcd impact, avoiding the etcd leader and preferring machines whose etcd member
+// is missing or unhealthy; if there are still many candidates, the oldest machine in the failure domain with the most
+// control plane machines is selected, so remediation preserves the spread of machines across failure domains.
+//
+// NOTE: if etcdStatus is nil, e.g. when using external etcd, machines are ranked by failure domain only.
+func selectMachineForRemediation(controlPlane *internal.ControlPlane, unhealthyMachines collections.Machines, etcdStatus *remediationEtcdStatus) (*clusterv1.Machine, string) {
+	if len(unhealthyMachines) <= 1 {
+		return unhealthyMachines.Oldest(), ""
+	}
+
+	// Gets the candidates with the lowest impact on etcd quorum.
+	candidates := unhealthyMachines
+	impact := notEtcdMemberImpact
+	if etcdStatus != nil {
+		candidatesByImpact := map[remediationImpact]collections.Machines{}
+		for _, m := range unhealthyMachines {
+			i := etcdImpact(m, etcdStatus)
+			if candidatesByImpact[i] == nil {
+				candidatesByImpact[i] = collections.New()
+			}
+			candidatesByImpact[i].Insert(m)
+		}
+		for i := notEtcdMemberImpact; i <= etcdLeaderImpact; i++ {
+			if len(candidatesByImpact[i]) > 0 {
+				candidates, impact = candidatesByImpact[i], i
+				break
+			}
+		}
+	}
+
+	reasons := []string{}
+	if etcdStatus != nil {
+		reasons = append(reasons, impact.String())
+	}
+
+	// Gets the candidate in the failure domain with the most control plane machines.
+	machine := candidates.Oldest()
+	if len(candidates) > 1 {
+		if m, err := controlPlane.MachineInFailureDomainWithMostMachines(candidates); err == nil {
+			machine = m
+		}
+		if machine.Spec.FailureDomain != nil {
+			reasons = append(reasons, fmt.Sprintf("it is in the failure domain %s with the most control plane machines", *machine.Spec.FailureDomain))
+		} else {
+			reasons = append(reasons, "it is the oldest machine")
+		}
+	}
+
+	return machine, fmt.Sprintf("Machine selected for remediation among %d unhealthy machines because %s", len(unhealthyMachines), strings.Join(reasons, " and "))
+}
+
+// etcdImpact returns the impact on etcd quorum of remediating a machine.
+func etcdImpact(machine *clusterv1.Machine, etcdStatus *remediationEtcdStatus) remediationImpact {
+	if machine.Status.NodeRef == nil {
+		return notEtcdMemberImpact
+	}
+	nodeName := machine.Status.NodeRef.Name
+
+	isMember := false
+	for _, member := range etcdStatus.members {
+		if member == nodeName {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return notEtcdMemberImpact
+	}
+
+	if etcdStatus.leader == nodeName {
+		return etcdLeaderImpact
+	}
+	if !conditions.IsTrue(machine, controlplanev1.MachineEtcdMemberHealthyCondition) {
+		return unhealthyEtcdMemberImpact
+	}
+	return healthyEtcdMemberImpact
+}
+
 // canSafelyRemoveEtcdMember assess if it is possible to remove the member hosted on the machine to be remediated
 // without loosing etcd quorum.
 //
@@ -390,6 +390,61 @@ func TestReconcileUnhealthyMachines(t *testing.T) {
 
 		g.Expect(env.Cleanup(ctx, m1, m2, m3, m4)).To(Succeed())
 	})
+	t.Run("Remediation deletes the unhealthy machine with the lowest impact on etcd quorum", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m1 := createMachine(ctx, g, ns.Name, "m1-unhealthy-leader-", withMachineHealthCheckFailed(), withHealthyEtcdMember())
+		m2 := createMachine(ctx, g, ns.Name, "m2-unhealthy-", withMachineHealthCheckFailed(), withUnhealthyEtcdMember())
+		patchHelper, err := patch.NewHelper(m2, env.GetClient())
+		g.Expect(err).ToNot(HaveOccurred())
+		m2.ObjectMeta.Finalizers = []string{"wait-before-delete"}
+		g.Expect(patchHelper.Patch(ctx, m2))
+
+		m3 := createMachine(ctx, g, ns.Name, "m3-healthy-", withHealthyEtcdMember())
+
+		controlPlane := &internal.ControlPlane{
+			KCP: &controlplanev1.KubeadmControlPlane{Spec: controlplanev1.KubeadmControlPlaneSpec{
+				Replicas: utilpointer.Int32Ptr(3),
+				Version:  "v1.19.1",
+			}},
+			Cluster:  &clusterv1.Cluster{},
+			Machines: collections.FromMachines(m1, m2, m3),
+		}
+
+		r := &KubeadmControlPlaneReconciler{
+			Client:   env.GetClient(),
+			recorder: record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{
+					EtcdMembersResult: nodes(controlPlane.Machines),
+					EtcdLeaderResult:  m1.Status.NodeRef.Name,
+				},
+			},
+		}
+
+		ret, err := r.reconcileUnhealthyMachines(context.TODO(), controlPlane)
+
+		g.Expect(ret.IsZero()).To(BeFalse()) // Remediation completed, requeue
+		g.Expect(err).ToNot(HaveOccurred())
+
+		assertMachineCondition(ctx, g, m2, clusterv1.MachineOwnerRemediatedCondition, corev1.ConditionFalse, clusterv1.RemediationInProgressReason, clusterv1.ConditionSeverityWarning,
+			"Machine selected for remediation among 2 unhealthy machines because it hosts an unhealthy etcd member")
+
+		err = env.Get(ctx, client.ObjectKey{Namespace: m1.Namespace, Name: m1.Name}, m1)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(m1.ObjectMeta.DeletionTimestamp.IsZero()).To(BeTrue())
+
+		err = env.Get(ctx, client.ObjectKey{Namespace: m2.Namespace, Name: m2.Name}, m2)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(m2.ObjectMeta.DeletionTimestamp.IsZero()).To(BeFalse())
+
+		patchHelper, err = patch.NewHelper(m2, env.GetClient())
+		g.Expect(err).ToNot(HaveOccurred())
+		m2.ObjectMeta.Finalizers = nil
+		g.Expect(patchHelper.Patch(ctx, m2))
+
+		g.Expect(env.Cleanup(ctx, m1, m2, m3)).To(Succeed())
+	})
 }
 
 func TestCanSafelyRemoveEtcdMember(t *testing.T) {
@@ -766,6 +821,232 @@ func TestCanSafelyRemoveEtcdMember(t *testing.T) {
 	})
 }
 
+func TestGetRemediationEtcdStatus(t *testing.T) {
+	controlPlane := &internal.ControlPlane{Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"}}}
+
+	t.Run("gets the etcd members and the etcd leader", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{
+					EtcdMembersResult: []string{"node-m1", "node-m2"},
+					EtcdLeaderResult:  "node-m1",
+				},
+			},
+		}
+
+		etcdStatus, err := r.getRemediationEtcdStatus(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(etcdStatus).To(Equal(&remediationEtcdStatus{members: []string{"node-m1", "node-m2"}, leader: "node-m1"}))
+	})
+	t.Run("gets the etcd members without the etcd leader if the leader cannot be found", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{
+					EtcdMembersResult: []string{"node-m1", "node-m2"},
+					EtcdLeaderResult:  "node-m1",
+					EtcdLeaderErr:     errors.New("leader election in progress"),
+				},
+			},
+		}
+
+		etcdStatus, err := r.getRemediationEtcdStatus(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(etcdStatus).To(Equal(&remediationEtcdStatus{members: []string{"node-m1", "node-m2"}, leader: ""}))
+	})
+	t.Run("fails if the etcd members cannot be listed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{
+					EtcdMembersErr:   errors.New("etcd is not reachable"),
+					EtcdLeaderResult: "node-m1",
+				},
+			},
+		}
+
+		_, err := r.getRemediationEtcdStatus(ctx, controlPlane)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestSelectMachineForRemediation(t *testing.T) {
+	machine := func(name string, age time.Duration, failureDomain string, options ...machineOption) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+		}
+		if failureDomain != "" {
+			m.Spec.FailureDomain = utilpointer.StringPtr(failureDomain)
+		}
+		for _, opt := range append(options, withNodeRef(fmt.Sprintf("node-%s", name))) {
+			opt(m)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name            string
+		machines        []*clusterv1.Machine
+		unhealthy       []string
+		failureDomains  []string
+		etcdMembers     []string
+		etcdLeader      string
+		withoutEtcd     bool
+		expectedMachine string
+		expectedReason  string
+	}{
+		{
+			name: "selects the only unhealthy machine without a reason",
+			machines: []*clusterv1.Machine{
+				machine("m1", 3*time.Hour, "", withHealthyEtcdMember()),
+				machine("m2", 2*time.Hour, "", withHealthyEtcdMember()),
+				machine("m3", 1*time.Hour, "", withHealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m1"},
+			etcdMembers:     []string{"node-m1", "node-m2", "node-m3"},
+			etcdLeader:      "node-m1",
+			expectedMachine: "m1",
+			expectedReason:  "",
+		},
+		{
+			name: "avoids the etcd leader",
+			machines: []*clusterv1.Machine{
+				machine("m1", 3*time.Hour, "", withHealthyEtcdMember()),
+				machine("m2", 2*time.Hour, "", withHealthyEtcdMember()),
+				machine("m3", 1*time.Hour, "", withHealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m1", "m2"},
+			etcdMembers:     []string{"node-m1", "node-m2", "node-m3"},
+			etcdLeader:      "node-m1",
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 2 unhealthy machines because it hosts a healthy etcd member that is not the etcd leader",
+		},
+		{
+			name: "prefers machines with an unhealthy etcd member",
+			machines: []*clusterv1.Machine{
+				machine("m1", 3*time.Hour, "", withHealthyEtcdMember()),
+				machine("m2", 2*time.Hour, "", withUnhealthyEtcdMember()),
+				machine("m3", 1*time.Hour, "", withHealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m1", "m2"},
+			etcdMembers:     []string{"node-m1", "node-m2", "node-m3"},
+			etcdLeader:      "node-m3",
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 2 unhealthy machines because it hosts an unhealthy etcd member",
+		},
+		{
+			name: "prefers machines without an etcd member",
+			machines: []*clusterv1.Machine{
+				machine("m1", 3*time.Hour, "", withUnhealthyEtcdMember()),
+				machine("m2", 2*time.Hour, "", withUnhealthyEtcdMember()),
+				machine("m3", 1*time.Hour, "", withHealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m1", "m2"},
+			etcdMembers:     []string{"node-m1", "node-m3"},
+			etcdLeader:      "node-m3",
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 2 unhealthy machines because it does not host an etcd member",
+		},
+		{
+			name: "prefers the failure domain with the most machines among machines with the same etcd impact",
+			machines: []*clusterv1.Machine{
+				machine("m1", 5*time.Hour, "fd1", withHealthyEtcdMember()),
+				machine("m2", 4*time.Hour, "fd2", withHealthyEtcdMember()),
+				machine("m3", 3*time.Hour, "fd2", withHealthyEtcdMember()),
+				machine("m4", 2*time.Hour, "fd3", withHealthyEtcdMember()),
+				machine("m5", 1*time.Hour, "fd3", withHealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m1", "m2", "m3"},
+			failureDomains:  []string{"fd1", "fd2", "fd3"},
+			etcdMembers:     []string{"node-m1", "node-m2", "node-m3", "node-m4", "node-m5"},
+			etcdLeader:      "node-m4",
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 3 unhealthy machines because it hosts a healthy etcd member that is not the etcd leader and it is in the failure domain fd2 with the most control plane machines",
+		},
+		{
+			name: "selects the oldest machine in the failure domain with the most machines when using external etcd",
+			machines: []*clusterv1.Machine{
+				machine("m1", 3*time.Hour, "fd1"),
+				machine("m2", 2*time.Hour, "fd2"),
+				machine("m3", 1*time.Hour, "fd2"),
+			},
+			unhealthy:       []string{"m1", "m2"},
+			failureDomains:  []string{"fd1", "fd2"},
+			withoutEtcd:     true,
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 2 unhealthy machines because it is in the failure domain fd2 with the most control plane machines",
+		},
+		{
+			name: "selects the oldest machine without failure domains",
+			machines: []*clusterv1.Machine{
+				machine("m1", 3*time.Hour, "", withHealthyEtcdMember()),
+				machine("m2", 2*time.Hour, "", withHealthyEtcdMember()),
+				machine("m3", 1*time.Hour, "", withHealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m2", "m3"},
+			etcdMembers:     []string{"node-m1", "node-m2", "node-m3"},
+			etcdLeader:      "node-m1",
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 2 unhealthy machines because it hosts a healthy etcd member that is not the etcd leader and it is the oldest machine",
+		},
+		{
+			name: "ranks on the etcd member health and the failure domain when the etcd leader is unknown",
+			machines: []*clusterv1.Machine{
+				machine("m1", 4*time.Hour, "fd1", withHealthyEtcdMember()),
+				machine("m2", 3*time.Hour, "fd2", withUnhealthyEtcdMember()),
+				machine("m3", 2*time.Hour, "fd2", withUnhealthyEtcdMember()),
+				machine("m4", 1*time.Hour, "fd2", withUnhealthyEtcdMember()),
+			},
+			unhealthy:       []string{"m1", "m2", "m3"},
+			failureDomains:  []string{"fd1", "fd2"},
+			etcdMembers:     []string{"node-m1", "node-m2", "node-m3", "node-m4"},
+			expectedMachine: "m2",
+			expectedReason:  "Machine selected for remediation among 3 unhealthy machines because it hosts an unhealthy etcd member and it is in the failure domain fd2 with the most control plane machines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{}
+			for _, fd := range tt.failureDomains {
+				if cluster.Status.FailureDomains == nil {
+					cluster.Status.FailureDomains = clusterv1.FailureDomains{}
+				}
+				cluster.Status.FailureDomains[fd] = clusterv1.FailureDomainSpec{ControlPlane: true}
+			}
+			controlPlane := &internal.ControlPlane{
+				Cluster:  cluster,
+				Machines: collections.FromMachines(tt.machines...),
+			}
+			unhealthyMachines := controlPlane.Machines.Filter(func(m *clusterv1.Machine) bool {
+				for _, name := range tt.unhealthy {
+					if m.Name == name {
+						return true
+					}
+				}
+				return false
+			})
+
+			var etcdStatus *remediationEtcdStatus
+			if !tt.withoutEtcd {
+				etcdStatus = &remediationEtcdStatus{members: tt.etcdMembers, leader: tt.etcdLeader}
+			}
+
+			m, reason := selectMachineForRemediation(controlPlane, unhealthyMachines, etcdStatus)
+			g.Expect(m.Name).To(Equal(tt.expectedMachine))
+			g.Expect(reason).To(Equal(tt.expectedReason))
+		})
+	}
+}
+
 func nodes(machines collections.Machines) []string {
 	nodes := make([]string, 0, machines.Len())
 	for _, m := range machines {
@@ -244,3 +244,33 @@ func (w *Workload) EtcdMembers(ctx context.Context) ([]string, error) {
 	}
 	return names, nil
 }
+
+// EtcdLeader returns the name of the current etcd leader, or an empty string if the leader
+// is not in the list of etcd members.
+func (w *Workload) EtcdLeader(ctx context.Context) (string, error) {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list control plane nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	etcdClient, err := w.etcdClientGenerator.forLeader(ctx, nodeNames)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create etcd client")
+	}
+	defer etcdClient.Close()
+
+	members, err := etcdClient.Members(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list etcd members using etcd client")
+	}
+
+	for _, member := range members {
+		if member.ID == etcdClient.LeaderID {
+			return member.Name, nil
+		}
+	}
+	return "", nil
+}
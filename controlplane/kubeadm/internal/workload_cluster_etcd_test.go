@@ -536,6 +536,68 @@ func TestForwardEtcdLeadership(t *testing.T) {
 	})
 }
 
+func TestEtcdLeader(t *testing.T) {
+	tests := []struct {
+		name           string
+		leaderID       uint64
+		etcdClientErr  error
+		expectedLeader string
+		expectErr      bool
+	}{
+		{
+			name:           "it returns the name of the etcd leader",
+			leaderID:       102,
+			expectedLeader: "leader-node",
+		},
+		{
+			name:           "it returns an empty string if the leader is not a member",
+			leaderID:       555,
+			expectedLeader: "",
+		},
+		{
+			name:          "it returns an error if it fails to create the etcd client",
+			etcdClientErr: errors.New("no etcdClient"),
+			expectErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			etcdClientGenerator := &fakeEtcdClientGenerator{
+				forLeaderClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{
+						MemberListResponse: &clientv3.MemberListResponse{
+							Members: []*pb.Member{
+								{Name: "machine-node", ID: uint64(101)},
+								{Name: "leader-node", ID: uint64(102)},
+							},
+						},
+						AlarmResponse: &clientv3.AlarmResponse{
+							Alarms: []*pb.AlarmMember{},
+						},
+					},
+					LeaderID: tt.leaderID,
+				},
+				forLeaderErr: tt.etcdClientErr,
+			}
+
+			w := &Workload{
+				Client: &fakeClient{list: &corev1.NodeList{
+					Items: []corev1.Node{nodeNamed("machine-node"), nodeNamed("leader-node")},
+				}},
+				etcdClientGenerator: etcdClientGenerator,
+			}
+			leader, err := w.EtcdLeader(ctx)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(leader).To(Equal(tt.expectedLeader))
+		})
+	}
+}
+
 func TestReconcileEtcdMembers(t *testing.T) {
 	kubeadmConfig := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
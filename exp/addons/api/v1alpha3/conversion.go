@@ -17,20 +17,43 @@ limitations under the License.
 package v1alpha3
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	v1beta1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *ClusterResourceSet) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.ClusterResourceSet)
 
-	return Convert_v1alpha3_ClusterResourceSet_To_v1beta1_ClusterResourceSet(src, dst, nil)
+	if err := Convert_v1alpha3_ClusterResourceSet_To_v1beta1_ClusterResourceSet(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.ClusterResourceSet{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Prune = restored.Spec.Prune
+	dst.Spec.DeletionPolicy = restored.Spec.DeletionPolicy
+	dst.Spec.RenderTemplates = restored.Spec.RenderTemplates
+	dst.Spec.Phases = restored.Spec.Phases
+	dst.Spec.DependsOn = restored.Spec.DependsOn
+
+	return nil
 }
 
 func (dst *ClusterResourceSet) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.ClusterResourceSet)
 
-	return Convert_v1beta1_ClusterResourceSet_To_v1alpha3_ClusterResourceSet(src, dst, nil)
+	if err := Convert_v1beta1_ClusterResourceSet_To_v1alpha3_ClusterResourceSet(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 func (src *ClusterResourceSetList) ConvertTo(dstRaw conversion.Hub) error {
@@ -48,13 +71,41 @@ func (dst *ClusterResourceSetList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *ClusterResourceSetBinding) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.ClusterResourceSetBinding)
 
-	return Convert_v1alpha3_ClusterResourceSetBinding_To_v1beta1_ClusterResourceSetBinding(src, dst, nil)
+	if err := Convert_v1alpha3_ClusterResourceSetBinding_To_v1beta1_ClusterResourceSetBinding(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.ClusterResourceSetBinding{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	for _, binding := range dst.Spec.Bindings {
+		for _, restoredBinding := range restored.Spec.Bindings {
+			if binding == nil || restoredBinding == nil || binding.ClusterResourceSetName != restoredBinding.ClusterResourceSetName {
+				continue
+			}
+			for i := range binding.Resources {
+				if restoredResource := restoredBinding.GetResource(binding.Resources[i].ResourceRef); restoredResource != nil {
+					binding.Resources[i].AppliedObjects = restoredResource.AppliedObjects
+				}
+			}
+		}
+	}
+
+	return nil
 }
 
 func (dst *ClusterResourceSetBinding) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.ClusterResourceSetBinding)
 
-	return Convert_v1beta1_ClusterResourceSetBinding_To_v1alpha3_ClusterResourceSetBinding(src, dst, nil)
+	if err := Convert_v1beta1_ClusterResourceSetBinding_To_v1alpha3_ClusterResourceSetBinding(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 func (src *ClusterResourceSetBindingList) ConvertTo(dstRaw conversion.Hub) error {
@@ -68,3 +119,49 @@ func (dst *ClusterResourceSetBindingList) ConvertFrom(srcRaw conversion.Hub) err
 
 	return Convert_v1beta1_ClusterResourceSetBindingList_To_v1alpha3_ClusterResourceSetBindingList(src, dst, nil)
 }
+
+func Convert_v1beta1_ClusterResourceSetSpec_To_v1alpha3_ClusterResourceSetSpec(in *v1beta1.ClusterResourceSetSpec, out *ClusterResourceSetSpec, s apiconversion.Scope) error {
+	// spec.prune, spec.deletionPolicy, spec.renderTemplates, spec.phases and spec.dependsOn have been added with v1beta1.
+	return autoConvert_v1beta1_ClusterResourceSetSpec_To_v1alpha3_ClusterResourceSetSpec(in, out, s)
+}
+
+func Convert_v1beta1_ResourceBinding_To_v1alpha3_ResourceBinding(in *v1beta1.ResourceBinding, out *ResourceBinding, s apiconversion.Scope) error {
+	// appliedObjects has been added with v1beta1.
+	return autoConvert_v1beta1_ResourceBinding_To_v1alpha3_ResourceBinding(in, out, s)
+}
+
+func Convert_v1alpha3_ClusterResourceSetBindingSpec_To_v1beta1_ClusterResourceSetBindingSpec(in *ClusterResourceSetBindingSpec, out *v1beta1.ClusterResourceSetBindingSpec, s apiconversion.Scope) error {
+	if in.Bindings == nil {
+		out.Bindings = nil
+		return nil
+	}
+	out.Bindings = make([]*v1beta1.ResourceSetBinding, len(in.Bindings))
+	for i := range in.Bindings {
+		if in.Bindings[i] == nil {
+			continue
+		}
+		out.Bindings[i] = &v1beta1.ResourceSetBinding{}
+		if err := Convert_v1alpha3_ResourceSetBinding_To_v1beta1_ResourceSetBinding(in.Bindings[i], out.Bindings[i], s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Convert_v1beta1_ClusterResourceSetBindingSpec_To_v1alpha3_ClusterResourceSetBindingSpec(in *v1beta1.ClusterResourceSetBindingSpec, out *ClusterResourceSetBindingSpec, s apiconversion.Scope) error {
+	if in.Bindings == nil {
+		out.Bindings = nil
+		return nil
+	}
+	out.Bindings = make([]*ResourceSetBinding, len(in.Bindings))
+	for i := range in.Bindings {
+		if in.Bindings[i] == nil {
+			continue
+		}
+		out.Bindings[i] = &ResourceSetBinding{}
+		if err := Convert_v1beta1_ResourceSetBinding_To_v1alpha3_ResourceSetBinding(in.Bindings[i], out.Bindings[i], s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
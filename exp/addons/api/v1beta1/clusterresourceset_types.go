@@ -45,13 +45,102 @@ type ClusterResourceSetSpec struct {
 	Resources []ResourceRef `json:"resources,omitempty"`
 
 	// Strategy is the strategy to be used during applying resources. Defaults to ApplyOnce. This field is immutable.
-	// +kubebuilder:validation:Enum=ApplyOnce
+	// +kubebuilder:validation:Enum=ApplyOnce;Reconcile
 	// +optional
 	Strategy string `json:"strategy,omitempty"`
+
+	// Prune defines if the objects removed from the resources, or belonging to resources removed from
+	// the ClusterResourceSet, should be deleted from the clusters. Only the objects created by the ClusterResourceSet
+	// are deleted, while objects that already existed in a cluster when first applied are left in the cluster.
+	// It can be enabled only with the Reconcile strategy.
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+
+	// DeletionPolicy defines what happens to the objects applied to a cluster when the ClusterResourceSet is deleted
+	// or the cluster does not match the ClusterSelector anymore. Defaults to Orphan, that leaves the objects in the cluster;
+	// Delete deletes the objects tracked in the ClusterResourceSetBinding, i.e. the objects created by the ClusterResourceSet.
+	// +kubebuilder:validation:Enum=Orphan;Delete
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// RenderTemplates defines if the resources should be rendered as Go templates before being applied to a cluster.
+	// Templates have access to the Cluster name, namespace, labels, annotations, clusterNetwork and controlPlaneEndpoint,
+	// e.g. {{ .Cluster.Name }} or {{ index .Cluster.ClusterNetwork.Pods.CIDRBlocks 0 }}, and to the sprig functions.
+	// +optional
+	RenderTemplates bool `json:"renderTemplates,omitempty"`
+
+	// Phases is a list of groups of resources applied in order after Resources; the resources of a phase are applied
+	// only when the resources of the previous phases are applied and their readiness checks are satisfied.
+	// +optional
+	Phases []ClusterResourceSetPhase `json:"phases,omitempty"`
+
+	// DependsOn is a list of names of ClusterResourceSets, in the same namespace, that must be completed for a cluster
+	// before applying the resources of this ClusterResourceSet to that cluster. A ClusterResourceSet is completed when all
+	// its resources are applied and all its readiness checks are satisfied; ClusterResourceSets not matching the cluster are ignored.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // ANCHOR_END: ClusterResourceSetSpec
 
+// ClusterResourceSetPhase is a group of resources applied together.
+type ClusterResourceSetPhase struct {
+	// Name of the phase.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Resources is a list of Secrets/ConfigMaps where each contains 1 or more resources to be applied to remote clusters.
+	// +optional
+	Resources []ResourceRef `json:"resources,omitempty"`
+
+	// ReadinessChecks is a list of objects in the remote clusters that must be ready before applying the next phases.
+	// +optional
+	ReadinessChecks []ReadinessCheck `json:"readinessChecks,omitempty"`
+}
+
+// ReadinessCheck identifies an object in a remote cluster that must be ready.
+type ReadinessCheck struct {
+	// APIVersion of the object.
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object.
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Namespace of the object; empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ConditionType is the type of the condition in the object status that must be True.
+	// If not set, Available is used for Deployments and APIServices, Established for CustomResourceDefinitions,
+	// and only the existence of the object is checked for the other kinds.
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+}
+
+// GetPhases returns the phases of the ClusterResourceSet, starting with a phase for the resources in spec.resources.
+func (c *ClusterResourceSetSpec) GetPhases() []ClusterResourceSetPhase {
+	phases := make([]ClusterResourceSetPhase, 0, len(c.Phases)+1)
+	if len(c.Resources) > 0 {
+		phases = append(phases, ClusterResourceSetPhase{Resources: c.Resources})
+	}
+	return append(phases, c.Phases...)
+}
+
+// GetResources returns the resources in spec.resources and in all the phases of the ClusterResourceSet.
+func (c *ClusterResourceSetSpec) GetResources() []ResourceRef {
+	resources := append([]ResourceRef{}, c.Resources...)
+	for _, phase := range c.Phases {
+		resources = append(resources, phase.Resources...)
+	}
+	return resources
+}
+
 // ClusterResourceSetResourceKind is a string representation of a ClusterResourceSet resource kind.
 type ClusterResourceSetResourceKind string
 
@@ -79,6 +168,23 @@ const (
 	// ClusterResourceSetStrategyApplyOnce is the default strategy a ClusterResourceSet strategy is assigned by
 	// ClusterResourceSet controller after being created if not specified by user.
 	ClusterResourceSetStrategyApplyOnce ClusterResourceSetStrategy = "ApplyOnce"
+
+	// ClusterResourceSetStrategyReconcile is the strategy where resources are applied again, using server-side apply,
+	// every time their content changes and periodically, so changes made to the applied objects in the clusters are reverted.
+	ClusterResourceSetStrategyReconcile ClusterResourceSetStrategy = "Reconcile"
+)
+
+// ClusterResourceSetDeletionPolicy is a string representation of a ClusterResourceSet deletion policy.
+type ClusterResourceSetDeletionPolicy string
+
+const (
+	// ClusterResourceSetDeletionPolicyOrphan is the default deletion policy, where the objects applied to a cluster
+	// are left in the cluster.
+	ClusterResourceSetDeletionPolicyOrphan ClusterResourceSetDeletionPolicy = "Orphan"
+
+	// ClusterResourceSetDeletionPolicyDelete is the deletion policy where the objects applied to a cluster are deleted
+	// from the cluster.
+	ClusterResourceSetDeletionPolicyDelete ClusterResourceSetDeletionPolicy = "Delete"
 )
 
 // SetTypedStrategy sets the Strategy field to the string representation of ClusterResourceSetStrategy.
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestGetPhasesAndResources(t *testing.T) {
+	g := NewWithT(t)
+
+	crd := ResourceRef{Name: "crd", Kind: "ConfigMap"}
+	operator := ResourceRef{Name: "operator", Kind: "ConfigMap"}
+	cr := ResourceRef{Name: "cr", Kind: "Secret"}
+
+	spec := &ClusterResourceSetSpec{}
+	g.Expect(spec.GetPhases()).To(BeEmpty())
+	g.Expect(spec.GetResources()).To(BeEmpty())
+
+	spec.Resources = []ResourceRef{crd}
+	g.Expect(spec.GetPhases()).To(Equal([]ClusterResourceSetPhase{{Resources: []ResourceRef{crd}}}))
+	g.Expect(spec.GetResources()).To(Equal([]ResourceRef{crd}))
+
+	spec.Phases = []ClusterResourceSetPhase{
+		{Name: "operator", Resources: []ResourceRef{operator}},
+		{Name: "cr", Resources: []ResourceRef{cr}},
+	}
+	g.Expect(spec.GetPhases()).To(Equal([]ClusterResourceSetPhase{
+		{Resources: []ResourceRef{crd}},
+		{Name: "operator", Resources: []ResourceRef{operator}},
+		{Name: "cr", Resources: []ResourceRef{cr}},
+	}))
+	g.Expect(spec.GetResources()).To(Equal([]ResourceRef{crd, operator, cr}))
+	g.Expect(spec.Resources).To(Equal([]ResourceRef{crd}))
+}
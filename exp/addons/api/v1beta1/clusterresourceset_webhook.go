@@ -46,6 +46,10 @@ func (m *ClusterResourceSet) Default() {
 	if m.Spec.Strategy == "" {
 		m.Spec.Strategy = string(ClusterResourceSetStrategyApplyOnce)
 	}
+	// ClusterResourceSet DeletionPolicy defaults to Orphan.
+	if m.Spec.DeletionPolicy == "" {
+		m.Spec.DeletionPolicy = string(ClusterResourceSetDeletionPolicyOrphan)
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
@@ -94,6 +98,59 @@ func (m *ClusterResourceSet) validate(old *ClusterResourceSet) error {
 		)
 	}
 
+	if m.Spec.Prune && m.Spec.Strategy != string(ClusterResourceSetStrategyReconcile) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "prune"), m.Spec.Prune, fmt.Sprintf("can be enabled only with the %s strategy", ClusterResourceSetStrategyReconcile)),
+		)
+	}
+
+	phaseNames := map[string]bool{}
+	for i, phase := range m.Spec.Phases {
+		if phaseNames[phase.Name] {
+			allErrs = append(
+				allErrs,
+				field.Duplicate(field.NewPath("spec", "phases").Index(i).Child("name"), phase.Name),
+			)
+		}
+		phaseNames[phase.Name] = true
+	}
+
+	// A resource can be applied only once, so it cannot be listed both in spec.resources and in a phase, or in two phases.
+	resourcePaths := map[ResourceRef]*field.Path{}
+	for i, resource := range m.Spec.Resources {
+		resourcePaths[resource] = field.NewPath("spec", "resources").Index(i)
+	}
+	for i, phase := range m.Spec.Phases {
+		phasePaths := map[ResourceRef]*field.Path{}
+		for j, resource := range phase.Resources {
+			path := field.NewPath("spec", "phases").Index(i).Child("resources").Index(j)
+			if otherPath, ok := resourcePaths[resource]; ok {
+				allErrs = append(
+					allErrs,
+					field.Invalid(path, resource, fmt.Sprintf("resource is already listed in %s", otherPath)),
+				)
+			}
+			if _, ok := phasePaths[resource]; !ok {
+				phasePaths[resource] = path
+			}
+		}
+		for resource, path := range phasePaths {
+			if _, ok := resourcePaths[resource]; !ok {
+				resourcePaths[resource] = path
+			}
+		}
+	}
+
+	for i, name := range m.Spec.DependsOn {
+		if name == m.Name {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "dependsOn").Index(i), name, "a ClusterResourceSet cannot depend on itself"),
+			)
+		}
+	}
+
 	if old != nil && !reflect.DeepEqual(old.Spec.ClusterSelector, m.Spec.ClusterSelector) {
 		allErrs = append(
 			allErrs,
@@ -36,6 +36,7 @@ func TestClusterResourcesetDefault(t *testing.T) {
 	clusterResourceSet.Default()
 
 	g.Expect(clusterResourceSet.Spec.Strategy).To(Equal(string(ClusterResourceSetStrategyApplyOnce)))
+	g.Expect(clusterResourceSet.Spec.DeletionPolicy).To(Equal(string(ClusterResourceSetDeletionPolicyOrphan)))
 }
 
 func TestClusterResourceSetLabelSelectorAsSelectorValidation(t *testing.T) {
@@ -133,6 +134,56 @@ func TestClusterResourceSetStrategyImmutable(t *testing.T) {
 	}
 }
 
+func TestClusterResourceSetPruneValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  string
+		prune     bool
+		expectErr bool
+	}{
+		{
+			name:      "should not return error when prune is enabled with the Reconcile strategy",
+			strategy:  string(ClusterResourceSetStrategyReconcile),
+			prune:     true,
+			expectErr: false,
+		},
+		{
+			name:      "should not return error when prune is disabled with the ApplyOnce strategy",
+			strategy:  string(ClusterResourceSetStrategyApplyOnce),
+			prune:     false,
+			expectErr: false,
+		},
+		{
+			name:      "should return error when prune is enabled with the ApplyOnce strategy",
+			strategy:  string(ClusterResourceSetStrategyApplyOnce),
+			prune:     true,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterResourceSet := &ClusterResourceSet{
+				Spec: ClusterResourceSetSpec{
+					ClusterSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"foo": "bar"},
+					},
+					Strategy: tt.strategy,
+					Prune:    tt.prune,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterResourceSet.ValidateCreate()).NotTo(Succeed())
+				g.Expect(clusterResourceSet.ValidateUpdate(clusterResourceSet)).NotTo(Succeed())
+			} else {
+				g.Expect(clusterResourceSet.ValidateCreate()).To(Succeed())
+				g.Expect(clusterResourceSet.ValidateUpdate(clusterResourceSet)).To(Succeed())
+			}
+		})
+	}
+}
+
 func TestClusterResourceSetClusterSelectorImmutable(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -190,3 +241,77 @@ func TestClusterResourceSetSelectorNotEmptyValidation(t *testing.T) {
 	g.Expect(err).ToNot(BeNil())
 	g.Expect(err.Error()).To(ContainSubstring("selector must not be empty"))
 }
+
+func TestClusterResourceSetPhasesAndDependsOnValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []ResourceRef
+		phases    []ClusterResourceSetPhase
+		dependsOn []string
+		expectErr bool
+	}{
+		{
+			name:      "should not return error for phases with different names and dependencies on other ClusterResourceSets",
+			phases:    []ClusterResourceSetPhase{{Name: "crds"}, {Name: "crs"}},
+			dependsOn: []string{"cni"},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for phases with the same name",
+			phases:    []ClusterResourceSetPhase{{Name: "crds"}, {Name: "crds"}},
+			expectErr: true,
+		},
+		{
+			name:      "should not return error for a resource listed more than once in the same phase",
+			phases:    []ClusterResourceSetPhase{{Name: "crds", Resources: []ResourceRef{{Name: "crds", Kind: "ConfigMap"}, {Name: "crds", Kind: "ConfigMap"}}}},
+			expectErr: false,
+		},
+		{
+			name:      "should not return error for resources with the same name and a different kind in different phases",
+			resources: []ResourceRef{{Name: "calico", Kind: "ConfigMap"}},
+			phases:    []ClusterResourceSetPhase{{Name: "crds", Resources: []ResourceRef{{Name: "calico", Kind: "Secret"}}}},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for a resource listed both in resources and in a phase",
+			resources: []ResourceRef{{Name: "calico", Kind: "ConfigMap"}},
+			phases:    []ClusterResourceSetPhase{{Name: "crds", Resources: []ResourceRef{{Name: "calico", Kind: "ConfigMap"}}}},
+			expectErr: true,
+		},
+		{
+			name: "should return error for a resource listed in two phases",
+			phases: []ClusterResourceSetPhase{
+				{Name: "crds", Resources: []ResourceRef{{Name: "crds", Kind: "ConfigMap"}}},
+				{Name: "crs", Resources: []ResourceRef{{Name: "crs", Kind: "ConfigMap"}, {Name: "crds", Kind: "ConfigMap"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for a dependency on itself",
+			dependsOn: []string{"cni", "test-crs"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterResourceSet := &ClusterResourceSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-crs"},
+				Spec: ClusterResourceSetSpec{
+					ClusterSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"foo": "bar"},
+					},
+					Resources: tt.resources,
+					Phases:    tt.phases,
+					DependsOn: tt.dependsOn,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterResourceSet.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(clusterResourceSet.ValidateCreate()).To(Succeed())
+			}
+		})
+	}
+}
@@ -30,7 +30,8 @@ type ResourceBinding struct {
 	ResourceRef `json:",inline"`
 
 	// Hash is the hash of a resource's data. This can be used to decide if a resource is changed.
-	// For "ApplyOnce" ClusterResourceSet.spec.strategy, this is no-op as that strategy does not act on change.
+	// For "ApplyOnce" ClusterResourceSet.spec.strategy, this is no-op as that strategy does not act on change;
+	// for "Reconcile" ClusterResourceSet.spec.strategy, the resource is applied again when the hash changes.
 	// +optional
 	Hash string `json:"hash,omitempty"`
 
@@ -40,6 +41,28 @@ type ResourceBinding struct {
 
 	// Applied is to track if a resource is applied to the cluster or not.
 	Applied bool `json:"applied"`
+
+	// AppliedObjects is the list of the objects in the resource's data that have been applied to the cluster.
+	// With the "ApplyOnce" ClusterResourceSet.spec.strategy only the objects created by the ClusterResourceSet are tracked,
+	// while objects already existing in the cluster are not.
+	// +optional
+	AppliedObjects []AppliedObjectRef `json:"appliedObjects,omitempty"`
+}
+
+// AppliedObjectRef identifies an object applied to a cluster from a ClusterResourceSet resource.
+type AppliedObjectRef struct {
+	// APIVersion of the object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object.
+	Kind string `json:"kind"`
+
+	// Namespace of the object; empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
 }
 
 // ANCHOR_END: ResourceBinding
@@ -66,6 +89,26 @@ func (r *ResourceSetBinding) IsApplied(resourceRef ResourceRef) bool {
 	return false
 }
 
+// GetResource returns a copy of the ResourceBinding for a resource, if any.
+func (r *ResourceSetBinding) GetResource(resourceRef ResourceRef) *ResourceBinding {
+	for i := range r.Resources {
+		if reflect.DeepEqual(r.Resources[i].ResourceRef, resourceRef) {
+			return r.Resources[i].DeepCopy()
+		}
+	}
+	return nil
+}
+
+// DeleteResource removes the ResourceBinding for a resource, if any.
+func (r *ResourceSetBinding) DeleteResource(resourceRef ResourceRef) {
+	for i := range r.Resources {
+		if reflect.DeepEqual(r.Resources[i].ResourceRef, resourceRef) {
+			r.Resources = append(r.Resources[:i], r.Resources[i+1:]...)
+			return
+		}
+	}
+}
+
 // SetBinding sets resourceBinding for a resource in resourceSetbinding either by updating the existing one or
 // creating a new one.
 func (r *ResourceSetBinding) SetBinding(resourceBinding ResourceBinding) {
@@ -91,6 +134,16 @@ func (c *ClusterResourceSetBinding) GetOrCreateBinding(clusterResourceSet *Clust
 	return binding
 }
 
+// GetBinding returns the ResourceSetBinding for a given ClusterResourceSet, if any.
+func (c *ClusterResourceSetBinding) GetBinding(clusterResourceSet *ClusterResourceSet) *ResourceSetBinding {
+	for _, binding := range c.Spec.Bindings {
+		if binding.ClusterResourceSetName == clusterResourceSet.Name {
+			return binding
+		}
+	}
+	return nil
+}
+
 // DeleteBinding removes the ClusterResourceSet from the ClusterResourceSetBinding Bindings list.
 func (c *ClusterResourceSetBinding) DeleteBinding(clusterResourceSet *ClusterResourceSet) {
 	for i, binding := range c.Spec.Bindings {
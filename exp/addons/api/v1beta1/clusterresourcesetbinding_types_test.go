@@ -157,3 +157,64 @@ func TestSetResourceBinding(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAndDeleteResource(t *testing.T) {
+	g := NewWithT(t)
+
+	resourceRef := ResourceRef{
+		Name: "applied",
+		Kind: "ConfigMap",
+	}
+	resourceRefNotExist := ResourceRef{
+		Name: "notExist",
+		Kind: "ConfigMap",
+	}
+	CRSBinding := &ResourceSetBinding{
+		ClusterResourceSetName: "test-clusterResourceSet",
+		Resources: []ResourceBinding{
+			{
+				ResourceRef: resourceRef,
+				Applied:     true,
+				Hash:        "xyz",
+				AppliedObjects: []AppliedObjectRef{
+					{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "foo"},
+				},
+			},
+		},
+	}
+
+	resourceBinding := CRSBinding.GetResource(resourceRef)
+	g.Expect(resourceBinding).ToNot(BeNil())
+	g.Expect(resourceBinding.Hash).To(Equal("xyz"))
+	g.Expect(resourceBinding.AppliedObjects).To(HaveLen(1))
+
+	// Changes to the returned copy should not affect the binding.
+	resourceBinding.AppliedObjects[0].Name = "bar"
+	g.Expect(CRSBinding.Resources[0].AppliedObjects[0].Name).To(Equal("foo"))
+
+	g.Expect(CRSBinding.GetResource(resourceRefNotExist)).To(BeNil())
+
+	CRSBinding.DeleteResource(resourceRefNotExist)
+	g.Expect(CRSBinding.Resources).To(HaveLen(1))
+
+	CRSBinding.DeleteResource(resourceRef)
+	g.Expect(CRSBinding.Resources).To(BeEmpty())
+}
+
+func TestGetBinding(t *testing.T) {
+	g := NewWithT(t)
+
+	crs := &ClusterResourceSet{ObjectMeta: metav1.ObjectMeta{Name: "test-clusterResourceSet"}}
+	crsNotBound := &ClusterResourceSet{ObjectMeta: metav1.ObjectMeta{Name: "notBound"}}
+	binding := &ClusterResourceSetBinding{
+		Spec: ClusterResourceSetBindingSpec{
+			Bindings: []*ResourceSetBinding{
+				{ClusterResourceSetName: "test-clusterResourceSet"},
+			},
+		},
+	}
+
+	g.Expect(binding.GetBinding(crs)).To(Equal(binding.Spec.Bindings[0]))
+	g.Expect(binding.GetBinding(crsNotBound)).To(BeNil())
+	g.Expect(binding.Spec.Bindings).To(HaveLen(1))
+}
@@ -39,4 +39,28 @@ const (
 
 	// WrongSecretTypeReason (Severity=Warning) documents at least one of the Secret's type in the resource list is not supported.
 	WrongSecretTypeReason = "WrongSecretType"
+
+	// TemplateRenderFailedReason (Severity=Warning) documents at least one of the resources cannot be rendered as a template
+	// for one of the matching clusters.
+	TemplateRenderFailedReason = "TemplateRenderFailed"
+
+	// WaitingForDependenciesReason (Severity=Info) documents at least one of the ClusterResourceSets in dependsOn
+	// is not completed yet for one of the matching clusters.
+	WaitingForDependenciesReason = "WaitingForDependencies"
+
+	// DependencyCycleReason (Severity=Warning) documents the ClusterResourceSets in dependsOn, walked transitively,
+	// form a cycle, so they can never be completed.
+	DependencyCycleReason = "DependencyCycle"
+
+	// WaitingForReadinessReason (Severity=Info) documents at least one of the readiness checks of a phase is not
+	// satisfied yet for one of the matching clusters.
+	WaitingForReadinessReason = "WaitingForReadiness"
+
+	// ReadinessCheckFailedReason (Severity=Warning) documents a failure while checking the readiness of an object
+	// in one of the matching clusters.
+	ReadinessCheckFailedReason = "ReadinessCheckFailed"
+
+	// DeletingObjectsFailedReason (Severity=Warning) documents a failure deleting the objects applied to one of the clusters
+	// with the Delete deletion policy, e.g. because the cluster is not reachable.
+	DeletingObjectsFailedReason = "DeletingObjectsFailed"
 )
@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
 	resourcepredicates "sigs.k8s.io/cluster-api/exp/addons/controllers/predicates"
@@ -54,6 +55,14 @@ var (
 	ErrSecretTypeNotSupported = errors.New("unsupported secret type")
 )
 
+// orphanObjectsTimeout is how long a deleted ClusterResourceSet with the Delete deletion policy keeps trying to delete
+// the objects applied to a cluster that is not reachable, before leaving them in the cluster.
+const orphanObjectsTimeout = 10 * time.Minute
+
+// resyncPeriod is how often the resources of a ClusterResourceSet with the Reconcile strategy are applied again even if
+// they are not changed, so changes made by hand to the objects in the clusters are reverted.
+const resyncPeriod = 10 * time.Minute
+
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=addons.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
@@ -78,7 +87,7 @@ func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr
 			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet),
 			builder.OnlyMetadata,
 			builder.WithPredicates(
-				resourcepredicates.ResourceCreate(ctrl.LoggerFrom(ctx)),
+				resourcepredicates.ResourceCreateOrUpdate(ctrl.LoggerFrom(ctx)),
 			),
 		).
 		Watches(
@@ -86,7 +95,7 @@ func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr
 			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet),
 			builder.OnlyMetadata,
 			builder.WithPredicates(
-				resourcepredicates.ResourceCreate(ctrl.LoggerFrom(ctx)),
+				resourcepredicates.ResourceCreateOrUpdate(ctrl.LoggerFrom(ctx)),
 			),
 		).
 		WithOptions(options).
@@ -143,58 +152,197 @@ func (r *ClusterResourceSetReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	// Handle deletion reconciliation loop.
 	if !clusterResourceSet.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, clusters, clusterResourceSet)
+		return r.reconcileDelete(ctx, clusterResourceSet)
+	}
+
+	// NOTE: failures deleting the objects applied to the clusters not matching anymore do not block applying the
+	// resources to the matching clusters; the failure is reported in the condition once all the clusters are reconciled.
+	unmatchedErr := r.reconcileUnmatchedClusters(ctx, clusters, clusterResourceSet)
+	var unmatchedCondition *clusterv1.Condition
+	if unmatchedErr != nil {
+		unmatchedCondition = conditions.Get(clusterResourceSet, addonsv1.ResourcesAppliedCondition).DeepCopy()
 	}
 
+	result := ctrl.Result{}
+	var waitingCondition *clusterv1.Condition
 	for _, cluster := range clusters {
-		if err := r.ApplyClusterResourceSet(ctx, cluster, clusterResourceSet); err != nil {
-			return ctrl.Result{}, err
+		clusterResult, err := r.ApplyClusterResourceSet(ctx, cluster, clusterResourceSet)
+		if err != nil {
+			return ctrl.Result{}, kerrors.NewAggregate([]error{unmatchedErr, err})
 		}
+		if !clusterResult.IsZero() && !conditions.IsTrue(clusterResourceSet, addonsv1.ResourcesAppliedCondition) {
+			// Keep the condition reporting what the ClusterResourceSet is waiting for, so it is not overridden by the next clusters.
+			waitingCondition = conditions.Get(clusterResourceSet, addonsv1.ResourcesAppliedCondition).DeepCopy()
+		}
+		result = util.LowestNonZeroResult(result, clusterResult)
+	}
+	if waitingCondition != nil {
+		conditions.Set(clusterResourceSet, waitingCondition)
+	}
+	if unmatchedErr != nil {
+		if unmatchedCondition != nil {
+			conditions.Set(clusterResourceSet, unmatchedCondition)
+		}
+		return ctrl.Result{}, unmatchedErr
 	}
 
+	return result, nil
+}
+
+// reconcileDelete removes the deleted ClusterResourceSet from all the ClusterResourceSetBindings it is added to;
+// if the deletion policy is Delete, the objects applied to the clusters are deleted first.
+func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, crs *addonsv1.ClusterResourceSet) (ctrl.Result, error) {
+	clusterResourceSetBindings, err := r.getClusterResourceSetBindings(ctx, crs)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get ClusterResourceSetBindings during ClusterResourceSet deletion")
+	}
+
+	errList := []error{}
+	for _, clusterResourceSetBinding := range clusterResourceSetBindings {
+		if err := r.unbindCluster(ctx, clusterResourceSetBinding, crs); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if len(errList) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errList)
+	}
+
+	controllerutil.RemoveFinalizer(crs, addonsv1.ClusterResourceSetFinalizer)
 	return ctrl.Result{}, nil
 }
 
-// reconcileDelete removes the deleted ClusterResourceSet from all the ClusterResourceSetBindings it is added to.
-func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, clusters []*clusterv1.Cluster, crs *addonsv1.ClusterResourceSet) (ctrl.Result, error) {
-	log := ctrl.LoggerFrom(ctx)
+// reconcileUnmatchedClusters removes the ClusterResourceSet from the ClusterResourceSetBindings of the clusters that
+// do not match the ClusterResourceSet anymore, deleting the objects applied to them; this is done only if the deletion policy is Delete.
+func (r *ClusterResourceSetReconciler) reconcileUnmatchedClusters(ctx context.Context, clusters []*clusterv1.Cluster, crs *addonsv1.ClusterResourceSet) error {
+	if crs.Spec.DeletionPolicy != string(addonsv1.ClusterResourceSetDeletionPolicyDelete) {
+		return nil
+	}
 
+	clusterResourceSetBindings, err := r.getClusterResourceSetBindings(ctx, crs)
+	if err != nil {
+		return err
+	}
+
+	matchingClusters := map[string]bool{}
 	for _, cluster := range clusters {
-		clusterResourceSetBinding := &addonsv1.ClusterResourceSetBinding{}
-		clusterResourceSetBindingKey := client.ObjectKey{
-			Namespace: cluster.Namespace,
-			Name:      cluster.Name,
-		}
-		if err := r.Client.Get(ctx, clusterResourceSetBindingKey, clusterResourceSetBinding); err != nil {
-			if !apierrors.IsNotFound(err) {
-				return ctrl.Result{}, errors.Wrapf(err, "failed to get ClusterResourceSetBinding during ClusterResourceSet deletion")
+		matchingClusters[cluster.Name] = true
+	}
+
+	errList := []error{}
+	for _, clusterResourceSetBinding := range clusterResourceSetBindings {
+		// NOTE: ClusterResourceSetBindings have the same name as the cluster they belong to.
+		if matchingClusters[clusterResourceSetBinding.Name] {
+			continue
+		}
+		if err := r.unbindCluster(ctx, clusterResourceSetBinding, crs); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// unbindCluster removes the ClusterResourceSet from a ClusterResourceSetBinding, deleting the binding if it does not have other
+// ClusterResourceSets; if the deletion policy is Delete, the objects applied to the cluster are deleted first, unless the cluster is being deleted.
+// If the objects cannot be deleted, e.g. because the cluster is not reachable, they are left in the cluster when the infrastructure
+// of the cluster is gone, or when the ClusterResourceSet has been deleted for more than orphanObjectsTimeout.
+func (r *ClusterResourceSetReconciler) unbindCluster(ctx context.Context, clusterResourceSetBinding *addonsv1.ClusterResourceSetBinding, crs *addonsv1.ClusterResourceSet) error {
+	log := ctrl.LoggerFrom(ctx, "cluster", clusterResourceSetBinding.Name)
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
+	if err != nil {
+		return err
+	}
+
+	resourceSetBinding := clusterResourceSetBinding.GetBinding(crs)
+	if resourceSetBinding != nil && crs.Spec.DeletionPolicy == string(addonsv1.ClusterResourceSetDeletionPolicyDelete) {
+		cluster := &clusterv1.Cluster{}
+		clusterKey := client.ObjectKey{Namespace: clusterResourceSetBinding.Namespace, Name: clusterResourceSetBinding.Name}
+		if err := r.Client.Get(ctx, clusterKey, cluster); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get Cluster %s", clusterKey)
+		}
+
+		// If the cluster is deleted or being deleted, there is no need to delete the objects applied to it.
+		if cluster.Name != "" && cluster.DeletionTimestamp.IsZero() {
+			if err := r.deleteAppliedObjects(ctx, cluster, resourceSetBinding); err != nil {
+				orphanReason, orphanErr := r.getOrphanReason(ctx, cluster, crs)
+				if orphanReason == "" {
+					// Keep track of the resources whose objects are not deleted yet.
+					if patchErr := patchHelper.Patch(ctx, clusterResourceSetBinding); patchErr != nil {
+						log.Error(patchErr, "failed to patch ClusterResourceSetBinding")
+					}
+					err = errors.Wrapf(kerrors.NewAggregate([]error{err, orphanErr}), "failed to delete the objects applied by ClusterResourceSet %s to Cluster %s", crs.Name, cluster.Name)
+					conditions.MarkFalse(crs, addonsv1.ResourcesAppliedCondition, addonsv1.DeletingObjectsFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+					return err
+				}
+				log.Error(err, "Leaving in the cluster the objects applied by the ClusterResourceSet", "ClusterResourceSet", crs.Name, "reason", orphanReason)
 			}
-			controllerutil.RemoveFinalizer(crs, addonsv1.ClusterResourceSetFinalizer)
-			return ctrl.Result{}, nil
 		}
+	}
 
-		// Initialize the patch helper.
-		patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
-		if err != nil {
-			return ctrl.Result{}, err
+	clusterResourceSetBinding.DeleteBinding(crs)
+
+	// If CRS list is empty in the binding, delete the binding else
+	// attempt to Patch the ClusterResourceSetBinding object after delete reconciliation if there is at least 1 binding left.
+	if len(clusterResourceSetBinding.Spec.Bindings) == 0 {
+		if err := r.Client.Delete(ctx, clusterResourceSetBinding); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "failed to delete empty ClusterResourceSetBinding")
 		}
+	} else if err := patchHelper.Patch(ctx, clusterResourceSetBinding); err != nil {
+		log.Error(err, "failed to patch ClusterResourceSetBinding")
+		return err
+	}
+	return nil
+}
 
-		clusterResourceSetBinding.DeleteBinding(crs)
+// deleteAppliedObjects deletes from the cluster the objects applied for the resources in the ResourceSetBinding.
+func (r *ClusterResourceSetReconciler) deleteAppliedObjects(ctx context.Context, cluster *clusterv1.Cluster, resourceSetBinding *addonsv1.ResourceSetBinding) error {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 
-		// If CRS list is empty in the binding, delete the binding else
-		// attempt to Patch the ClusterResourceSetBinding object after delete reconciliation if there is at least 1 binding left.
-		if len(clusterResourceSetBinding.Spec.Bindings) == 0 {
-			if r.Client.Delete(ctx, clusterResourceSetBinding) != nil {
-				log.Error(err, "failed to delete empty ClusterResourceSetBinding")
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return err
+	}
+
+	log.Info("Deleting the objects applied by the ClusterResourceSet", "ClusterResourceSet", resourceSetBinding.ClusterResourceSetName)
+	return deleteAppliedObjects(ctx, remoteClient, resourceSetBinding)
+}
+
+// getOrphanReason returns why the objects applied to a cluster can be left in the cluster when they cannot be deleted, or an
+// empty string if they cannot: the objects are orphaned if the infrastructure of the cluster is gone, so the cluster is not going
+// to be reachable again, or if the ClusterResourceSet has been deleted for more than orphanObjectsTimeout, so a cluster that is not
+// reachable doesn't block the deletion forever.
+func (r *ClusterResourceSetReconciler) getOrphanReason(ctx context.Context, cluster *clusterv1.Cluster, crs *addonsv1.ClusterResourceSet) (string, error) {
+	if !crs.DeletionTimestamp.IsZero() && time.Since(crs.DeletionTimestamp.Time) >= orphanObjectsTimeout {
+		return fmt.Sprintf("the ClusterResourceSet has been deleted for more than %s", orphanObjectsTimeout), nil
+	}
+
+	if cluster.Spec.InfrastructureRef != nil {
+		if _, err := external.Get(ctx, r.Client, cluster.Spec.InfrastructureRef, cluster.Namespace); err != nil {
+			if apierrors.IsNotFound(errors.Cause(err)) {
+				return "the infrastructure of the cluster is gone", nil
 			}
-		} else if err := patchHelper.Patch(ctx, clusterResourceSetBinding); err != nil {
-			log.Error(err, "failed to patch ClusterResourceSetBinding")
-			return ctrl.Result{}, err
+			return "", err
 		}
 	}
+	return "", nil
+}
 
-	controllerutil.RemoveFinalizer(crs, addonsv1.ClusterResourceSetFinalizer)
-	return ctrl.Result{}, nil
+// getClusterResourceSetBindings returns the ClusterResourceSetBindings the ClusterResourceSet is added to.
+func (r *ClusterResourceSetReconciler) getClusterResourceSetBindings(ctx context.Context, crs *addonsv1.ClusterResourceSet) ([]*addonsv1.ClusterResourceSetBinding, error) {
+	clusterResourceSetBindingList := &addonsv1.ClusterResourceSetBindingList{}
+	if err := r.Client.List(ctx, clusterResourceSetBindingList, client.InNamespace(crs.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list ClusterResourceSetBindings")
+	}
+
+	clusterResourceSetBindings := []*addonsv1.ClusterResourceSetBinding{}
+	for i := range clusterResourceSetBindingList.Items {
+		clusterResourceSetBinding := &clusterResourceSetBindingList.Items[i]
+		if clusterResourceSetBinding.GetBinding(crs) != nil {
+			clusterResourceSetBindings = append(clusterResourceSetBindings, clusterResourceSetBinding)
+		}
+	}
+	return clusterResourceSetBindings, nil
 }
 
 // getClustersByClusterResourceSetSelector fetches Clusters matched by the ClusterResourceSet's label selector that are in the same namespace as the ClusterResourceSet object.
@@ -230,27 +378,33 @@ func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(c
 // ApplyClusterResourceSet applies resources in a ClusterResourceSet to a Cluster. Once applied, a record will be added to the
 // cluster's ClusterResourceSetBinding.
 // In ApplyOnce strategy, resources are applied only once to a particular cluster. ClusterResourceSetBinding is used to check if a resource is applied before.
+// In Reconcile strategy, resources are applied again using server-side apply when their hash changes, and anyway every resyncPeriod;
+// if prune is enabled, objects created by the ClusterResourceSet and removed from a resource, or belonging to resources removed from
+// the ClusterResourceSet, are deleted from the cluster.
+// Resources are applied in phases: the resources of a phase are applied only when the resources of the previous phases are applied
+// and their readiness checks are satisfied; the resources are applied only when the ClusterResourceSets in dependsOn are completed for the cluster.
+// When waiting for readiness checks or dependencies, a result requeuing the ClusterResourceSet is returned.
 // It applies resources best effort and continue on scenarios like: unsupported resource types, failure during creation, missing resources.
 // TODO: If a resource already exists in the cluster but not applied by ClusterResourceSet, the resource will be updated ?
-func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) error {
+func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 
 	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
 	if err != nil {
 		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.RemoteClusterClientFailedReason, clusterv1.ConditionSeverityError, err.Error())
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// Get ClusterResourceSetBinding object for the cluster.
 	clusterResourceSetBinding, err := r.getOrCreateClusterResourceSetBinding(ctx, cluster, clusterResourceSet)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper.
 	patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	defer func() {
@@ -260,106 +414,243 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 		}
 	}()
 
+	// If the ClusterResourceSet depends on other ClusterResourceSets, wait for them to be completed for the cluster.
+	pendingDependency, err := r.getPendingDependency(ctx, remoteClient, cluster, clusterResourceSet, clusterResourceSetBinding)
+	if err != nil {
+		var cycleErr *dependencyCycleError
+		if errors.As(err, &cycleErr) {
+			// The cycle can only be broken by changing the ClusterResourceSets, so report it and check again periodically.
+			log.Info("ClusterResourceSet dependencies form a cycle", "reason", cycleErr.Error())
+			conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.DependencyCycleReason, clusterv1.ConditionSeverityWarning, "Dependencies of Cluster %s cannot be completed: %s", cluster.Name, cycleErr.Error())
+			return ctrl.Result{RequeueAfter: waitingRequeueAfter}, nil
+		}
+		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ReadinessCheckFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+	if pendingDependency != "" {
+		log.Info("Waiting for ClusterResourceSet dependencies", "reason", pendingDependency)
+		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.WaitingForDependenciesReason, clusterv1.ConditionSeverityInfo, "Waiting for dependencies of Cluster %s: %s", cluster.Name, pendingDependency)
+		return ctrl.Result{RequeueAfter: waitingRequeueAfter}, nil
+	}
+
 	errList := []error{}
 	resourceSetBinding := clusterResourceSetBinding.GetOrCreateBinding(clusterResourceSet)
+	reconcileStrategy := clusterResourceSet.Spec.Strategy == string(addonsv1.ClusterResourceSetStrategyReconcile)
 
-	// Iterate all resources and apply them to the cluster and update the resource status in the ClusterResourceSetBinding object.
-	for _, resource := range clusterResourceSet.Spec.Resources {
-		// If resource is already applied successfully and clusterResourceSet mode is "ApplyOnce", continue. (No need to check hash changes here)
-		if resourceSetBinding.IsApplied(resource) {
-			continue
+	// If clusterResourceSet mode is "Reconcile", handle the resources removed from the ClusterResourceSet.
+	if reconcileStrategy {
+		if err := r.reconcileRemovedResources(ctx, remoteClient, clusterResourceSet, resourceSetBinding); err != nil {
+			conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			errList = append(errList, err)
 		}
+	}
 
-		unstructuredObj, err := r.getResource(ctx, resource, cluster.GetNamespace())
-		if err != nil {
-			if err == ErrSecretTypeNotSupported {
-				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.WrongSecretTypeReason, clusterv1.ConditionSeverityWarning, err.Error())
-			} else {
-				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.RetrievingResourceFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+	// Iterate all phases and apply their resources to the cluster and update the resource status in the ClusterResourceSetBinding object.
+	phases := clusterResourceSet.Spec.GetPhases()
+	for phaseIndex, phase := range phases {
+		for _, resource := range phase.Resources {
+			// If resource is already applied successfully and clusterResourceSet mode is "ApplyOnce", continue. (No need to check hash changes here)
+			if !reconcileStrategy && resourceSetBinding.IsApplied(resource) {
+				continue
+			}
+			previousBinding := resourceSetBinding.GetResource(resource)
+			var previousObjects []addonsv1.AppliedObjectRef
+			if previousBinding != nil {
+				previousObjects = previousBinding.AppliedObjects
+			}
 
-				// Continue without adding the error to the aggregate if we can't find the resource.
-				if apierrors.IsNotFound(err) {
-					continue
+			unstructuredObj, err := r.getResource(ctx, resource, cluster.GetNamespace())
+			if err != nil {
+				if err == ErrSecretTypeNotSupported {
+					conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.WrongSecretTypeReason, clusterv1.ConditionSeverityWarning, err.Error())
+				} else {
+					conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.RetrievingResourceFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+
+					// Continue without adding the error to the aggregate if we can't find the resource.
+					if apierrors.IsNotFound(err) {
+						continue
+					}
 				}
+				errList = append(errList, err)
+				continue
 			}
-			errList = append(errList, err)
-			continue
-		}
 
-		// Set status in ClusterResourceSetBinding in case of early continue due to a failure.
-		// Set only when resource is retrieved successfully.
-		resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
-			ResourceRef:     resource,
-			Hash:            "",
-			Applied:         false,
-			LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
-		})
+			// Set status in ClusterResourceSetBinding in case of early continue due to a failure.
+			// Set only when resource is retrieved successfully.
+			resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
+				ResourceRef:     resource,
+				Hash:            "",
+				Applied:         false,
+				LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
+				AppliedObjects:  previousObjects,
+			})
+
+			if err := r.patchOwnerRefToResource(ctx, clusterResourceSet, unstructuredObj); err != nil {
+				log.Error(err, "Failed to patch ClusterResourceSet as resource owner reference",
+					"Resource type", unstructuredObj.GetKind(), "Resource name", unstructuredObj.GetName())
+				errList = append(errList, err)
+			}
 
-		if err := r.patchOwnerRefToResource(ctx, clusterResourceSet, unstructuredObj); err != nil {
-			log.Error(err, "Failed to patch ClusterResourceSet as resource owner reference",
-				"Resource type", unstructuredObj.GetKind(), "Resource name", unstructuredObj.GetName())
-			errList = append(errList, err)
-		}
+			// Since maps are not ordered, we need to order them to get the same hash at each reconcile.
+			keys := make([]string, 0)
+			data, ok := unstructuredObj.UnstructuredContent()["data"]
+			if !ok {
+				errList = append(errList, errors.New("failed to get data field from the resource"))
+				continue
+			}
 
-		// Since maps are not ordered, we need to order them to get the same hash at each reconcile.
-		keys := make([]string, 0)
-		data, ok := unstructuredObj.UnstructuredContent()["data"]
-		if !ok {
-			errList = append(errList, errors.New("failed to get data field from the resource"))
-			continue
-		}
+			unstructuredData := data.(map[string]interface{})
+			for key := range unstructuredData {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
 
-		unstructuredData := data.(map[string]interface{})
-		for key := range unstructuredData {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
+			dataList := make([][]byte, 0)
+			for _, key := range keys {
+				val, ok, err := unstructured.NestedString(unstructuredData, key)
+				if !ok || err != nil {
+					errList = append(errList, errors.New("failed to get value field from the resource"))
+					continue
+				}
+
+				byteArr := []byte(val)
+				// If the resource is a Secret, data needs to be decoded.
+				if unstructuredObj.GetKind() == string(addonsv1.SecretClusterResourceSetResourceKind) {
+					byteArr, _ = base64.StdEncoding.DecodeString(val)
+				}
+
+				dataList = append(dataList, byteArr)
+			}
 
-		dataList := make([][]byte, 0)
-		for _, key := range keys {
-			val, ok, err := unstructured.NestedString(unstructuredData, key)
-			if !ok || err != nil {
-				errList = append(errList, errors.New("failed to get value field from the resource"))
+			// If templates are enabled, render the resource data with the values from the cluster.
+			if clusterResourceSet.Spec.RenderTemplates {
+				renderedDataList, err := renderTemplates(dataList, cluster)
+				if err != nil {
+					log.Error(err, "failed to render ClusterResourceSet resource", "Resource kind", resource.Kind, "Resource name", resource.Name)
+					conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.TemplateRenderFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+					errList = append(errList, err)
+					continue
+				}
+				dataList = renderedDataList
+			}
+
+			// If clusterResourceSet mode is "Reconcile" and the resource is already applied successfully, apply it again only if it is changed
+			// or if the resync period is elapsed, so changes made to the objects in the cluster are reverted.
+			hash := computeHash(dataList)
+			if previousBinding != nil && previousBinding.Applied && previousBinding.Hash == hash && !isResyncDue(previousBinding) {
+				resourceSetBinding.SetBinding(*previousBinding)
 				continue
 			}
 
-			byteArr := []byte(val)
-			// If the resource is a Secret, data needs to be decoded.
-			if unstructuredObj.GetKind() == string(addonsv1.SecretClusterResourceSetResourceKind) {
-				byteArr, _ = base64.StdEncoding.DecodeString(val)
+			// Apply all values in the key-value pair of the resource to the cluster.
+			// As there can be multiple key-value pairs in a resource, each value may have multiple objects in it.
+			isSuccessful := true
+			appliedObjects := []addonsv1.AppliedObjectRef{}
+			for i := range dataList {
+				objs, err := getObjects(dataList[i])
+				if err == nil {
+					var refs []addonsv1.AppliedObjectRef
+					if reconcileStrategy {
+						refs, err = serverSideApply(ctx, remoteClient, objs, previousObjects)
+					} else {
+						refs, err = apply(ctx, remoteClient, objs)
+					}
+					appliedObjects = append(appliedObjects, refs...)
+				}
+				if err != nil {
+					isSuccessful = false
+					log.Error(err, "failed to apply ClusterResourceSet resource", "Resource kind", resource.Kind, "Resource name", resource.Name)
+					conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+					errList = append(errList, err)
+				}
+			}
+
+			// If prune is enabled and the resource is applied successfully, delete the objects that are not in the resource anymore;
+			// otherwise keep tracking them.
+			pruned := false
+			if isSuccessful && reconcileStrategy && clusterResourceSet.Spec.Prune {
+				if err := prune(ctx, remoteClient, subtractAppliedObjectRefs(previousObjects, appliedObjects)); err != nil {
+					log.Error(err, "failed to prune ClusterResourceSet resource objects", "Resource kind", resource.Kind, "Resource name", resource.Name)
+					conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+					errList = append(errList, err)
+				} else {
+					pruned = true
+				}
+			}
+			if !pruned {
+				appliedObjects = mergeAppliedObjectRefs(appliedObjects, previousObjects)
 			}
 
-			dataList = append(dataList, byteArr)
+			resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
+				ResourceRef:     resource,
+				Hash:            hash,
+				Applied:         isSuccessful,
+				LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
+				AppliedObjects:  appliedObjects,
+			})
 		}
 
-		// Apply all values in the key-value pair of the resource to the cluster.
-		// As there can be multiple key-value pairs in a resource, each value may have multiple objects in it.
-		isSuccessful := true
-		for i := range dataList {
-			data := dataList[i]
+		if len(errList) > 0 {
+			break
+		}
 
-			if err := apply(ctx, remoteClient, data); err != nil {
-				isSuccessful = false
-				log.Error(err, "failed to apply ClusterResourceSet resource", "Resource kind", resource.Kind, "Resource name", resource.Name)
-				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
-				errList = append(errList, err)
-			}
+		// Some resources of the phase are missing, so the next phases cannot be applied yet;
+		// the ClusterResourceSet is reconciled again when the missing resources are created.
+		if phaseIndex < len(phases)-1 && !isPhaseApplied(resourceSetBinding, phase) {
+			return ctrl.Result{}, nil
 		}
 
-		resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
-			ResourceRef:     resource,
-			Hash:            computeHash(dataList),
-			Applied:         isSuccessful,
-			LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
-		})
+		message, err := checkReadiness(ctx, remoteClient, phase.ReadinessChecks)
+		if err != nil {
+			conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ReadinessCheckFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, err
+		}
+		if message != "" {
+			log.Info("Waiting for ClusterResourceSet phase readiness", "phase", phase.Name, "reason", message)
+			conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.WaitingForReadinessReason, clusterv1.ConditionSeverityInfo, "Waiting for phase %q of Cluster %s: %s", phase.Name, cluster.Name, message)
+			return ctrl.Result{RequeueAfter: waitingRequeueAfter}, nil
+		}
 	}
 	if len(errList) > 0 {
-		return kerrors.NewAggregate(errList)
+		return ctrl.Result{}, kerrors.NewAggregate(errList)
 	}
 
 	conditions.MarkTrue(clusterResourceSet, addonsv1.ResourcesAppliedCondition)
 
-	return nil
+	// With the Reconcile strategy, apply the resources again when the resync period is elapsed.
+	if reconcileStrategy {
+		return ctrl.Result{RequeueAfter: resyncPeriod}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// isResyncDue returns true if the resync period is elapsed since the resource was last applied.
+func isResyncDue(resourceBinding *addonsv1.ResourceBinding) bool {
+	return resourceBinding.LastAppliedTime == nil || time.Since(resourceBinding.LastAppliedTime.Time) >= resyncPeriod
+}
+
+// reconcileRemovedResources removes from the ResourceSetBinding the resources that are not in the ClusterResourceSet anymore;
+// if prune is enabled, the objects of those resources are deleted from the cluster first.
+func (r *ClusterResourceSetReconciler) reconcileRemovedResources(ctx context.Context, remoteClient client.Client, clusterResourceSet *addonsv1.ClusterResourceSet, resourceSetBinding *addonsv1.ResourceSetBinding) error {
+	resources := map[addonsv1.ResourceRef]bool{}
+	for _, resource := range clusterResourceSet.Spec.GetResources() {
+		resources[resource] = true
+	}
+
+	errList := []error{}
+	for _, resourceBinding := range append([]addonsv1.ResourceBinding{}, resourceSetBinding.Resources...) {
+		if resources[resourceBinding.ResourceRef] {
+			continue
+		}
+		if clusterResourceSet.Spec.Prune {
+			if err := prune(ctx, remoteClient, resourceBinding.AppliedObjects); err != nil {
+				errList = append(errList, err)
+				continue
+			}
+		}
+		resourceSetBinding.DeleteResource(resourceBinding.ResourceRef)
+	}
+	return kerrors.NewAggregate(errList)
 }
 
 // getResource retrieves the requested resource and convert it to unstructured type.
@@ -452,9 +743,30 @@ func (r *ClusterResourceSetReconciler) clusterToClusterResourceSet(o client.Obje
 		name := client.ObjectKey{Namespace: rs.Namespace, Name: rs.Name}
 		result = append(result, ctrl.Request{NamespacedName: name})
 	}
+
+	// Add the ClusterResourceSets bound to the cluster, so they can handle clusters not matching anymore.
+	clusterResourceSetBinding := &addonsv1.ClusterResourceSetBinding{}
+	if err := r.Client.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, clusterResourceSetBinding); err != nil {
+		return result
+	}
+	for _, binding := range clusterResourceSetBinding.Spec.Bindings {
+		name := client.ObjectKey{Namespace: cluster.Namespace, Name: binding.ClusterResourceSetName}
+		if !containsRequest(result, name) {
+			result = append(result, ctrl.Request{NamespacedName: name})
+		}
+	}
 	return result
 }
 
+func containsRequest(requests []ctrl.Request, name client.ObjectKey) bool {
+	for _, request := range requests {
+		if request.NamespacedName == name {
+			return true
+		}
+	}
+	return false
+}
+
 // resourceToClusterResourceSet is mapper function that maps resources to ClusterResourceSet.
 func (r *ClusterResourceSetReconciler) resourceToClusterResourceSet(o client.Object) []ctrl.Request {
 	result := []ctrl.Request{}
@@ -487,7 +799,7 @@ func (r *ClusterResourceSetReconciler) resourceToClusterResourceSet(o client.Obj
 		return nil
 	}
 	for _, crs := range crsList.Items {
-		for _, resource := range crs.Spec.Resources {
+		for _, resource := range crs.Spec.GetResources() {
 			if resource.Kind == objKind.Kind && resource.Name == o.GetName() {
 				name := client.ObjectKey{Namespace: o.GetNamespace(), Name: crs.Name}
 				result = append(result, ctrl.Request{NamespacedName: name})
@@ -27,10 +27,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/builder"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const (
@@ -466,6 +470,127 @@ metadata:
 		}, timeout).Should(BeTrue())
 	})
 
+	t.Run("Should apply again and prune resources changed in a ClusterResourceSet with the Reconcile strategy", func(t *testing.T) {
+		g := NewWithT(t)
+		ns := setup(t, g)
+		defer teardown(t, g, ns)
+
+		oldObjName := fmt.Sprintf("reconcile-configmap-%s", util.RandomString(6))
+		newObjName := fmt.Sprintf("reconcile-configmap-%s", util.RandomString(6))
+		existingObjName := fmt.Sprintf("reconcile-configmap-%s", util.RandomString(6))
+		resourceConfigMap := func(name, value string) string {
+			return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+ name: %s
+ namespace: default
+data:
+ key: %s`, name, value)
+		}
+
+		t.Log("Creating an object already existing in the cluster")
+		existingObj := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: existingObjName, Namespace: metav1.NamespaceDefault},
+			Data:       map[string]string{"key": "existing"},
+		}
+		g.Expect(env.Create(ctx, existingObj)).To(Succeed())
+
+		t.Log("Updating the ConfigMap with the objects to be applied")
+		configmap := &corev1.ConfigMap{}
+		g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: configmapName}, configmap)).To(Succeed())
+		configmap.Data = map[string]string{"cm": resourceConfigMap(oldObjName, "foo"), "existing": resourceConfigMap(existingObjName, "foo")}
+		g.Expect(env.Update(ctx, configmap)).To(Succeed())
+
+		testCluster.SetLabels(labels)
+		g.Expect(env.Update(ctx, testCluster)).To(Succeed())
+
+		t.Log("Creating a ClusterResourceSet instance with the Reconcile strategy and prune enabled")
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources: []addonsv1.ResourceRef{{Name: configmapName, Kind: "ConfigMap"}},
+				Strategy:  string(addonsv1.ClusterResourceSetStrategyReconcile),
+				Prune:     true,
+			},
+		}
+		g.Expect(env.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		t.Log("Verifying the objects are applied to the cluster")
+		g.Eventually(func() error {
+			obj := &corev1.ConfigMap{}
+			return env.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: oldObjName}, obj)
+		}, timeout).Should(Succeed())
+		g.Eventually(func() bool {
+			obj := &corev1.ConfigMap{}
+			if err := env.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: existingObjName}, obj); err != nil {
+				return false
+			}
+			return obj.Data["key"] == "foo"
+		}, timeout).Should(BeTrue())
+
+		t.Log("Verifying ClusterResourceSetBinding tracks only the object created by the ClusterResourceSet")
+		g.Eventually(func() bool {
+			binding := &addonsv1.ClusterResourceSetBinding{}
+			if err := env.Get(ctx, client.ObjectKey{Namespace: testCluster.Namespace, Name: testCluster.Name}, binding); err != nil {
+				return false
+			}
+			if len(binding.Spec.Bindings) != 1 || len(binding.Spec.Bindings[0].Resources) != 1 {
+				return false
+			}
+			resource := binding.Spec.Bindings[0].Resources[0]
+			return resource.Applied && len(resource.AppliedObjects) == 1 && resource.AppliedObjects[0].Name == oldObjName
+		}, timeout).Should(BeTrue())
+
+		t.Log("Updating the ConfigMap with a changed object and a new object")
+		g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: configmapName}, configmap)).To(Succeed())
+		configmap.Data = map[string]string{"cm": resourceConfigMap(newObjName, "bar")}
+		g.Expect(env.Update(ctx, configmap)).To(Succeed())
+
+		t.Log("Verifying the new object is applied and the removed object is pruned")
+		g.Eventually(func() bool {
+			obj := &corev1.ConfigMap{}
+			if err := env.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: newObjName}, obj); err != nil {
+				return false
+			}
+			return obj.Data["key"] == "bar"
+		}, timeout).Should(BeTrue())
+		g.Eventually(func() bool {
+			obj := &corev1.ConfigMap{}
+			err := env.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: oldObjName}, obj)
+			return apierrors.IsNotFound(err)
+		}, timeout).Should(BeTrue())
+
+		t.Log("Verifying the object already existing in the cluster is not pruned")
+		g.Consistently(func() error {
+			obj := &corev1.ConfigMap{}
+			return env.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: existingObjName}, obj)
+		}, 2*time.Second).Should(Succeed())
+
+		t.Log("Verifying ClusterResourceSetBinding tracks the applied objects")
+		g.Eventually(func() bool {
+			binding := &addonsv1.ClusterResourceSetBinding{}
+			if err := env.Get(ctx, client.ObjectKey{Namespace: testCluster.Namespace, Name: testCluster.Name}, binding); err != nil {
+				return false
+			}
+			if len(binding.Spec.Bindings) != 1 || len(binding.Spec.Bindings[0].Resources) != 1 {
+				return false
+			}
+			resource := binding.Spec.Bindings[0].Resources[0]
+			return resource.Applied && len(resource.AppliedObjects) == 1 && resource.AppliedObjects[0].Name == newObjName
+		}, timeout).Should(BeTrue())
+
+		g.Expect(env.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: newObjName, Namespace: metav1.NamespaceDefault}})).To(Succeed())
+		g.Expect(env.Delete(ctx, existingObj)).To(Succeed())
+		t.Log("Deleting the Cluster")
+		g.Expect(env.Delete(ctx, testCluster)).To(Succeed())
+	})
+
 	t.Run("Should delete ClusterResourceSet from the bindings list when ClusterResourceSet is deleted", func(t *testing.T) {
 		g := NewWithT(t)
 		ns := setup(t, g)
@@ -553,6 +678,304 @@ metadata:
 		g.Expect(env.Delete(ctx, testCluster)).To(Succeed())
 	})
 
+	t.Run("Should delete the applied objects when the cluster does not match and when the ClusterResourceSet is deleted with the Delete deletion policy", func(t *testing.T) {
+		g := NewWithT(t)
+		ns := setup(t, g)
+		defer teardown(t, g, ns)
+
+		objName := fmt.Sprintf("delete-configmap-%s", util.RandomString(6))
+		objKey := client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: objName}
+
+		t.Log("Updating the ConfigMap with the object to be applied")
+		configmap := &corev1.ConfigMap{}
+		g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: configmapName}, configmap)).To(Succeed())
+		configmap.Data = map[string]string{"cm": fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+ name: %s
+ namespace: default`, objName)}
+		g.Expect(env.Update(ctx, configmap)).To(Succeed())
+
+		testCluster.SetLabels(labels)
+		g.Expect(env.Update(ctx, testCluster)).To(Succeed())
+
+		t.Log("Creating a ClusterResourceSet instance with the Delete deletion policy")
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources:      []addonsv1.ResourceRef{{Name: configmapName, Kind: "ConfigMap"}},
+				DeletionPolicy: string(addonsv1.ClusterResourceSetDeletionPolicyDelete),
+			},
+		}
+		g.Expect(env.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		t.Log("Verifying the object is applied to the cluster")
+		g.Eventually(func() error {
+			return env.Get(ctx, objKey, &corev1.ConfigMap{})
+		}, timeout).Should(Succeed())
+
+		t.Log("Removing the labels from the cluster")
+		g.Expect(env.Get(ctx, client.ObjectKeyFromObject(testCluster), testCluster)).To(Succeed())
+		testCluster.SetLabels(nil)
+		g.Expect(env.Update(ctx, testCluster)).To(Succeed())
+
+		t.Log("Verifying the object is deleted and the ClusterResourceSetBinding is deleted")
+		g.Eventually(func() bool {
+			return apierrors.IsNotFound(env.Get(ctx, objKey, &corev1.ConfigMap{}))
+		}, timeout).Should(BeTrue())
+		g.Eventually(func() bool {
+			binding := &addonsv1.ClusterResourceSetBinding{}
+			return apierrors.IsNotFound(env.Get(ctx, client.ObjectKeyFromObject(testCluster), binding))
+		}, timeout).Should(BeTrue())
+
+		t.Log("Adding the labels back to the cluster")
+		g.Expect(env.Get(ctx, client.ObjectKeyFromObject(testCluster), testCluster)).To(Succeed())
+		testCluster.SetLabels(labels)
+		g.Expect(env.Update(ctx, testCluster)).To(Succeed())
+
+		t.Log("Verifying the object is applied again to the cluster")
+		g.Eventually(func() error {
+			return env.Get(ctx, objKey, &corev1.ConfigMap{})
+		}, timeout).Should(Succeed())
+
+		t.Log("Deleting the ClusterResourceSet")
+		g.Expect(env.Delete(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		t.Log("Verifying the object is deleted")
+		g.Eventually(func() bool {
+			return apierrors.IsNotFound(env.Get(ctx, objKey, &corev1.ConfigMap{}))
+		}, timeout).Should(BeTrue())
+
+		t.Log("Deleting the Cluster")
+		g.Expect(env.Delete(ctx, testCluster)).To(Succeed())
+	})
+
+	t.Run("Should orphan the applied objects when the cluster is not reachable and its infrastructure is gone", func(t *testing.T) {
+		g := NewWithT(t)
+		ns := setup(t, g)
+		defer teardown(t, g, ns)
+
+		t.Log("Creating a Cluster without a kubeconfig, whose infrastructure does not exist")
+		unreachableCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("cluster-%s", util.RandomString(6)),
+				Namespace: ns.Name,
+				Labels:    labels,
+			},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{
+					APIVersion: builder.InfrastructureGroupVersion.String(),
+					Kind:       builder.GenericInfrastructureClusterKind,
+					Name:       "gone",
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, unreachableCluster)).To(Succeed())
+
+		t.Log("Creating a ClusterResourceSet instance with the Delete deletion policy")
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources:      []addonsv1.ResourceRef{{Name: configmapName, Kind: "ConfigMap"}},
+				DeletionPolicy: string(addonsv1.ClusterResourceSetDeletionPolicyDelete),
+			},
+		}
+		g.Expect(env.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+		g.Eventually(func() bool {
+			crs := &addonsv1.ClusterResourceSet{}
+			if err := env.Get(ctx, client.ObjectKeyFromObject(clusterResourceSetInstance), crs); err != nil {
+				return false
+			}
+			return len(crs.Finalizers) > 0
+		}, timeout).Should(BeTrue())
+
+		t.Log("Creating a ClusterResourceSetBinding tracking an object applied to the cluster")
+		binding := &addonsv1.ClusterResourceSetBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      unreachableCluster.Name,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetBindingSpec{
+				Bindings: []*addonsv1.ResourceSetBinding{{
+					ClusterResourceSetName: clusterResourceSetName,
+					Resources: []addonsv1.ResourceBinding{{
+						ResourceRef:    addonsv1.ResourceRef{Name: configmapName, Kind: "ConfigMap"},
+						Applied:        true,
+						AppliedObjects: []addonsv1.AppliedObjectRef{{APIVersion: "v1", Kind: "ConfigMap", Namespace: metav1.NamespaceDefault, Name: "orphaned"}},
+					}},
+				}},
+			},
+		}
+		g.Expect(env.Create(ctx, binding)).To(Succeed())
+
+		t.Log("Deleting the ClusterResourceSet")
+		g.Expect(env.Delete(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		t.Log("Verifying the ClusterResourceSet is deleted and the ClusterResourceSetBinding is deleted")
+		g.Eventually(func() bool {
+			return apierrors.IsNotFound(env.Get(ctx, client.ObjectKeyFromObject(clusterResourceSetInstance), &addonsv1.ClusterResourceSet{}))
+		}, timeout).Should(BeTrue())
+		g.Eventually(func() bool {
+			return apierrors.IsNotFound(env.Get(ctx, client.ObjectKeyFromObject(binding), &addonsv1.ClusterResourceSetBinding{}))
+		}, timeout).Should(BeTrue())
+
+		t.Log("Deleting the Cluster")
+		g.Expect(env.Delete(ctx, unreachableCluster)).To(Succeed())
+	})
+
+	t.Run("Should report a TemplateRenderFailed condition when a resource of a ClusterResourceSet cannot be rendered", func(t *testing.T) {
+		g := NewWithT(t)
+		ns := setup(t, g)
+		defer teardown(t, g, ns)
+
+		objName := fmt.Sprintf("template-configmap-%s", util.RandomString(6))
+
+		t.Log("Updating the ConfigMap with an object referencing a field that does not exist")
+		configmap := &corev1.ConfigMap{}
+		g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: configmapName}, configmap)).To(Succeed())
+		configmap.Data = map[string]string{"cm": fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+ name: %s
+ namespace: default
+data:
+ cluster: "{{ .Cluster.DoesNotExist }}"`, objName)}
+		g.Expect(env.Update(ctx, configmap)).To(Succeed())
+
+		testCluster.SetLabels(labels)
+		g.Expect(env.Update(ctx, testCluster)).To(Succeed())
+
+		t.Log("Creating a ClusterResourceSet instance rendering templates")
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources:       []addonsv1.ResourceRef{{Name: configmapName, Kind: "ConfigMap"}},
+				RenderTemplates: true,
+			},
+		}
+		g.Expect(env.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		t.Log("Verifying the ClusterResourceSet reports the template rendering failure")
+		g.Eventually(func() bool {
+			crs := &addonsv1.ClusterResourceSet{}
+			if err := env.Get(ctx, client.ObjectKeyFromObject(clusterResourceSetInstance), crs); err != nil {
+				return false
+			}
+			return conditions.IsFalse(crs, addonsv1.ResourcesAppliedCondition) &&
+				conditions.GetReason(crs, addonsv1.ResourcesAppliedCondition) == addonsv1.TemplateRenderFailedReason &&
+				conditions.GetSeverity(crs, addonsv1.ResourcesAppliedCondition) != nil &&
+				*conditions.GetSeverity(crs, addonsv1.ResourcesAppliedCondition) == clusterv1.ConditionSeverityWarning
+		}, timeout).Should(BeTrue())
+
+		t.Log("Verifying the object is not applied and the resource is not marked as applied")
+		g.Expect(apierrors.IsNotFound(env.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: objName}, &corev1.ConfigMap{}))).To(BeTrue())
+		binding := &addonsv1.ClusterResourceSetBinding{}
+		g.Expect(env.Get(ctx, client.ObjectKeyFromObject(testCluster), binding)).To(Succeed())
+		g.Expect(binding.Spec.Bindings).To(HaveLen(1))
+		g.Expect(binding.Spec.Bindings[0].IsApplied(addonsv1.ResourceRef{Name: configmapName, Kind: "ConfigMap"})).To(BeFalse())
+
+		t.Log("Deleting the Cluster")
+		g.Expect(env.Delete(ctx, testCluster)).To(Succeed())
+	})
+
+	t.Run("Should apply a ClusterResourceSet only after the ClusterResourceSets it depends on are completed", func(t *testing.T) {
+		g := NewWithT(t)
+		ns := setup(t, g)
+		defer teardown(t, g, ns)
+
+		testCluster.SetLabels(labels)
+		g.Expect(env.Update(ctx, testCluster)).To(Succeed())
+
+		dependencyName := fmt.Sprintf("clusterresourceset-dependency-%s", util.RandomString(6))
+
+		t.Log("Creating a ClusterResourceSet instance that depends on a ClusterResourceSet not existing yet")
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources: []addonsv1.ResourceRef{{Name: secretName, Kind: "Secret"}},
+				DependsOn: []string{dependencyName},
+			},
+		}
+		g.Expect(env.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		t.Log("Verifying the ClusterResourceSet is waiting for its dependencies")
+		g.Eventually(func() bool {
+			crs := &addonsv1.ClusterResourceSet{}
+			if err := env.Get(ctx, client.ObjectKeyFromObject(clusterResourceSetInstance), crs); err != nil {
+				return false
+			}
+			return conditions.GetReason(crs, addonsv1.ResourcesAppliedCondition) == addonsv1.WaitingForDependenciesReason
+		}, timeout).Should(BeTrue())
+
+		t.Log("Creating the ClusterResourceSet dependency with a phase")
+		dependencyInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dependencyName,
+				Namespace: ns.Name,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Phases: []addonsv1.ClusterResourceSetPhase{
+					{
+						Name:      "config",
+						Resources: []addonsv1.ResourceRef{{Name: configmapName, Kind: "ConfigMap"}},
+						ReadinessChecks: []addonsv1.ReadinessCheck{
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: metav1.NamespaceDefault, Name: "resource-configmap"},
+						},
+					},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, dependencyInstance)).To(Succeed())
+		defer func() {
+			g.Expect(env.Delete(ctx, dependencyInstance)).To(Succeed())
+		}()
+
+		t.Log("Verifying both the ClusterResourceSets are applied")
+		g.Eventually(func() bool {
+			binding := &addonsv1.ClusterResourceSetBinding{}
+			if err := env.Get(ctx, client.ObjectKey{Namespace: testCluster.Namespace, Name: testCluster.Name}, binding); err != nil {
+				return false
+			}
+			for _, crs := range []*addonsv1.ClusterResourceSet{clusterResourceSetInstance, dependencyInstance} {
+				resourceSetBinding := binding.GetBinding(crs)
+				if resourceSetBinding == nil || !isPhaseApplied(resourceSetBinding, crs.Spec.GetPhases()[0]) {
+					return false
+				}
+			}
+			return true
+		}, timeout).Should(BeTrue())
+
+		t.Log("Deleting the Cluster")
+		g.Expect(env.Delete(ctx, testCluster)).To(Succeed())
+	})
+
 	t.Run("Should add finalizer after reconcile", func(t *testing.T) {
 		g := NewWithT(t)
 		ns := setup(t, g)
@@ -589,3 +1012,69 @@ metadata:
 		}, timeout).Should(BeTrue())
 	})
 }
+
+func TestClusterResourceSetReconciler_getOrphanReason(t *testing.T) {
+	infraCluster := &unstructured.Unstructured{}
+	infraCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	infraCluster.SetKind("GenericInfrastructureCluster")
+	infraCluster.SetNamespace(metav1.NamespaceDefault)
+	infraCluster.SetName("existing")
+
+	newCluster := func(infraClusterName string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "cluster"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{APIVersion: infraCluster.GetAPIVersion(), Kind: infraCluster.GetKind(), Name: infraClusterName},
+			},
+		}
+	}
+	deletedCRS := func(deletedSince time.Duration) *addonsv1.ClusterResourceSet {
+		return &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-deletedSince)}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		cluster    *clusterv1.Cluster
+		crs        *addonsv1.ClusterResourceSet
+		wantOrphan bool
+	}{
+		{
+			name:       "does not orphan the objects if the infrastructure exists",
+			cluster:    newCluster("existing"),
+			crs:        &addonsv1.ClusterResourceSet{},
+			wantOrphan: false,
+		},
+		{
+			name:       "does not orphan the objects if the ClusterResourceSet has just been deleted",
+			cluster:    newCluster("existing"),
+			crs:        deletedCRS(time.Minute),
+			wantOrphan: false,
+		},
+		{
+			name:       "orphans the objects if the ClusterResourceSet has been deleted for more than the timeout",
+			cluster:    newCluster("existing"),
+			crs:        deletedCRS(orphanObjectsTimeout),
+			wantOrphan: true,
+		},
+		{
+			name:       "orphans the objects if the infrastructure is gone",
+			cluster:    newCluster("gone"),
+			crs:        &addonsv1.ClusterResourceSet{},
+			wantOrphan: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &ClusterResourceSetReconciler{
+				Client: fake.NewClientBuilder().WithObjects(infraCluster.DeepCopy()).Build(),
+			}
+			reason, err := r.getOrphanReason(ctx, tt.cluster, tt.crs)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(reason != "").To(Equal(tt.wantOrphan))
+		})
+	}
+}
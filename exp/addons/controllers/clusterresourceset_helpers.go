@@ -54,10 +54,14 @@ func isJSONList(data []byte) (bool, error) {
 	return bytes.HasPrefix(trim, jsonListPrefix), nil
 }
 
-func apply(ctx context.Context, c client.Client, data []byte) error {
+// clusterResourceSetManagerName is the field manager used when applying resources with server-side apply.
+const clusterResourceSetManagerName = "capi-clusterresourceset"
+
+// getObjects returns the objects defined in data, that can be either in JSON list, JSON or YAML format.
+func getObjects(data []byte) ([]unstructured.Unstructured, error) {
 	isJSONList, err := isJSONList(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	objs := []unstructured.Unstructured{}
 	// If it is a json list, convert each list element to an unstructured object.
@@ -75,36 +79,171 @@ func apply(ctx context.Context, c client.Client, data []byte) error {
 		// If it is not a json list, data is either json or yaml format.
 		objs, err = utilyaml.ToUnstructured(data)
 		if err != nil {
-			return errors.Wrapf(err, "failed converting data to unstructured objects")
+			return nil, errors.Wrapf(err, "failed converting data to unstructured objects")
 		}
 	}
+	return objs, nil
+}
 
+// apply creates the objects in the cluster and returns the references to the objects created; objects already existing are not changed.
+func apply(ctx context.Context, c client.Client, objs []unstructured.Unstructured) ([]addonsv1.AppliedObjectRef, error) {
 	errList := []error{}
+	created := []addonsv1.AppliedObjectRef{}
 	sortedObjs := utilresource.SortForCreate(objs)
 	for i := range sortedObjs {
-		if err := applyUnstructured(ctx, c, &objs[i]); err != nil {
+		ok, err := applyUnstructured(ctx, c, &sortedObjs[i])
+		if err != nil {
 			errList = append(errList, err)
+			continue
+		}
+		if ok {
+			created = append(created, appliedObjectRef(&sortedObjs[i]))
 		}
 	}
-	return kerrors.NewAggregate(errList)
+	return created, kerrors.NewAggregate(errList)
 }
 
-func applyUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+// applyUnstructured creates the object in the cluster, returning true if the object did not exist.
+func applyUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) (bool, error) {
 	// Create the object on the API server.
 	// TODO: Errors are only logged. If needed, exponential backoff or requeuing could be used here for remedying connection glitches etc.
 	if err := c.Create(ctx, obj); err != nil {
 		// The create call is idempotent, so if the object already exists
 		// then do not consider it to be an error.
 		if !apierrors.IsAlreadyExists(err) {
-			return errors.Wrapf(
+			return false, errors.Wrapf(
 				err,
 				"failed to create object %s %s/%s",
 				obj.GroupVersionKind(),
 				obj.GetNamespace(),
 				obj.GetName())
 		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// serverSideApply applies the objects to the cluster using server-side apply, thus creating the objects or updating the objects
+// already existing; it returns the references to the objects created by the ClusterResourceSet, i.e. the objects that did not exist
+// when first applied, and the objects already tracked. Objects that existed in the cluster before being applied are never tracked,
+// so they are never deleted by prune or by the Delete deletion policy.
+// NOTE: objects are applied forcing the ownership of conflicting fields, so changes made to the applied fields by other field
+// managers, e.g. with kubectl edit, are reverted; without forcing the ownership, such changes would make the apply fail.
+func serverSideApply(ctx context.Context, c client.Client, objs []unstructured.Unstructured, tracked []addonsv1.AppliedObjectRef) ([]addonsv1.AppliedObjectRef, error) {
+	isTracked := map[addonsv1.AppliedObjectRef]bool{}
+	for _, ref := range tracked {
+		isTracked[ref] = true
+	}
+
+	errList := []error{}
+	created := []addonsv1.AppliedObjectRef{}
+	sortedObjs := utilresource.SortForCreate(objs)
+	for i := range sortedObjs {
+		obj := &sortedObjs[i]
+		ref := appliedObjectRef(obj)
+
+		// Check if the object is going to be created by the ClusterResourceSet.
+		isCreated := isTracked[ref]
+		if !isCreated {
+			existing := &unstructured.Unstructured{}
+			existing.SetGroupVersionKind(obj.GroupVersionKind())
+			err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+			if err != nil && !apierrors.IsNotFound(err) {
+				errList = append(errList, errors.Wrapf(
+					err,
+					"failed to get object %s %s/%s",
+					obj.GroupVersionKind(),
+					obj.GetNamespace(),
+					obj.GetName()))
+				continue
+			}
+			isCreated = apierrors.IsNotFound(err)
+		}
+
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+		if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(clusterResourceSetManagerName), client.ForceOwnership); err != nil {
+			errList = append(errList, errors.Wrapf(
+				err,
+				"failed to apply object %s %s/%s",
+				obj.GroupVersionKind(),
+				obj.GetNamespace(),
+				obj.GetName()))
+			continue
+		}
+		if isCreated {
+			created = append(created, ref)
+		}
+	}
+	return created, kerrors.NewAggregate(errList)
+}
+
+// prune deletes from the cluster the objects; objects already deleted are ignored.
+func prune(ctx context.Context, c client.Client, objs []addonsv1.AppliedObjectRef) error {
+	errList := []error{}
+	for _, ref := range objs {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(ref.APIVersion)
+		obj.SetKind(ref.Kind)
+		obj.SetNamespace(ref.Namespace)
+		obj.SetName(ref.Name)
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			errList = append(errList, errors.Wrapf(
+				err,
+				"failed to delete object %s %s/%s",
+				obj.GroupVersionKind(),
+				obj.GetNamespace(),
+				obj.GetName()))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// appliedObjectRef returns the reference to the object.
+func appliedObjectRef(obj *unstructured.Unstructured) addonsv1.AppliedObjectRef {
+	return addonsv1.AppliedObjectRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// deleteAppliedObjects deletes from the cluster the objects applied for the resources in the ResourceSetBinding, starting
+// from the last resource; the resources whose objects are deleted are removed from the ResourceSetBinding.
+func deleteAppliedObjects(ctx context.Context, c client.Client, resourceSetBinding *addonsv1.ResourceSetBinding) error {
+	errList := []error{}
+	for i := len(resourceSetBinding.Resources) - 1; i >= 0; i-- {
+		resourceBinding := resourceSetBinding.Resources[i]
+		if err := prune(ctx, c, resourceBinding.AppliedObjects); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		resourceSetBinding.DeleteResource(resourceBinding.ResourceRef)
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// mergeAppliedObjectRefs returns the references in a and the references in b not already in a.
+func mergeAppliedObjectRefs(a, b []addonsv1.AppliedObjectRef) []addonsv1.AppliedObjectRef {
+	merged := append([]addonsv1.AppliedObjectRef{}, a...)
+	merged = append(merged, subtractAppliedObjectRefs(b, a)...)
+	return merged
+}
+
+// subtractAppliedObjectRefs returns the references in a that are not in b.
+func subtractAppliedObjectRefs(a, b []addonsv1.AppliedObjectRef) []addonsv1.AppliedObjectRef {
+	inB := map[addonsv1.AppliedObjectRef]bool{}
+	for _, ref := range b {
+		inB[ref] = true
+	}
+	out := []addonsv1.AppliedObjectRef{}
+	for _, ref := range a {
+		if !inB[ref] {
+			out = append(out, ref)
+		}
 	}
-	return nil
+	return out
 }
 
 // getOrCreateClusterResourceSetBinding retrieves ClusterResourceSetBinding resource owned by the cluster or create a new one if not found.
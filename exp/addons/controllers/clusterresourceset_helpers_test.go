@@ -219,3 +219,15 @@ func TestGetConfigMapFromNamespacedName(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeAndSubtractAppliedObjectRefs(t *testing.T) {
+	g := NewWithT(t)
+
+	cm1 := addonsv1.AppliedObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: metav1.NamespaceDefault, Name: "cm1"}
+	cm2 := addonsv1.AppliedObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: metav1.NamespaceDefault, Name: "cm2"}
+	crd := addonsv1.AppliedObjectRef{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "foos.example.com"}
+
+	g.Expect(subtractAppliedObjectRefs([]addonsv1.AppliedObjectRef{cm1, cm2, crd}, []addonsv1.AppliedObjectRef{cm2})).To(Equal([]addonsv1.AppliedObjectRef{cm1, crd}))
+	g.Expect(subtractAppliedObjectRefs(nil, []addonsv1.AppliedObjectRef{cm2})).To(BeEmpty())
+	g.Expect(mergeAppliedObjectRefs([]addonsv1.AppliedObjectRef{cm1, cm2}, []addonsv1.AppliedObjectRef{cm2, crd})).To(Equal([]addonsv1.AppliedObjectRef{cm1, cm2, crd}))
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// waitingRequeueAfter is how long to wait before checking again dependencies and readiness checks not satisfied.
+const waitingRequeueAfter = 20 * time.Second
+
+// defaultReadinessConditions are the condition types checked for known kinds when a readiness check does not define one.
+var defaultReadinessConditions = map[schema.GroupKind]string{
+	{Group: "apps", Kind: "Deployment"}:                               "Available",
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:             "Available",
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: "Established",
+}
+
+// checkReadiness checks the objects of the readiness checks in the cluster, returning a message describing the first
+// object not ready, or an empty string if all the objects are ready.
+func checkReadiness(ctx context.Context, c client.Client, checks []addonsv1.ReadinessCheck) (string, error) {
+	for _, check := range checks {
+		gv, err := schema.ParseGroupVersion(check.APIVersion)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid apiVersion %q in readiness check", check.APIVersion)
+		}
+		name := check.Name
+		if check.Namespace != "" {
+			name = check.Namespace + "/" + check.Name
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(check.APIVersion)
+		obj.SetKind(check.Kind)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: check.Namespace, Name: check.Name}, obj); err != nil {
+			// NOTE: the kind is not known yet if the object is a custom resource whose CRD is not established yet.
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				return fmt.Sprintf("%s %s does not exist", check.Kind, name), nil
+			}
+			return "", errors.Wrapf(err, "failed to get %s %s", check.Kind, name)
+		}
+
+		conditionType := check.ConditionType
+		if conditionType == "" {
+			conditionType = defaultReadinessConditions[gv.WithKind(check.Kind).GroupKind()]
+		}
+		if conditionType != "" && !isStatusConditionTrue(obj, conditionType) {
+			return fmt.Sprintf("%s %s is not %s", check.Kind, name, conditionType), nil
+		}
+	}
+	return "", nil
+}
+
+// isStatusConditionTrue returns true if the object has a condition of the given type with status True.
+func isStatusConditionTrue(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for i := range conditions {
+		condition, ok := conditions[i].(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		return condition["status"] == string(metav1.ConditionTrue)
+	}
+	return false
+}
+
+// isPhaseApplied returns true if all the resources of the phase are applied to the cluster.
+func isPhaseApplied(resourceSetBinding *addonsv1.ResourceSetBinding, phase addonsv1.ClusterResourceSetPhase) bool {
+	for _, resource := range phase.Resources {
+		if resourceSetBinding == nil || !resourceSetBinding.IsApplied(resource) {
+			return false
+		}
+	}
+	return true
+}
+
+// dependencyCycleError is returned when the dependencies of a ClusterResourceSet form a cycle, so they can never be completed.
+type dependencyCycleError struct {
+	// cycle are the names of the ClusterResourceSets forming the cycle, starting and ending with the same name.
+	cycle []string
+}
+
+func (e *dependencyCycleError) Error() string {
+	return fmt.Sprintf("dependsOn forms a cycle: %s", strings.Join(e.cycle, " -> "))
+}
+
+// getPendingDependency returns a message describing the first ClusterResourceSet in dependsOn that is not completed for the
+// cluster, or an empty string if all the dependencies are completed; dependencies not matching the cluster are ignored.
+// A dependencyCycleError is returned if dependsOn, walked transitively, forms a cycle.
+func (r *ClusterResourceSetReconciler) getPendingDependency(ctx context.Context, remoteClient client.Client, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet, clusterResourceSetBinding *addonsv1.ClusterResourceSetBinding) (string, error) {
+	cycle, err := r.getDependencyCycle(ctx, clusterResourceSet)
+	if err != nil {
+		return "", err
+	}
+	if cycle != nil {
+		return "", &dependencyCycleError{cycle: cycle}
+	}
+
+	for _, name := range clusterResourceSet.Spec.DependsOn {
+		dependency := &addonsv1.ClusterResourceSet{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterResourceSet.Namespace, Name: name}, dependency); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("ClusterResourceSet %s does not exist", name), nil
+			}
+			return "", errors.Wrapf(err, "failed to get ClusterResourceSet %s", name)
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&dependency.Spec.ClusterSelector)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to convert the selector of ClusterResourceSet %s", name)
+		}
+		if selector.Empty() || !selector.Matches(labels.Set(cluster.GetLabels())) {
+			continue
+		}
+
+		resourceSetBinding := clusterResourceSetBinding.GetBinding(dependency)
+		for _, phase := range dependency.Spec.GetPhases() {
+			if !isPhaseApplied(resourceSetBinding, phase) {
+				return fmt.Sprintf("ClusterResourceSet %s is not applied", name), nil
+			}
+			message, err := checkReadiness(ctx, remoteClient, phase.ReadinessChecks)
+			if err != nil {
+				return "", err
+			}
+			if message != "" {
+				return fmt.Sprintf("ClusterResourceSet %s is not ready: %s", name, message), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// getDependencyCycle walks dependsOn transitively starting from the ClusterResourceSet, returning the names of the
+// ClusterResourceSets forming the first cycle found, or nil if there are no cycles; ClusterResourceSets that do not exist
+// are ignored.
+func (r *ClusterResourceSetReconciler) getDependencyCycle(ctx context.Context, clusterResourceSet *addonsv1.ClusterResourceSet) ([]string, error) {
+	dependsOn := map[string][]string{clusterResourceSet.Name: clusterResourceSet.Spec.DependsOn}
+	visited := map[string]bool{}
+
+	var visit func(path []string) ([]string, error)
+	visit = func(path []string) ([]string, error) {
+		name := path[len(path)-1]
+		for i := range path[:len(path)-1] {
+			if path[i] == name {
+				return append([]string{}, path[i:]...), nil
+			}
+		}
+		if visited[name] {
+			return nil, nil
+		}
+
+		names, ok := dependsOn[name]
+		if !ok {
+			dependency := &addonsv1.ClusterResourceSet{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterResourceSet.Namespace, Name: name}, dependency); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return nil, errors.Wrapf(err, "failed to get ClusterResourceSet %s", name)
+				}
+			}
+			names = dependency.Spec.DependsOn
+			dependsOn[name] = names
+		}
+		for _, next := range names {
+			cycle, err := visit(append(path, next))
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		visited[name] = true
+		return nil, nil
+	}
+	return visit([]string{clusterResourceSet.Name})
+}
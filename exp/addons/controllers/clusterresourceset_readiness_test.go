@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckReadiness(t *testing.T) {
+	readinessScheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(readinessScheme)
+	_ = appsv1.AddToScheme(readinessScheme)
+	_ = apiextensionsv1.AddToScheme(readinessScheme)
+
+	availableDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "available", Namespace: metav1.NamespaceSystem},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+		},
+	}
+	progressingDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "progressing", Namespace: metav1.NamespaceSystem},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	establishedCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue}},
+		},
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: metav1.NamespaceSystem}}
+
+	tests := []struct {
+		name        string
+		checks      []addonsv1.ReadinessCheck
+		wantMessage string
+		wantErr     bool
+	}{
+		{
+			name: "ready when there are no checks",
+		},
+		{
+			name: "ready for an available Deployment, an established CRD and an existing ConfigMap",
+			checks: []addonsv1.ReadinessCheck{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: metav1.NamespaceSystem, Name: "available"},
+				{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "foos.example.com"},
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: metav1.NamespaceSystem, Name: "config"},
+			},
+		},
+		{
+			name: "not ready for a Deployment not available",
+			checks: []addonsv1.ReadinessCheck{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: metav1.NamespaceSystem, Name: "available"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: metav1.NamespaceSystem, Name: "progressing"},
+			},
+			wantMessage: "Deployment kube-system/progressing is not Available",
+		},
+		{
+			name: "ready for a Deployment with a custom condition type",
+			checks: []addonsv1.ReadinessCheck{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: metav1.NamespaceSystem, Name: "progressing", ConditionType: "Progressing"},
+			},
+		},
+		{
+			name: "not ready for a ConfigMap without the custom condition type",
+			checks: []addonsv1.ReadinessCheck{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: metav1.NamespaceSystem, Name: "config", ConditionType: "Ready"},
+			},
+			wantMessage: "ConfigMap kube-system/config is not Ready",
+		},
+		{
+			name: "not ready for a missing object",
+			checks: []addonsv1.ReadinessCheck{
+				{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "bars.example.com"},
+			},
+			wantMessage: "CustomResourceDefinition bars.example.com does not exist",
+		},
+		{
+			name: "fails for an invalid apiVersion",
+			checks: []addonsv1.ReadinessCheck{
+				{APIVersion: "apps/v1/beta", Kind: "Deployment", Namespace: metav1.NamespaceSystem, Name: "available"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().
+				WithScheme(readinessScheme).
+				WithObjects(availableDeployment, progressingDeployment, establishedCRD, configMap).
+				Build()
+
+			message, err := checkReadiness(context.TODO(), c, tt.checks)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(message).To(Equal(tt.wantMessage))
+		})
+	}
+}
+
+func TestIsPhaseApplied(t *testing.T) {
+	g := NewWithT(t)
+
+	applied := addonsv1.ResourceRef{Name: "applied", Kind: "ConfigMap"}
+	notApplied := addonsv1.ResourceRef{Name: "not-applied", Kind: "ConfigMap"}
+	resourceSetBinding := &addonsv1.ResourceSetBinding{
+		Resources: []addonsv1.ResourceBinding{
+			{ResourceRef: applied, Applied: true},
+			{ResourceRef: notApplied, Applied: false},
+		},
+	}
+
+	g.Expect(isPhaseApplied(resourceSetBinding, addonsv1.ClusterResourceSetPhase{})).To(BeTrue())
+	g.Expect(isPhaseApplied(resourceSetBinding, addonsv1.ClusterResourceSetPhase{Resources: []addonsv1.ResourceRef{applied}})).To(BeTrue())
+	g.Expect(isPhaseApplied(resourceSetBinding, addonsv1.ClusterResourceSetPhase{Resources: []addonsv1.ResourceRef{applied, notApplied}})).To(BeFalse())
+	g.Expect(isPhaseApplied(nil, addonsv1.ClusterResourceSetPhase{Resources: []addonsv1.ResourceRef{applied}})).To(BeFalse())
+}
+
+func TestGetPendingDependencyCycle(t *testing.T) {
+	dependencyScheme := runtime.NewScheme()
+	_ = addonsv1.AddToScheme(dependencyScheme)
+
+	newCRS := func(name string, dependsOn ...string) *addonsv1.ClusterResourceSet {
+		return &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+			Spec:       addonsv1.ClusterResourceSetSpec{DependsOn: dependsOn},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		crs       *addonsv1.ClusterResourceSet
+		objs      []*addonsv1.ClusterResourceSet
+		wantCycle []string
+	}{
+		{
+			name: "no cycle for dependencies without dependencies, or not existing",
+			crs:  newCRS("a", "b", "c", "missing"),
+			objs: []*addonsv1.ClusterResourceSet{newCRS("b", "c"), newCRS("c")},
+		},
+		{
+			name:      "cycle for a dependency depending on the ClusterResourceSet",
+			crs:       newCRS("a", "b"),
+			objs:      []*addonsv1.ClusterResourceSet{newCRS("b", "c"), newCRS("c", "a")},
+			wantCycle: []string{"a", "b", "c", "a"},
+		},
+		{
+			name:      "cycle between dependencies of the ClusterResourceSet",
+			crs:       newCRS("a", "b"),
+			objs:      []*addonsv1.ClusterResourceSet{newCRS("b", "c"), newCRS("c", "b")},
+			wantCycle: []string{"b", "c", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			builder := fake.NewClientBuilder().WithScheme(dependencyScheme)
+			for _, obj := range tt.objs {
+				builder = builder.WithObjects(obj)
+			}
+			r := &ClusterResourceSetReconciler{Client: builder.Build()}
+
+			// NOTE: the cycle is detected before checking the dependencies, so clusters and bindings are not required.
+			_, err := r.getPendingDependency(context.TODO(), nil, &clusterv1.Cluster{}, tt.crs, &addonsv1.ClusterResourceSetBinding{})
+			if tt.wantCycle == nil {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			var cycleErr *dependencyCycleError
+			g.Expect(errors.As(err, &cycleErr)).To(BeTrue())
+			g.Expect(cycleErr.cycle).To(Equal(tt.wantCycle))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// templateData is the data available to the templates in ClusterResourceSet resources.
+type templateData struct {
+	Cluster clusterTemplateData
+}
+
+// clusterTemplateData are the Cluster fields available to the templates in ClusterResourceSet resources.
+type clusterTemplateData struct {
+	Name                 string
+	Namespace            string
+	Labels               map[string]string
+	Annotations          map[string]string
+	ClusterNetwork       *clusterv1.ClusterNetwork
+	ControlPlaneEndpoint clusterv1.APIEndpoint
+}
+
+// templateFuncs returns the functions available in templates, that are the sprig functions
+// except the ones giving access to the controller environment.
+func templateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+	return funcs
+}
+
+// renderTemplates renders each data in dataList as a Go template using the values from the cluster.
+func renderTemplates(dataList [][]byte, cluster *clusterv1.Cluster) ([][]byte, error) {
+	values := templateData{
+		Cluster: clusterTemplateData{
+			Name:                 cluster.Name,
+			Namespace:            cluster.Namespace,
+			Labels:               cluster.Labels,
+			Annotations:          cluster.Annotations,
+			ClusterNetwork:       cluster.Spec.ClusterNetwork,
+			ControlPlaneEndpoint: cluster.Spec.ControlPlaneEndpoint,
+		},
+	}
+
+	renderedDataList := make([][]byte, 0, len(dataList))
+	for i := range dataList {
+		t, err := template.New("resource").Funcs(templateFuncs()).Option("missingkey=error").Parse(string(dataList[i]))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse template")
+		}
+
+		var out bytes.Buffer
+		if err := t.Execute(&out, values); err != nil {
+			return nil, errors.Wrapf(err, "failed to render template for Cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+		renderedDataList = append(renderedDataList, out.Bytes())
+	}
+	return renderedDataList, nil
+}
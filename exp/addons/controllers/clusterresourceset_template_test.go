@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-cluster",
+			Namespace:   "my-namespace",
+			Labels:      map[string]string{"cni": "calico"},
+			Annotations: map[string]string{"mtu": "1440"},
+		},
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				Pods: &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16", "fd00::/48"}},
+			},
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "data without template actions is not changed",
+			data: "kind: ConfigMap\napiVersion: v1",
+			want: "kind: ConfigMap\napiVersion: v1",
+		},
+		{
+			name: "renders cluster metadata",
+			data: "name: {{ .Cluster.Name }}\nnamespace: {{ .Cluster.Namespace }}\ncni: {{ .Cluster.Labels.cni }}\nmtu: {{ index .Cluster.Annotations \"mtu\" }}",
+			want: "name: my-cluster\nnamespace: my-namespace\ncni: calico\nmtu: 1440",
+		},
+		{
+			name: "renders cluster network and control plane endpoint",
+			data: "cidr: {{ index .Cluster.ClusterNetwork.Pods.CIDRBlocks 0 }}\ncidrs: {{ join \",\" .Cluster.ClusterNetwork.Pods.CIDRBlocks }}\nendpoint: {{ .Cluster.ControlPlaneEndpoint.Host }}:{{ .Cluster.ControlPlaneEndpoint.Port }}",
+			want: "cidr: 192.168.0.0/16\ncidrs: 192.168.0.0/16,fd00::/48\nendpoint: 10.0.0.1:6443",
+		},
+		{
+			name:    "fails for invalid templates",
+			data:    "name: {{ .Cluster.Name",
+			wantErr: true,
+		},
+		{
+			name:    "fails for missing map keys",
+			data:    "cni: {{ .Cluster.Labels.missing }}",
+			wantErr: true,
+		},
+		{
+			name:    "fails for fields not available",
+			data:    "name: {{ .Cluster.Spec.Topology.Class }}",
+			wantErr: true,
+		},
+		{
+			name:    "fails for functions giving access to the environment",
+			data:    "home: {{ env \"HOME\" }}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := renderTemplates([][]byte{[]byte(tt.data)}, cluster)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(HaveLen(1))
+			g.Expect(string(got[0])).To(Equal(tt.want))
+		})
+	}
+}
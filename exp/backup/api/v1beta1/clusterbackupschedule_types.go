@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// DefaultRetention is the number of backups kept when the retention is not specified.
+	DefaultRetention int32 = 7
+
+	// S3AccessKeyIDKey is the key of the access key id in the S3 credentials Secret.
+	S3AccessKeyIDKey = "accessKeyID"
+
+	// S3SecretAccessKeyKey is the key of the secret access key in the S3 credentials Secret.
+	S3SecretAccessKeyKey = "secretAccessKey" //nolint:gosec
+
+	// S3SessionTokenKey is the key of the optional session token in the S3 credentials Secret.
+	S3SessionTokenKey = "sessionToken" //nolint:gosec
+)
+
+// ANCHOR: ClusterBackupScheduleSpec
+
+// ClusterBackupScheduleSpec defines the desired state of ClusterBackupSchedule.
+type ClusterBackupScheduleSpec struct {
+	// Namespaces whose Cluster API objects are backed up, each one into a separate archive.
+	// If empty, the Cluster API objects in all the namespaces are backed up into a single archive.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Interval between two backups, e.g. 6h.
+	Interval metav1.Duration `json:"interval"`
+
+	// Destination where the backup archives are stored.
+	Destination BackupDestination `json:"destination"`
+
+	// Encryption defines the keys the backup archives are encrypted for.
+	// If not set, the backup archives are stored unencrypted.
+	// +optional
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+
+	// Retention is the number of successful backups to keep; older backups are deleted from the destination.
+	// Defaults to 7.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+
+	// Suspend tells the controller to suspend subsequent backups; it does not affect the backups already stored.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// ANCHOR_END: ClusterBackupScheduleSpec
+
+// BackupDestination defines where the backup archives are stored.
+type BackupDestination struct {
+	// S3 defines a bucket of a S3 compatible object storage, e.g. AWS S3 or MinIO.
+	// +optional
+	S3 *S3BackupDestination `json:"s3,omitempty"`
+}
+
+// S3BackupDestination defines a bucket of a S3 compatible object storage.
+type S3BackupDestination struct {
+	// Endpoint is the URL of the object storage, e.g. https://minio.example.com:9000.
+	// If empty, the AWS S3 endpoint for the region is used.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region of the bucket. Defaults to us-east-1.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Bucket storing the backup archives.
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix of the backup archive names in the bucket.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret, in the same namespace of the ClusterBackupSchedule,
+	// with the accessKeyID, secretAccessKey and, optionally, sessionToken keys.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretName string `json:"credentialsSecretName"`
+}
+
+// BackupEncryption defines the keys the backup archives are encrypted for.
+// Only one of AgeRecipients and PGPPublicKeys can be set.
+type BackupEncryption struct {
+	// AgeRecipients are the age X25519 recipients, in the format age1..., the backup archives are encrypted for.
+	// +optional
+	AgeRecipients []string `json:"ageRecipients,omitempty"`
+
+	// PGPPublicKeys are the armored PGP public keys the backup archives are encrypted for.
+	// +optional
+	PGPPublicKeys string `json:"pgpPublicKeys,omitempty"`
+}
+
+// ANCHOR: ClusterBackupScheduleStatus
+
+// ClusterBackupScheduleStatus defines the observed state of ClusterBackupSchedule.
+type ClusterBackupScheduleStatus struct {
+	// LastSuccessfulBackupTime is the time of the last successful backup.
+	// +optional
+	LastSuccessfulBackupTime *metav1.Time `json:"lastSuccessfulBackupTime,omitempty"`
+
+	// Backups are the successful backups kept in the destination, the most recent first.
+	// +optional
+	Backups []ClusterBackup `json:"backups,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed ClusterBackupSchedule.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions defines current state of the ClusterBackupSchedule.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// ANCHOR_END: ClusterBackupScheduleStatus
+
+// ClusterBackup is a point-in-time backup of the Cluster API objects.
+type ClusterBackup struct {
+	// Time the backup was taken.
+	Time metav1.Time `json:"time"`
+
+	// Archives are the names of the backup archives in the destination, one for each namespace.
+	Archives []string `json:"archives"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (m *ClusterBackupSchedule) GetConditions() clusterv1.Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (m *ClusterBackupSchedule) SetConditions(conditions clusterv1.Conditions) {
+	m.Status.Conditions = conditions
+}
+
+// GetRetention returns the number of backups to keep.
+func (m *ClusterBackupSchedule) GetRetention() int {
+	if m.Spec.Retention == nil {
+		return int(DefaultRetention)
+	}
+	return int(*m.Spec.Retention)
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clusterbackupschedules,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Interval",type="string",JSONPath=".spec.interval",description="Interval between two backups"
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspend",description="Backups are suspended"
+// +kubebuilder:printcolumn:name="Last Backup",type="date",JSONPath=".status.lastSuccessfulBackupTime",description="Time since the last successful backup"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of ClusterBackupSchedule"
+
+// ClusterBackupSchedule is the Schema for the clusterbackupschedules API.
+type ClusterBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterBackupScheduleSpec   `json:"spec,omitempty"`
+	Status ClusterBackupScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterBackupScheduleList contains a list of ClusterBackupSchedule.
+type ClusterBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterBackupSchedule{}, &ClusterBackupScheduleList{})
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+// Conditions and condition Reasons for the ClusterBackupSchedule object
+
+const (
+	// BackupSucceededCondition documents that the last backup of the Cluster API objects completed successfully.
+	BackupSucceededCondition clusterv1.ConditionType = "BackupSucceeded"
+
+	// BackupSuspendedReason (Severity=Info) documents that backups are suspended.
+	BackupSuspendedReason = "BackupSuspended"
+
+	// DestinationFailedReason (Severity=Error) documents a failure getting the destination of the backup archives,
+	// e.g. because the credentials Secret is missing.
+	DestinationFailedReason = "DestinationFailed"
+
+	// EncryptionFailedReason (Severity=Error) documents a failure encrypting the backup archives, e.g. because of invalid keys.
+	EncryptionFailedReason = "EncryptionFailed"
+
+	// BackupFailedReason (Severity=Error) documents a failure reading the Cluster API objects or writing the backup archives.
+	BackupFailedReason = "BackupFailed"
+)
+
+const (
+	// RetentionAppliedCondition documents that the backups exceeding the retention were deleted from the destination.
+	RetentionAppliedCondition clusterv1.ConditionType = "RetentionApplied"
+
+	// RetentionFailedReason (Severity=Warning) documents a failure deleting the backups exceeding the retention.
+	RetentionFailedReason = "RetentionFailed"
+)
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	backupv1 "sigs.k8s.io/cluster-api/exp/backup/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/backup"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+const (
+	// backupTimeFormat is the format of the timestamp in the backup archive names.
+	backupTimeFormat = "20060102T150405Z"
+
+	// allNamespacesArchive is the archive name used when the backup includes all the namespaces.
+	allNamespacesArchive = "all-namespaces"
+)
+
+// +kubebuilder:rbac:groups=backup.cluster.x-k8s.io,resources=clusterbackupschedules,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=backup.cluster.x-k8s.io,resources=clusterbackupschedules/status;clusterbackupschedules/finalizers,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets;configmaps,verbs=get;list;watch
+
+// ClusterBackupScheduleReconciler reconciles a ClusterBackupSchedule object, periodically saving the
+// Cluster API objects to the configured destination.
+type ClusterBackupScheduleReconciler struct {
+	Client client.Client
+
+	WatchFilterValue string
+
+	// backupArchive, newDestination and now are injectable for testing.
+	backupArchive  func(ctx context.Context, namespace string, w io.Writer) error
+	newDestination func(options backup.S3Options) (backup.Destination, error)
+	now            func() time.Time
+}
+
+func (r *ClusterBackupScheduleReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	if r.backupArchive == nil {
+		r.backupArchive = r.snapshotArchive
+	}
+	if r.newDestination == nil {
+		r.newDestination = func(options backup.S3Options) (backup.Destination, error) {
+			return backup.NewS3Destination(options)
+		}
+	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1.ClusterBackupSchedule{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	return nil
+}
+
+func (r *ClusterBackupScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	// Fetch the ClusterBackupSchedule instance.
+	schedule := &backupv1.ClusterBackupSchedule{}
+	if err := r.Client.Get(ctx, req.NamespacedName, schedule); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return. The backups already stored are kept in the destination.
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	// Handle deletion reconciliation loop; there is nothing to clean up, because backups outlive their schedule.
+	if !schedule.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(schedule, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always update the readyCondition by summarizing the state of other conditions.
+		conditions.SetSummary(schedule,
+			conditions.WithConditions(
+				backupv1.BackupSucceededCondition,
+				backupv1.RetentionAppliedCondition,
+			),
+		)
+
+		// Always attempt to Patch the ClusterBackupSchedule object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, schedule,
+			patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+				clusterv1.ReadyCondition,
+				backupv1.BackupSucceededCondition,
+				backupv1.RetentionAppliedCondition,
+			}},
+			patch.WithStatusObservedGeneration{},
+		); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	return r.reconcile(ctx, schedule)
+}
+
+func (r *ClusterBackupScheduleReconciler) reconcile(ctx context.Context, schedule *backupv1.ClusterBackupSchedule) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if schedule.Spec.Suspend {
+		conditions.MarkFalse(schedule, backupv1.BackupSucceededCondition, backupv1.BackupSuspendedReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	interval := schedule.Spec.Interval.Duration
+	if interval <= 0 {
+		conditions.MarkFalse(schedule, backupv1.BackupSucceededCondition, backupv1.BackupFailedReason, clusterv1.ConditionSeverityError, "the interval must be greater than zero")
+		return ctrl.Result{}, nil
+	}
+
+	now := r.now().UTC().Truncate(time.Second)
+	backupDue := schedule.Status.LastSuccessfulBackupTime == nil || !now.Before(schedule.Status.LastSuccessfulBackupTime.Add(interval))
+	retentionDue := len(schedule.Status.Backups) > schedule.GetRetention()
+	if !backupDue && !retentionDue {
+		// A backup is attempted only when due, so if the next one is not due yet the last one succeeded.
+		conditions.MarkTrue(schedule, backupv1.BackupSucceededCondition)
+		conditions.MarkTrue(schedule, backupv1.RetentionAppliedCondition)
+		return ctrl.Result{RequeueAfter: schedule.Status.LastSuccessfulBackupTime.Add(interval).Sub(now)}, nil
+	}
+
+	destination, err := r.getDestination(ctx, schedule)
+	if err != nil {
+		if backupDue {
+			conditions.MarkFalse(schedule, backupv1.BackupSucceededCondition, backupv1.DestinationFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		} else {
+			conditions.MarkFalse(schedule, backupv1.RetentionAppliedCondition, backupv1.RetentionFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	if backupDue {
+		log.Info("Taking a backup of the Cluster API objects")
+		if err := r.reconcileBackup(ctx, schedule, destination, now); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	conditions.MarkTrue(schedule, backupv1.BackupSucceededCondition)
+
+	if err := r.reconcileRetention(ctx, schedule, destination); err != nil {
+		conditions.MarkFalse(schedule, backupv1.RetentionAppliedCondition, backupv1.RetentionFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+	conditions.MarkTrue(schedule, backupv1.RetentionAppliedCondition)
+
+	return ctrl.Result{RequeueAfter: schedule.Status.LastSuccessfulBackupTime.Add(interval).Sub(now)}, nil
+}
+
+// reconcileBackup saves the Cluster API objects of each namespace to an archive in the destination, and records the backup in the status.
+// If the backup fails, the archives already written are deleted, so only complete backups are kept in the destination.
+func (r *ClusterBackupScheduleReconciler) reconcileBackup(ctx context.Context, schedule *backupv1.ClusterBackupSchedule, destination backup.Destination, now time.Time) error {
+	encrypter, extension, err := getEncrypter(schedule)
+	if err != nil {
+		conditions.MarkFalse(schedule, backupv1.BackupSucceededCondition, backupv1.EncryptionFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return err
+	}
+
+	namespaces := schedule.Spec.Namespaces
+	if len(namespaces) == 0 {
+		// An empty namespace means all the namespaces.
+		namespaces = []string{""}
+	}
+
+	archives := []string{}
+	for _, namespace := range namespaces {
+		name := archiveName(schedule, now, namespace) + extension
+		if err := r.writeArchive(ctx, namespace, encrypter, destination, name); err != nil {
+			errList := []error{errors.Wrapf(err, "failed to backup the Cluster API objects to %q", name)}
+			for _, archive := range archives {
+				if err := destination.Delete(archive); err != nil {
+					errList = append(errList, err)
+				}
+			}
+			err = kerrors.NewAggregate(errList)
+			conditions.MarkFalse(schedule, backupv1.BackupSucceededCondition, backupv1.BackupFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return err
+		}
+		archives = append(archives, name)
+	}
+
+	backupTime := metav1.NewTime(now)
+	schedule.Status.LastSuccessfulBackupTime = &backupTime
+	schedule.Status.Backups = append([]backupv1.ClusterBackup{{Time: backupTime, Archives: archives}}, schedule.Status.Backups...)
+	return nil
+}
+
+// reconcileRetention deletes from the destination the oldest backups exceeding the retention.
+func (r *ClusterBackupScheduleReconciler) reconcileRetention(ctx context.Context, schedule *backupv1.ClusterBackupSchedule, destination backup.Destination) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	for i := len(schedule.Status.Backups) - 1; i >= schedule.GetRetention(); i-- {
+		log.Info("Deleting a backup exceeding the retention", "time", schedule.Status.Backups[i].Time)
+		for _, archive := range schedule.Status.Backups[i].Archives {
+			if err := destination.Delete(archive); err != nil {
+				return err
+			}
+		}
+		schedule.Status.Backups = schedule.Status.Backups[:i]
+	}
+	return nil
+}
+
+// getDestination returns the destination of the backup archives, using the credentials in the Secret referenced by the ClusterBackupSchedule.
+func (r *ClusterBackupScheduleReconciler) getDestination(ctx context.Context, schedule *backupv1.ClusterBackupSchedule) (backup.Destination, error) {
+	s3 := schedule.Spec.Destination.S3
+	if s3 == nil {
+		return nil, errors.New("the backup destination must be defined")
+	}
+
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{Namespace: schedule.Namespace, Name: s3.CredentialsSecretName}
+	if err := r.Client.Get(ctx, secretKey, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the S3 credentials Secret %s", secretKey)
+	}
+
+	return r.newDestination(backup.S3Options{
+		Endpoint:        s3.Endpoint,
+		Region:          s3.Region,
+		Bucket:          s3.Bucket,
+		AccessKeyID:     string(secret.Data[backupv1.S3AccessKeyIDKey]),
+		SecretAccessKey: string(secret.Data[backupv1.S3SecretAccessKeyKey]),
+		SessionToken:    string(secret.Data[backupv1.S3SessionTokenKey]),
+	})
+}
+
+// getEncrypter returns the encrypter for the keys defined in the ClusterBackupSchedule and the extension of the
+// encrypted archives; if no keys are defined, the encrypter is nil.
+func getEncrypter(schedule *backupv1.ClusterBackupSchedule) (backup.Encrypter, string, error) {
+	encryption := schedule.Spec.Encryption
+	if encryption == nil || (len(encryption.AgeRecipients) == 0 && encryption.PGPPublicKeys == "") {
+		return nil, "", nil
+	}
+	if len(encryption.AgeRecipients) > 0 && encryption.PGPPublicKeys != "" {
+		return nil, "", errors.New("age recipients and PGP public keys cannot be used at the same time")
+	}
+
+	if len(encryption.AgeRecipients) > 0 {
+		encrypter, err := backup.NewAgeEncrypter(encryption.AgeRecipients...)
+		if err != nil {
+			return nil, "", err
+		}
+		return encrypter, ".age", nil
+	}
+
+	encrypter, err := backup.NewPGPEncrypter([]byte(encryption.PGPPublicKeys))
+	if err != nil {
+		return nil, "", err
+	}
+	return encrypter, ".pgp", nil
+}
+
+// writeArchive saves the Cluster API objects of a namespace to an archive, encrypting it if an encrypter is defined.
+func (r *ClusterBackupScheduleReconciler) writeArchive(ctx context.Context, namespace string, encrypter backup.Encrypter, destination backup.Destination, name string) error {
+	buf := &bytes.Buffer{}
+	if err := r.backupArchive(ctx, namespace, buf); err != nil {
+		return err
+	}
+
+	data := buf.Bytes()
+	if encrypter != nil {
+		var err error
+		if data, err = encrypter.Encrypt(data); err != nil {
+			return err
+		}
+	}
+
+	return destination.Write(name, data)
+}
+
+// archiveName returns the name of the archive of a namespace, e.g. prefix/default/daily/20211019T120000Z/default.tar.gz.
+func archiveName(schedule *backupv1.ClusterBackupSchedule, t time.Time, namespace string) string {
+	if namespace == "" {
+		namespace = allNamespacesArchive
+	}
+	return path.Join(schedule.Spec.Destination.S3.Prefix, schedule.Namespace, schedule.Name, t.UTC().Format(backupTimeFormat), namespace+".tar.gz")
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	backupv1 "sigs.k8s.io/cluster-api/exp/backup/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/backup"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// The test identity was generated with age v1.0.0.
+const (
+	testAgeIdentity  = "AGE-SECRET-KEY-1VPEN9T2JX788Y362PLPM246UH74GUZKCMSM57R092L7AR6VFE5UQ6PDH6S"
+	testAgeRecipient = "age1xcpsvm5cq8quwrmczan9qjzg3wpk00auqlyn7d44tvtkw75seycs0du9ea"
+)
+
+// fakeBackupArchive writes the namespace as the content of the backup archives.
+func fakeBackupArchive(failNamespace string) func(ctx context.Context, namespace string, w io.Writer) error {
+	return func(_ context.Context, namespace string, w io.Writer) error {
+		if failNamespace != "" && namespace == failNamespace {
+			return errors.Errorf("failed to backup %q", namespace)
+		}
+		_, err := w.Write([]byte("backup of " + namespace))
+		return err
+	}
+}
+
+func TestClusterBackupScheduleReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 10, 19, 12, 0, 0, 0, time.UTC)
+	previous := metav1.NewTime(now.Add(-6 * time.Hour))
+	oldest := metav1.NewTime(now.Add(-12 * time.Hour))
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "credentials"},
+		Data: map[string][]byte{
+			backupv1.S3AccessKeyIDKey:     []byte("id"),
+			backupv1.S3SecretAccessKeyKey: []byte("secret"),
+		},
+	}
+
+	newSchedule := func() *backupv1.ClusterBackupSchedule {
+		return &backupv1.ClusterBackupSchedule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "daily"},
+			Spec: backupv1.ClusterBackupScheduleSpec{
+				Namespaces: []string{"ns1", "ns2"},
+				Interval:   metav1.Duration{Duration: 6 * time.Hour},
+				Destination: backupv1.BackupDestination{
+					S3: &backupv1.S3BackupDestination{
+						Bucket:                "bucket",
+						Prefix:                "backups",
+						CredentialsSecretName: "credentials",
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		schedule         func() *backupv1.ClusterBackupSchedule
+		failNamespace    string
+		existingArchives []string
+		wantErr          bool
+		wantRequeueAfter time.Duration
+		wantArchives     []string
+		wantBackups      int
+		wantCondition    *clusterv1.Condition
+	}{
+		{
+			name:             "takes the first backup",
+			schedule:         newSchedule,
+			wantRequeueAfter: 6 * time.Hour,
+			wantArchives: []string{
+				"backups/default/daily/20211019T120000Z/ns1.tar.gz",
+				"backups/default/daily/20211019T120000Z/ns2.tar.gz",
+			},
+			wantBackups:   1,
+			wantCondition: conditions.TrueCondition(backupv1.BackupSucceededCondition),
+		},
+		{
+			name: "takes a backup of all the namespaces if none is defined",
+			schedule: func() *backupv1.ClusterBackupSchedule {
+				s := newSchedule()
+				s.Spec.Namespaces = nil
+				return s
+			},
+			wantRequeueAfter: 6 * time.Hour,
+			wantArchives: []string{
+				"backups/default/daily/20211019T120000Z/all-namespaces.tar.gz",
+			},
+			wantBackups:   1,
+			wantCondition: conditions.TrueCondition(backupv1.BackupSucceededCondition),
+		},
+		{
+			name: "does not take a backup before the interval elapses",
+			schedule: func() *backupv1.ClusterBackupSchedule {
+				s := newSchedule()
+				lastBackup := metav1.NewTime(now.Add(-time.Hour))
+				s.Status.LastSuccessfulBackupTime = &lastBackup
+				s.Status.Backups = []backupv1.ClusterBackup{{Time: lastBackup, Archives: []string{"last.tar.gz"}}}
+				return s
+			},
+			existingArchives: []string{"last.tar.gz"},
+			wantRequeueAfter: 5 * time.Hour,
+			wantArchives:     []string{"last.tar.gz"},
+			wantBackups:      1,
+			wantCondition:    conditions.TrueCondition(backupv1.BackupSucceededCondition),
+		},
+		{
+			name: "deletes the backups exceeding the retention",
+			schedule: func() *backupv1.ClusterBackupSchedule {
+				s := newSchedule()
+				s.Spec.Namespaces = []string{"ns1"}
+				s.Spec.Retention = pointer.Int32(2)
+				s.Status.LastSuccessfulBackupTime = &previous
+				s.Status.Backups = []backupv1.ClusterBackup{
+					{Time: previous, Archives: []string{"previous.tar.gz"}},
+					{Time: oldest, Archives: []string{"oldest.tar.gz"}},
+				}
+				return s
+			},
+			existingArchives: []string{"previous.tar.gz", "oldest.tar.gz"},
+			wantRequeueAfter: 6 * time.Hour,
+			wantArchives: []string{
+				"backups/default/daily/20211019T120000Z/ns1.tar.gz",
+				"previous.tar.gz",
+			},
+			wantBackups:   2,
+			wantCondition: conditions.TrueCondition(backupv1.RetentionAppliedCondition),
+		},
+		{
+			name: "does not take a backup if suspended",
+			schedule: func() *backupv1.ClusterBackupSchedule {
+				s := newSchedule()
+				s.Spec.Suspend = true
+				return s
+			},
+			wantCondition: conditions.FalseCondition(backupv1.BackupSucceededCondition, backupv1.BackupSuspendedReason, clusterv1.ConditionSeverityInfo, ""),
+		},
+		{
+			name: "fails if the credentials Secret does not exist",
+			schedule: func() *backupv1.ClusterBackupSchedule {
+				s := newSchedule()
+				s.Spec.Destination.S3.CredentialsSecretName = "does-not-exist"
+				return s
+			},
+			wantErr:       true,
+			wantCondition: &clusterv1.Condition{Type: backupv1.BackupSucceededCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityError, Reason: backupv1.DestinationFailedReason},
+		},
+		{
+			name: "fails if the encryption keys are not valid",
+			schedule: func() *backupv1.ClusterBackupSchedule {
+				s := newSchedule()
+				s.Spec.Encryption = &backupv1.BackupEncryption{AgeRecipients: []string{"not-a-recipient"}}
+				return s
+			},
+			wantErr:       true,
+			wantCondition: &clusterv1.Condition{Type: backupv1.BackupSucceededCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityError, Reason: backupv1.EncryptionFailedReason},
+		},
+		{
+			name:          "deletes the archives already written if the backup fails",
+			schedule:      newSchedule,
+			failNamespace: "ns2",
+			wantErr:       true,
+			wantCondition: &clusterv1.Condition{Type: backupv1.BackupSucceededCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityError, Reason: backupv1.BackupFailedReason},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := os.MkdirTemp("", "cluster-backup")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			destination := backup.NewLocalDestination(dir)
+			for _, archive := range tt.existingArchives {
+				g.Expect(destination.Write(archive, []byte(archive))).To(Succeed())
+			}
+
+			schedule := tt.schedule()
+			r := &ClusterBackupScheduleReconciler{
+				Client:        fake.NewClientBuilder().WithScheme(newTestScheme(g)).WithObjects(schedule, credentials).Build(),
+				backupArchive: fakeBackupArchive(tt.failNamespace),
+				newDestination: func(options backup.S3Options) (backup.Destination, error) {
+					g.Expect(options.Bucket).To(Equal("bucket"))
+					g.Expect(options.AccessKeyID).To(Equal("id"))
+					g.Expect(options.SecretAccessKey).To(Equal("secret"))
+					return destination, nil
+				},
+				now: func() time.Time { return now },
+			}
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(schedule)})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(result.RequeueAfter).To(Equal(tt.wantRequeueAfter))
+			g.Expect(listArchives(g, dir)).To(ConsistOf(tt.wantArchives))
+
+			got := &backupv1.ClusterBackupSchedule{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(schedule), got)).To(Succeed())
+			g.Expect(got.Status.Backups).To(HaveLen(tt.wantBackups))
+			if tt.wantBackups > 0 {
+				g.Expect(got.Status.LastSuccessfulBackupTime.Time).To(Equal(got.Status.Backups[0].Time.Time))
+			}
+
+			condition := conditions.Get(got, tt.wantCondition.Type)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(tt.wantCondition.Status))
+			g.Expect(condition.Severity).To(Equal(tt.wantCondition.Severity))
+			g.Expect(condition.Reason).To(Equal(tt.wantCondition.Reason))
+		})
+	}
+}
+
+func TestClusterBackupScheduleReconciler_ReconcileEncryption(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "cluster-backup")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	schedule := &backupv1.ClusterBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "daily"},
+		Spec: backupv1.ClusterBackupScheduleSpec{
+			Namespaces:  []string{"ns1"},
+			Interval:    metav1.Duration{Duration: time.Hour},
+			Destination: backupv1.BackupDestination{S3: &backupv1.S3BackupDestination{Bucket: "bucket", CredentialsSecretName: "credentials"}},
+			Encryption:  &backupv1.BackupEncryption{AgeRecipients: []string{testAgeRecipient}},
+		},
+	}
+	credentials := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "credentials"}}
+
+	r := &ClusterBackupScheduleReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(newTestScheme(g)).WithObjects(schedule, credentials).Build(),
+		backupArchive: fakeBackupArchive(""),
+		newDestination: func(options backup.S3Options) (backup.Destination, error) {
+			return backup.NewLocalDestination(dir), nil
+		},
+		now: func() time.Time { return time.Date(2021, 10, 19, 12, 0, 0, 0, time.UTC) },
+	}
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(schedule)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	data, err := os.ReadFile(filepath.Join(dir, "default", "daily", "20211019T120000Z", "ns1.tar.gz.age"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(backup.IsEncrypted(data)).To(BeTrue())
+
+	decrypter, err := backup.NewAgeDecrypter([]byte(testAgeIdentity))
+	g.Expect(err).NotTo(HaveOccurred())
+	plaintext, err := decrypter.Decrypt(data)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(plaintext)).To(Equal("backup of ns1"))
+}
+
+func newTestScheme(g *WithT) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(backupv1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+// listArchives returns the names of the archives stored in a directory.
+func listArchives(g *WithT, dir string) []string {
+	archives := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		archives = append(archives, filepath.ToSlash(name))
+		return err
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	return archives
+}
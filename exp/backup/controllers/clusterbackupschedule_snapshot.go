@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/internal/backup"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// snapshotArchive saves the Cluster API objects existing in a namespace (or in all the namespaces if empty) to a gzip'd tar
+// archive, with the same layout of the archives created by clusterctl backup, so they can be restored with clusterctl restore.
+// NOTE: the snapshot differs from clusterctl backup as follows:
+//   - it only reads objects and never pauses the Clusters, so an interrupted backup cannot leave them paused; as a consequence,
+//     objects are read while being reconciled, and they are consistent only if the Clusters are not changing while the backup is taken.
+//   - it includes all the objects of the providers' namespaced types, not only the ones linked to a Cluster via the owner chain.
+//   - Secrets and ConfigMaps are included if they are owned by an object in the snapshot or if they belong to a Cluster by
+//     name or label, without walking the rest of the owner chain.
+func (r *ClusterBackupScheduleReconciler) snapshotArchive(ctx context.Context, namespace string, w io.Writer) error {
+	objs, err := r.getSnapshotObjects(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	archive := backup.NewArchiveWriter(w)
+	for i := range objs {
+		data, err := objs[i].MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if err := archive.Write(snapshotFilename(&objs[i]), data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// getSnapshotObjects returns the objects of the types defined by the providers' CRDs existing in a namespace (or in all the
+// namespaces if empty), plus the Secrets and the ConfigMaps belonging to them.
+// Cluster scoped objects, e.g. the global identities of some infrastructure providers, are included only if their CRD has the
+// clusterctl move or move-hierarchy label, as in clusterctl backup.
+func (r *ClusterBackupScheduleReconciler) getSnapshotObjects(ctx context.Context, namespace string) ([]unstructured.Unstructured, error) {
+	listOptions := []client.ListOption{}
+	if namespace != "" {
+		listOptions = append(listOptions, client.InNamespace(namespace))
+	}
+
+	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, crdList, client.HasLabels{clusterv1.ProviderLabelName}); err != nil {
+		return nil, errors.Wrap(err, "failed to list the CRDs of the providers")
+	}
+
+	objs := []unstructured.Unstructured{}
+	uids := map[types.UID]bool{}
+	clusters := map[string][]string{}
+	for _, crd := range crdList.Items {
+		crdListOptions := listOptions
+		if crd.Spec.Scope != apiextensionsv1.NamespaceScoped {
+			if !isForceMove(&crd) {
+				continue
+			}
+			crdListOptions = nil
+		}
+		for _, version := range crd.Spec.Versions {
+			if !version.Storage {
+				continue
+			}
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind + "List"})
+			if err := r.Client.List(ctx, list, crdListOptions...); err != nil {
+				return nil, errors.Wrapf(err, "failed to list %s", crd.Name)
+			}
+			for _, obj := range list.Items {
+				uids[obj.GetUID()] = true
+				if crd.Spec.Group == clusterv1.GroupVersion.Group && crd.Spec.Names.Kind == "Cluster" {
+					clusters[obj.GetNamespace()] = append(clusters[obj.GetNamespace()], obj.GetName())
+				}
+			}
+			objs = append(objs, list.Items...)
+		}
+	}
+
+	for _, kind := range []string{"SecretList", "ConfigMapList"} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+		if err := r.Client.List(ctx, list, listOptions...); err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s", kind)
+		}
+		for _, obj := range list.Items {
+			if belongsToSnapshot(&obj, uids, clusters[obj.GetNamespace()]) {
+				objs = append(objs, obj)
+			}
+		}
+	}
+	return objs, nil
+}
+
+// isForceMove returns true if the CRD has the clusterctl move or move-hierarchy label, i.e. its objects are moved and
+// backed up by clusterctl even if they are not linked to a Cluster.
+func isForceMove(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	if _, ok := crd.Labels[clusterctlv1.ClusterctlMoveLabelName]; ok {
+		return true
+	}
+	_, ok := crd.Labels[clusterctlv1.ClusterctlMoveHierarchyLabelName]
+	return ok
+}
+
+// belongsToSnapshot returns true if an object is owned by an object in the snapshot, or if it belongs to one of the Clusters
+// in its namespace, either because of the cluster name label or because of the naming convention, e.g. <cluster>-kubeconfig.
+func belongsToSnapshot(obj *unstructured.Unstructured, uids map[types.UID]bool, clusters []string) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if uids[ref.UID] {
+			return true
+		}
+	}
+	for _, cluster := range clusters {
+		if obj.GetLabels()[clusterv1.ClusterLabelName] == cluster || strings.HasPrefix(obj.GetName(), cluster+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotFilename returns the name of the file storing an object in the archive, e.g. Cluster_default_my-cluster.yaml,
+// as in the archives created by clusterctl backup.
+func snapshotFilename(obj *unstructured.Unstructured) string {
+	return obj.GetKind() + "_" + obj.GetNamespace() + "_" + obj.GetName() + ".yaml"
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/internal/backup"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterBackupScheduleReconciler_snapshotArchive(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	newCRD := func(group, kind string, scope apiextensionsv1.ResourceScope, labels map[string]string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: kind + "s." + group, Labels: labels},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: group,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: kind},
+				Scope: scope,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha4"},
+					{Name: "v1beta1", Storage: true},
+				},
+			},
+		}
+	}
+	newObj := func(apiVersion, kind, namespace, name string, uid types.UID) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetUID(uid)
+		return obj
+	}
+	providerLabels := map[string]string{clusterv1.ProviderLabelName: "cluster-api"}
+
+	infraCluster := newObj("infrastructure.cluster.x-k8s.io/v1beta1", "GenericInfrastructureCluster", "ns1", "my-cluster", "infra-cluster-uid")
+	ownedSecret := newObj("v1", "Secret", "ns1", "owned", "owned-secret-uid")
+	ownedSecret.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: infraCluster.GetAPIVersion(), Kind: infraCluster.GetKind(), Name: infraCluster.GetName(), UID: infraCluster.GetUID()}})
+	labeledConfigMap := newObj("v1", "ConfigMap", "ns1", "labeled", "labeled-configmap-uid")
+	labeledConfigMap.SetLabels(map[string]string{clusterv1.ClusterLabelName: "my-cluster"})
+	globalIdentity := newObj("infrastructure.cluster.x-k8s.io/v1beta1", "GenericClusterRoleIdentity", "", "role-identity", "role-identity-uid")
+	globalIdentitySecret := newObj("v1", "Secret", "ns1", "role-identity-credentials", "role-identity-secret-uid")
+	globalIdentitySecret.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: globalIdentity.GetAPIVersion(), Kind: globalIdentity.GetKind(), Name: globalIdentity.GetName(), UID: globalIdentity.GetUID()}})
+
+	objs := []client.Object{
+		newCRD(clusterv1.GroupVersion.Group, "Cluster", apiextensionsv1.NamespaceScoped, providerLabels),
+		newCRD("infrastructure.cluster.x-k8s.io", "GenericInfrastructureCluster", apiextensionsv1.NamespaceScoped, providerLabels),
+		newCRD("infrastructure.cluster.x-k8s.io", "GenericClusterIdentity", apiextensionsv1.ClusterScoped, providerLabels),
+		newCRD("infrastructure.cluster.x-k8s.io", "GenericClusterRoleIdentity", apiextensionsv1.ClusterScoped, map[string]string{
+			clusterv1.ProviderLabelName:                   "infrastructure-generic",
+			clusterctlv1.ClusterctlMoveHierarchyLabelName: "",
+		}),
+		newCRD("example.com", "Unrelated", apiextensionsv1.NamespaceScoped, nil),
+		newObj(clusterv1.GroupVersion.String(), "Cluster", "ns1", "my-cluster", "cluster-uid"),
+		newObj(clusterv1.GroupVersion.String(), "Cluster", "ns2", "other-cluster", "other-cluster-uid"),
+		infraCluster,
+		newObj("infrastructure.cluster.x-k8s.io/v1beta1", "GenericClusterIdentity", "", "identity", "identity-uid"),
+		globalIdentity,
+		globalIdentitySecret,
+		newObj("example.com/v1beta1", "Unrelated", "ns1", "unrelated", "unrelated-uid"),
+		newObj("v1", "Secret", "ns1", "my-cluster-kubeconfig", "kubeconfig-uid"),
+		ownedSecret,
+		labeledConfigMap,
+		newObj("v1", "Secret", "ns1", "unrelated", "unrelated-secret-uid"),
+		newObj("v1", "Secret", "ns2", "my-cluster-kubeconfig", "other-kubeconfig-uid"),
+	}
+
+	scheme := newTestScheme(g)
+	g.Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	r := &ClusterBackupScheduleReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+	}
+
+	buf := &bytes.Buffer{}
+	g.Expect(r.snapshotArchive(ctx, "ns1", buf)).To(Succeed())
+
+	files, err := backup.ReadArchive(buf)
+	g.Expect(err).NotTo(HaveOccurred())
+	got := []string{}
+	for _, file := range files {
+		obj := &unstructured.Unstructured{}
+		g.Expect(obj.UnmarshalJSON(file)).To(Succeed())
+		got = append(got, snapshotFilename(obj))
+	}
+	g.Expect(got).To(ConsistOf(
+		"Cluster_ns1_my-cluster.yaml",
+		"GenericInfrastructureCluster_ns1_my-cluster.yaml",
+		"Secret_ns1_my-cluster-kubeconfig.yaml",
+		"Secret_ns1_owned.yaml",
+		"ConfigMap_ns1_labeled.yaml",
+		// Cluster scoped objects are included only if their CRD has the clusterctl move or move-hierarchy label,
+		// together with the Secrets they own.
+		"GenericClusterRoleIdentity__role-identity.yaml",
+		"Secret_ns1_role-identity-credentials.yaml",
+	))
+
+	// The snapshot never changes the objects, e.g. it doesn't pause the Clusters.
+	cluster := newObj(clusterv1.GroupVersion.String(), "Cluster", "", "", "")
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "my-cluster"}, cluster)).To(Succeed())
+	g.Expect(cluster.Object).NotTo(HaveKey("spec"))
+
+	// The objects of all the namespaces are included if the namespace is empty, e.g. the Cluster in ns2, but Secrets are
+	// matched only with the Clusters in their namespace.
+	buf = &bytes.Buffer{}
+	g.Expect(r.snapshotArchive(ctx, "", buf)).To(Succeed())
+	files, err = backup.ReadArchive(buf)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(HaveLen(8))
+}
@@ -44,6 +44,11 @@ const (
 	//
 	// alpha: v0.4
 	ClusterTopology featuregate.Feature = "ClusterTopology"
+
+	// ClusterBackup is a feature gate for the ClusterBackupSchedule functionality.
+	//
+	// alpha: v1.0
+	ClusterBackup featuregate.Feature = "ClusterBackup"
 )
 
 func init() {
@@ -57,4 +62,5 @@ var defaultClusterAPIFeatureGates = map[featuregate.Feature]featuregate.FeatureS
 	MachinePool:        {Default: false, PreRelease: featuregate.Alpha},
 	ClusterResourceSet: {Default: true, PreRelease: featuregate.Beta},
 	ClusterTopology:    {Default: false, PreRelease: featuregate.Alpha},
+	ClusterBackup:      {Default: false, PreRelease: featuregate.Alpha},
 }
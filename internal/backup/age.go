@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+)
+
+// AgeEncrypter encrypts data in the age v1 format (https://age-encryption.org/v1) for a list of X25519 recipients,
+// so backups can be encrypted for, and decrypted with, keys generated with the age-keygen tool.
+type AgeEncrypter struct {
+	recipients []age.Recipient
+}
+
+var _ Encrypter = &AgeEncrypter{}
+
+// NewAgeEncrypter returns an encrypter for a list of age recipients, in the format "age1...".
+func NewAgeEncrypter(recipients ...string) (*AgeEncrypter, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one age recipient is required")
+	}
+
+	e := &AgeEncrypter{}
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(r))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid age recipient %q, only X25519 recipients are supported", r)
+		}
+		e.recipients = append(e.recipients, recipient)
+	}
+	return e, nil
+}
+
+// Encrypt returns the data encrypted in the age format.
+func (e *AgeEncrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	out := &bytes.Buffer{}
+	w, err := age.Encrypt(out, e.recipients...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt")
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt")
+	}
+	return out.Bytes(), nil
+}
+
+// AgeDecrypter decrypts data encrypted in the age format with a list of X25519 identities.
+type AgeDecrypter struct {
+	identities []age.Identity
+}
+
+var _ Decrypter = &AgeDecrypter{}
+
+// NewAgeDecrypter returns a decrypter for the identities in an age identity file, as generated by age-keygen;
+// empty lines and lines starting with # are ignored.
+func NewAgeDecrypter(identityFile []byte) (*AgeDecrypter, error) {
+	identities, err := age.ParseIdentities(bytes.NewReader(identityFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid age identities, only X25519 identities are supported")
+	}
+	return &AgeDecrypter{identities: identities}, nil
+}
+
+// Decrypt returns the data decrypted from the age format.
+func (d *AgeDecrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), d.identities...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt")
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"filippo.io/age"
+	. "github.com/onsi/gomega"
+)
+
+// The test identity and the encrypted message were generated with age v1.0.0.
+const (
+	testAgeIdentity   = "AGE-SECRET-KEY-1VPEN9T2JX788Y362PLPM246UH74GUZKCMSM57R092L7AR6VFE5UQ6PDH6S"
+	testAgeRecipient  = "age1xcpsvm5cq8quwrmczan9qjzg3wpk00auqlyn7d44tvtkw75seycs0du9ea"
+	testAgeCiphertext = "YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBMbjZ5STllbkJSY2k1QjcwN1k3MnBQTGlTMXMvQktZNGhGdnl2cEdUK0RvCmY2VTR3RktwclVxbFN6bmJhVmdSNzhrV28vQ3l5c2o4MWwyWDZiN3hyQ00KLS0tIHBheCtJTzcxRzhGQ1A2RnhLaTlNQUt4YkVxMFJFbkMzZzVabzdkR1pkdWcKd4xWbFqVClFjHAETMbPIzsauUWCyiSxGWOgiidu9s/dnVg/2jdZMwE6IaBv+XIyB"
+)
+
+// ageStreamChunkSize is the size of the chunks of the age payload.
+const ageStreamChunkSize = 64 * 1024
+
+// newTestAgeKey returns a new age identity and the corresponding recipient.
+func newTestAgeKey(g *WithT) (string, string) {
+	identity, err := age.GenerateX25519Identity()
+	g.Expect(err).NotTo(HaveOccurred())
+	return identity.String(), identity.Recipient().String()
+}
+
+func TestAge_Decrypt(t *testing.T) {
+	g := NewWithT(t)
+
+	ciphertext, err := base64.StdEncoding.DecodeString(testAgeCiphertext)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(IsEncrypted(ciphertext)).To(BeTrue())
+
+	d, err := NewAgeDecrypter([]byte("# created: 2021-10-01T00:00:00Z\n# public key: " + testAgeRecipient + "\n" + testAgeIdentity + "\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	plaintext, err := d.Decrypt(ciphertext)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(plaintext)).To(Equal("hello clusterctl"))
+}
+
+func TestAge_EncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "smaller than a chunk", size: 1000},
+		{name: "exactly a chunk", size: ageStreamChunkSize},
+		{name: "more than a chunk", size: 3*ageStreamChunkSize + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			identity1, recipient1 := newTestAgeKey(g)
+			identity2, recipient2 := newTestAgeKey(g)
+
+			plaintext := make([]byte, tt.size)
+			_, err := rand.Read(plaintext)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			e, err := NewAgeEncrypter(recipient1, recipient2)
+			g.Expect(err).NotTo(HaveOccurred())
+			ciphertext, err := e.Encrypt(plaintext)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// Every recipient can decrypt.
+			for _, identity := range []string{identity1, identity2} {
+				d, err := NewAgeDecrypter([]byte(identity))
+				g.Expect(err).NotTo(HaveOccurred())
+				got, err := d.Decrypt(ciphertext)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(got).To(Equal(plaintext))
+			}
+		})
+	}
+}
+
+func TestAge_Errors(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewAgeEncrypter()
+	g.Expect(err).To(HaveOccurred())
+	_, err = NewAgeEncrypter("not-a-recipient")
+	g.Expect(err).To(HaveOccurred())
+	_, err = NewAgeEncrypter(testAgeRecipient[:len(testAgeRecipient)-1] + "q")
+	g.Expect(err).To(HaveOccurred())
+	_, err = NewAgeDecrypter([]byte(testAgeIdentity[:len(testAgeIdentity)-1] + "Q"))
+	g.Expect(err).To(HaveOccurred())
+	_, err = NewAgeDecrypter([]byte("# no identities\n"))
+	g.Expect(err).To(HaveOccurred())
+
+	identity, recipient := newTestAgeKey(g)
+	otherIdentity, _ := newTestAgeKey(g)
+
+	e, err := NewAgeEncrypter(recipient)
+	g.Expect(err).NotTo(HaveOccurred())
+	ciphertext, err := e.Encrypt([]byte("secret"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	d, err := NewAgeDecrypter([]byte(otherIdentity))
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = d.Decrypt(ciphertext)
+	g.Expect(err).To(HaveOccurred())
+
+	// Tampering with the payload is detected.
+	d, err = NewAgeDecrypter([]byte(identity))
+	g.Expect(err).NotTo(HaveOccurred())
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	_, err = d.Decrypt(ciphertext)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveWriter writes files into a gzip'd tar archive.
+type ArchiveWriter struct {
+	gz  *gzip.Writer
+	tar *tar.Writer
+}
+
+// NewArchiveWriter returns an ArchiveWriter writing a gzip'd tar archive to w.
+// NOTE: Close must be called for flushing the archive.
+func NewArchiveWriter(w io.Writer) *ArchiveWriter {
+	gz := gzip.NewWriter(w)
+	return &ArchiveWriter{
+		gz:  gz,
+		tar: tar.NewWriter(gz),
+	}
+}
+
+// Write adds a file to the archive.
+func (a *ArchiveWriter) Write(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := a.tar.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "failed to write the header for %q to the archive", name)
+	}
+	if _, err := a.tar.Write(data); err != nil {
+		return errors.Wrapf(err, "failed to write %q to the archive", name)
+	}
+	return nil
+}
+
+// Close flushes and closes the archive; it does not close the underlying writer.
+func (a *ArchiveWriter) Close() error {
+	if err := a.tar.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the archive")
+	}
+	if err := a.gz.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the archive")
+	}
+	return nil
+}
+
+// ReadArchive reads all the files in a gzip'd tar archive, in the order they are stored.
+func ReadArchive(r io.Reader) ([][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the archive, it is not a gzip'd tar archive")
+	}
+	defer gz.Close()
+
+	files := [][]byte{}
+	t := tar.NewReader(gz)
+	for {
+		header, err := t.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the archive")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(t)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %q from the archive", header.Name)
+		}
+		files = append(files, data)
+	}
+	return files, nil
+}
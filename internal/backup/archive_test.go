@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestArchive(t *testing.T) {
+	g := NewWithT(t)
+
+	files := map[string][]byte{
+		"Cluster_ns1_cluster1.yaml":   []byte(`{"kind":"Cluster"}`),
+		"Secret_ns1_cluster1-ca.yaml": []byte(`{"kind":"Secret"}`),
+		"empty.yaml":                  []byte{},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewArchiveWriter(buf)
+	for _, name := range []string{"Cluster_ns1_cluster1.yaml", "Secret_ns1_cluster1-ca.yaml", "empty.yaml"} {
+		g.Expect(w.Write(name, files[name])).To(Succeed())
+	}
+	g.Expect(w.Close()).To(Succeed())
+	g.Expect(IsEncrypted(buf.Bytes())).To(BeFalse())
+
+	got, err := ReadArchive(buf)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(HaveLen(3))
+	g.Expect(got[0]).To(Equal(files["Cluster_ns1_cluster1.yaml"]))
+	g.Expect(got[1]).To(Equal(files["Secret_ns1_cluster1-ca.yaml"]))
+	g.Expect(got[2]).To(BeEmpty())
+}
+
+func TestReadArchive_NotAnArchive(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := ReadArchive(bytes.NewReader([]byte(`{"kind":"Cluster"}`)))
+	g.Expect(err).To(HaveOccurred())
+}
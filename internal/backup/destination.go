@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Destination stores backup archives.
+type Destination interface {
+	// Write stores an archive with the given name, overwriting it if it already exists.
+	Write(name string, data []byte) error
+
+	// Read returns an archive with the given name.
+	Read(name string) ([]byte, error)
+
+	// Delete removes an archive with the given name; deleting an archive that does not exist is not an error.
+	Delete(name string) error
+}
+
+// s3Scheme is the URL scheme for archives stored in a S3 compatible object storage.
+const s3Scheme = "s3"
+
+// ParseLocation returns the destination and the archive name for a location, that can be either
+// a local file path or a s3://bucket/path/to/archive URL; the S3 options are used only for S3 locations.
+func ParseLocation(location string, s3Options S3Options) (Destination, string, error) {
+	if location == "" {
+		return nil, "", errors.New("the archive location must be defined")
+	}
+
+	if strings.HasPrefix(location, s3Scheme+"://") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "invalid archive location %q", location)
+		}
+		name := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || name == "" || strings.HasSuffix(name, "/") {
+			return nil, "", errors.Errorf("invalid archive location %q, it must be in the form s3://bucket/path/to/archive", location)
+		}
+		s3Options.Bucket = u.Host
+		destination, err := NewS3Destination(s3Options)
+		if err != nil {
+			return nil, "", err
+		}
+		return destination, name, nil
+	}
+
+	return NewLocalDestination(filepath.Dir(location)), filepath.Base(location), nil
+}
+
+// LocalDestination stores archives in a local directory.
+type LocalDestination struct {
+	directory string
+}
+
+var _ Destination = &LocalDestination{}
+
+// NewLocalDestination returns a destination storing archives in the given directory.
+func NewLocalDestination(directory string) *LocalDestination {
+	return &LocalDestination{directory: directory}
+}
+
+// Write stores an archive in the directory; the file is readable only by the current user.
+func (d *LocalDestination) Write(name string, data []byte) error {
+	path := filepath.Join(d.directory, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "failed to create the directory %q", filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the archive %q", path)
+	}
+	return nil
+}
+
+// Delete removes an archive from the directory.
+func (d *LocalDestination) Delete(name string) error {
+	path := filepath.Join(d.directory, name)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to delete the archive %q", path)
+	}
+	return nil
+}
+
+// Read returns an archive from the directory.
+func (d *LocalDestination) Read(name string) ([]byte, error) {
+	path := filepath.Join(d.directory, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the archive %q", path)
+	}
+	return data, nil
+}
@@ -0,0 +1,23 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup implements the archive format, the encryption and the destinations used by clusterctl
+// and by the ClusterBackupSchedule controller for storing backups of Cluster API objects.
+//
+// A backup archive is a gzip'd tar containing one file for each object; the archive can be encrypted
+// using envelope encryption, with a random file key wrapped for each age recipient or PGP key, and it can
+// be stored in a local directory or in a S3 compatible object storage.
+package backup
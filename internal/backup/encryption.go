@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+)
+
+// Encrypter encrypts backup archives.
+type Encrypter interface {
+	// Encrypt returns the encrypted data.
+	Encrypt(plaintext []byte) ([]byte, error)
+}
+
+// Decrypter decrypts backup archives.
+type Decrypter interface {
+	// Decrypt returns the decrypted data.
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// IsEncrypted returns true if the data is encrypted, i.e. it is not a gzip'd archive.
+func IsEncrypted(data []byte) bool {
+	return !bytes.HasPrefix(data, gzipMagic)
+}
+
+// gzipMagic is the header of gzip files.
+var gzipMagic = []byte{0x1f, 0x8b}
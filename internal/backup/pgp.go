@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// PGPEncrypter encrypts data for a list of PGP public keys.
+type PGPEncrypter struct {
+	keyRing openpgp.EntityList
+}
+
+var _ Encrypter = &PGPEncrypter{}
+
+// NewPGPEncrypter returns an encrypter for the PGP public keys in an armored key ring, e.g. as exported by gpg --export --armor.
+func NewPGPEncrypter(armoredKeyRing []byte) (*PGPEncrypter, error) {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKeyRing))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the PGP public keys")
+	}
+	if len(keyRing) == 0 {
+		return nil, errors.New("no PGP public keys found")
+	}
+	return &PGPEncrypter{keyRing: keyRing}, nil
+}
+
+// Encrypt returns the data encrypted as a binary PGP message.
+func (e *PGPEncrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	out := &bytes.Buffer{}
+	w, err := openpgp.Encrypt(out, e.keyRing, nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt with the PGP public keys")
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt with the PGP public keys")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt with the PGP public keys")
+	}
+	return out.Bytes(), nil
+}
+
+// PGPDecrypter decrypts data encrypted for PGP keys.
+type PGPDecrypter struct {
+	keyRing openpgp.EntityList
+}
+
+var _ Decrypter = &PGPDecrypter{}
+
+// NewPGPDecrypter returns a decrypter for the PGP private keys in an armored key ring, e.g. as exported by
+// gpg --export-secret-keys --armor; if the private keys are protected, the passphrase is used for decrypting them.
+func NewPGPDecrypter(armoredKeyRing []byte, passphrase string) (*PGPDecrypter, error) {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKeyRing))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the PGP private keys")
+	}
+
+	for _, entity := range keyRing {
+		if entity.PrivateKey == nil {
+			return nil, errors.Errorf("no PGP private key found for %s", entity.PrimaryKey.KeyIdString())
+		}
+		if err := decryptPGPPrivateKey(entity.PrivateKey, passphrase); err != nil {
+			return nil, err
+		}
+		for _, subkey := range entity.Subkeys {
+			if err := decryptPGPPrivateKey(subkey.PrivateKey, passphrase); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(keyRing) == 0 {
+		return nil, errors.New("no PGP private keys found")
+	}
+	return &PGPDecrypter{keyRing: keyRing}, nil
+}
+
+func decryptPGPPrivateKey(key *packet.PrivateKey, passphrase string) error {
+	if key == nil || !key.Encrypted {
+		return nil
+	}
+	if passphrase == "" {
+		return errors.Errorf("the PGP private key %s is protected by a passphrase", key.KeyIdString())
+	}
+	if err := key.Decrypt([]byte(passphrase)); err != nil {
+		return errors.Wrapf(err, "failed to decrypt the PGP private key %s", key.KeyIdString())
+	}
+	return nil
+}
+
+// Decrypt returns the data decrypted from a binary PGP message.
+func (d *PGPDecrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	md, err := openpgp.ReadMessage(bytes.NewReader(ciphertext), d.keyRing, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt with the PGP private keys")
+	}
+	plaintext, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt with the PGP private keys")
+	}
+	return plaintext, nil
+}
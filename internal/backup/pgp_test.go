@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	. "github.com/onsi/gomega"
+)
+
+// newTestPGPKey returns a new armored PGP public key and the corresponding armored private key.
+func newTestPGPKey(g *WithT) ([]byte, []byte) {
+	entity, err := openpgp.NewEntity("clusterctl", "test", "clusterctl@example.com", &packet.Config{RSABits: 2048})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	public := &bytes.Buffer{}
+	w, err := armor.Encode(public, openpgp.PublicKeyType, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entity.Serialize(w)).To(Succeed())
+	g.Expect(w.Close()).To(Succeed())
+
+	private := &bytes.Buffer{}
+	w, err = armor.Encode(private, openpgp.PrivateKeyType, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entity.SerializePrivate(w, nil)).To(Succeed())
+	g.Expect(w.Close()).To(Succeed())
+
+	return public.Bytes(), private.Bytes()
+}
+
+func TestPGP_EncryptDecrypt(t *testing.T) {
+	g := NewWithT(t)
+
+	publicKey, privateKey := newTestPGPKey(g)
+
+	e, err := NewPGPEncrypter(publicKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	ciphertext, err := e.Encrypt([]byte("hello clusterctl"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(IsEncrypted(ciphertext)).To(BeTrue())
+
+	d, err := NewPGPDecrypter(privateKey, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	plaintext, err := d.Decrypt(ciphertext)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(plaintext)).To(Equal("hello clusterctl"))
+
+	// A public key can't be used for decrypting.
+	_, err = NewPGPDecrypter(publicKey, "")
+	g.Expect(err).To(HaveOccurred())
+
+	// Another private key can't decrypt.
+	_, otherPrivateKey := newTestPGPKey(g)
+	d, err = NewPGPDecrypter(otherPrivateKey, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = d.Decrypt(ciphertext)
+	g.Expect(err).To(HaveOccurred())
+}
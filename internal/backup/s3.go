@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultS3Region is the region used when none is defined.
+	defaultS3Region = "us-east-1"
+
+	// s3Service is the service name used for signing the requests.
+	s3Service = "s3"
+)
+
+// S3Options are the options for connecting to a S3 compatible object storage.
+type S3Options struct {
+	// Endpoint is the URL of the object storage, e.g. https://minio.example.com:9000; if empty, the
+	// AWS endpoint for the region is used.
+	Endpoint string
+
+	// Region of the bucket; if empty, us-east-1 is used.
+	Region string
+
+	// Bucket storing the archives.
+	Bucket string
+
+	// AccessKeyID and SecretAccessKey are the credentials used for signing the requests.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is the optional token for temporary credentials.
+	SessionToken string
+
+	// HTTPClient is the client used for the requests; if nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// S3Destination stores archives in a bucket of a S3 compatible object storage, e.g. AWS S3 or MinIO.
+// NOTE: requests use path style URLs and are signed with AWS Signature Version 4.
+type S3Destination struct {
+	options  S3Options
+	endpoint *url.URL
+	signer   *v4.Signer
+	now      func() time.Time
+}
+
+var _ Destination = &S3Destination{}
+
+// NewS3Destination returns a destination storing archives in a S3 bucket.
+func NewS3Destination(options S3Options) (*S3Destination, error) {
+	if options.Bucket == "" {
+		return nil, errors.New("the S3 bucket must be defined")
+	}
+	if options.AccessKeyID == "" || options.SecretAccessKey == "" {
+		return nil, errors.New("the S3 access key id and secret access key must be defined")
+	}
+	if options.Region == "" {
+		options.Region = defaultS3Region
+	}
+	if options.Endpoint == "" {
+		options.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", options.Region)
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, errors.Errorf("invalid S3 endpoint %q", options.Endpoint)
+	}
+
+	return &S3Destination{
+		options:  options,
+		endpoint: endpoint,
+		// NOTE: S3 paths must be escaped only once, so the signer uses the path already escaped in the request.
+		signer: v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true }),
+		now:    time.Now,
+	}, nil
+}
+
+// Write uploads an archive to the bucket.
+func (d *S3Destination) Write(name string, data []byte) error {
+	if _, err := d.do(http.MethodPut, name, data); err != nil {
+		return errors.Wrapf(err, "failed to upload %q to the S3 bucket %q", name, d.options.Bucket)
+	}
+	return nil
+}
+
+// Read downloads an archive from the bucket.
+func (d *S3Destination) Read(name string) ([]byte, error) {
+	response, err := d.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download %q from the S3 bucket %q", name, d.options.Bucket)
+	}
+	return response, nil
+}
+
+// Delete removes an archive from the bucket.
+func (d *S3Destination) Delete(name string) error {
+	if _, err := d.do(http.MethodDelete, name, nil); err != nil {
+		return errors.Wrapf(err, "failed to delete %q from the S3 bucket %q", name, d.options.Bucket)
+	}
+	return nil
+}
+
+// do sends a signed request for an object in the bucket and returns the response body.
+func (d *S3Destination) do(method, name string, body []byte) ([]byte, error) {
+	u := *d.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + d.options.Bucket + "/" + strings.TrimPrefix(name, "/")
+	u.RawPath = s3EscapePath(u.Path)
+
+	request, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.ContentLength = int64(len(body))
+		request.Header.Set("Content-Type", "application/octet-stream")
+	}
+	if err := d.sign(request, body); err != nil {
+		return nil, errors.Wrap(err, "failed to sign the request")
+	}
+
+	response, err := d.options.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the response")
+	}
+	// NOTE: S3 returns 204 No Content when deleting objects, including objects that do not exist.
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return nil, errors.Errorf("unexpected response status %q: %s", response.Status, strings.TrimSpace(string(content)))
+	}
+	return content, nil
+}
+
+// sign adds the AWS Signature Version 4 authorization header to a request, signing the host and all the request headers.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+func (d *S3Destination) sign(request *http.Request, body []byte) error {
+	payloadHash := sha256.Sum256(body)
+	// NOTE: S3 requires the payload hash to be sent as a header, which is signed as well.
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	credentials := aws.Credentials{
+		AccessKeyID:     d.options.AccessKeyID,
+		SecretAccessKey: d.options.SecretAccessKey,
+		SessionToken:    d.options.SessionToken,
+	}
+	return d.signer.SignHTTP(context.Background(), credentials, request, hex.EncodeToString(payloadHash[:]), s3Service, d.options.Region, d.now().UTC())
+}
+
+// s3EscapePath escapes a path as required by AWS Signature Version 4, i.e. escaping all the characters
+// except the unreserved ones and the slashes.
+func s3EscapePath(path string) string {
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || strings.IndexByte("-_.~/", c) != -1 {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
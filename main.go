@@ -48,6 +48,8 @@ import (
 	expv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	expv1alpha4 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	backupv1 "sigs.k8s.io/cluster-api/exp/backup/api/v1beta1"
+	backupcontrollers "sigs.k8s.io/cluster-api/exp/backup/controllers"
 	expcontrollers "sigs.k8s.io/cluster-api/exp/controllers"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/version"
@@ -77,6 +79,7 @@ var (
 	machineDeploymentConcurrency  int
 	machinePoolConcurrency        int
 	clusterResourceSetConcurrency int
+	clusterBackupConcurrency      int
 	machineHealthCheckConcurrency int
 	syncPeriod                    time.Duration
 	webhookPort                   int
@@ -102,6 +105,8 @@ func init() {
 	_ = addonsv1alpha4.AddToScheme(scheme)
 	_ = addonsv1.AddToScheme(scheme)
 
+	_ = backupv1.AddToScheme(scheme)
+
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -152,6 +157,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&clusterResourceSetConcurrency, "clusterresourceset-concurrency", 10,
 		"Number of cluster resource sets to process simultaneously")
 
+	fs.IntVar(&clusterBackupConcurrency, "clusterbackupschedule-concurrency", 1,
+		"Number of cluster backup schedules to process simultaneously")
+
 	fs.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
 		"Number of machine health checks to process simultaneously")
 
@@ -375,6 +383,16 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		}
 	}
 
+	if feature.Gates.Enabled(feature.ClusterBackup) {
+		if err := (&backupcontrollers.ClusterBackupScheduleReconciler{
+			Client:           mgr.GetClient(),
+			WatchFilterValue: watchFilterValue,
+		}).SetupWithManager(ctx, mgr, concurrency(clusterBackupConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterBackupSchedule")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&controllers.MachineHealthCheckReconciler{
 		Client:           mgr.GetClient(),
 		Tracker:          tracker,